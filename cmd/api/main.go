@@ -4,22 +4,32 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
 	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/mohammadshabab/order-food-online/config"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/audit"
+	"github.com/mohammadshabab/order-food-online/internal/auth"
+	"github.com/mohammadshabab/order-food-online/internal/category"
 	"github.com/mohammadshabab/order-food-online/internal/db"
+	"github.com/mohammadshabab/order-food-online/internal/event"
 	"github.com/mohammadshabab/order-food-online/internal/health"
+	"github.com/mohammadshabab/order-food-online/internal/idempotency"
 	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/mohammadshabab/order-food-online/internal/loglevel"
+	"github.com/mohammadshabab/order-food-online/internal/metrics"
 	"github.com/mohammadshabab/order-food-online/internal/middleware"
 	"github.com/mohammadshabab/order-food-online/internal/order"
 	"github.com/mohammadshabab/order-food-online/internal/promo"
+	"github.com/mohammadshabab/order-food-online/internal/ratelimit"
+	"github.com/mohammadshabab/order-food-online/internal/restaurant"
+	"github.com/mohammadshabab/order-food-online/internal/schedule"
+	"github.com/mohammadshabab/order-food-online/internal/stream"
 
 	"github.com/mohammadshabab/order-food-online/internal/product"
 )
@@ -31,19 +41,27 @@ func main() {
 		log.Fatalf("failed to load config: %v", err)
 	}
 
-	// Initialize logger
-	var level slog.Level
-	switch strings.ToLower(cfg.LogLevel) {
-	case "debug":
-		level = slog.LevelDebug
-	case "warn", "warning":
-		level = slog.LevelWarn
-	case "error":
-		level = slog.LevelError
-	default:
-		level = slog.LevelInfo
+	// Initialize logger, levels can be changed at runtime through PUT /log-level
+	level, err := logger.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		log.Fatalf("invalid LOG_LEVEL: %v", err)
+	}
+	packageLevels, err := logger.ParsePackageLevels(cfg.LogLevels)
+	if err != nil {
+		log.Fatalf("invalid LOG_LEVELS: %v", err)
 	}
-	logger.Init(cfg.Service, cfg.Env, level)
+	err = logger.InitWithOptions(cfg.Service, cfg.Env, logger.Options{
+		Level:    level,
+		Packages: packageLevels,
+		Format:   cfg.LogFormat,
+		File:     cfg.LogFile,
+		MaxBytes: int64(cfg.LogFileMaxMB) * 1024 * 1024,
+		MaxFiles: cfg.LogFileMaxFiles,
+	})
+	if err != nil {
+		log.Fatalf("logger init failed: %v", err)
+	}
+	defer logger.Close()
 
 	// Initialize DB pool
 	if err := db.Connect(cfg); err != nil {
@@ -54,15 +72,94 @@ func main() {
 
 	e := echo.New()
 
-	// Apply API key middleware (required by OpenAPI spec for /order endpoint)
-	e.Use(middleware.NewAPIKeyMiddleware(*cfg))
+	// Handlers and middleware return errors, written as problem+json and logged in one place
+	e.HTTPErrorHandler = apperrors.HTTPErrorHandler
+
+	// Every request gets an X-Request-ID, carried by its logs, events and error responses
+	e.Use(middleware.NewRequestIDMiddleware())
+	// One structured log line per request, after the request ID is known
+	e.Use(middleware.NewAccessLogMiddleware(cfg.AccessLogHealthSample))
+	// Request counts and latencies per route and status for GET /metrics
+	e.Use(middleware.NewMetricsMiddleware())
+	// A panic becomes a 500 for this request only, logged with its stack
+	e.Use(middleware.NewRecoverMiddleware())
+
+	// Admin changes are appended to the audit log with the key or user that made them
+	auditRec := audit.Setup(e, audit.NewMariaDBRepository(), auth.Actor, auth.RequireScope(auth.ScopeAuditRead))
+
+	// API keys are stored hashed in the database, each with its scopes. Every route
+	// but the health checks needs a key; API_KEY is a bootstrap key that can only manage keys.
+	keySvc := auth.Setup(e, auth.NewMariaDBRepository(), auditRec)
+
+	// Customer apps authenticate with bearer JWTs instead, verified against a local JWKS file
+	if cfg.JWTJWKSFile != "" {
+		jwks, err := auth.LoadJWKS(cfg.JWTJWKSFile)
+		if err != nil {
+			logger.Log().Error("failed to load JWKS", "error", err)
+			log.Fatalf("jwks load failed: %v", err)
+		}
+		verifier := auth.NewVerifier(jwks, auth.VerifierConfig{
+			Issuer:   cfg.JWTIssuer,
+			Audience: cfg.JWTAudience,
+			Leeway:   time.Duration(cfg.JWTLeewaySec) * time.Second,
+		})
+		e.Use(middleware.NewJWTMiddleware(verifier))
+	}
+	e.Use(middleware.NewAPIKeyMiddleware(*cfg, keySvc))
+
+	// Token bucket rate limits per route and API key, user or IP, kept in memory per instance
+	defaultLimit, err := ratelimit.ParseLimit(cfg.RateLimitDefault)
+	if err != nil {
+		log.Fatalf("invalid RATE_LIMIT_DEFAULT: %v", err)
+	}
+	routeLimits, err := ratelimit.ParseRoutes(cfg.RateLimitRoutes)
+	if err != nil {
+		log.Fatalf("invalid RATE_LIMIT_ROUTES: %v", err)
+	}
+	if cfg.RateLimitTrustProxy {
+		e.IPExtractor = echo.ExtractIPFromXFFHeader()
+	} else {
+		e.IPExtractor = echo.ExtractIPDirect()
+	}
+	rateStore := ratelimit.NewMemoryStore()
+	rateCtx, stopRate := context.WithCancel(context.Background())
+	defer stopRate()
+	go ratelimit.RunJanitor(rateCtx, rateStore, time.Minute)
+	e.Use(ratelimit.Middleware(rateStore, ratelimit.Config{Default: defaultLimit, Routes: routeLimits}))
 
 	// Setup health check routes
 	health.Register(e)
 
-	// Product module
+	// Prometheus text format metrics, scraped with a metrics:read key
+	metrics.Register(e, auth.RequireScope(auth.ScopeMetricsRead))
+
+	// Log levels, changed at runtime with a logs:admin key, optionally for a while
+	loglevel.Setup(e, auditRec)
+
+	// Menu schedules and opening hours, checked by product listing and order creation
+	scheduleTTL := time.Duration(cfg.ScheduleCacheSec) * time.Second
+	clock := schedule.SystemClock{}
+	scheduleSvc := schedule.Setup(e, schedule.NewMariaDBRepository(), clock, scheduleTTL, auditRec)
+
+	// Product module, reads are cached in memory unless PRODUCT_CACHE_SEC is 0
 	productRepo := product.NewMariaDBRepository()
-	product.Setup(e, productRepo)
+	if cfg.ProductCacheSec > 0 {
+		productRepo = product.NewCachedRepository(productRepo, time.Duration(cfg.ProductCacheSec)*time.Second)
+	}
+	indexTTL := time.Duration(cfg.SearchIndexSec) * time.Second
+	productSvc := product.Setup(e, productRepo, scheduleSvc, indexTTL, auditRec)
+
+	// Scheduled price changes reach products.price within a minute
+	pricesCtx, stopPrices := context.WithCancel(context.Background())
+	defer stopPrices()
+	go product.RunPriceUpdates(pricesCtx, productSvc, time.Minute)
+
+	// Categories and the menu grouped by the category tree
+	category.Setup(e, category.NewMariaDBRepository(), productSvc, auditRec)
+
+	// Restaurants (vendors) and the coupons scoped to them
+	restaurantRepo := restaurant.NewMariaDBRepository()
+	restaurant.Setup(e, restaurantRepo, auditRec)
 
 	// Promo validator: load coupons from configs/coupons (create this folder and add your .gz files there)
 	fmt.Println("cfg.CouponDir ", cfg.CouponDir)
@@ -71,10 +168,39 @@ func main() {
 		logger.Log().Error("failed to load promo coupons", "error", promoErr)
 		log.Fatalf("promo validator load failed: %v", promoErr)
 	}
+	promoValidator.WithScopes(restaurantRepo)
+
+	// Live order updates over SSE, fed by order events
+	eventBroker := stream.NewBroker(cfg.SSEReplaySize)
+	stream.Setup(e, eventBroker, time.Duration(cfg.SSEHeartbeatSec)*time.Second)
+
+	// Optional JSONL event log for audits and local debugging
+	var publisher event.EventPublisher = eventBroker
+	if cfg.EventLogDir != "" {
+		eventLog, err := event.NewFileSink(event.FileSinkConfig{
+			Dir:      cfg.EventLogDir,
+			MaxBytes: int64(cfg.EventLogMaxMB) * 1024 * 1024,
+			MaxAge:   time.Duration(cfg.EventLogMaxAgeMin) * time.Minute,
+			Sync:     event.SyncMode(cfg.EventLogSync),
+		})
+		if err != nil {
+			logger.Log().Error("failed to open event log", "error", err)
+			log.Fatalf("event log open failed: %v", err)
+		}
+		defer eventLog.Close()
+		publisher = event.NewMultiPublisher(eventBroker, eventLog)
+	}
 
-	// Order module (pass promoValidator)
+	// Idempotency-Key support so client retries of POST /order do not create duplicates
+	idempotencyTTL := time.Duration(cfg.IdempotencyTTLHours) * time.Hour
+	idempotencyRepo := idempotency.NewMariaDBRepository()
+	janitorCtx, stopJanitor := context.WithCancel(context.Background())
+	defer stopJanitor()
+	go idempotency.RunJanitor(janitorCtx, idempotencyRepo, time.Hour)
+
+	// Order module (pass promoValidator and event publisher)
 	orderRepo := order.NewMariaDBRepository()
-	order.Setup(e, orderRepo, promoValidator)
+	order.Setup(e, orderRepo, productSvc, scheduleSvc, clock, promoValidator, publisher, idempotency.Middleware(idempotencyRepo, idempotencyTTL))
 
 	// Start server in a goroutine
 	go func() {
@@ -91,6 +217,10 @@ func main() {
 
 	// Graceful shutdown with 10 second timeout
 	logger.Log().Info("shutting down server gracefully")
+
+	// Close open event streams first, otherwise Shutdown waits on them until the timeout
+	eventBroker.Close()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/mohammadshabab/order-food-online/config"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/db"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/mohammadshabab/order-food-online/internal/product"
+)
+
+// catalog imports and exports the product catalog straight against the
+// database, using the same rules as POST /product/import and GET /product/export.
+// Database settings come from the same environment variables as the API.
+//
+//	catalog export -format csv > menu.csv
+//	catalog import -dry-run menu.csv
+//	catalog import menu.csv
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+	// keep stdout for the command output
+	logger.Init(cfg.Service, cfg.Env, slog.LevelWarn)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	switch os.Args[1] {
+	case "import":
+		err = runImport(ctx, cfg, os.Args[2:])
+	case "export":
+		err = runExport(ctx, cfg, os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		var appErr *apperrors.AppError
+		if errors.As(err, &appErr) && appErr.Details != nil {
+			details, _ := json.MarshalIndent(appErr.Details, "", "  ")
+			fmt.Fprintln(os.Stderr, string(details))
+		}
+		log.Fatalf("%s failed: %v", os.Args[1], err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: catalog import [-format csv|json] [-restaurant ID] [-dry-run] FILE")
+	fmt.Fprintln(os.Stderr, "       catalog export [-format csv|json] [-restaurant ID] [-out FILE]")
+	os.Exit(2)
+}
+
+func runImport(ctx context.Context, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	format := fs.String("format", "", "csv or json, taken from the file extension when empty")
+	restaurant := fs.String("restaurant", "", "id of the restaurant whose catalog the file holds, the default restaurant when empty")
+	dryRun := fs.Bool("dry-run", false, "only print what would be created, updated or archived")
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		usage()
+	}
+	path := fs.Arg(0)
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	svc, err := connect(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	res, err := svc.ImportProducts(ctx, *restaurant, *format, f, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(res); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "created %d, updated %d, archived %d, unchanged %d (dry run: %t)\n",
+		len(res.Created), len(res.Updated), len(res.Archived), res.Unchanged, res.DryRun)
+	return nil
+}
+
+func runExport(ctx context.Context, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", product.FormatJSON, "csv or json")
+	restaurant := fs.String("restaurant", "", "id of the restaurant to export, the default restaurant when empty")
+	out := fs.String("out", "", "write to this file instead of stdout")
+	_ = fs.Parse(args)
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	svc, err := connect(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return svc.ExportProducts(ctx, *restaurant, *format, w)
+}
+
+func connect(cfg *config.Config) (product.Service, error) {
+	if err := db.Connect(cfg); err != nil {
+		return nil, err
+	}
+	// the catalog never searches, the index is not built
+	return product.NewService(product.NewMariaDBRepository(), nil, 0), nil
+}
@@ -2,6 +2,8 @@ package config
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/sethvargo/go-envconfig"
 )
@@ -18,11 +20,59 @@ type Config struct {
 	DBMinConns int    `env:"DB_MIN_CONNS, default=2"`
 	DBConnLife int    `env:"DB_CONN_LIFETIME_MIN, default=30"`
 	LogLevel   string `env:"LOG_LEVEL, default=info"`
-	APIKey     string `env:"API_KEY, default=test"`
+	// Bootstrap key allowed to manage API keys only, disabled when empty
+	APIKey string `env:"API_KEY"`
+
+	// Per-package levels as comma separated <package>=<level> entries, e.g. "db=warn"
+	LogLevels string `env:"LOG_LEVELS"`
+	// json or text
+	LogFormat string `env:"LOG_FORMAT, default=json"`
+	// Also log to this file, rotated past LOG_FILE_MAX_MB, disabled when empty
+	LogFile         string `env:"LOG_FILE"`
+	LogFileMaxMB    int    `env:"LOG_FILE_MAX_MB, default=100"`
+	LogFileMaxFiles int    `env:"LOG_FILE_MAX_FILES, default=5"`
+
+	// Successful health checks are access logged 1 in N, 0 logs none of them
+	AccessLogHealthSample int `env:"ACCESS_LOG_HEALTH_SAMPLE, default=10"`
+
+	// Bearer JWTs for customer apps, disabled when JWT_JWKS_FILE is empty.
+	// Empty issuer or audience are not checked.
+	JWTJWKSFile  string `env:"JWT_JWKS_FILE"`
+	JWTIssuer    string `env:"JWT_ISSUER"`
+	JWTAudience  string `env:"JWT_AUDIENCE"`
+	JWTLeewaySec int    `env:"JWT_LEEWAY_SEC, default=30"`
 
 	CouponDir string `env:"COUPON_DIR, default=coupons"`
+
+	// Rate limits as <requests>/<period>, per route as comma separated
+	// "<METHOD> <path>=<limit>" entries; an empty default leaves other routes unlimited
+	RateLimitDefault string `env:"RATE_LIMIT_DEFAULT, default=600/m"`
+	RateLimitRoutes  string `env:"RATE_LIMIT_ROUTES, default=POST /order=30/m"`
+	// Take the client IP from X-Forwarded-For, only safe behind a proxy that sets it
+	RateLimitTrustProxy bool `env:"RATE_LIMIT_TRUST_PROXY, default=false"`
+
+	SSEHeartbeatSec int `env:"SSE_HEARTBEAT_SEC, default=15"`
+	SSEReplaySize   int `env:"SSE_REPLAY_SIZE, default=1000"`
+
+	IdempotencyTTLHours int `env:"IDEMPOTENCY_TTL_HOURS, default=24"`
+
+	// How long menu schedules are cached before they are re-read from the database
+	ScheduleCacheSec int `env:"SCHEDULE_CACHE_SEC, default=30"`
+	// How long product reads are cached, 0 disables the cache
+	ProductCacheSec int `env:"PRODUCT_CACHE_SEC, default=30"`
+	// How long the product search index is used before it is rebuilt from the database
+	SearchIndexSec int `env:"SEARCH_INDEX_SEC, default=60"`
+
+	// JSONL event log, disabled when EVENT_LOG_DIR is empty
+	EventLogDir       string `env:"EVENT_LOG_DIR"`
+	EventLogMaxMB     int    `env:"EVENT_LOG_MAX_MB, default=100"`
+	EventLogMaxAgeMin int    `env:"EVENT_LOG_MAX_AGE_MIN, default=1440"`
+	EventLogSync      string `env:"EVENT_LOG_FSYNC, default=rotate"`
 }
 
+// MinBootstrapKeyLength is the shortest API_KEY accepted in production
+const MinBootstrapKeyLength = 32
+
 func LoadConfig() (*Config, error) {
 	cfg := &Config{}
 	if err := envconfig.Process(context.Background(), cfg); err != nil {
@@ -32,6 +82,24 @@ func LoadConfig() (*Config, error) {
 	if cfg.DBConnLife <= 0 {
 		cfg.DBConnLife = 30
 	}
+	if err := cfg.checkBootstrapKey(); err != nil {
+		return nil, err
+	}
 
 	return cfg, nil
 }
+
+// checkBootstrapKey refuses to run production without a strong bootstrap key,
+// it is compared in plain text and cannot be revoked or expire
+func (c *Config) checkBootstrapKey() error {
+	if c.Env != "prod" {
+		return nil
+	}
+	if c.APIKey == "" {
+		return errors.New("API_KEY is required in prod, set it to a random value to create the first keys")
+	}
+	if len(c.APIKey) < MinBootstrapKeyLength {
+		return fmt.Errorf("API_KEY must be at least %d characters in prod", MinBootstrapKeyLength)
+	}
+	return nil
+}
@@ -20,6 +20,7 @@ func TestLoadConfig_WithEnvVars(t *testing.T) {
 	os.Setenv("DB_MIN_CONNS", "10")
 	os.Setenv("DB_CONN_LIFETIME_MIN", "15")
 	os.Setenv("LOG_LEVEL", "debug")
+	os.Setenv("API_KEY", "0123456789abcdef0123456789abcdef")
 	defer os.Clearenv()
 
 	cfg, err := LoadConfig()
@@ -55,6 +56,20 @@ func TestLoadConfig_Defaults(t *testing.T) {
 	require.Equal(t, 2, cfg.DBMinConns)
 	require.Equal(t, 30, cfg.DBConnLife) // default
 	require.Equal(t, "info", cfg.LogLevel)
+	require.Equal(t, "", cfg.APIKey)
+	require.Equal(t, "", cfg.LogLevels)
+	require.Equal(t, "json", cfg.LogFormat)
+	require.Equal(t, "", cfg.LogFile)
+	require.Equal(t, 100, cfg.LogFileMaxMB)
+	require.Equal(t, 5, cfg.LogFileMaxFiles)
+	require.Equal(t, 15, cfg.SSEHeartbeatSec)
+	require.Equal(t, 1000, cfg.SSEReplaySize)
+	require.Equal(t, "", cfg.EventLogDir)
+	require.Equal(t, "rotate", cfg.EventLogSync)
+	require.Equal(t, 24, cfg.IdempotencyTTLHours)
+	require.Equal(t, 30, cfg.ScheduleCacheSec)
+	require.Equal(t, 30, cfg.ProductCacheSec)
+	require.Equal(t, 10, cfg.AccessLogHealthSample)
 }
 
 func TestLoadConfig_InvalidConnLife_ShouldFallback(t *testing.T) {
@@ -67,3 +82,22 @@ func TestLoadConfig_InvalidConnLife_ShouldFallback(t *testing.T) {
 
 	require.Equal(t, 30, cfg.DBConnLife) // should fallback to default
 }
+
+func TestLoadConfig_BootstrapKeyInProd(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ENV", "prod")
+	defer os.Clearenv()
+
+	_, err := LoadConfig()
+	require.EqualError(t, err, "API_KEY is required in prod, set it to a random value to create the first keys")
+
+	os.Setenv("API_KEY", "test")
+	_, err = LoadConfig()
+	require.EqualError(t, err, "API_KEY must be at least 32 characters in prod")
+
+	// anything goes outside prod
+	os.Setenv("ENV", "dev")
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	require.Equal(t, "test", cfg.APIKey)
+}
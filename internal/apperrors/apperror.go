@@ -2,6 +2,7 @@ package apperrors
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 )
@@ -20,6 +21,20 @@ type AppError struct {
 	Message string `json:"message"`
 	Err     error  `json:"-"`
 	Level   Level  `json:"-"`
+	// Details carries structured context for the client, e.g. the items of an
+	// order that are out of stock. It is omitted from the response when nil.
+	Details any `json:"details,omitempty"`
+	// ErrorCode is the machine-readable code from the catalog, e.g.
+	// out_of_stock. Without one the generic code of the status is used.
+	ErrorCode string `json:"-"`
+	// Errors lists the field errors of a request that failed validation
+	Errors []FieldError `json:"errors,omitempty"`
+	// RequestID is the X-Request-ID of the request that failed, set when the
+	// error is written to the response
+	RequestID string `json:"requestId,omitempty"`
+	// Instance is the path of the request that failed, set when the error is
+	// written to the response
+	Instance string `json:"instance,omitempty"`
 }
 
 func (e *AppError) Error() string {
@@ -39,7 +54,8 @@ func Wrap(code int, msg string, level Level, err error) *AppError {
 	if err == nil {
 		return New(code, msg, level)
 	}
-	if ae, ok := err.(*AppError); ok {
+	var ae *AppError
+	if errors.As(err, &ae) {
 		return ae
 	}
 	return &AppError{Code: code, Message: msg, Err: err, Level: level}
@@ -58,14 +74,65 @@ func Unauthorized(msg string, err error) *AppError {
 func NotFound(msg string, err error) *AppError {
 	return Wrap(http.StatusNotFound, msg, LevelWarn, err)
 }
+func Conflict(msg string, err error) *AppError {
+	return Wrap(http.StatusConflict, msg, LevelWarn, err)
+}
 func Internal(msg string, err error) *AppError {
 	return Wrap(http.StatusInternalServerError, msg, LevelError, err)
 }
 
+// WithRequestID returns a copy of e carrying the request ID
+func (e *AppError) WithRequestID(id string) *AppError {
+	c := *e
+	c.RequestID = id
+	return &c
+}
+
+// WithDetails returns a copy of e carrying details, so shared sentinel errors stay untouched
+func (e *AppError) WithDetails(details any) *AppError {
+	c := *e
+	c.Details = details
+	return &c
+}
+
+// WithCode returns a copy of e carrying a code from the catalog
+func (e *AppError) WithCode(code string) *AppError {
+	c := *e
+	c.ErrorCode = code
+	return &c
+}
+
+// WithInstance returns a copy of e carrying the path of the failed request
+func (e *AppError) WithInstance(path string) *AppError {
+	c := *e
+	c.Instance = path
+	return &c
+}
+
+// MarshalJSON writes e as an RFC 7807 problem. The message is the detail, the
+// code names the type; code, requestId, errors and details are extensions.
 func (e *AppError) MarshalJSON() ([]byte, error) {
-	type out struct {
-		Code    int    `json:"code"`
-		Message string `json:"message"`
+	type problem struct {
+		Type      string       `json:"type"`
+		Title     string       `json:"title"`
+		Status    int          `json:"status"`
+		Detail    string       `json:"detail,omitempty"`
+		Instance  string       `json:"instance,omitempty"`
+		Code      string       `json:"code"`
+		RequestID string       `json:"requestId,omitempty"`
+		Errors    []FieldError `json:"errors,omitempty"`
+		Details   any          `json:"details,omitempty"`
 	}
-	return json.Marshal(out{Code: e.Code, Message: e.Message})
+	code := codeOf(e)
+	return json.Marshal(problem{
+		Type:      TypeBase + code,
+		Title:     titleOf(code, e.Code),
+		Status:    e.Code,
+		Detail:    e.Message,
+		Instance:  e.Instance,
+		Code:      code,
+		RequestID: e.RequestID,
+		Errors:    e.Errors,
+		Details:   e.Details,
+	})
 }
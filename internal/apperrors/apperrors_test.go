@@ -3,6 +3,7 @@ package apperrors
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"testing"
 
@@ -83,6 +84,10 @@ func TestHelpers(t *testing.T) {
 	nf := NotFound("missing", nil)
 	require.Equal(t, http.StatusNotFound, nf.Code)
 
+	conflict := Conflict("stale", nil)
+	require.Equal(t, http.StatusConflict, conflict.Code)
+	require.Equal(t, LevelWarn, conflict.Level)
+
 	intErr := Internal("fail", nil)
 	require.Equal(t, http.StatusInternalServerError, intErr.Code)
 	require.Equal(t, LevelError, intErr.Level)
@@ -102,13 +107,112 @@ func TestMarshalJSON(t *testing.T) {
 	require.NoError(t, err)
 
 	var parsed struct {
-		Code    int    `json:"code"`
-		Message string `json:"message"`
+		Type   string `json:"type"`
+		Title  string `json:"title"`
+		Status int    `json:"status"`
+		Detail string `json:"detail"`
+		Code   string `json:"code"`
 	}
 
 	err = json.Unmarshal(data, &parsed)
 	require.NoError(t, err)
 
-	require.Equal(t, 404, parsed.Code)
-	require.Equal(t, "not found", parsed.Message)
+	require.Equal(t, "urn:order-food-online:problem:not_found", parsed.Type)
+	require.Equal(t, "Not found", parsed.Title)
+	require.Equal(t, 404, parsed.Status)
+	require.Equal(t, "not found", parsed.Detail)
+	require.Equal(t, CodeNotFound, parsed.Code)
+}
+
+func TestMarshalJSON_Problem(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *AppError
+		want string
+	}{
+		{
+			name: "catalog code",
+			err:  Conflict("some items are out of stock", nil).WithCode(CodeOutOfStock).WithInstance("/orders"),
+			want: `{"type":"urn:order-food-online:problem:out_of_stock","title":"Out of stock","status":409,
+				"detail":"some items are out of stock","instance":"/orders","code":"out_of_stock"}`,
+		},
+		{
+			name: "status without generic code",
+			err:  New(418, "short and stout", LevelWarn),
+			want: `{"type":"urn:order-food-online:problem:bad_request","title":"Bad request","status":418,
+				"detail":"short and stout","code":"bad_request"}`,
+		},
+		{
+			name: "field errors",
+			err: func() *AppError {
+				var v Validation
+				v.Add(Pointer("items", 0, "quantity"), FieldOutOfRange, "quantity must be greater than 0")
+				v.Add(Pointer("items", 1, "productId"), FieldRequired, "product ID is required")
+				return v.Err(New(422, "validation failed", LevelWarn))
+			}(),
+			want: `{"type":"urn:order-food-online:problem:validation_failed","title":"Validation failed","status":422,
+				"detail":"validation failed","code":"validation_failed","errors":[
+				{"pointer":"/items/0/quantity","code":"out_of_range","detail":"quantity must be greater than 0"},
+				{"pointer":"/items/1/productId","code":"required","detail":"product ID is required"}]}`,
+		},
+		{
+			name: "no detail",
+			err:  New(500, "", LevelError),
+			want: `{"type":"urn:order-food-online:problem:internal","title":"Internal error","status":500,"code":"internal"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.err)
+			require.NoError(t, err)
+			require.JSONEq(t, tt.want, string(data))
+		})
+	}
+}
+
+func TestWithDetails(t *testing.T) {
+	base := Conflict("out of stock", nil)
+	withDetails := base.WithDetails(map[string]any{"items": []string{"p1"}})
+
+	require.Nil(t, base.Details)
+	require.Equal(t, base.Code, withDetails.Code)
+	require.Equal(t, base.Message, withDetails.Message)
+
+	data, err := json.Marshal(withDetails)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type":"urn:order-food-online:problem:conflict","title":"Conflict","status":409,
+		"detail":"out of stock","code":"conflict","details":{"items":["p1"]}}`, string(data))
+
+	data, err = json.Marshal(base)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type":"urn:order-food-online:problem:conflict","title":"Conflict","status":409,
+		"detail":"out of stock","code":"conflict"}`, string(data))
+}
+
+func TestWithRequestID(t *testing.T) {
+	base := NotFound("not found", nil)
+	withID := base.WithRequestID("req-1")
+
+	require.Empty(t, base.RequestID)
+
+	data, err := json.Marshal(withID)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type":"urn:order-food-online:problem:not_found","title":"Not found","status":404,
+		"detail":"not found","code":"not_found","requestId":"req-1"}`, string(data))
+}
+
+func TestWrap_WithWrappedAppError_ReturnsIt(t *testing.T) {
+	orig := NotFound("order not found", nil)
+	e := Wrap(500, "ignored", LevelError, fmt.Errorf("cancel order: %w", orig))
+	require.Same(t, orig, e)
+}
+
+func TestWithCode(t *testing.T) {
+	base := NotFound("order not found", nil)
+	withCode := base.WithCode(CodeOrderNotFound)
+
+	require.Empty(t, base.ErrorCode)
+	require.Equal(t, CodeOrderNotFound, withCode.ErrorCode)
+	require.Equal(t, base.Code, withCode.Code)
 }
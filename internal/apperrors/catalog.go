@@ -0,0 +1,155 @@
+package apperrors
+
+import "net/http"
+
+// TypeBase prefixes an error code to form the type URI of its problem
+const TypeBase = "urn:order-food-online:problem:"
+
+// Error codes are the stable, machine-readable part of an error response:
+// clients branch on the code, messages may change. Errors created without a
+// code get the generic code of their status.
+const (
+	// Generic codes, one per status
+	CodeBadRequest           = "bad_request"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodeNotFound             = "not_found"
+	CodeMethodNotAllowed     = "method_not_allowed"
+	CodeConflict             = "conflict"
+	CodePayloadTooLarge      = "payload_too_large"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeValidationFailed     = "validation_failed"
+	CodeTooManyRequests      = "too_many_requests"
+	CodeInternal             = "internal"
+	CodeServiceUnavailable   = "service_unavailable"
+
+	// Authentication
+	CodeInvalidToken      = "invalid_token"
+	CodeTokenExpired      = "token_expired"
+	CodeInsufficientScope = "insufficient_scope"
+	CodeAPIKeyNotFound    = "api_key_not_found"
+
+	// Catalog
+	CodeProductNotFound     = "product_not_found"
+	CodeRestaurantNotFound  = "restaurant_not_found"
+	CodeCategoryNotFound    = "category_not_found"
+	CodeVersionConflict     = "version_conflict"
+	CodeStockUntracked      = "stock_untracked"
+	CodeStockNegative       = "stock_negative"
+	CodeUnknownCategory     = "unknown_category"
+	CodeUnknownRestaurant   = "unknown_restaurant"
+	CodeUnknownParent       = "unknown_parent"
+	CodeSlugTaken           = "slug_taken"
+	CodeImportEmpty         = "import_empty"
+	CodeImportInvalid       = "import_invalid"
+	CodePriceChangeNotFound = "price_change_not_found"
+	CodePriceChangeApplied  = "price_change_applied"
+	CodePriceRuleNotFound   = "price_rule_not_found"
+	CodeRestaurantClosed    = "restaurant_closed"
+	CodeItemsUnavailable    = "items_unavailable"
+
+	// Orders and coupons
+	CodeOrderNotFound          = "order_not_found"
+	CodeOrderCancelled         = "order_cancelled"
+	CodeOutOfStock             = "out_of_stock"
+	CodeMixedRestaurants       = "mixed_restaurants"
+	CodeInvalidModifiers       = "invalid_modifiers"
+	CodeInvalidCoupon          = "invalid_coupon"
+	CodeCouponNotForRestaurant = "coupon_not_for_restaurant"
+	CodeCouponNotScoped        = "coupon_not_scoped"
+
+	// Idempotency-Key
+	CodeIdempotencyKeyInvalid  = "idempotency_key_invalid"
+	CodeIdempotencyKeyReused   = "idempotency_key_reused"
+	CodeIdempotencyKeyInFlight = "idempotency_key_in_flight"
+)
+
+// titles are the short summaries of the codes, the same for every occurrence
+var titles = map[string]string{
+	CodeBadRequest:           "Bad request",
+	CodeUnauthorized:         "Authentication required",
+	CodeForbidden:            "Forbidden",
+	CodeNotFound:             "Not found",
+	CodeMethodNotAllowed:     "Method not allowed",
+	CodeConflict:             "Conflict",
+	CodePayloadTooLarge:      "Payload too large",
+	CodeUnsupportedMediaType: "Unsupported media type",
+	CodeValidationFailed:     "Validation failed",
+	CodeTooManyRequests:      "Too many requests",
+	CodeInternal:             "Internal error",
+	CodeServiceUnavailable:   "Service unavailable",
+
+	CodeInvalidToken:      "Invalid bearer token",
+	CodeTokenExpired:      "Bearer token expired",
+	CodeInsufficientScope: "Insufficient scope",
+	CodeAPIKeyNotFound:    "API key not found",
+
+	CodeProductNotFound:     "Product not found",
+	CodeRestaurantNotFound:  "Restaurant not found",
+	CodeCategoryNotFound:    "Category not found",
+	CodeVersionConflict:     "Version conflict",
+	CodeStockUntracked:      "Stock not tracked",
+	CodeStockNegative:       "Stock below zero",
+	CodeUnknownCategory:     "Unknown category",
+	CodeUnknownRestaurant:   "Unknown restaurant",
+	CodeUnknownParent:       "Unknown parent category",
+	CodeSlugTaken:           "Slug already taken",
+	CodeImportEmpty:         "Empty import",
+	CodeImportInvalid:       "Invalid import",
+	CodePriceChangeNotFound: "Price change not found",
+	CodePriceChangeApplied:  "Price change already applied",
+	CodePriceRuleNotFound:   "Price rule not found",
+	CodeRestaurantClosed:    "Restaurant closed",
+	CodeItemsUnavailable:    "Items unavailable",
+
+	CodeOrderNotFound:          "Order not found",
+	CodeOrderCancelled:         "Order already cancelled",
+	CodeOutOfStock:             "Out of stock",
+	CodeMixedRestaurants:       "Items from several restaurants",
+	CodeInvalidModifiers:       "Invalid modifiers",
+	CodeInvalidCoupon:          "Invalid coupon",
+	CodeCouponNotForRestaurant: "Coupon not valid for this restaurant",
+	CodeCouponNotScoped:        "Coupon not scoped",
+
+	CodeIdempotencyKeyInvalid:  "Invalid Idempotency-Key",
+	CodeIdempotencyKeyReused:   "Idempotency-Key reused",
+	CodeIdempotencyKeyInFlight: "Idempotency-Key in flight",
+}
+
+// statusCodes are the generic codes of the statuses the API uses
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            CodeBadRequest,
+	http.StatusUnauthorized:          CodeUnauthorized,
+	http.StatusForbidden:             CodeForbidden,
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusMethodNotAllowed:      CodeMethodNotAllowed,
+	http.StatusConflict:              CodeConflict,
+	http.StatusRequestEntityTooLarge: CodePayloadTooLarge,
+	http.StatusUnsupportedMediaType:  CodeUnsupportedMediaType,
+	http.StatusUnprocessableEntity:   CodeValidationFailed,
+	http.StatusTooManyRequests:       CodeTooManyRequests,
+	http.StatusInternalServerError:   CodeInternal,
+	http.StatusServiceUnavailable:    CodeServiceUnavailable,
+}
+
+// codeOf returns the code of e, falling back to the generic code of its status
+func codeOf(e *AppError) string {
+	if e.ErrorCode != "" {
+		return e.ErrorCode
+	}
+	if code, ok := statusCodes[e.Code]; ok {
+		return code
+	}
+	if e.Code >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
+
+// titleOf returns the title of code, the status text for codes outside the catalog
+func titleOf(code string, status int) string {
+	if title, ok := titles[code]; ok {
+		return title
+	}
+	return http.StatusText(status)
+}
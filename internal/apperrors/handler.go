@@ -0,0 +1,110 @@
+package apperrors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+)
+
+// MIMEProblemJSON is the content type of error responses (RFC 7807)
+const MIMEProblemJSON = "application/problem+json"
+
+// From turns any error into an AppError. An AppError anywhere in the chain is
+// returned as is, echo errors (unknown route, wrong method, body too large)
+// keep their status and everything else is an internal error. Codes outside
+// the 4xx and 5xx ranges become 500, a returned error is never a success.
+func From(err error) *AppError {
+	var appErr *AppError
+	var httpErr *echo.HTTPError
+	switch {
+	case errors.As(err, &appErr):
+	case errors.As(err, &httpErr):
+		msg, ok := httpErr.Message.(string)
+		if !ok {
+			msg = http.StatusText(httpErr.Code)
+		}
+		level := LevelWarn
+		if httpErr.Code >= http.StatusInternalServerError {
+			level = LevelError
+		}
+		appErr = &AppError{Code: httpErr.Code, Message: msg, Err: httpErr.Internal, Level: level}
+	default:
+		return Internal(http.StatusText(http.StatusInternalServerError), err)
+	}
+
+	if appErr.Code < http.StatusBadRequest || appErr.Code > 599 {
+		c := *appErr
+		c.Code, c.Level = http.StatusInternalServerError, LevelError
+		return &c
+	}
+	return appErr
+}
+
+// HTTPErrorHandler is the echo.HTTPErrorHandler of the API: handlers and
+// middleware return errors and it writes them as application/problem+json
+// with the request path and ID, logged once at the level of the error. In
+// prod a 5xx only carries its type and title, elsewhere the cause is added to
+// the details to ease debugging.
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	appErr := From(err)
+	req := c.Request()
+	logError(req.Context(), appErr, "method", req.Method, "route", c.Path(), "path", req.URL.Path)
+
+	res := appErr
+	if appErr.Code >= http.StatusInternalServerError {
+		if logger.IsProd() {
+			res = &AppError{Code: appErr.Code, Level: appErr.Level, ErrorCode: appErr.ErrorCode}
+		} else if appErr.Err != nil && appErr.Details == nil {
+			res = appErr.WithDetails(map[string]string{"cause": appErr.Err.Error()})
+		}
+	}
+
+	if req.Method == http.MethodHead {
+		c.NoContent(res.Code)
+		return
+	}
+
+	res = res.WithInstance(req.URL.Path)
+	res.RequestID = logger.RequestID(req.Context())
+	body, err := json.Marshal(res)
+	if err == nil {
+		err = c.Blob(res.Code, MIMEProblemJSON, body)
+	}
+	if err != nil {
+		logger.Error(req.Context(), "failed to write error response", "error", err.Error())
+	}
+}
+
+func logError(ctx context.Context, e *AppError, kv ...any) {
+	kv = append(kv, "status", e.Code)
+	if e.Err != nil {
+		kv = append(kv, "error", e.Err.Error())
+	}
+
+	switch e.Level {
+	case LevelDebug:
+		logger.Debug(ctx, e.Message, kv...)
+	case LevelInfo:
+		logger.Info(ctx, e.Message, kv...)
+	case LevelWarn:
+		logger.Warn(ctx, e.Message, kv...)
+	default:
+		logger.Error(ctx, e.Message, kv...)
+	}
+}
+
+// Handle runs h the way Echo does, writing a returned error with
+// HTTPErrorHandler. Tests use it to check the responses of handlers.
+func Handle(h echo.HandlerFunc, c echo.Context) {
+	if err := h(c); err != nil {
+		HTTPErrorHandler(err, c)
+	}
+}
@@ -0,0 +1,127 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrom(t *testing.T) {
+	notFound := NotFound("product not found", nil)
+	assert.Same(t, notFound, From(notFound))
+	assert.Same(t, notFound, From(fmt.Errorf("get product: %w", notFound)))
+
+	e := From(echo.ErrMethodNotAllowed)
+	assert.Equal(t, http.StatusMethodNotAllowed, e.Code)
+	assert.Equal(t, "Method Not Allowed", e.Message)
+	assert.Equal(t, LevelWarn, e.Level)
+
+	e = From(errors.New("db down"))
+	assert.Equal(t, http.StatusInternalServerError, e.Code)
+	assert.Equal(t, LevelError, e.Level)
+
+	// a returned error is never a success
+	e = From(Debug("trace", nil))
+	assert.Equal(t, http.StatusInternalServerError, e.Code)
+	assert.Equal(t, "trace", e.Message)
+}
+
+func TestHTTPErrorHandler(t *testing.T) {
+	serve := func(method string, err error) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(httptest.NewRequest(method, "/product/42", nil), rec)
+		HTTPErrorHandler(err, c)
+		return rec
+	}
+
+	t.Run("app error keeps its code and details", func(t *testing.T) {
+		logger.Init("test-service", "test", 0)
+		rec := serve(http.MethodGet, fmt.Errorf("wrapped: %w", Conflict("out of stock", nil).WithDetails(map[string]any{"items": []string{"p1"}})))
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Equal(t, MIMEProblemJSON, rec.Header().Get(echo.HeaderContentType))
+		assert.JSONEq(t, `{"type":"urn:order-food-online:problem:conflict","title":"Conflict","status":409,"detail":"out of stock",
+			"instance":"/product/42","code":"conflict","details":{"items":["p1"]}}`, rec.Body.String())
+	})
+
+	t.Run("echo errors keep their status", func(t *testing.T) {
+		logger.Init("test-service", "test", 0)
+		rec := serve(http.MethodGet, echo.ErrNotFound)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.JSONEq(t, `{"type":"urn:order-food-online:problem:not_found","title":"Not found","status":404,"detail":"Not Found",
+			"instance":"/product/42","code":"not_found"}`, rec.Body.String())
+	})
+
+	t.Run("internal errors show their cause outside prod", func(t *testing.T) {
+		logger.Init("test-service", "dev", 0)
+		rec := serve(http.MethodGet, Internal("failed to fetch product", errors.New("connection refused")))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.JSONEq(t, `{"type":"urn:order-food-online:problem:internal","title":"Internal error","status":500,"detail":"failed to fetch product",
+			"instance":"/product/42","code":"internal","details":{"cause":"connection refused"}}`, rec.Body.String())
+	})
+
+	t.Run("internal errors are hidden in prod", func(t *testing.T) {
+		logger.Init("test-service", "prod", 0)
+		defer logger.Init("test-service", "test", 0)
+
+		rec := serve(http.MethodGet, Internal("failed to fetch product", errors.New("connection refused")))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.JSONEq(t, `{"type":"urn:order-food-online:problem:internal","title":"Internal error","status":500,
+			"instance":"/product/42","code":"internal"}`, rec.Body.String())
+
+		rec = serve(http.MethodGet, BadRequest("invalid ID supplied", errors.New("invalid UUID length: 2")))
+		assert.JSONEq(t, `{"type":"urn:order-food-online:problem:bad_request","title":"Bad request","status":400,"detail":"invalid ID supplied",
+			"instance":"/product/42","code":"bad_request"}`, rec.Body.String())
+	})
+
+	t.Run("catalog code survives in prod", func(t *testing.T) {
+		logger.Init("test-service", "prod", 0)
+		defer logger.Init("test-service", "test", 0)
+
+		rec := serve(http.MethodGet, Wrap(http.StatusServiceUnavailable, "promo cache not ready", LevelError, nil).WithCode(CodeServiceUnavailable))
+		assert.JSONEq(t, `{"type":"urn:order-food-online:problem:service_unavailable","title":"Service unavailable","status":503,
+			"instance":"/product/42","code":"service_unavailable"}`, rec.Body.String())
+	})
+
+	t.Run("request ID is added", func(t *testing.T) {
+		logger.Init("test-service", "test", 0)
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/product/42", nil)
+		req = req.WithContext(logger.WithRequestID(req.Context(), "req-1"))
+		HTTPErrorHandler(NotFound("product not found", nil), echo.New().NewContext(req, rec))
+		assert.Contains(t, rec.Body.String(), `"requestId":"req-1"`)
+	})
+
+	t.Run("head requests get no body", func(t *testing.T) {
+		logger.Init("test-service", "test", 0)
+		rec := serve(http.MethodHead, NotFound("product not found", nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Empty(t, rec.Body.String())
+	})
+
+	t.Run("committed responses are left alone", func(t *testing.T) {
+		logger.Init("test-service", "test", 0)
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/events/stream", nil), rec)
+		require.NoError(t, c.String(http.StatusOK, "data"))
+
+		HTTPErrorHandler(errors.New("stream closed"), c)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "data", rec.Body.String())
+	})
+}
+
+func TestHandle(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+
+	Handle(func(echo.Context) error { return Unauthorized("unauthorized", nil) }, c)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
@@ -0,0 +1,64 @@
+package apperrors
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Field error codes say what is wrong with a field
+const (
+	FieldRequired   = "required"
+	FieldInvalid    = "invalid"
+	FieldOutOfRange = "out_of_range"
+	FieldTooLong    = "too_long"
+	FieldDuplicate  = "duplicate"
+)
+
+// FieldError is a problem with one field of a request body. Pointer is a JSON
+// pointer (RFC 6901) to the field, e.g. /items/2/quantity.
+type FieldError struct {
+	Pointer string `json:"pointer"`
+	Code    string `json:"code"`
+	Detail  string `json:"detail"`
+}
+
+// Validation collects every field error of a request, so clients can show
+// all mistakes at once instead of one per round trip
+type Validation struct {
+	errs []FieldError
+}
+
+// Add records a field error
+func (v *Validation) Add(pointer, code, detail string) {
+	v.errs = append(v.errs, FieldError{Pointer: pointer, Code: code, Detail: detail})
+}
+
+// Err returns nil without field errors, otherwise a copy of base carrying
+// them. With a single field error its detail is the message.
+func (v *Validation) Err(base *AppError) *AppError {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	c := *base
+	c.Errors = v.errs
+	if len(v.errs) == 1 {
+		c.Message = v.errs[0].Detail
+	}
+	return &c
+}
+
+// Pointer builds a JSON pointer from field names and array indexes,
+// Pointer("items", 2, "quantity") is /items/2/quantity
+func Pointer(tokens ...any) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		b.WriteByte('/')
+		switch v := t.(type) {
+		case int:
+			b.WriteString(strconv.Itoa(v))
+		case string:
+			b.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(v))
+		}
+	}
+	return b.String()
+}
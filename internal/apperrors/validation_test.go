@@ -0,0 +1,60 @@
+package apperrors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPointer(t *testing.T) {
+	assert.Equal(t, "/items", Pointer("items"))
+	assert.Equal(t, "/items/2/quantity", Pointer("items", 2, "quantity"))
+	assert.Equal(t, "/groups/0/modifiers/1/name", Pointer("groups", 0, "modifiers", 1, "name"))
+	assert.Equal(t, "/a~1b/c~0d", Pointer("a/b", "c~d"))
+}
+
+func TestValidation_Err(t *testing.T) {
+	base := New(422, "validation failed", LevelWarn).WithCode(CodeValidationFailed)
+
+	t.Run("no field errors", func(t *testing.T) {
+		var v Validation
+		assert.Nil(t, v.Err(base))
+	})
+
+	t.Run("one field error is the message", func(t *testing.T) {
+		var v Validation
+		v.Add("/name", FieldRequired, "name is required")
+
+		err := v.Err(base)
+		assert.Equal(t, 422, err.Code)
+		assert.Equal(t, CodeValidationFailed, err.ErrorCode)
+		assert.Equal(t, "name is required", err.Message)
+		assert.Len(t, err.Errors, 1)
+	})
+
+	t.Run("several field errors keep the base message", func(t *testing.T) {
+		var v Validation
+		v.Add("/name", FieldRequired, "name is required")
+		v.Add("/price", FieldOutOfRange, "price must be greater than 0")
+
+		err := v.Err(base)
+		assert.Equal(t, "validation failed", err.Message)
+		assert.Equal(t, []FieldError{
+			{Pointer: "/name", Code: FieldRequired, Detail: "name is required"},
+			{Pointer: "/price", Code: FieldOutOfRange, Detail: "price must be greater than 0"},
+		}, err.Errors)
+		assert.Nil(t, base.Errors, "base must stay untouched")
+	})
+}
+
+func TestCatalog(t *testing.T) {
+	for status, code := range statusCodes {
+		assert.Contains(t, titles, code, "status %d", status)
+	}
+
+	assert.Equal(t, CodeOutOfStock, codeOf(Conflict("x", nil).WithCode(CodeOutOfStock)))
+	assert.Equal(t, CodeConflict, codeOf(Conflict("x", nil)))
+	assert.Equal(t, CodeInternal, codeOf(New(502, "x", LevelError)))
+	assert.Equal(t, CodeBadRequest, codeOf(New(418, "x", LevelWarn)))
+	assert.Equal(t, "Bad Gateway", titleOf(CodeInternal+"_unknown", 502))
+}
@@ -0,0 +1,62 @@
+package audit
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+)
+
+type Handler struct {
+	repo Repository
+}
+
+func NewHandler(repo Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+// ListEntries serves GET /audit
+func (h *Handler) ListEntries(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	params := ListParams{
+		Resource:   c.QueryParam("resource"),
+		ResourceID: c.QueryParam("resourceId"),
+		Actor:      c.QueryParam("actor"),
+		Limit:      defaultLimit,
+	}
+	if raw := c.QueryParam("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxLimit {
+			return apperrors.BadRequest("limit must be between 1 and 500", err)
+		}
+		params.Limit = n
+	}
+	if raw := c.QueryParam("beforeId"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n < 1 {
+			return apperrors.BadRequest("beforeId must be a positive number", err)
+		}
+		params.BeforeID = n
+	}
+
+	entries, err := h.repo.List(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	res := &EntryList{Items: entries}
+	if res.Items == nil {
+		res.Items = []*Entry{}
+	}
+	if len(entries) == params.Limit {
+		res.NextBeforeID = entries[len(entries)-1].ID
+	}
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_ListEntries(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+	at := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	list := func(repo Repository, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, target, nil), rec)
+		apperrors.Handle(NewHandler(repo).ListEntries, c)
+		return rec
+	}
+
+	t.Run("filters and defaults", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepository(ctrl)
+		repo.EXPECT().List(gomock.Any(), ListParams{Resource: "product", ResourceID: "p1", Actor: "key:k1", Limit: 50}).
+			Return([]*Entry{{ID: 7, OccurredAt: at, Actor: "key:k1", Action: "product.update", Resource: "product", ResourceID: "p1", After: []byte(`{"id":"p1"}`)}}, nil)
+
+		rec := list(repo, "/audit?resource=product&resourceId=p1&actor=key:k1")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"items":[{"id":7,"occurredAt":"2026-10-19T12:00:00Z","actor":"key:k1","action":"product.update","resource":"product","resourceId":"p1","after":{"id":"p1"}}]}`, rec.Body.String())
+	})
+
+	t.Run("full page links to older entries", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepository(ctrl)
+		repo.EXPECT().List(gomock.Any(), ListParams{BeforeID: 10, Limit: 2}).
+			Return([]*Entry{{ID: 9}, {ID: 8}}, nil)
+
+		rec := list(repo, "/audit?limit=2&beforeId=10")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"nextBeforeId":8`)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepository(ctrl)
+		repo.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+		rec := list(repo, "/audit")
+		assert.JSONEq(t, `{"items":[]}`, rec.Body.String())
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		rec := list(NewMockRepository(gomock.NewController(t)), "/audit?limit=501")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("invalid beforeId", func(t *testing.T) {
+		rec := list(NewMockRepository(gomock.NewController(t)), "/audit?beforeId=abc")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepository(ctrl)
+		repo.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, errors.New("db down"))
+
+		rec := list(repo, "/audit")
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
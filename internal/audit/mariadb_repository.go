@@ -0,0 +1,104 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/db"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+)
+
+type MariaDBRepository struct{}
+
+func NewMariaDBRepository() Repository {
+	return &MariaDBRepository{}
+}
+
+func (r *MariaDBRepository) Append(ctx context.Context, e *Entry) error {
+	query := `INSERT INTO audit_log (occurred_at, actor, actor_name, action, resource, resource_id, request_id, before_state, after_state)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	res, err := db.Pool.Exec(ctx, query, e.OccurredAt, e.Actor, e.ActorName, e.Action, e.Resource, e.ResourceID, e.RequestID,
+		nullJSON(e.Before), nullJSON(e.After))
+	if err != nil {
+		appErr := apperrors.Internal("failed to append audit entry", err)
+		logger.Error(ctx, appErr.Message, "action", e.Action, "error", err.Error())
+		return appErr
+	}
+
+	if id, err := res.LastInsertId(); err == nil {
+		e.ID = id
+	}
+	return nil
+}
+
+func (r *MariaDBRepository) List(ctx context.Context, params ListParams) ([]*Entry, error) {
+	var (
+		where []string
+		args  []any
+	)
+	if params.Resource != "" {
+		where = append(where, "resource = ?")
+		args = append(args, params.Resource)
+	}
+	if params.ResourceID != "" {
+		where = append(where, "resource_id = ?")
+		args = append(args, params.ResourceID)
+	}
+	if params.Actor != "" {
+		where = append(where, "actor = ?")
+		args = append(args, params.Actor)
+	}
+	if params.BeforeID > 0 {
+		where = append(where, "id < ?")
+		args = append(args, params.BeforeID)
+	}
+
+	query := `SELECT id, occurred_at, actor, actor_name, action, resource, resource_id, request_id, before_state, after_state FROM audit_log`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, params.Limit)
+
+	rows, err := db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		appErr := apperrors.Internal("failed to list audit entries", err)
+		logger.Error(ctx, appErr.Message, "error", err.Error())
+		return nil, appErr
+	}
+	defer rows.Close()
+
+	var entries []*Entry
+	for rows.Next() {
+		var (
+			e             Entry
+			before, after sql.NullString
+		)
+		if err := rows.Scan(&e.ID, &e.OccurredAt, &e.Actor, &e.ActorName, &e.Action, &e.Resource, &e.ResourceID, &e.RequestID, &before, &after); err != nil {
+			appErr := apperrors.Internal("failed to scan audit entry row", err)
+			logger.Error(ctx, appErr.Message, "error", err.Error())
+			return nil, appErr
+		}
+		if before.Valid {
+			e.Before = []byte(before.String)
+		}
+		if after.Valid {
+			e.After = []byte(after.String)
+		}
+		entries = append(entries, &e)
+	}
+	if err := rows.Err(); err != nil {
+		appErr := apperrors.Internal("failed to read audit entry rows", err)
+		logger.Error(ctx, appErr.Message, "error", err.Error())
+		return nil, appErr
+	}
+
+	return entries, nil
+}
+
+func nullJSON(data []byte) sql.NullString {
+	return sql.NullString{String: string(data), Valid: len(data) > 0}
+}
@@ -0,0 +1,79 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mohammadshabab/order-food-online/internal/db"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMariaDBRepository(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	db.Pool = db.NewTestPool(sqlDB)
+
+	repo := NewMariaDBRepository()
+	at := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	cols := []string{"id", "occurred_at", "actor", "actor_name", "action", "resource", "resource_id", "request_id", "before_state", "after_state"}
+
+	t.Run("append", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO audit_log").
+			WithArgs(at, "key:k1", "kiosk", "product.update", "product", "p1", "req-1", `{"price":5}`, nil).
+			WillReturnResult(sqlmock.NewResult(42, 1))
+
+		e := &Entry{OccurredAt: at, Actor: "key:k1", ActorName: "kiosk", Action: "product.update", Resource: "product",
+			ResourceID: "p1", RequestID: "req-1", Before: []byte(`{"price":5}`)}
+		require.NoError(t, repo.Append(ctx, e))
+		assert.Equal(t, int64(42), e.ID)
+	})
+
+	t.Run("append fails", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO audit_log").WillReturnError(errors.New("db failed"))
+
+		err := repo.Append(ctx, &Entry{})
+		assert.ErrorContains(t, err, "db failed")
+	})
+
+	t.Run("list with filters", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM audit_log WHERE resource = \\? AND resource_id = \\? AND actor = \\? AND id < \\? ORDER BY id DESC LIMIT \\?").
+			WithArgs("product", "p1", "key:k1", int64(10), 50).
+			WillReturnRows(sqlmock.NewRows(cols).
+				AddRow(9, at, "key:k1", "kiosk", "product.update", "product", "p1", "req-1", `{"price":5}`, `{"price":6}`).
+				AddRow(8, at, "key:k1", "kiosk", "product.create", "product", "p1", "", nil, `{"price":5}`))
+
+		entries, err := repo.List(ctx, ListParams{Resource: "product", ResourceID: "p1", Actor: "key:k1", BeforeID: 10, Limit: 50})
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, int64(9), entries[0].ID)
+		assert.JSONEq(t, `{"price":5}`, string(entries[0].Before))
+		assert.Nil(t, entries[1].Before)
+	})
+
+	t.Run("list all", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM audit_log ORDER BY id DESC LIMIT \\?").
+			WithArgs(50).
+			WillReturnRows(sqlmock.NewRows(cols))
+
+		entries, err := repo.List(ctx, ListParams{Limit: 50})
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("list fails", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM audit_log").WillReturnError(errors.New("db failed"))
+
+		_, err := repo.List(ctx, ListParams{Limit: 50})
+		assert.ErrorContains(t, err, "db failed")
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
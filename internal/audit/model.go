@@ -0,0 +1,40 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Entry is a row of the audit log: an admin action, who took it and the state
+// of the resource before and after. Entries are never changed or deleted.
+type Entry struct {
+	ID         int64     `json:"id"`
+	OccurredAt time.Time `json:"occurredAt"`
+	// Actor identifies the caller: key:<id>, key:bootstrap or user:<subject>
+	Actor string `json:"actor"`
+	// ActorName is the name of the API key or the subject of the bearer token
+	ActorName  string          `json:"actorName,omitempty"`
+	Action     string          `json:"action"`
+	Resource   string          `json:"resource"`
+	ResourceID string          `json:"resourceId,omitempty"`
+	RequestID  string          `json:"requestId,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+}
+
+// ListParams filters GET /audit. Entries come newest first; BeforeID pages
+// through older ones.
+type ListParams struct {
+	Resource   string
+	ResourceID string
+	Actor      string
+	BeforeID   int64
+	Limit      int
+}
+
+// EntryList is the response of GET /audit. NextBeforeID is set when there may
+// be older entries.
+type EntryList struct {
+	Items        []*Entry `json:"items"`
+	NextBeforeID int64    `json:"nextBeforeId,omitempty"`
+}
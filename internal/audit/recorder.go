@@ -0,0 +1,164 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+)
+
+// ActorFunc names the caller of a request: an identifier such as key:<id> and
+// a readable name. auth.Actor is the one used by the API.
+type ActorFunc func(ctx context.Context) (actor, name string)
+
+// Action describes how the requests of a route are audited
+type Action struct {
+	// Name of the action, e.g. product.update
+	Name     string
+	Resource string
+	// IDParam is the path parameter holding the resource ID. Without it the
+	// id field of the response is used, e.g. for creates.
+	IDParam string
+	// Before loads the state of the resource before the request, nil for creates
+	Before func(c echo.Context) (any, error)
+	// Redact lists top level response fields left out of the entry, e.g. secrets
+	Redact []string
+	// Skip leaves requests out that change nothing, e.g. dry runs
+	Skip func(c echo.Context) bool
+}
+
+// Recorder appends entries to the audit log. A nil Recorder records nothing,
+// which keeps route setup simple in tests.
+type Recorder struct {
+	repo  Repository
+	actor ActorFunc
+}
+
+func NewRecorder(repo Repository, actor ActorFunc) *Recorder {
+	return &Recorder{repo: repo, actor: actor}
+}
+
+// Record fills in the time, actor and request ID of e and appends it. Failures
+// are logged, the action they describe has already happened.
+func (r *Recorder) Record(ctx context.Context, e *Entry) {
+	if r == nil {
+		return
+	}
+	e.OccurredAt = time.Now().UTC()
+	if r.actor != nil {
+		e.Actor, e.ActorName = r.actor(ctx)
+	}
+	if e.Actor == "" {
+		e.Actor = "anonymous"
+	}
+	e.RequestID = logger.RequestID(ctx)
+
+	if err := r.repo.Append(ctx, e); err != nil {
+		logger.Error(ctx, "audit entry lost", "action", e.Action, "resource", e.Resource, "resourceId", e.ResourceID, "error", err.Error())
+	}
+}
+
+// Middleware audits the successful requests of a route: the state from
+// a.Before and the JSON response as the state after
+func (r *Recorder) Middleware(a Action) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if r == nil {
+			return next
+		}
+		return func(c echo.Context) error {
+			if a.Skip != nil && a.Skip(c) {
+				return next(c)
+			}
+
+			ctx := c.Request().Context()
+			var before json.RawMessage
+			if a.Before != nil {
+				// a failing load, e.g. an unknown resource, fails the request
+				// itself, which is then not audited
+				if state, err := a.Before(c); err == nil {
+					before = marshal(ctx, state)
+				}
+			}
+
+			rec := &bodyRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = rec
+			if err := next(c); err != nil {
+				return err
+			}
+
+			status := c.Response().Status
+			if status < http.StatusOK || status >= http.StatusMultipleChoices {
+				return nil
+			}
+
+			e := &Entry{Action: a.Name, Resource: a.Resource, Before: before}
+			if strings.HasPrefix(c.Response().Header().Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+				e.After = redact(rec.body.Bytes(), a.Redact)
+			}
+			if a.IDParam != "" {
+				e.ResourceID = c.Param(a.IDParam)
+			} else {
+				e.ResourceID = responseID(e.After)
+			}
+			r.Record(ctx, e)
+			return nil
+		}
+	}
+}
+
+// bodyRecorder keeps a copy of the response body
+type bodyRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func marshal(ctx context.Context, v any) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		logger.Warn(ctx, "failed to encode audit state", "error", err.Error())
+		return nil
+	}
+	return data
+}
+
+// redact drops fields from a JSON object, other JSON is kept as is
+func redact(body []byte, fields []string) json.RawMessage {
+	body = bytes.TrimSpace(body)
+	if len(fields) == 0 || len(body) == 0 {
+		return body
+	}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return body
+	}
+	for _, f := range fields {
+		delete(obj, f)
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return body
+	}
+	return data
+}
+
+// responseID returns the id field of a JSON object, IDs are UUID strings
+func responseID(body json.RawMessage) string {
+	var obj struct {
+		ID string `json:"id"`
+	}
+	if len(body) == 0 || json.Unmarshal(body, &obj) != nil {
+		return ""
+	}
+	return obj.ID
+}
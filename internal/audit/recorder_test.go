@@ -0,0 +1,153 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testActor(ctx context.Context) (string, string) {
+	return "key:kiosk", "kiosk"
+}
+
+func TestRecorder_Middleware(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+
+	serve := func(rec *Recorder, a Action, method, target string, h echo.HandlerFunc) *httptest.ResponseRecorder {
+		e := echo.New()
+		e.Add(method, "/product", h, rec.Middleware(a))
+		e.Add(method, "/product/:productId", h, rec.Middleware(a))
+		req := httptest.NewRequest(method, target, nil)
+		req = req.WithContext(logger.WithRequestID(req.Context(), "req-1"))
+		res := httptest.NewRecorder()
+		e.ServeHTTP(res, req)
+		return res
+	}
+
+	t.Run("update with before and after", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepository(ctrl)
+		repo.EXPECT().Append(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *Entry) error {
+			assert.Equal(t, "product.update", e.Action)
+			assert.Equal(t, "product", e.Resource)
+			assert.Equal(t, "p1", e.ResourceID)
+			assert.Equal(t, "key:kiosk", e.Actor)
+			assert.Equal(t, "kiosk", e.ActorName)
+			assert.Equal(t, "req-1", e.RequestID)
+			assert.False(t, e.OccurredAt.IsZero())
+			assert.JSONEq(t, `{"id":"p1","price":5}`, string(e.Before))
+			assert.JSONEq(t, `{"id":"p1","price":6}`, string(e.After))
+			return nil
+		})
+
+		a := Action{Name: "product.update", Resource: "product", IDParam: "productId", Before: func(c echo.Context) (any, error) {
+			return map[string]any{"id": c.Param("productId"), "price": 5}, nil
+		}}
+		res := serve(NewRecorder(repo, testActor), a, http.MethodPatch, "/product/p1", func(c echo.Context) error {
+			return c.JSON(http.StatusOK, map[string]any{"id": "p1", "price": 6})
+		})
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.JSONEq(t, `{"id":"p1","price":6}`, res.Body.String())
+	})
+
+	t.Run("create takes the id from the response and redacts fields", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepository(ctrl)
+		repo.EXPECT().Append(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *Entry) error {
+			assert.Equal(t, "k1", e.ResourceID)
+			assert.Nil(t, e.Before)
+			assert.JSONEq(t, `{"id":"k1"}`, string(e.After))
+			return nil
+		})
+
+		a := Action{Name: "api_key.create", Resource: "api_key", Redact: []string{"key"}}
+		res := serve(NewRecorder(repo, testActor), a, http.MethodPost, "/product", func(c echo.Context) error {
+			return c.JSON(http.StatusCreated, map[string]any{"id": "k1", "key": "secret"})
+		})
+		assert.Equal(t, http.StatusCreated, res.Code)
+		assert.Contains(t, res.Body.String(), "secret")
+	})
+
+	t.Run("delete without a body", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepository(ctrl)
+		repo.EXPECT().Append(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *Entry) error {
+			assert.Equal(t, "p1", e.ResourceID)
+			assert.Nil(t, e.After)
+			return nil
+		})
+
+		a := Action{Name: "product.archive", Resource: "product", IDParam: "productId"}
+		res := serve(NewRecorder(repo, nil), a, http.MethodDelete, "/product/p1", func(c echo.Context) error {
+			return c.NoContent(http.StatusNoContent)
+		})
+		assert.Equal(t, http.StatusNoContent, res.Code)
+	})
+
+	t.Run("failed requests are not audited", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepository(ctrl)
+
+		a := Action{Name: "product.update", Resource: "product", IDParam: "productId", Before: func(echo.Context) (any, error) {
+			return nil, errors.New("not found")
+		}}
+		res := serve(NewRecorder(repo, testActor), a, http.MethodPatch, "/product/p1", func(c echo.Context) error {
+			return c.JSON(http.StatusNotFound, map[string]any{"code": 404})
+		})
+		assert.Equal(t, http.StatusNotFound, res.Code)
+	})
+
+	t.Run("skipped requests are not audited", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepository(ctrl)
+
+		a := Action{Name: "product.import", Resource: "product", Skip: func(echo.Context) bool { return true }}
+		res := serve(NewRecorder(repo, testActor), a, http.MethodPost, "/product", func(c echo.Context) error {
+			return c.JSON(http.StatusOK, map[string]any{"created": 1})
+		})
+		assert.Equal(t, http.StatusOK, res.Code)
+	})
+
+	t.Run("append failure does not fail the request", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepository(ctrl)
+		repo.EXPECT().Append(gomock.Any(), gomock.Any()).Return(errors.New("db down"))
+
+		a := Action{Name: "category.create", Resource: "category"}
+		res := serve(NewRecorder(repo, testActor), a, http.MethodPost, "/product", func(c echo.Context) error {
+			return c.JSON(http.StatusCreated, map[string]any{"id": "c1"})
+		})
+		assert.Equal(t, http.StatusCreated, res.Code)
+	})
+
+	t.Run("nil recorder passes through", func(t *testing.T) {
+		var rec *Recorder
+		res := serve(rec, Action{Name: "category.create"}, http.MethodPost, "/product", func(c echo.Context) error {
+			return c.JSON(http.StatusCreated, map[string]any{"id": "c1"})
+		})
+		assert.Equal(t, http.StatusCreated, res.Code)
+	})
+}
+
+func TestRecorder_Record(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctrl := gomock.NewController(t)
+	repo := NewMockRepository(ctrl)
+	repo.EXPECT().Append(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *Entry) error {
+		assert.Equal(t, "anonymous", e.Actor)
+		return nil
+	})
+
+	NewRecorder(repo, func(context.Context) (string, string) { return "", "" }).Record(context.Background(), &Entry{Action: "x"})
+
+	var nilRec *Recorder
+	require.NotPanics(t, func() { nilRec.Record(context.Background(), &Entry{}) })
+}
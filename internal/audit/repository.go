@@ -0,0 +1,12 @@
+package audit
+
+import "context"
+
+// Repository is append-only on purpose, entries cannot be changed or removed
+//
+//go:generate mockgen -source=repository.go -destination=mock_repository.go -package=audit
+type Repository interface {
+	Append(ctx context.Context, e *Entry) error
+	// List returns the entries matching params, newest first
+	List(ctx context.Context, params ListParams) ([]*Entry, error)
+}
@@ -0,0 +1,13 @@
+package audit
+
+import "github.com/labstack/echo/v4"
+
+// Setup registers GET /audit, guarded by routeMiddleware (e.g. a scope check),
+// and returns the recorder the admin routes of the other modules are audited with
+func Setup(e *echo.Echo, repo Repository, actor ActorFunc, routeMiddleware ...echo.MiddlewareFunc) *Recorder {
+	h := NewHandler(repo)
+
+	e.GET("/audit", h.ListEntries, routeMiddleware...)
+
+	return NewRecorder(repo, actor)
+}
@@ -0,0 +1,29 @@
+package audit
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+)
+
+func TestSetup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	e := echo.New()
+	if rec := Setup(e, NewMockRepository(ctrl), nil); rec == nil {
+		t.Fatal("expected a recorder")
+	}
+
+	found := false
+	for _, r := range e.Routes() {
+		if r.Method == http.MethodGet && r.Path == "/audit" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected GET /audit to be registered but it was not")
+	}
+}
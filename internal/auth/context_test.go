@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireScope(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+
+	next := func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	}
+	serve := func(k *Key) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/order", nil)
+		if k != nil {
+			req = req.WithContext(NewContext(req.Context(), k))
+		}
+		rec := httptest.NewRecorder()
+		apperrors.Handle(RequireScope(ScopeOrdersWrite)(next), e.NewContext(req, rec))
+		return rec
+	}
+
+	t.Run("key with the scope", func(t *testing.T) {
+		rec := serve(&Key{Scopes: []string{ScopeCatalogRead, ScopeOrdersWrite}})
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("key without the scope", func(t *testing.T) {
+		rec := serve(&Key{Scopes: []string{ScopeCatalogRead}})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.JSONEq(t, `{"type":"urn:order-food-online:problem:insufficient_scope","title":"Insufficient scope","status":403,
+			"detail":"api key lacks the required scope","instance":"/order","code":"insufficient_scope","details":{"scope":"orders:write"}}`, rec.Body.String())
+	})
+
+	t.Run("user whose roles grant the scope", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/order", nil)
+		req = req.WithContext(NewUserContext(req.Context(), &User{Subject: "user-42", Roles: []string{"customer"}}))
+		rec := httptest.NewRecorder()
+		apperrors.Handle(RequireScope(ScopeOrdersWrite)(next), e.NewContext(req, rec))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("no key", func(t *testing.T) {
+		rec := serve(nil)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
+
+func TestActor(t *testing.T) {
+	ctx := context.Background()
+
+	actor, name := Actor(NewContext(ctx, &Key{ID: keyID1, Name: "kiosk"}))
+	assert.Equal(t, "key:"+keyID1, actor)
+	assert.Equal(t, "kiosk", name)
+
+	actor, name = Actor(NewContext(ctx, &Key{Name: "bootstrap"}))
+	assert.Equal(t, "key:bootstrap", actor)
+	assert.Equal(t, "bootstrap", name)
+
+	actor, name = Actor(NewUserContext(ctx, &User{Subject: "cust-1"}))
+	assert.Equal(t, "user:cust-1", actor)
+	assert.Equal(t, "cust-1", name)
+
+	actor, name = Actor(ctx)
+	assert.Empty(t, actor)
+	assert.Empty(t, name)
+}
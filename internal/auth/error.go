@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+)
+
+var (
+	ErrUnauthorized = apperrors.Unauthorized("unauthorized", nil)
+	ErrForbidden    = apperrors.Wrap(http.StatusForbidden, "api key lacks the required scope", apperrors.LevelWarn, nil).WithCode(apperrors.CodeInsufficientScope)
+	ErrKeyNotFound  = apperrors.NotFound("api key not found", nil).WithCode(apperrors.CodeAPIKeyNotFound)
+	ErrInvalidToken = apperrors.Unauthorized("invalid token", nil).WithCode(apperrors.CodeInvalidToken)
+	ErrTokenExpired = apperrors.Unauthorized("token expired", nil).WithCode(apperrors.CodeTokenExpired)
+)
+
+const maxNameLength = 100
+
+// base of the field errors of a request, the message is used when there are several
+var errInvalidKey = apperrors.BadRequest("invalid api key request", nil).WithCode(apperrors.CodeValidationFailed)
+
+// Validate checks the request and removes duplicate scopes
+func (r *KeyReq) Validate(now time.Time) *apperrors.AppError {
+	var v apperrors.Validation
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		v.Add(apperrors.Pointer("name"), apperrors.FieldRequired, "name is required")
+	} else if len(r.Name) > maxNameLength {
+		v.Add(apperrors.Pointer("name"), apperrors.FieldTooLong, "name must be at most 100 characters")
+	}
+
+	if len(r.Scopes) == 0 {
+		v.Add(apperrors.Pointer("scopes"), apperrors.FieldRequired, "at least one scope is required")
+	}
+	scopes := make([]string, 0, len(r.Scopes))
+	for i, s := range r.Scopes {
+		if !slices.Contains(AllScopes, s) {
+			v.Add(apperrors.Pointer("scopes", i), apperrors.FieldInvalid, "unknown scope "+s+", expected one of "+strings.Join(AllScopes, ", "))
+			continue
+		}
+		if !slices.Contains(scopes, s) {
+			scopes = append(scopes, s)
+		}
+	}
+	r.Scopes = scopes
+
+	if r.ExpiresAt != nil && !r.ExpiresAt.After(now) {
+		v.Add(apperrors.Pointer("expiresAt"), apperrors.FieldOutOfRange, "expiresAt must be in the future")
+	}
+	return v.Err(errInvalidKey)
+}
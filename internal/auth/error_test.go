@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyReq_Validate(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		req     KeyReq
+		scopes  []string
+		message string
+	}{
+		{name: "valid", req: KeyReq{Name: " kiosk ", Scopes: []string{ScopeOrdersWrite, ScopeCatalogRead}, ExpiresAt: &future}, scopes: []string{ScopeOrdersWrite, ScopeCatalogRead}},
+		{name: "duplicate scopes", req: KeyReq{Name: "kiosk", Scopes: []string{ScopeOrdersWrite, ScopeOrdersWrite}}, scopes: []string{ScopeOrdersWrite}},
+		{name: "missing name", req: KeyReq{Scopes: []string{ScopeOrdersWrite}}, message: "name is required"},
+		{name: "long name", req: KeyReq{Name: strings.Repeat("k", 101), Scopes: []string{ScopeOrdersWrite}}, message: "name must be at most 100 characters"},
+		{name: "missing scopes", req: KeyReq{Name: "kiosk"}, message: "at least one scope is required"},
+		{name: "unknown scope", req: KeyReq{Name: "kiosk", Scopes: []string{"orders:delete"}}, message: "unknown scope orders:delete, expected one of catalog:read, products:admin, orders:write, orders:cancel, orders:read, keys:admin, audit:read, metrics:read, logs:admin"},
+		{name: "expired", req: KeyReq{Name: "kiosk", Scopes: []string{ScopeOrdersWrite}, ExpiresAt: &past}, message: "expiresAt must be in the future"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			appErr := req.Validate(now)
+			if tt.message == "" {
+				assert.Nil(t, appErr)
+				assert.Equal(t, "kiosk", req.Name)
+				assert.Equal(t, tt.scopes, req.Scopes)
+				return
+			}
+			assert.Equal(t, tt.message, appErr.Message)
+		})
+	}
+}
+
+func TestKeyReq_Validate_CollectsFieldErrors(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	req := KeyReq{Scopes: []string{ScopeOrdersWrite, "orders:delete"}, ExpiresAt: &now}
+
+	err := req.Validate(now)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, apperrors.CodeValidationFailed, err.ErrorCode)
+		assert.Equal(t, "invalid api key request", err.Message)
+		pointers := make([]string, 0, len(err.Errors))
+		for _, fe := range err.Errors {
+			pointers = append(pointers, fe.Pointer)
+		}
+		assert.Equal(t, []string{"/name", "/scopes/1", "/expiresAt"}, pointers)
+	}
+}
+
+func TestKey_Active(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	assert.True(t, (&Key{}).Active(now))
+	assert.True(t, (&Key{ExpiresAt: &future}).Active(now))
+	assert.False(t, (&Key{ExpiresAt: &past}).Active(now))
+	assert.False(t, (&Key{RevokedAt: &past}).Active(now))
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+)
+
+type Handler struct {
+	svc Service
+}
+
+func NewHandler(svc Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+func (h *Handler) ListKeys(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	res, err := h.svc.ListKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) CreateKey(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req KeyReq
+	if err := c.Bind(&req); err != nil {
+		return apperrors.BadRequest("invalid api key request", err)
+	}
+
+	res, err := h.svc.CreateKey(ctx, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, res)
+}
+
+// RevokeKey serves DELETE /api-key/{keyId}
+func (h *Handler) RevokeKey(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, appErr := keyID(c)
+	if appErr != nil {
+		return appErr
+	}
+
+	if err := h.svc.RevokeKey(ctx, id); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RotateKey serves POST /api-key/{keyId}/rotate
+func (h *Handler) RotateKey(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, appErr := keyID(c)
+	if appErr != nil {
+		return appErr
+	}
+
+	res, err := h.svc.RotateKey(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func keyID(c echo.Context) (string, *apperrors.AppError) {
+	id := c.Param("keyId")
+	if _, err := uuid.Parse(id); err != nil {
+		appErr := apperrors.BadRequest("invalid ID supplied", err)
+		logger.Warn(c.Request().Context(), appErr.Message, "id", id)
+		return "", appErr
+	}
+	return id, nil
+}
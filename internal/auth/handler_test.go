@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+var createdAt = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+func TestHandler_ListKeys(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+
+	t.Run("success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().ListKeys(gomock.Any()).
+			Return(&KeyList{Items: []*Key{{ID: keyID1, Name: "kiosk", Prefix: "0123456789ab", Scopes: []string{ScopeOrdersWrite}, CreatedAt: createdAt}}}, nil)
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/api-key", nil), rec)
+
+		apperrors.Handle(NewHandler(mockSvc).ListKeys, c)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"items":[{"id":"`+keyID1+`","name":"kiosk","prefix":"0123456789ab","scopes":["orders:write"],"createdAt":"2026-10-19T12:00:00Z"}]}`, rec.Body.String())
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().ListKeys(gomock.Any()).Return(nil, errors.New("db down"))
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/api-key", nil), rec)
+
+		apperrors.Handle(NewHandler(mockSvc).ListKeys, c)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestHandler_CreateKey(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+
+	post := func(h *Handler, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api-key", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		apperrors.Handle(h.CreateKey, e.NewContext(req, rec))
+		return rec
+	}
+
+	t.Run("success returns the key once", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().CreateKey(gomock.Any(), &KeyReq{Name: "kiosk", Scopes: []string{ScopeOrdersWrite}}).
+			Return(&IssuedKey{Key: Key{ID: keyID1, Name: "kiosk", Prefix: "0123456789ab", Scopes: []string{ScopeOrdersWrite}, CreatedAt: createdAt}, Secret: "ofo_0123456789ab_secret"}, nil)
+
+		rec := post(NewHandler(mockSvc), `{"name":"kiosk","scopes":["orders:write"]}`)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.JSONEq(t, `{"id":"`+keyID1+`","name":"kiosk","prefix":"0123456789ab","scopes":["orders:write"],"createdAt":"2026-10-19T12:00:00Z","key":"ofo_0123456789ab_secret"}`, rec.Body.String())
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		rec := post(NewHandler(NewMockService(ctrl)), `{"name":`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().CreateKey(gomock.Any(), gomock.Any()).Return(nil, (&KeyReq{Name: "kiosk"}).Validate(createdAt))
+
+		rec := post(NewHandler(mockSvc), `{"name":"kiosk"}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestHandler_RevokeKey(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+
+	revoke := func(h *Handler, id string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodDelete, "/api-key/"+id, nil), rec)
+		c.SetParamNames("keyId")
+		c.SetParamValues(id)
+		apperrors.Handle(h.RevokeKey, c)
+		return rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().RevokeKey(gomock.Any(), keyID1).Return(nil)
+
+		assert.Equal(t, http.StatusNoContent, revoke(NewHandler(mockSvc), keyID1).Code)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().RevokeKey(gomock.Any(), keyID1).Return(ErrKeyNotFound)
+
+		assert.Equal(t, http.StatusNotFound, revoke(NewHandler(mockSvc), keyID1).Code)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		assert.Equal(t, http.StatusBadRequest, revoke(NewHandler(NewMockService(ctrl)), "abc").Code)
+	})
+}
+
+func TestHandler_RotateKey(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+
+	rotate := func(h *Handler, id string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodPost, "/api-key/"+id+"/rotate", nil), rec)
+		c.SetParamNames("keyId")
+		c.SetParamValues(id)
+		apperrors.Handle(h.RotateKey, c)
+		return rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().RotateKey(gomock.Any(), keyID1).
+			Return(&IssuedKey{Key: Key{ID: keyID1, Prefix: "ba9876543210"}, Secret: "ofo_ba9876543210_secret"}, nil)
+
+		rec := rotate(NewHandler(mockSvc), keyID1)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"key":"ofo_ba9876543210_secret"`)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().RotateKey(gomock.Any(), keyID1).Return(nil, ErrKeyNotFound)
+
+		assert.Equal(t, http.StatusNotFound, rotate(NewHandler(mockSvc), keyID1).Code)
+	})
+}
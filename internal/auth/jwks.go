@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// Signing algorithms accepted for bearer tokens
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+)
+
+// jwk is a key of a JSON Web Key Set. Only the members of oct (HMAC) and RSA keys are read.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	K   string `json:"k"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// verificationKey is a parsed JWKS key: an HMAC secret for HS256 or an RSA
+// public key for RS256
+type verificationKey struct {
+	kid    string
+	alg    string
+	secret []byte
+	public *rsa.PublicKey
+}
+
+// KeySet holds the keys bearer tokens are verified with
+type KeySet struct {
+	keys []verificationKey
+}
+
+// LoadJWKS reads a JSON Web Key Set from a local file
+func LoadJWKS(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKS(data)
+}
+
+// ParseJWKS parses a JSON Web Key Set. Keys meant for encryption are skipped;
+// a key without alg gets HS256 for kty oct and RS256 for kty RSA.
+func ParseJWKS(data []byte) (*KeySet, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	set := &KeySet{}
+	for i, k := range doc.Keys {
+		if k.Use == "enc" {
+			continue
+		}
+		key, err := parseJWK(k)
+		if err != nil {
+			return nil, fmt.Errorf("JWKS key %d (kid %q): %w", i, k.Kid, err)
+		}
+		set.keys = append(set.keys, key)
+	}
+	if len(set.keys) == 0 {
+		return nil, errors.New("JWKS has no signing keys")
+	}
+	return set, nil
+}
+
+func parseJWK(k jwk) (verificationKey, error) {
+	key := verificationKey{kid: k.Kid, alg: k.Alg}
+	switch k.Kty {
+	case "oct":
+		if key.alg == "" {
+			key.alg = AlgHS256
+		}
+		if key.alg != AlgHS256 {
+			return key, fmt.Errorf("unsupported alg %s for kty oct", key.alg)
+		}
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil || len(secret) == 0 {
+			return key, errors.New("k must be a non-empty base64url value")
+		}
+		key.secret = secret
+	case "RSA":
+		if key.alg == "" {
+			key.alg = AlgRS256
+		}
+		if key.alg != AlgRS256 {
+			return key, fmt.Errorf("unsupported alg %s for kty RSA", key.alg)
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+		if errN != nil || errE != nil || len(n) == 0 || len(e) == 0 {
+			return key, errors.New("n and e must be non-empty base64url values")
+		}
+		exp := new(big.Int).SetBytes(e)
+		if !exp.IsInt64() || exp.Int64() > 1<<31-1 {
+			return key, errors.New("e is too large")
+		}
+		key.public = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}
+	default:
+		return key, fmt.Errorf("unsupported kty %q", k.Kty)
+	}
+	return key, nil
+}
+
+// find returns the key for a token header. Tokens without kid match when
+// exactly one key has their alg. The alg must always match the key, so an RSA
+// public key is never used as an HMAC secret.
+func (s *KeySet) find(kid, alg string) *verificationKey {
+	var match *verificationKey
+	for i := range s.keys {
+		k := &s.keys[i]
+		if k.alg != alg {
+			continue
+		}
+		if kid != "" {
+			if k.kid == kid {
+				return k
+			}
+			continue
+		}
+		if match != nil {
+			return nil
+		}
+		match = k
+	}
+	return match
+}
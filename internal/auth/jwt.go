@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+)
+
+// User is the caller of a request authenticated with a bearer token
+type User struct {
+	Subject string   `json:"sub"`
+	Roles   []string `json:"roles"`
+}
+
+// RoleScopes maps the roles of a bearer token to the scopes they grant.
+// Unknown roles grant nothing. Customers only place orders: following and
+// cancelling them is not limited to their own orders.
+var RoleScopes = map[string][]string{
+	"customer": {ScopeCatalogRead, ScopeOrdersWrite},
+	"staff":    {ScopeCatalogRead, ScopeProductsAdmin, ScopeOrdersWrite, ScopeOrdersCancel, ScopeOrdersRead},
+	"admin":    AllScopes,
+}
+
+// HasScope reports whether any role of the user grants scope
+func (u *User) HasScope(scope string) bool {
+	for _, role := range u.Roles {
+		if slices.Contains(RoleScopes[role], scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifierConfig lists the claims a token must carry. Empty Issuer or Audience
+// are not checked; Leeway allows for clock skew on exp and nbf.
+type VerifierConfig struct {
+	Issuer   string
+	Audience string
+	Leeway   time.Duration
+}
+
+// Verifier validates HS256 and RS256 signed JWTs against a KeySet
+type Verifier struct {
+	keys *KeySet
+	cfg  VerifierConfig
+	now  func() time.Time
+}
+
+func NewVerifier(keys *KeySet, cfg VerifierConfig) *Verifier {
+	return &Verifier{keys: keys, cfg: cfg, now: time.Now}
+}
+
+type tokenHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type tokenClaims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *float64 `json:"exp"`
+	NotBefore *float64 `json:"nbf"`
+	Roles     []string `json:"roles"`
+}
+
+// audience is the aud claim, a single string or an array of strings
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*a = audience{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+// Verify checks the signature and the exp, nbf, iss and aud claims of a token
+// and returns its user. exp and sub are required.
+func (v *Verifier) Verify(token string) (*User, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, invalidToken("malformed token")
+	}
+
+	var header tokenHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, invalidToken("malformed header")
+	}
+	key := v.keys.find(header.Kid, header.Alg)
+	if key == nil {
+		return nil, invalidToken("no key for alg " + header.Alg + " and kid " + header.Kid)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, invalidToken("malformed signature")
+	}
+	if !key.verify(parts[0]+"."+parts[1], sig) {
+		return nil, invalidToken("signature mismatch")
+	}
+
+	var claims tokenClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, invalidToken("malformed claims")
+	}
+	if err := v.checkClaims(&claims); err != nil {
+		return nil, err
+	}
+
+	return &User{Subject: claims.Subject, Roles: claims.Roles}, nil
+}
+
+func (v *Verifier) checkClaims(c *tokenClaims) *apperrors.AppError {
+	now := v.now()
+	if c.ExpiresAt == nil {
+		return invalidToken("exp is required")
+	}
+	if !now.Before(numericDate(*c.ExpiresAt).Add(v.cfg.Leeway)) {
+		return ErrTokenExpired
+	}
+	if c.NotBefore != nil && now.Add(v.cfg.Leeway).Before(numericDate(*c.NotBefore)) {
+		return invalidToken("token not valid yet")
+	}
+	if v.cfg.Issuer != "" && c.Issuer != v.cfg.Issuer {
+		return invalidToken("unexpected issuer " + c.Issuer)
+	}
+	if v.cfg.Audience != "" && !slices.Contains(c.Audience, v.cfg.Audience) {
+		return invalidToken("token is not meant for this audience")
+	}
+	if c.Subject == "" {
+		return invalidToken("sub is required")
+	}
+	return nil
+}
+
+func (k *verificationKey) verify(signingInput string, sig []byte) bool {
+	switch k.alg {
+	case AlgHS256:
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write([]byte(signingInput))
+		return hmac.Equal(mac.Sum(nil), sig)
+	case AlgRS256:
+		digest := sha256.Sum256([]byte(signingInput))
+		return rsa.VerifyPKCS1v15(k.public, crypto.SHA256, digest[:], sig) == nil
+	}
+	return false
+}
+
+func decodeSegment(seg string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// numericDate converts a JWT NumericDate, seconds since the epoch, to a time
+func numericDate(v float64) time.Time {
+	sec, frac := math.Modf(v)
+	return time.Unix(int64(sec), int64(frac*1e9))
+}
+
+// invalidToken keeps the reason as the cause for the log, clients only see "invalid token"
+func invalidToken(reason string) *apperrors.AppError {
+	return apperrors.Unauthorized(ErrInvalidToken.Message, errors.New(reason)).WithCode(apperrors.CodeInvalidToken)
+}
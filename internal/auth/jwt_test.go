@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	hmacSecret = []byte("0123456789abcdef0123456789abcdef")
+	jwtNow     = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+)
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func mustJSON(t *testing.T, v any) []byte {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return data
+}
+
+// signHS256 and signRS256 build a token from a header and claims
+func signHS256(t *testing.T, header, claims map[string]any, secret []byte) string {
+	input := b64(mustJSON(t, header)) + "." + b64(mustJSON(t, claims))
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(input))
+	return input + "." + b64(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, header, claims map[string]any, key *rsa.PrivateKey) string {
+	input := b64(mustJSON(t, header)) + "." + b64(mustJSON(t, claims))
+	digest := sha256.Sum256([]byte(input))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	return input + "." + b64(sig)
+}
+
+func testJWKS(t *testing.T, rsaKey *rsa.PrivateKey) *KeySet {
+	doc := map[string]any{"keys": []map[string]any{
+		{"kty": "oct", "kid": "app", "alg": "HS256", "k": b64(hmacSecret)},
+		{"kty": "RSA", "kid": "idp-1", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+	}}
+	set, err := ParseJWKS(mustJSON(t, doc))
+	require.NoError(t, err)
+	return set
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	v := NewVerifier(testJWKS(t, rsaKey), VerifierConfig{Issuer: "https://id.example.com", Audience: "order-api", Leeway: 30 * time.Second})
+	v.now = func() time.Time { return jwtNow }
+
+	claims := func(overrides map[string]any) map[string]any {
+		c := map[string]any{
+			"sub":   "user-42",
+			"iss":   "https://id.example.com",
+			"aud":   "order-api",
+			"exp":   jwtNow.Add(time.Hour).Unix(),
+			"nbf":   jwtNow.Add(-time.Minute).Unix(),
+			"roles": []string{"customer"},
+		}
+		for k, val := range overrides {
+			if val == nil {
+				delete(c, k)
+				continue
+			}
+			c[k] = val
+		}
+		return c
+	}
+	hs := map[string]any{"alg": "HS256", "kid": "app", "typ": "JWT"}
+	rs := map[string]any{"alg": "RS256", "kid": "idp-1", "typ": "JWT"}
+
+	t.Run("HS256", func(t *testing.T) {
+		u, err := v.Verify(signHS256(t, hs, claims(nil), hmacSecret))
+		require.NoError(t, err)
+		assert.Equal(t, &User{Subject: "user-42", Roles: []string{"customer"}}, u)
+	})
+
+	t.Run("RS256", func(t *testing.T) {
+		u, err := v.Verify(signRS256(t, rs, claims(map[string]any{"aud": []string{"other", "order-api"}}), rsaKey))
+		require.NoError(t, err)
+		assert.Equal(t, "user-42", u.Subject)
+	})
+
+	t.Run("token without kid uses the only key of its alg", func(t *testing.T) {
+		_, err := v.Verify(signRS256(t, map[string]any{"alg": "RS256"}, claims(nil), rsaKey))
+		assert.NoError(t, err)
+	})
+
+	t.Run("expiry within the leeway", func(t *testing.T) {
+		_, err := v.Verify(signHS256(t, hs, claims(map[string]any{"exp": jwtNow.Add(-10 * time.Second).Unix()}), hmacSecret))
+		assert.NoError(t, err)
+	})
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		token   string
+		message string
+	}{
+		{name: "expired", token: signHS256(t, hs, claims(map[string]any{"exp": jwtNow.Add(-time.Minute).Unix()}), hmacSecret), message: "token expired"},
+		{name: "missing exp", token: signHS256(t, hs, claims(map[string]any{"exp": nil}), hmacSecret)},
+		{name: "not valid yet", token: signHS256(t, hs, claims(map[string]any{"nbf": jwtNow.Add(time.Minute).Unix()}), hmacSecret)},
+		{name: "wrong issuer", token: signHS256(t, hs, claims(map[string]any{"iss": "https://evil.example.com"}), hmacSecret)},
+		{name: "wrong audience", token: signHS256(t, hs, claims(map[string]any{"aud": "other"}), hmacSecret)},
+		{name: "missing subject", token: signHS256(t, hs, claims(map[string]any{"sub": nil}), hmacSecret)},
+		{name: "wrong secret", token: signHS256(t, hs, claims(nil), []byte("another secret"))},
+		{name: "wrong RSA key", token: signRS256(t, rs, claims(nil), otherKey)},
+		{name: "unknown kid", token: signHS256(t, map[string]any{"alg": "HS256", "kid": "nope"}, claims(nil), hmacSecret)},
+		{name: "alg none", token: b64(mustJSON(t, map[string]any{"alg": "none"})) + "." + b64(mustJSON(t, claims(nil))) + "."},
+		{name: "HS256 signed with the RSA key id", token: signHS256(t, map[string]any{"alg": "HS256", "kid": "idp-1"}, claims(nil), hmacSecret)},
+		{name: "malformed", token: "not-a-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.Verify(tt.token)
+			require.Error(t, err)
+			appErr, ok := err.(*apperrors.AppError)
+			require.True(t, ok)
+			assert.Equal(t, 401, appErr.Code)
+			want := tt.message
+			if want == "" {
+				want = "invalid token"
+			}
+			assert.Equal(t, want, appErr.Message)
+		})
+	}
+}
+
+func TestParseJWKS(t *testing.T) {
+	t.Run("skips encryption keys", func(t *testing.T) {
+		set, err := ParseJWKS([]byte(`{"keys":[{"kty":"RSA","use":"enc","n":"AQ","e":"AQAB"},{"kty":"oct","k":"c2VjcmV0"}]}`))
+		require.NoError(t, err)
+		require.Len(t, set.keys, 1)
+		assert.Equal(t, AlgHS256, set.keys[0].alg)
+	})
+
+	tests := []struct {
+		name string
+		doc  string
+	}{
+		{name: "not JSON", doc: `{`},
+		{name: "no keys", doc: `{"keys":[]}`},
+		{name: "unsupported kty", doc: `{"keys":[{"kty":"EC"}]}`},
+		{name: "alg does not fit the key", doc: `{"keys":[{"kty":"oct","alg":"RS256","k":"c2VjcmV0"}]}`},
+		{name: "empty secret", doc: `{"keys":[{"kty":"oct"}]}`},
+		{name: "RSA without modulus", doc: `{"keys":[{"kty":"RSA","e":"AQAB"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseJWKS([]byte(tt.doc))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestUser_HasScope(t *testing.T) {
+	customer := &User{Subject: "user-42", Roles: []string{"customer"}}
+	assert.True(t, customer.HasScope(ScopeOrdersWrite))
+	assert.False(t, customer.HasScope(ScopeOrdersRead), "customers must not follow every order")
+	assert.False(t, customer.HasScope(ScopeOrdersCancel), "customers must not cancel every order")
+	assert.True(t, (&User{Roles: []string{"staff"}}).HasScope(ScopeOrdersCancel))
+	assert.False(t, customer.HasScope(ScopeProductsAdmin))
+	assert.False(t, (&User{Roles: []string{"unknown"}}).HasScope(ScopeCatalogRead))
+	assert.True(t, (&User{Roles: []string{"customer", "admin"}}).HasScope(ScopeKeysAdmin))
+}
@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/db"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+)
+
+type MariaDBRepository struct{}
+
+func NewMariaDBRepository() Repository {
+	return &MariaDBRepository{}
+}
+
+const keyColumns = `id, name, prefix, scopes, expires_at, last_used_at, revoked_at, created_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanKey reads the keyColumns of a row followed by extra columns
+func scanKey(row rowScanner, extra ...any) (*Key, error) {
+	var (
+		k                              Key
+		scopes                         string
+		expiresAt, lastUsed, revokedAt sql.NullTime
+	)
+	dest := append([]any{&k.ID, &k.Name, &k.Prefix, &scopes, &expiresAt, &lastUsed, &revokedAt, &k.CreatedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	k.Scopes = []string{}
+	if scopes != "" {
+		k.Scopes = strings.Split(scopes, ",")
+	}
+	k.ExpiresAt = nullTime(expiresAt)
+	k.LastUsedAt = nullTime(lastUsed)
+	k.RevokedAt = nullTime(revokedAt)
+	return &k, nil
+}
+
+func (r *MariaDBRepository) List(ctx context.Context) ([]*Key, error) {
+	rows, err := db.Pool.Query(ctx, `SELECT `+keyColumns+` FROM api_keys ORDER BY created_at DESC, id`)
+	if err != nil {
+		appErr := apperrors.Internal("failed to list api keys", err)
+		logger.Error(ctx, appErr.Message, "error", err.Error())
+		return nil, appErr
+	}
+	defer rows.Close()
+
+	var keys []*Key
+	for rows.Next() {
+		k, err := scanKey(rows)
+		if err != nil {
+			appErr := apperrors.Internal("failed to scan api key row", err)
+			logger.Error(ctx, appErr.Message, "error", err.Error())
+			return nil, appErr
+		}
+		keys = append(keys, k)
+	}
+	if err := rows.Err(); err != nil {
+		appErr := apperrors.Internal("failed to read api key rows", err)
+		logger.Error(ctx, appErr.Message, "error", err.Error())
+		return nil, appErr
+	}
+
+	return keys, nil
+}
+
+func (r *MariaDBRepository) GetByID(ctx context.Context, id string) (*Key, error) {
+	k, err := scanKey(db.Pool.QueryRow(ctx, `SELECT `+keyColumns+` FROM api_keys WHERE id = ?`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn(ctx, ErrKeyNotFound.Message, "id", id)
+			return nil, ErrKeyNotFound
+		}
+		appErr := apperrors.Internal("failed to fetch api key", err)
+		logger.Error(ctx, appErr.Message, "id", id, "error", err.Error())
+		return nil, appErr
+	}
+	return k, nil
+}
+
+func (r *MariaDBRepository) GetByPrefix(ctx context.Context, prefix string) (*Key, *Credentials, error) {
+	cred := Credentials{Prefix: prefix}
+	k, err := scanKey(db.Pool.QueryRow(ctx, `SELECT `+keyColumns+`, salt, hash FROM api_keys WHERE prefix = ?`, prefix), &cred.Salt, &cred.Hash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, ErrKeyNotFound
+		}
+		appErr := apperrors.Internal("failed to fetch api key", err)
+		logger.Error(ctx, appErr.Message, "prefix", prefix, "error", err.Error())
+		return nil, nil, appErr
+	}
+	return k, &cred, nil
+}
+
+func (r *MariaDBRepository) Create(ctx context.Context, k *Key, cred *Credentials) error {
+	query := `INSERT INTO api_keys (id, name, prefix, salt, hash, scopes, expires_at, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := db.Pool.Exec(ctx, query, k.ID, k.Name, cred.Prefix, cred.Salt, cred.Hash, strings.Join(k.Scopes, ","), k.ExpiresAt, k.CreatedAt)
+	if err != nil {
+		appErr := apperrors.Internal("failed to create api key", err)
+		logger.Error(ctx, appErr.Message, "name", k.Name, "error", err.Error())
+		return appErr
+	}
+
+	logger.Info(ctx, "api key created", "id", k.ID, "name", k.Name, "prefix", cred.Prefix)
+	return nil
+}
+
+func (r *MariaDBRepository) Rotate(ctx context.Context, id string, cred *Credentials) error {
+	query := `UPDATE api_keys SET prefix = ?, salt = ?, hash = ? WHERE id = ? AND revoked_at IS NULL`
+
+	if err := r.execOne(ctx, "failed to rotate api key", query, cred.Prefix, cred.Salt, cred.Hash, id); err != nil {
+		return err
+	}
+
+	logger.Info(ctx, "api key rotated", "id", id, "prefix", cred.Prefix)
+	return nil
+}
+
+func (r *MariaDBRepository) Revoke(ctx context.Context, id string, at time.Time) error {
+	query := `UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`
+
+	if err := r.execOne(ctx, "failed to revoke api key", query, at, id); err != nil {
+		return err
+	}
+
+	logger.Info(ctx, "api key revoked", "id", id)
+	return nil
+}
+
+func (r *MariaDBRepository) TouchLastUsed(ctx context.Context, id string, at time.Time) error {
+	if _, err := db.Pool.Exec(ctx, `UPDATE api_keys SET last_used_at = ? WHERE id = ?`, at, id); err != nil {
+		appErr := apperrors.Internal("failed to record api key use", err)
+		logger.Error(ctx, appErr.Message, "id", id, "error", err.Error())
+		return appErr
+	}
+	return nil
+}
+
+// execOne runs an update of the key whose id is the last argument and returns
+// ErrKeyNotFound when it matched no row
+func (r *MariaDBRepository) execOne(ctx context.Context, msg, query string, args ...any) error {
+	id := args[len(args)-1]
+	res, err := db.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		appErr := apperrors.Internal(msg, err)
+		logger.Error(ctx, appErr.Message, "id", id, "error", err.Error())
+		return appErr
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		appErr := apperrors.Internal("failed to read affected rows", err)
+		logger.Error(ctx, appErr.Message, "id", id, "error", err.Error())
+		return appErr
+	}
+	if n == 0 {
+		logger.Warn(ctx, ErrKeyNotFound.Message, "id", id)
+		return ErrKeyNotFound
+	}
+	return nil
+}
+
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mohammadshabab/order-food-online/internal/db"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMariaDBRepository(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	db.Pool = db.NewTestPool(sqlDB)
+
+	repo := NewMariaDBRepository()
+	cols := []string{"id", "name", "prefix", "scopes", "expires_at", "last_used_at", "revoked_at", "created_at"}
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	t.Run("list", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, name, prefix, scopes, expires_at, last_used_at, revoked_at, created_at FROM api_keys ORDER BY created_at DESC, id").
+			WillReturnRows(sqlmock.NewRows(cols).
+				AddRow(keyID1, "kiosk", "0123456789ab", "orders:write,catalog:read", now, nil, nil, now).
+				AddRow("77777777-7777-4777-8777-777777777777", "old", "ba9876543210", "", nil, now, now, now))
+
+		keys, err := repo.List(ctx)
+		require.NoError(t, err)
+		require.Len(t, keys, 2)
+		assert.Equal(t, &Key{ID: keyID1, Name: "kiosk", Prefix: "0123456789ab", Scopes: []string{ScopeOrdersWrite, ScopeCatalogRead}, ExpiresAt: &now, CreatedAt: now}, keys[0])
+		assert.Equal(t, []string{}, keys[1].Scopes)
+		assert.Equal(t, &now, keys[1].RevokedAt)
+		assert.Equal(t, &now, keys[1].LastUsedAt)
+	})
+
+	t.Run("list fails", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM api_keys").WillReturnError(errors.New("db failed"))
+
+		_, err := repo.List(ctx)
+		assert.ErrorContains(t, err, "db failed")
+	})
+
+	t.Run("get by prefix", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+), salt, hash FROM api_keys WHERE prefix = \\?").
+			WithArgs("0123456789ab").
+			WillReturnRows(sqlmock.NewRows(append(cols, "salt", "hash")).
+				AddRow(keyID1, "kiosk", "0123456789ab", "orders:write", nil, nil, nil, now, "00ff", "abcd"))
+
+		k, cred, err := repo.GetByPrefix(ctx, "0123456789ab")
+		require.NoError(t, err)
+		assert.Equal(t, keyID1, k.ID)
+		assert.Equal(t, &Credentials{Prefix: "0123456789ab", Salt: "00ff", Hash: "abcd"}, cred)
+	})
+
+	t.Run("get by unknown prefix", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE prefix").WillReturnError(sql.ErrNoRows)
+
+		_, _, err := repo.GetByPrefix(ctx, "0123456789ab")
+		assert.Equal(t, ErrKeyNotFound, err)
+	})
+
+	t.Run("get unknown id", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE id").WithArgs(keyID1).WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.GetByID(ctx, keyID1)
+		assert.Equal(t, ErrKeyNotFound, err)
+	})
+
+	t.Run("create", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO api_keys \\(id, name, prefix, salt, hash, scopes, expires_at, created_at\\) VALUES").
+			WithArgs(keyID1, "kiosk", "0123456789ab", "00ff", "abcd", "orders:write,catalog:read", nil, now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		k := &Key{ID: keyID1, Name: "kiosk", Scopes: []string{ScopeOrdersWrite, ScopeCatalogRead}, CreatedAt: now}
+		assert.NoError(t, repo.Create(ctx, k, &Credentials{Prefix: "0123456789ab", Salt: "00ff", Hash: "abcd"}))
+	})
+
+	t.Run("rotate", func(t *testing.T) {
+		mock.ExpectExec("UPDATE api_keys SET prefix = \\?, salt = \\?, hash = \\? WHERE id = \\? AND revoked_at IS NULL").
+			WithArgs("ba9876543210", "11ee", "dcba", keyID1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.Rotate(ctx, keyID1, &Credentials{Prefix: "ba9876543210", Salt: "11ee", Hash: "dcba"}))
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		mock.ExpectExec("UPDATE api_keys SET revoked_at = \\? WHERE id = \\? AND revoked_at IS NULL").
+			WithArgs(now, keyID1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.Revoke(ctx, keyID1, now))
+	})
+
+	t.Run("revoke unknown or revoked key", func(t *testing.T) {
+		mock.ExpectExec("UPDATE api_keys SET revoked_at").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.Equal(t, ErrKeyNotFound, repo.Revoke(ctx, keyID1, now))
+	})
+
+	t.Run("touch last used", func(t *testing.T) {
+		mock.ExpectExec("UPDATE api_keys SET last_used_at = \\? WHERE id = \\?").
+			WithArgs(now, keyID1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.TouchLastUsed(ctx, keyID1, now))
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
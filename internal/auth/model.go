@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"slices"
+	"time"
+)
+
+// Scopes a key can be given. Routes declare the scope they need with RequireScope.
+const (
+	// ScopeCatalogRead reads products, categories, the menu, restaurants and schedules
+	ScopeCatalogRead = "catalog:read"
+	// ScopeProductsAdmin manages the catalog: products, prices, categories,
+	// schedules, restaurants and coupon scopes
+	ScopeProductsAdmin = "products:admin"
+	// ScopeOrdersWrite places orders
+	ScopeOrdersWrite = "orders:write"
+	// ScopeOrdersCancel cancels any order. Orders carry no owner, so it is not
+	// granted to customers.
+	ScopeOrdersCancel = "orders:cancel"
+	// ScopeOrdersRead follows the events of every order, it is not granted to
+	// customers either
+	ScopeOrdersRead = "orders:read"
+	// ScopeKeysAdmin manages API keys
+	ScopeKeysAdmin = "keys:admin"
+	// ScopeAuditRead reads the audit log
+	ScopeAuditRead = "audit:read"
+	// ScopeMetricsRead scrapes the Prometheus metrics
+	ScopeMetricsRead = "metrics:read"
+	// ScopeLogsAdmin reads and changes the log levels
+	ScopeLogsAdmin = "logs:admin"
+)
+
+// AllScopes lists every scope in the order they are shown
+var AllScopes = []string{ScopeCatalogRead, ScopeProductsAdmin, ScopeOrdersWrite, ScopeOrdersCancel, ScopeOrdersRead, ScopeKeysAdmin, ScopeAuditRead, ScopeMetricsRead, ScopeLogsAdmin}
+
+// Key is an API key without its secret. The secret is only shown when the key
+// is created or rotated; the database keeps a salted hash of it.
+type Key struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Prefix is the public part of the key, it identifies the key in listings and logs
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// HasScope reports whether the key grants scope
+func (k *Key) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// Active reports whether the key can be used at t
+func (k *Key) Active(t time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || t.Before(*k.ExpiresAt)
+}
+
+// Credentials is what the repository stores to verify a secret: a random salt
+// and the SHA-256 of salt and secret, both hex encoded
+type Credentials struct {
+	Prefix string
+	Salt   string
+	Hash   string
+}
+
+// KeyReq is the body of POST /api-key. A key without ExpiresAt does not expire.
+type KeyReq struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// IssuedKey is the response of creating or rotating a key, the only time the
+// full key is returned
+type IssuedKey struct {
+	Key
+	Secret string `json:"key"`
+}
+
+// KeyList is the response of GET /api-key
+type KeyList struct {
+	Items []*Key `json:"items"`
+}
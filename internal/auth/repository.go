@@ -0,0 +1,22 @@
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+//go:generate mockgen -source=repository.go -destination=mock_repository.go -package=auth
+type Repository interface {
+	// List returns every key, revoked ones included, newest first
+	List(ctx context.Context) ([]*Key, error)
+	// GetByID returns ErrKeyNotFound when there is no such key
+	GetByID(ctx context.Context, id string) (*Key, error)
+	// GetByPrefix returns the key and its credentials, ErrKeyNotFound when there is no such key
+	GetByPrefix(ctx context.Context, prefix string) (*Key, *Credentials, error)
+	Create(ctx context.Context, k *Key, cred *Credentials) error
+	// Rotate replaces the credentials of a key that is not revoked, ErrKeyNotFound otherwise
+	Rotate(ctx context.Context, id string, cred *Credentials) error
+	// Revoke returns ErrKeyNotFound when there is no such key or it is already revoked
+	Revoke(ctx context.Context, id string, at time.Time) error
+	TouchLastUsed(ctx context.Context, id string, at time.Time) error
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// Keys look like ofo_<prefix>_<secret>. The prefix finds the key in the
+// database, the secret is checked against its salted hash.
+const (
+	keyMarker    = "ofo_"
+	prefixLength = 12
+	secretBytes  = 32
+	saltBytes    = 16
+)
+
+// newSecret generates a key and the credentials to store for it
+func newSecret() (string, *Credentials, error) {
+	prefix := make([]byte, prefixLength/2)
+	secret := make([]byte, secretBytes)
+	salt := make([]byte, saltBytes)
+	for _, b := range [][]byte{prefix, secret, salt} {
+		if _, err := rand.Read(b); err != nil {
+			return "", nil, err
+		}
+	}
+
+	cred := &Credentials{Prefix: hex.EncodeToString(prefix), Salt: hex.EncodeToString(salt)}
+	encoded := base64.RawURLEncoding.EncodeToString(secret)
+	cred.Hash = hashSecret(salt, encoded)
+	return keyMarker + cred.Prefix + "_" + encoded, cred, nil
+}
+
+// parseKey splits a key into its prefix and secret
+func parseKey(raw string) (prefix, secret string, ok bool) {
+	rest, found := strings.CutPrefix(raw, keyMarker)
+	if !found || len(rest) <= prefixLength+1 || rest[prefixLength] != '_' {
+		return "", "", false
+	}
+	return rest[:prefixLength], rest[prefixLength+1:], true
+}
+
+func hashSecret(salt []byte, secret string) string {
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(secret))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// verify checks secret against the stored hash in constant time
+func (c *Credentials) verify(secret string) bool {
+	salt, err := hex.DecodeString(c.Salt)
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(hashSecret(salt, secret)), []byte(c.Hash)) == 1
+}
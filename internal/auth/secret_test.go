@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSecret(t *testing.T) {
+	raw, cred, err := newSecret()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(raw, "ofo_"+cred.Prefix+"_"))
+	assert.Len(t, cred.Prefix, prefixLength)
+	assert.Len(t, cred.Salt, 2*saltBytes)
+	assert.Len(t, cred.Hash, 64)
+
+	prefix, secret, ok := parseKey(raw)
+	require.True(t, ok)
+	assert.Equal(t, cred.Prefix, prefix)
+	assert.True(t, cred.verify(secret))
+	assert.False(t, cred.verify(secret+"x"))
+
+	other, otherCred, err := newSecret()
+	require.NoError(t, err)
+	assert.NotEqual(t, raw, other)
+	assert.NotEqual(t, cred.Salt, otherCred.Salt)
+}
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		raw    string
+		prefix string
+		secret string
+		ok     bool
+	}{
+		{raw: "ofo_0123456789ab_s3cr_et", prefix: "0123456789ab", secret: "s3cr_et", ok: true},
+		{raw: "test"},
+		{raw: "ofo_0123456789ab_"},
+		{raw: "ofo_0123456789abcdef"},
+		{raw: "xyz_0123456789ab_secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			prefix, secret, ok := parseKey(tt.raw)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.prefix, prefix)
+			assert.Equal(t, tt.secret, secret)
+		})
+	}
+}
+
+func TestCredentials_VerifyInvalidSalt(t *testing.T) {
+	assert.False(t, (&Credentials{Salt: "not hex", Hash: ""}).verify("secret"))
+}
@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+)
+
+// touchInterval is how often the last-used timestamp of a key is written,
+// so busy keys don't cost an UPDATE on every request
+const touchInterval = time.Minute
+
+//go:generate mockgen -source=service.go -destination=mock_service.go -package=auth
+type Service interface {
+	// Authenticate returns the key of a raw api_key header value. Unknown,
+	// malformed, expired and revoked keys all give ErrUnauthorized.
+	Authenticate(ctx context.Context, raw string) (*Key, error)
+	ListKeys(ctx context.Context) (*KeyList, error)
+	GetKey(ctx context.Context, id string) (*Key, error)
+	CreateKey(ctx context.Context, req *KeyReq) (*IssuedKey, error)
+	RevokeKey(ctx context.Context, id string) error
+	// RotateKey replaces the secret of a key, the old one stops working at once
+	RotateKey(ctx context.Context, id string) (*IssuedKey, error)
+}
+
+type service struct {
+	repo Repository
+	// dummy is verified against when a prefix is unknown, so unknown keys take
+	// as long to reject as wrong secrets
+	dummy *Credentials
+}
+
+func NewService(repo Repository) Service {
+	_, dummy, err := newSecret()
+	if err != nil {
+		dummy = &Credentials{}
+	}
+	return &service{repo: repo, dummy: dummy}
+}
+
+func (s *service) Authenticate(ctx context.Context, raw string) (*Key, error) {
+	prefix, secret, ok := parseKey(raw)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	k, cred, err := s.repo.GetByPrefix(ctx, prefix)
+	if errors.Is(err, ErrKeyNotFound) {
+		s.dummy.verify(secret)
+		return nil, ErrUnauthorized
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !cred.verify(secret) {
+		return nil, ErrUnauthorized
+	}
+
+	now := time.Now().UTC()
+	if !k.Active(now) {
+		logger.Warn(ctx, "inactive api key used", "id", k.ID, "prefix", k.Prefix)
+		return nil, ErrUnauthorized
+	}
+
+	if k.LastUsedAt == nil || now.Sub(*k.LastUsedAt) >= touchInterval {
+		// a failed write is logged by the repository and does not fail the request
+		if err := s.repo.TouchLastUsed(ctx, k.ID, now); err == nil {
+			k.LastUsedAt = &now
+		}
+	}
+	return k, nil
+}
+
+func (s *service) ListKeys(ctx context.Context) (*KeyList, error) {
+	keys, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if keys == nil {
+		keys = []*Key{}
+	}
+	return &KeyList{Items: keys}, nil
+}
+
+func (s *service) GetKey(ctx context.Context, id string) (*Key, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *service) CreateKey(ctx context.Context, req *KeyReq) (*IssuedKey, error) {
+	now := time.Now().UTC()
+	if err := req.Validate(now); err != nil {
+		return nil, err
+	}
+
+	raw, cred, err := s.newSecret(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	k := Key{
+		ID:        uuid.New().String(),
+		Name:      req.Name,
+		Prefix:    cred.Prefix,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+		CreatedAt: now,
+	}
+	if k.ExpiresAt != nil {
+		expires := k.ExpiresAt.UTC()
+		k.ExpiresAt = &expires
+	}
+	if err := s.repo.Create(ctx, &k, cred); err != nil {
+		return nil, err
+	}
+	return &IssuedKey{Key: k, Secret: raw}, nil
+}
+
+func (s *service) RevokeKey(ctx context.Context, id string) error {
+	return s.repo.Revoke(ctx, id, time.Now().UTC())
+}
+
+func (s *service) RotateKey(ctx context.Context, id string) (*IssuedKey, error) {
+	k, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if k.RevokedAt != nil {
+		logger.Warn(ctx, "cannot rotate a revoked api key", "id", id)
+		return nil, ErrKeyNotFound
+	}
+
+	raw, cred, err := s.newSecret(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Rotate(ctx, id, cred); err != nil {
+		return nil, err
+	}
+
+	k.Prefix = cred.Prefix
+	return &IssuedKey{Key: *k, Secret: raw}, nil
+}
+
+func (s *service) newSecret(ctx context.Context) (string, *Credentials, error) {
+	raw, cred, err := newSecret()
+	if err != nil {
+		appErr := apperrors.Internal("failed to generate api key", err)
+		logger.Error(ctx, appErr.Message, "error", err.Error())
+		return "", nil, appErr
+	}
+	return raw, cred, nil
+}
@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const keyID1 = "66666666-6666-4666-8666-666666666666"
+
+// issue returns a raw key and the stored key and credentials for it
+func issue(t *testing.T) (string, *Key, *Credentials) {
+	raw, cred, err := newSecret()
+	require.NoError(t, err)
+	return raw, &Key{ID: keyID1, Name: "kiosk", Prefix: cred.Prefix, Scopes: []string{ScopeOrdersWrite}}, cred
+}
+
+func TestService_Authenticate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("valid key records its use", func(t *testing.T) {
+		raw, k, cred := issue(t)
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().GetByPrefix(ctx, cred.Prefix).Return(k, cred, nil)
+		mockRepo.EXPECT().TouchLastUsed(ctx, keyID1, gomock.Any()).Return(nil)
+
+		got, err := NewService(mockRepo).Authenticate(ctx, raw)
+		require.NoError(t, err)
+		assert.Equal(t, keyID1, got.ID)
+		assert.NotNil(t, got.LastUsedAt)
+	})
+
+	t.Run("recently used key is not touched again", func(t *testing.T) {
+		raw, k, cred := issue(t)
+		recent := time.Now().UTC().Add(-10 * time.Second)
+		k.LastUsedAt = &recent
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().GetByPrefix(ctx, cred.Prefix).Return(k, cred, nil)
+
+		_, err := NewService(mockRepo).Authenticate(ctx, raw)
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed touch does not fail the request", func(t *testing.T) {
+		raw, k, cred := issue(t)
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().GetByPrefix(ctx, cred.Prefix).Return(k, cred, nil)
+		mockRepo.EXPECT().TouchLastUsed(ctx, keyID1, gomock.Any()).Return(errors.New("db down"))
+
+		_, err := NewService(mockRepo).Authenticate(ctx, raw)
+		assert.NoError(t, err)
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		raw, k, cred := issue(t)
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().GetByPrefix(ctx, cred.Prefix).Return(k, cred, nil)
+
+		_, err := NewService(mockRepo).Authenticate(ctx, raw+"x")
+		assert.Equal(t, ErrUnauthorized, err)
+	})
+
+	t.Run("unknown prefix", func(t *testing.T) {
+		raw, _, cred := issue(t)
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().GetByPrefix(ctx, cred.Prefix).Return(nil, nil, ErrKeyNotFound)
+
+		_, err := NewService(mockRepo).Authenticate(ctx, raw)
+		assert.Equal(t, ErrUnauthorized, err)
+	})
+
+	t.Run("malformed key", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		_, err := NewService(NewMockRepository(ctrl)).Authenticate(ctx, "test")
+		assert.Equal(t, ErrUnauthorized, err)
+	})
+
+	t.Run("revoked key", func(t *testing.T) {
+		raw, k, cred := issue(t)
+		revoked := time.Now().UTC().Add(-time.Hour)
+		k.RevokedAt = &revoked
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().GetByPrefix(ctx, cred.Prefix).Return(k, cred, nil)
+
+		_, err := NewService(mockRepo).Authenticate(ctx, raw)
+		assert.Equal(t, ErrUnauthorized, err)
+	})
+
+	t.Run("expired key", func(t *testing.T) {
+		raw, k, cred := issue(t)
+		expired := time.Now().UTC().Add(-time.Minute)
+		k.ExpiresAt = &expired
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().GetByPrefix(ctx, cred.Prefix).Return(k, cred, nil)
+
+		_, err := NewService(mockRepo).Authenticate(ctx, raw)
+		assert.Equal(t, ErrUnauthorized, err)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		raw, _, cred := issue(t)
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().GetByPrefix(ctx, cred.Prefix).Return(nil, nil, errors.New("db down"))
+
+		_, err := NewService(mockRepo).Authenticate(ctx, raw)
+		assert.EqualError(t, err, "db down")
+	})
+}
+
+func TestService_ListKeys(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	svc := NewService(mockRepo)
+
+	mockRepo.EXPECT().List(ctx).Return(nil, nil)
+	res, err := svc.ListKeys(ctx)
+	require.NoError(t, err)
+	assert.NotNil(t, res.Items)
+
+	mockRepo.EXPECT().List(ctx).Return(nil, errors.New("db error"))
+	_, err = svc.ListKeys(ctx)
+	assert.EqualError(t, err, "db error")
+}
+
+func TestService_CreateKey(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		var stored *Credentials
+		mockRepo.EXPECT().Create(ctx, gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, k *Key, cred *Credentials) error {
+			assert.NotEmpty(t, k.ID)
+			assert.Equal(t, cred.Prefix, k.Prefix)
+			stored = cred
+			return nil
+		})
+
+		res, err := NewService(mockRepo).CreateKey(ctx, &KeyReq{Name: "kiosk", Scopes: []string{ScopeOrdersWrite}})
+		require.NoError(t, err)
+		assert.Equal(t, "kiosk", res.Name)
+		assert.Equal(t, []string{ScopeOrdersWrite}, res.Scopes)
+
+		// only the hash is stored, the returned key verifies against it
+		_, secret, ok := parseKey(res.Secret)
+		require.True(t, ok)
+		assert.NotContains(t, stored.Hash, secret)
+		assert.True(t, stored.verify(secret))
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		_, err := NewService(NewMockRepository(ctrl)).CreateKey(ctx, &KeyReq{Name: "kiosk"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "at least one scope is required")
+	})
+}
+
+func TestService_RotateKey(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		_, k, cred := issue(t)
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().GetByID(ctx, keyID1).Return(k, nil)
+		mockRepo.EXPECT().Rotate(ctx, keyID1, gomock.Any()).DoAndReturn(func(_ context.Context, _ string, c *Credentials) error {
+			assert.NotEqual(t, cred.Prefix, c.Prefix)
+			assert.NotEqual(t, cred.Hash, c.Hash)
+			return nil
+		})
+
+		res, err := NewService(mockRepo).RotateKey(ctx, keyID1)
+		require.NoError(t, err)
+		assert.NotEqual(t, cred.Prefix, res.Prefix)
+		assert.Contains(t, res.Secret, res.Prefix)
+	})
+
+	t.Run("revoked key", func(t *testing.T) {
+		_, k, _ := issue(t)
+		revoked := time.Now().UTC()
+		k.RevokedAt = &revoked
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().GetByID(ctx, keyID1).Return(k, nil)
+
+		_, err := NewService(mockRepo).RotateKey(ctx, keyID1)
+		assert.Equal(t, ErrKeyNotFound, err)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().GetByID(ctx, keyID1).Return(nil, ErrKeyNotFound)
+
+		_, err := NewService(mockRepo).RotateKey(ctx, keyID1)
+		assert.Equal(t, ErrKeyNotFound, err)
+	})
+}
+
+func TestService_RevokeKey(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	mockRepo.EXPECT().Revoke(ctx, keyID1, gomock.Any()).Return(nil)
+
+	assert.NoError(t, NewService(mockRepo).RevokeKey(ctx, keyID1))
+}
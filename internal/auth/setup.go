@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/audit"
+)
+
+// Setup registers the API key admin routes and returns the service, which the
+// API key middleware authenticates requests with. Key changes are recorded in
+// the audit log without their secrets.
+func Setup(e *echo.Echo, repo Repository, rec *audit.Recorder) Service {
+	svc := NewService(repo)
+	h := NewHandler(svc)
+
+	current := func(c echo.Context) (any, error) {
+		return svc.GetKey(c.Request().Context(), c.Param("keyId"))
+	}
+
+	admin := RequireScope(ScopeKeysAdmin)
+	e.GET("/api-key", h.ListKeys, admin)
+	e.POST("/api-key", h.CreateKey, admin,
+		rec.Middleware(audit.Action{Name: "api_key.create", Resource: "api_key", Redact: []string{"key"}}))
+	e.DELETE("/api-key/:keyId", h.RevokeKey, admin,
+		rec.Middleware(audit.Action{Name: "api_key.revoke", Resource: "api_key", IDParam: "keyId", Before: current}))
+	e.POST("/api-key/:keyId/rotate", h.RotateKey, admin,
+		rec.Middleware(audit.Action{Name: "api_key.rotate", Resource: "api_key", IDParam: "keyId", Before: current, Redact: []string{"key"}}))
+
+	return svc
+}
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+)
+
+func TestSetup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	e := echo.New()
+	if svc := Setup(e, NewMockRepository(ctrl), nil); svc == nil {
+		t.Fatal("expected Setup to return the service")
+	}
+
+	want := map[string]bool{
+		http.MethodGet + " /api-key":                false,
+		http.MethodPost + " /api-key":               false,
+		http.MethodDelete + " /api-key/:keyId":      false,
+		http.MethodPost + " /api-key/:keyId/rotate": false,
+	}
+	for _, r := range e.Routes() {
+		if _, ok := want[r.Method+" "+r.Path]; ok {
+			want[r.Method+" "+r.Path] = true
+		}
+	}
+	for route, found := range want {
+		if !found {
+			t.Errorf("expected %s to be registered but it was not", route)
+		}
+	}
+}
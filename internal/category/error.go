@@ -0,0 +1,60 @@
+package category
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+)
+
+var (
+	ErrSlugTaken     = apperrors.Conflict("a category with this slug already exists", nil).WithCode(apperrors.CodeSlugTaken)
+	ErrUnknownParent = apperrors.BadRequest("parentId does not match any category", nil).WithCode(apperrors.CodeUnknownParent)
+)
+
+const (
+	maxNameLength = 100
+	maxSlugLength = 100
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Validate checks the request and fills in the slug from the name when it is missing
+func (r *CategoryReq) Validate() *apperrors.AppError {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" || len(r.Name) > maxNameLength {
+		return apperrors.BadRequest("name must be 1-100 characters", nil)
+	}
+
+	if r.Slug == "" {
+		r.Slug = Slugify(r.Name)
+		if r.Slug == "" {
+			return apperrors.BadRequest("slug is required when the name has no letters or digits", nil)
+		}
+	}
+	if len(r.Slug) > maxSlugLength || !slugPattern.MatchString(r.Slug) {
+		return apperrors.BadRequest("slug must be lower case letters, digits and single dashes, at most 100 characters", nil)
+	}
+
+	if r.ParentID != nil {
+		if _, err := uuid.Parse(*r.ParentID); err != nil {
+			return apperrors.BadRequest("parentId must be a UUID", err)
+		}
+	}
+	return nil
+}
+
+// Slugify lower cases s and joins its runs of ASCII letters and digits with
+// dashes, "Hot Drinks & Tea" becomes "hot-drinks-tea"
+func Slugify(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r))
+	})
+	slug := strings.Join(words, "-")
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(slug[:maxSlugLength], "-")
+	}
+	return slug
+}
@@ -0,0 +1,50 @@
+package category
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlugify(t *testing.T) {
+	assert.Equal(t, "hot-drinks-tea", Slugify("  Hot Drinks & Tea "))
+	assert.Equal(t, "waffle", Slugify("Waffle"))
+	assert.Equal(t, "pizza-2-go", Slugify("pizza_2--go"))
+	assert.Equal(t, "", Slugify("!!!"))
+}
+
+func TestCategoryReq_Validate(t *testing.T) {
+	parent := "c1a2b3c4-d5e6-4f70-8a9b-0c1d2e3f4a5b"
+	bad := "top"
+
+	tests := []struct {
+		name    string
+		req     CategoryReq
+		slug    string
+		message string
+	}{
+		{name: "slug from name", req: CategoryReq{Name: " Hot Drinks "}, slug: "hot-drinks"},
+		{name: "explicit slug", req: CategoryReq{Name: "Hot Drinks", Slug: "drinks-hot", ParentID: &parent}, slug: "drinks-hot"},
+		{name: "missing name", req: CategoryReq{}, message: "name must be 1-100 characters"},
+		{name: "name without letters", req: CategoryReq{Name: "&&"}, message: "slug is required when the name has no letters or digits"},
+		{name: "invalid slug", req: CategoryReq{Name: "Drinks", Slug: "Hot Drinks"}, message: "slug must be lower case letters, digits and single dashes, at most 100 characters"},
+		{name: "invalid parent", req: CategoryReq{Name: "Drinks", ParentID: &bad}, message: "parentId must be a UUID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			appErr := req.Validate()
+			if tt.message == "" {
+				assert.Nil(t, appErr)
+				assert.Equal(t, tt.slug, req.Slug)
+				return
+			}
+			if assert.NotNil(t, appErr) {
+				assert.Equal(t, http.StatusBadRequest, appErr.Code)
+				assert.Equal(t, tt.message, appErr.Message)
+			}
+		})
+	}
+}
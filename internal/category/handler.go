@@ -0,0 +1,64 @@
+package category
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+)
+
+type Handler struct {
+	svc Service
+}
+
+func NewHandler(svc Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+func (h *Handler) ListCategories(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	res, err := h.svc.ListCategories(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) CreateCategory(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req CategoryReq
+	if err := c.Bind(&req); err != nil {
+		return apperrors.BadRequest("invalid category request", err)
+	}
+
+	res, err := h.svc.CreateCategory(ctx, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, res)
+}
+
+// Menu serves GET /menu
+func (h *Handler) Menu(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var availableNow bool
+	if raw := c.QueryParam("availableNow"); raw != "" {
+		var err error
+		if availableNow, err = strconv.ParseBool(raw); err != nil {
+			return apperrors.BadRequest("availableNow must be true or false", err)
+		}
+	}
+
+	res, err := h.svc.Menu(ctx, availableNow)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
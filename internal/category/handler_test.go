@@ -0,0 +1,142 @@
+package category
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/mohammadshabab/order-food-online/internal/product"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_ListCategories(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+
+	t.Run("success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().ListCategories(gomock.Any()).
+			Return(&CategoryList{Items: []*Category{{ID: drinksID, Slug: "drinks", Name: "Drinks", SortOrder: 2}}}, nil)
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/category", nil), rec)
+
+		apperrors.Handle(NewHandler(mockSvc).ListCategories, c)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"items":[{"id":"`+drinksID+`","slug":"drinks","name":"Drinks","sortOrder":2}]}`, rec.Body.String())
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().ListCategories(gomock.Any()).Return(nil, errors.New("db down"))
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/category", nil), rec)
+
+		apperrors.Handle(NewHandler(mockSvc).ListCategories, c)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestHandler_CreateCategory(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+
+	newRequest := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/category", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		return req
+	}
+
+	t.Run("created", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().CreateCategory(gomock.Any(), &CategoryReq{Name: "Drinks", SortOrder: 2}).
+			Return(&Category{ID: drinksID, Slug: "drinks", Name: "Drinks", SortOrder: 2}, nil)
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(newRequest(`{"name":"Drinks","sortOrder":2}`), rec)
+
+		apperrors.Handle(NewHandler(mockSvc).CreateCategory, c)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("slug taken", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().CreateCategory(gomock.Any(), gomock.Any()).Return(nil, ErrSlugTaken)
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(newRequest(`{"name":"Drinks"}`), rec)
+
+		apperrors.Handle(NewHandler(mockSvc).CreateCategory, c)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("malformed body", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(newRequest(`{"name":`), rec)
+
+		apperrors.Handle(NewHandler(mockSvc).CreateCategory, c)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestHandler_Menu(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+
+	t.Run("success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().Menu(gomock.Any(), true).DoAndReturn(func(_ context.Context, _ bool) (*Menu, error) {
+			return &Menu{Categories: []*Section{{ID: pizzaID, Slug: "pizza", Name: "Pizza",
+				Products: []*product.Product{{ID: "p1", Name: "Margherita", CategoryID: pizzaID, Category: "Pizza", Price: 9, Available: true, Version: 1,
+					Allergens: []string{"gluten", "milk"}, AllergensDeclared: true, Dietary: []string{"vegetarian"}}}}}}, nil
+		})
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/menu?availableNow=true", nil), rec)
+
+		apperrors.Handle(NewHandler(mockSvc).Menu, c)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"categories":[{"id":"`+pizzaID+`","slug":"pizza","name":"Pizza","products":[
+			{"id":"p1","restaurantId":"","name":"Margherita","price":9,"categoryId":"`+pizzaID+`","category":"Pizza","available":true,"version":1,
+			"allergens":["gluten","milk"],"allergensDeclared":true,"dietary":["vegetarian"]}]}]}`, rec.Body.String())
+	})
+
+	t.Run("invalid availableNow", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/menu?availableNow=soon", nil), rec)
+
+		apperrors.Handle(NewHandler(mockSvc).Menu, c)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().Menu(gomock.Any(), false).Return(nil, errors.New("db down"))
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/menu", nil), rec)
+
+		apperrors.Handle(NewHandler(mockSvc).Menu, c)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
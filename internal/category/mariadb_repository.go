@@ -0,0 +1,58 @@
+package category
+
+import (
+	"context"
+
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/db"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+)
+
+type MariaDBRepository struct{}
+
+func NewMariaDBRepository() Repository {
+	return &MariaDBRepository{}
+}
+
+func (r *MariaDBRepository) List(ctx context.Context) ([]*Category, error) {
+	query := `SELECT id, slug, name, sort_order, parent_id FROM categories ORDER BY sort_order, name, id`
+
+	rows, err := db.Pool.Query(ctx, query)
+	if err != nil {
+		appErr := apperrors.Internal("failed to list categories", err)
+		logger.Error(ctx, appErr.Message, "error", err.Error())
+		return nil, appErr
+	}
+	defer rows.Close()
+
+	var categories []*Category
+	for rows.Next() {
+		var c Category
+		if err := rows.Scan(&c.ID, &c.Slug, &c.Name, &c.SortOrder, &c.ParentID); err != nil {
+			appErr := apperrors.Internal("failed to scan category row", err)
+			logger.Error(ctx, appErr.Message, "error", err.Error())
+			return nil, appErr
+		}
+		categories = append(categories, &c)
+	}
+	if err := rows.Err(); err != nil {
+		appErr := apperrors.Internal("failed to read category rows", err)
+		logger.Error(ctx, appErr.Message, "error", err.Error())
+		return nil, appErr
+	}
+
+	return categories, nil
+}
+
+func (r *MariaDBRepository) Create(ctx context.Context, c *Category) error {
+	query := `INSERT INTO categories (id, slug, name, sort_order, parent_id) VALUES (?, ?, ?, ?, ?)`
+
+	if _, err := db.Pool.Exec(ctx, query, c.ID, c.Slug, c.Name, c.SortOrder, c.ParentID); err != nil {
+		appErr := apperrors.Internal("failed to create category", err)
+		logger.Error(ctx, appErr.Message, "slug", c.Slug, "error", err.Error())
+		return appErr
+	}
+
+	logger.Info(ctx, "category created", "id", c.ID, "slug", c.Slug)
+	return nil
+}
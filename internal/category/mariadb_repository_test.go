@@ -0,0 +1,88 @@
+package category
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/db"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMariaDBRepository_List(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	db.Pool = db.NewTestPool(sqlDB)
+
+	repo := NewMariaDBRepository()
+	cols := []string{"id", "slug", "name", "sort_order", "parent_id"}
+
+	t.Run("success", func(t *testing.T) {
+		rows := sqlmock.NewRows(cols).
+			AddRow(drinksID, "drinks", "Drinks", 1, nil).
+			AddRow(hotID, "hot-drinks", "Hot drinks", 1, drinksID)
+		mock.ExpectQuery("SELECT id, slug, name, sort_order, parent_id FROM categories ORDER BY sort_order, name, id").WillReturnRows(rows)
+
+		categories, err := repo.List(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []*Category{
+			{ID: drinksID, Slug: "drinks", Name: "Drinks", SortOrder: 1},
+			{ID: hotID, Slug: "hot-drinks", Name: "Hot drinks", SortOrder: 1, ParentID: strPtr(drinksID)},
+		}, categories)
+	})
+
+	t.Run("query fails", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM categories").WillReturnError(errors.New("db failed"))
+
+		categories, err := repo.List(ctx)
+		assert.Nil(t, categories)
+		assert.ErrorContains(t, err, "db failed")
+	})
+
+	t.Run("scan fails", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM categories").
+			WillReturnRows(sqlmock.NewRows(cols).AddRow(nil, nil, nil, nil, nil))
+
+		_, err := repo.List(ctx)
+		appErr, ok := err.(*apperrors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, "failed to scan category row", appErr.Message)
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMariaDBRepository_Create(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	db.Pool = db.NewTestPool(sqlDB)
+
+	repo := NewMariaDBRepository()
+	c := &Category{ID: hotID, Slug: "hot-drinks", Name: "Hot drinks", SortOrder: 1, ParentID: strPtr(drinksID)}
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO categories").
+			WithArgs(hotID, "hot-drinks", "Hot drinks", 1, strPtr(drinksID)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		assert.NoError(t, repo.Create(ctx, c))
+	})
+
+	t.Run("insert fails", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO categories").WillReturnError(errors.New("duplicate"))
+
+		assert.ErrorContains(t, repo.Create(ctx, c), "duplicate")
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
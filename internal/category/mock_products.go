@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: products.go
+
+// Package category is a generated GoMock package.
+package category
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	product "github.com/mohammadshabab/order-food-online/internal/product"
+)
+
+// MockProducts is a mock of Products interface.
+type MockProducts struct {
+	ctrl     *gomock.Controller
+	recorder *MockProductsMockRecorder
+}
+
+// MockProductsMockRecorder is the mock recorder for MockProducts.
+type MockProductsMockRecorder struct {
+	mock *MockProducts
+}
+
+// NewMockProducts creates a new mock instance.
+func NewMockProducts(ctrl *gomock.Controller) *MockProducts {
+	mock := &MockProducts{ctrl: ctrl}
+	mock.recorder = &MockProductsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProducts) EXPECT() *MockProductsMockRecorder {
+	return m.recorder
+}
+
+// ListAll mocks base method.
+func (m *MockProducts) ListAll(ctx context.Context, params product.ListParams) ([]*product.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAll", ctx, params)
+	ret0, _ := ret[0].([]*product.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAll indicates an expected call of ListAll.
+func (mr *MockProductsMockRecorder) ListAll(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAll", reflect.TypeOf((*MockProducts)(nil).ListAll), ctx, params)
+}
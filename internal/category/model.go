@@ -0,0 +1,43 @@
+package category
+
+import "github.com/mohammadshabab/order-food-online/internal/product"
+
+// Category groups products on the menu. Categories nest through ParentID and
+// are shown by SortOrder, then by name.
+type Category struct {
+	ID        string  `json:"id"`
+	Slug      string  `json:"slug"`
+	Name      string  `json:"name"`
+	SortOrder int     `json:"sortOrder"`
+	ParentID  *string `json:"parentId,omitempty"`
+}
+
+// CategoryReq is the body of POST /category. Slug is derived from Name when empty.
+type CategoryReq struct {
+	Name      string  `json:"name"`
+	Slug      string  `json:"slug,omitempty"`
+	SortOrder int     `json:"sortOrder"`
+	ParentID  *string `json:"parentId,omitempty"`
+}
+
+// CategoryList is the response of GET /category
+type CategoryList struct {
+	Items []*Category `json:"items"`
+}
+
+// Menu is the whole catalog grouped by the category tree, the response of GET /menu
+type Menu struct {
+	Categories []*Section `json:"categories"`
+	// Uncategorized holds products that have no category yet
+	Uncategorized []*product.Product `json:"uncategorized,omitempty"`
+}
+
+// Section is a category on the menu with its products and subcategories.
+// Categories without products, directly or below them, are left out.
+type Section struct {
+	ID       string             `json:"id"`
+	Slug     string             `json:"slug"`
+	Name     string             `json:"name"`
+	Products []*product.Product `json:"products"`
+	Children []*Section         `json:"children,omitempty"`
+}
@@ -0,0 +1,14 @@
+package category
+
+import (
+	"context"
+
+	"github.com/mohammadshabab/order-food-online/internal/product"
+)
+
+// Products lists the catalog the menu is built from. product.Service satisfies it.
+//
+//go:generate mockgen -source=products.go -destination=mock_products.go -package=category
+type Products interface {
+	ListAll(ctx context.Context, params product.ListParams) ([]*product.Product, error)
+}
@@ -0,0 +1,10 @@
+package category
+
+import "context"
+
+//go:generate mockgen -source=repository.go -destination=mock_repository.go -package=category
+type Repository interface {
+	// List returns every category ordered by sort order and name
+	List(ctx context.Context) ([]*Category, error)
+	Create(ctx context.Context, c *Category) error
+}
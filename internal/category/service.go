@@ -0,0 +1,141 @@
+package category
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/mohammadshabab/order-food-online/internal/product"
+)
+
+//go:generate mockgen -source=service.go -destination=mock_service.go -package=category
+type Service interface {
+	ListCategories(ctx context.Context) (*CategoryList, error)
+	CreateCategory(ctx context.Context, req *CategoryReq) (*Category, error)
+	// Menu returns the catalog grouped by category. With availableNow only
+	// products that can be ordered right now are included.
+	Menu(ctx context.Context, availableNow bool) (*Menu, error)
+}
+
+type service struct {
+	repo     Repository
+	products Products
+}
+
+func NewService(repo Repository, products Products) Service {
+	return &service{repo: repo, products: products}
+}
+
+func (s *service) ListCategories(ctx context.Context) (*CategoryList, error) {
+	categories, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if categories == nil {
+		categories = []*Category{}
+	}
+	return &CategoryList{Items: categories}, nil
+}
+
+func (s *service) CreateCategory(ctx context.Context, req *CategoryReq) (*Category, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	// the table is small, checking against the full list keeps the errors precise
+	existing, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	parentFound := req.ParentID == nil
+	for _, c := range existing {
+		if c.Slug == req.Slug {
+			return nil, ErrSlugTaken
+		}
+		if req.ParentID != nil && c.ID == *req.ParentID {
+			parentFound = true
+		}
+	}
+	if !parentFound {
+		return nil, ErrUnknownParent
+	}
+
+	c := &Category{
+		ID:        uuid.New().String(),
+		Slug:      req.Slug,
+		Name:      req.Name,
+		SortOrder: req.SortOrder,
+		ParentID:  req.ParentID,
+	}
+	if err := s.repo.Create(ctx, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *service) Menu(ctx context.Context, availableNow bool) (*Menu, error) {
+	categories, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := s.products.ListAll(ctx, product.ListParams{AvailableNow: availableNow})
+	if err != nil {
+		return nil, err
+	}
+
+	return buildMenu(categories, products), nil
+}
+
+// buildMenu hangs products under their categories and categories under their
+// parents, keeping the order of both inputs
+func buildMenu(categories []*Category, products []*product.Product) *Menu {
+	sections := make(map[string]*Section, len(categories))
+	for _, c := range categories {
+		sections[c.ID] = &Section{ID: c.ID, Slug: c.Slug, Name: c.Name, Products: []*product.Product{}}
+	}
+
+	menu := &Menu{}
+	for _, p := range products {
+		if sec, ok := sections[p.CategoryID]; ok {
+			sec.Products = append(sec.Products, p)
+		} else {
+			menu.Uncategorized = append(menu.Uncategorized, p)
+		}
+	}
+
+	var roots []*Section
+	for _, c := range categories {
+		sec := sections[c.ID]
+		if parent, ok := parentSection(c, sections); ok {
+			parent.Children = append(parent.Children, sec)
+		} else {
+			roots = append(roots, sec)
+		}
+	}
+
+	menu.Categories = prune(roots)
+	return menu
+}
+
+func parentSection(c *Category, sections map[string]*Section) (*Section, bool) {
+	if c.ParentID == nil || *c.ParentID == c.ID {
+		return nil, false
+	}
+	parent, ok := sections[*c.ParentID]
+	return parent, ok
+}
+
+// prune drops sections that have no products anywhere below them
+func prune(sections []*Section) []*Section {
+	kept := []*Section{}
+	for _, sec := range sections {
+		sec.Children = prune(sec.Children)
+		if len(sec.Products) > 0 || len(sec.Children) > 0 {
+			if len(sec.Children) == 0 {
+				sec.Children = nil
+			}
+			kept = append(kept, sec)
+		}
+	}
+	return kept
+}
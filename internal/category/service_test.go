@@ -0,0 +1,165 @@
+package category
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mohammadshabab/order-food-online/internal/product"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	drinksID = "11111111-1111-4111-8111-111111111111"
+	hotID    = "22222222-2222-4222-8222-222222222222"
+	pizzaID  = "33333333-3333-4333-8333-333333333333"
+	emptyID  = "44444444-4444-4444-8444-444444444444"
+)
+
+func strPtr(s string) *string { return &s }
+
+func testCategories() []*Category {
+	return []*Category{
+		{ID: pizzaID, Slug: "pizza", Name: "Pizza", SortOrder: 1},
+		{ID: drinksID, Slug: "drinks", Name: "Drinks", SortOrder: 2},
+		{ID: hotID, Slug: "hot-drinks", Name: "Hot drinks", SortOrder: 1, ParentID: strPtr(drinksID)},
+		{ID: emptyID, Slug: "desserts", Name: "Desserts", SortOrder: 3},
+	}
+}
+
+func TestService_ListCategories(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	svc := NewService(mockRepo, nil)
+
+	mockRepo.EXPECT().List(ctx).Return(nil, nil)
+	res, err := svc.ListCategories(ctx)
+	require.NoError(t, err)
+	assert.NotNil(t, res.Items)
+
+	mockRepo.EXPECT().List(ctx).Return(nil, errors.New("db error"))
+	_, err = svc.ListCategories(ctx)
+	assert.EqualError(t, err, "db error")
+}
+
+func TestService_CreateCategory(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().List(ctx).Return(testCategories(), nil)
+		mockRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, c *Category) error {
+			assert.NotEmpty(t, c.ID)
+			assert.Equal(t, "cold-drinks", c.Slug)
+			assert.Equal(t, drinksID, *c.ParentID)
+			return nil
+		})
+
+		c, err := NewService(mockRepo, nil).CreateCategory(ctx, &CategoryReq{Name: "Cold Drinks", SortOrder: 2, ParentID: strPtr(drinksID)})
+		require.NoError(t, err)
+		assert.Equal(t, "Cold Drinks", c.Name)
+		assert.Equal(t, 2, c.SortOrder)
+	})
+
+	t.Run("slug taken", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().List(ctx).Return(testCategories(), nil)
+
+		_, err := NewService(mockRepo, nil).CreateCategory(ctx, &CategoryReq{Name: "PIZZA"})
+		assert.Equal(t, ErrSlugTaken, err)
+	})
+
+	t.Run("unknown parent", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().List(ctx).Return(testCategories(), nil)
+
+		_, err := NewService(mockRepo, nil).CreateCategory(ctx, &CategoryReq{Name: "Tea", ParentID: strPtr("55555555-5555-4555-8555-555555555555")})
+		assert.Equal(t, ErrUnknownParent, err)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		_, err := NewService(NewMockRepository(ctrl), nil).CreateCategory(ctx, &CategoryReq{})
+		assert.Error(t, err)
+	})
+}
+
+func TestService_Menu(t *testing.T) {
+	ctx := context.Background()
+
+	margherita := &product.Product{ID: "p1", Name: "Margherita", CategoryID: pizzaID}
+	cola := &product.Product{ID: "p2", Name: "Cola", CategoryID: drinksID}
+	espresso := &product.Product{ID: "p3", Name: "Espresso", CategoryID: hotID}
+	loose := &product.Product{ID: "p4", Name: "Mystery box"}
+
+	t.Run("groups products by category tree", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockProducts := NewMockProducts(ctrl)
+
+		mockRepo.EXPECT().List(ctx).Return(testCategories(), nil)
+		mockProducts.EXPECT().ListAll(ctx, product.ListParams{AvailableNow: true}).
+			Return([]*product.Product{cola, espresso, margherita, loose}, nil)
+
+		menu, err := NewService(mockRepo, mockProducts).Menu(ctx, true)
+		require.NoError(t, err)
+
+		assert.Equal(t, &Menu{
+			Categories: []*Section{
+				{ID: pizzaID, Slug: "pizza", Name: "Pizza", Products: []*product.Product{margherita}},
+				{ID: drinksID, Slug: "drinks", Name: "Drinks", Products: []*product.Product{cola}, Children: []*Section{
+					{ID: hotID, Slug: "hot-drinks", Name: "Hot drinks", Products: []*product.Product{espresso}},
+				}},
+			},
+			Uncategorized: []*product.Product{loose},
+		}, menu)
+	})
+
+	t.Run("parent kept for products in a subcategory", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockProducts := NewMockProducts(ctrl)
+
+		mockRepo.EXPECT().List(ctx).Return(testCategories(), nil)
+		mockProducts.EXPECT().ListAll(ctx, gomock.Any()).Return([]*product.Product{espresso}, nil)
+
+		menu, err := NewService(mockRepo, mockProducts).Menu(ctx, false)
+		require.NoError(t, err)
+		require.Len(t, menu.Categories, 1)
+		assert.Equal(t, drinksID, menu.Categories[0].ID)
+		assert.Empty(t, menu.Categories[0].Products)
+		assert.Len(t, menu.Categories[0].Children, 1)
+	})
+
+	t.Run("empty catalog", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockProducts := NewMockProducts(ctrl)
+
+		mockRepo.EXPECT().List(ctx).Return(testCategories(), nil)
+		mockProducts.EXPECT().ListAll(ctx, gomock.Any()).Return([]*product.Product{}, nil)
+
+		menu, err := NewService(mockRepo, mockProducts).Menu(ctx, false)
+		require.NoError(t, err)
+		assert.NotNil(t, menu.Categories)
+		assert.Empty(t, menu.Categories)
+	})
+
+	t.Run("products error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockProducts := NewMockProducts(ctrl)
+
+		mockRepo.EXPECT().List(ctx).Return(testCategories(), nil)
+		mockProducts.EXPECT().ListAll(ctx, gomock.Any()).Return(nil, errors.New("db error"))
+
+		_, err := NewService(mockRepo, mockProducts).Menu(ctx, false)
+		assert.EqualError(t, err, "db error")
+	})
+}
@@ -0,0 +1,20 @@
+package category
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/audit"
+	"github.com/mohammadshabab/order-food-online/internal/auth"
+)
+
+// Setup registers the category and menu routes
+func Setup(e *echo.Echo, repo Repository, products Products, rec *audit.Recorder) {
+	svc := NewService(repo, products)
+	h := NewHandler(svc)
+
+	read := auth.RequireScope(auth.ScopeCatalogRead)
+
+	e.GET("/category", h.ListCategories, read)
+	e.POST("/category", h.CreateCategory, auth.RequireScope(auth.ScopeProductsAdmin),
+		rec.Middleware(audit.Action{Name: "category.create", Resource: "category"}))
+	e.GET("/menu", h.Menu, read)
+}
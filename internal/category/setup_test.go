@@ -0,0 +1,33 @@
+package category
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+)
+
+func TestSetup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	e := echo.New()
+	Setup(e, NewMockRepository(ctrl), NewMockProducts(ctrl), nil)
+
+	want := map[string]bool{
+		http.MethodGet + " /category":  false,
+		http.MethodPost + " /category": false,
+		http.MethodGet + " /menu":      false,
+	}
+	for _, r := range e.Routes() {
+		if _, ok := want[r.Method+" "+r.Path]; ok {
+			want[r.Method+" "+r.Path] = true
+		}
+	}
+	for route, found := range want {
+		if !found {
+			t.Errorf("expected %s to be registered but it was not", route)
+		}
+	}
+}
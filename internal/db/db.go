@@ -49,6 +49,7 @@ func (p *SQLPool) Exec(ctx context.Context, query string, args ...any) (sql.Resu
 	start := time.Now()
 	res, err := p.DB.ExecContext(ctx, query, args...)
 	duration := time.Since(start)
+	observe(query, start, err)
 
 	if err != nil {
 		logger.Error(ctx, "DB Exec failed",
@@ -72,6 +73,7 @@ func (p *SQLPool) Query(ctx context.Context, query string, args ...any) (*sql.Ro
 	start := time.Now()
 	rows, err := p.DB.QueryContext(ctx, query, args...)
 	duration := time.Since(start)
+	observe(query, start, err)
 
 	if err != nil {
 		logger.Error(ctx, "DB Query failed",
@@ -96,7 +98,10 @@ func (p *SQLPool) QueryRow(ctx context.Context, query string, args ...any) *sql.
 		"query", query,
 		"args", args,
 	)
-	return p.DB.QueryRowContext(ctx, query, args...)
+	start := time.Now()
+	row := p.DB.QueryRowContext(ctx, query, args...)
+	observe(query, start, row.Err())
+	return row
 }
 
 func Close() {
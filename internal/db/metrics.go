@@ -0,0 +1,108 @@
+package db
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/mohammadshabab/order-food-online/internal/metrics"
+)
+
+var (
+	queryDuration = metrics.NewHistogram("db_query_duration_seconds",
+		"Time to run a statement in seconds, by statement label.", metrics.DefBuckets, "statement")
+	queryErrors = metrics.NewCounter("db_query_errors_total",
+		"Statements that failed, by statement label.", "statement")
+)
+
+// The sql.DBStats of the pool, read when metrics are scraped
+func init() {
+	gauge := func(name, help string, fn func(sql.DBStats) float64) {
+		metrics.NewGaugeFunc(name, help, func() (float64, bool) { return readStats(fn) })
+	}
+	counter := func(name, help string, fn func(sql.DBStats) float64) {
+		metrics.NewCounterFunc(name, help, func() (float64, bool) { return readStats(fn) })
+	}
+
+	gauge("db_pool_max_open_connections", "Maximum number of open connections to the database.",
+		func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) })
+	gauge("db_pool_open_connections", "Established connections, in use and idle.",
+		func(s sql.DBStats) float64 { return float64(s.OpenConnections) })
+	gauge("db_pool_in_use_connections", "Connections currently in use.",
+		func(s sql.DBStats) float64 { return float64(s.InUse) })
+	gauge("db_pool_idle_connections", "Idle connections.",
+		func(s sql.DBStats) float64 { return float64(s.Idle) })
+	counter("db_pool_wait_count_total", "Connections waited for.",
+		func(s sql.DBStats) float64 { return float64(s.WaitCount) })
+	counter("db_pool_wait_duration_seconds_total", "Time blocked waiting for a connection in seconds.",
+		func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() })
+	counter("db_pool_max_idle_closed_total", "Connections closed because of the idle limit.",
+		func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) })
+	counter("db_pool_max_idle_time_closed_total", "Connections closed because they were idle too long.",
+		func(s sql.DBStats) float64 { return float64(s.MaxIdleTimeClosed) })
+	counter("db_pool_max_lifetime_closed_total", "Connections closed because of their maximum lifetime.",
+		func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) })
+}
+
+// readStats applies fn to the stats of the pool, there are none before Connect
+func readStats(fn func(sql.DBStats) float64) (float64, bool) {
+	if Pool == nil || Pool.DB == nil {
+		return 0, false
+	}
+	return fn(Pool.DB.Stats()), true
+}
+
+// observe records the duration of a statement and whether it failed
+func observe(query string, start time.Time, err error) {
+	label := statementLabel(query)
+	queryDuration.Observe(time.Since(start).Seconds(), label)
+	if err != nil {
+		queryErrors.Inc(label)
+	}
+}
+
+// statementLabel names a statement by its verb and main table, e.g.
+// "select products" or "insert order_items", so every query of a kind shares
+// one series whatever its arguments and joins
+func statementLabel(query string) string {
+	fields := strings.Fields(strings.ToLower(query))
+	if len(fields) == 0 {
+		return "unknown"
+	}
+
+	verb := fields[0]
+	var after string
+	switch verb {
+	case "select", "delete":
+		after = "from"
+	case "insert", "replace":
+		after = "into"
+	case "update":
+		if len(fields) > 1 {
+			return verb + " " + tableName(fields[1])
+		}
+		return verb
+	default:
+		return verb
+	}
+
+	for i := 1; i+1 < len(fields); i++ {
+		if fields[i] == after {
+			if table := tableName(fields[i+1]); table != "" {
+				return verb + " " + table
+			}
+			break
+		}
+	}
+	return verb
+}
+
+// tableName strips quotes and anything that is not part of a table name from s
+func tableName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == '.' || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, strings.SplitN(s, "(", 2)[0])
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+)
+
+func TestStatementLabel(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT id, name FROM products WHERE id = ?", "select products"},
+		{"\n\t\tSELECT p.id FROM `products` p LEFT JOIN categories c ON c.id = p.category_id", "select products"},
+		{"SELECT COUNT(*) FROM (SELECT id FROM orders) o", "select"},
+		{"INSERT INTO order_items (id, order_id) VALUES (?, ?)", "insert order_items"},
+		{"INSERT IGNORE INTO restaurants(id) VALUES (?)", "insert restaurants"},
+		{"UPDATE products p JOIN (SELECT 1) i SET p.stock = 1", "update products"},
+		{"DELETE FROM coupon_scopes WHERE code = ?", "delete coupon_scopes"},
+		{"SELECT 1", "select"},
+		{"  ", "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, statementLabel(tt.query))
+		})
+	}
+}
+
+func TestSQLPool_Metrics(t *testing.T) {
+	logger.Init("test-service", "test", slog.LevelInfo)
+	mock, cleanup := newMockPool(t)
+	defer cleanup()
+
+	label := "update metrics_test"
+	durations, failures := queryDuration.Count(label), queryErrors.Value(label)
+
+	mock.ExpectExec("UPDATE metrics_test").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE metrics_test").WillReturnError(errors.New("deadlock"))
+
+	_, err := Pool.Exec(context.Background(), "UPDATE metrics_test SET n = 1")
+	require.NoError(t, err)
+	_, err = Pool.Exec(context.Background(), "UPDATE metrics_test SET n = 2")
+	require.Error(t, err)
+
+	assert.Equal(t, durations+2, queryDuration.Count(label))
+	assert.Equal(t, failures+1, queryErrors.Value(label))
+
+	v, ok := readStats(func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) })
+	assert.True(t, ok)
+	assert.Equal(t, float64(0), v)
+}
+
+func TestReadStats_WithoutPool(t *testing.T) {
+	Pool = nil
+	_, ok := readStats(func(s sql.DBStats) float64 { return float64(s.OpenConnections) })
+	assert.False(t, ok)
+}
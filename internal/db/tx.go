@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+)
+
+// Querier is the query surface shared by SQLPool and SQLTx, so repository
+// helpers can run either on the pool or inside a transaction.
+type Querier interface {
+	Exec(ctx context.Context, query string, args ...any) (sql.Result, error)
+	Query(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRow(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// SQLTx wraps a transaction with the same logging and error wrapping as SQLPool
+type SQLTx struct {
+	Tx *sql.Tx
+}
+
+// InTx runs fn in a transaction. It commits when fn returns nil and rolls back
+// otherwise; the error returned by fn is passed through unchanged.
+func (p *SQLPool) InTx(ctx context.Context, fn func(tx *SQLTx) error) error {
+	sqlTx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error(ctx, "DB Begin failed", "error", err.Error())
+		return apperrors.Internal("DB Begin failed", err)
+	}
+
+	if err := fn(&SQLTx{Tx: sqlTx}); err != nil {
+		if rbErr := sqlTx.Rollback(); rbErr != nil {
+			logger.Error(ctx, "DB Rollback failed", "error", rbErr.Error())
+		}
+		return err
+	}
+
+	if err := sqlTx.Commit(); err != nil {
+		logger.Error(ctx, "DB Commit failed", "error", err.Error())
+		return apperrors.Internal("DB Commit failed", err)
+	}
+	return nil
+}
+
+func (t *SQLTx) Exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	start := time.Now()
+	res, err := t.Tx.ExecContext(ctx, query, args...)
+	duration := time.Since(start)
+	observe(query, start, err)
+
+	if err != nil {
+		logger.Error(ctx, "DB Exec failed",
+			"query", query,
+			"args", args,
+			"duration", duration.String(),
+			"error", err.Error(),
+		)
+		return nil, apperrors.Internal("DB Exec failed", err)
+	}
+
+	logger.Info(ctx, "DB Exec success",
+		"query", query,
+		"args", args,
+		"duration", duration.String(),
+	)
+	return res, nil
+}
+
+func (t *SQLTx) Query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := t.Tx.QueryContext(ctx, query, args...)
+	duration := time.Since(start)
+	observe(query, start, err)
+
+	if err != nil {
+		logger.Error(ctx, "DB Query failed",
+			"query", query,
+			"args", args,
+			"duration", duration.String(),
+			"error", err.Error(),
+		)
+		return nil, apperrors.Internal("DB Query failed", err)
+	}
+
+	logger.Info(ctx, "DB Query success",
+		"query", query,
+		"args", args,
+		"duration", duration.String(),
+	)
+	return rows, nil
+}
+
+func (t *SQLTx) QueryRow(ctx context.Context, query string, args ...any) *sql.Row {
+	logger.Info(ctx, "DB QueryRow",
+		"query", query,
+		"args", args,
+	)
+	start := time.Now()
+	row := t.Tx.QueryRowContext(ctx, query, args...)
+	observe(query, start, row.Err())
+	return row
+}
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+)
+
+var (
+	_ Querier = (*SQLPool)(nil)
+	_ Querier = (*SQLTx)(nil)
+)
+
+func TestSQLPool_InTx(t *testing.T) {
+	logger.Init("test-service", "test", slog.LevelInfo)
+	ctx := context.Background()
+
+	t.Run("commits when fn succeeds", func(t *testing.T) {
+		mock, cleanup := newMockPool(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE stock").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("SELECT stock").WillReturnRows(sqlmock.NewRows([]string{"stock"}).AddRow(4))
+		mock.ExpectCommit()
+
+		err := Pool.InTx(ctx, func(tx *SQLTx) error {
+			if _, err := tx.Exec(ctx, "UPDATE stock SET n = n - 1"); err != nil {
+				return err
+			}
+			var n int
+			return tx.QueryRow(ctx, "SELECT stock FROM stock").Scan(&n)
+		})
+
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rolls back and returns the fn error", func(t *testing.T) {
+		mock, cleanup := newMockPool(t)
+		defer cleanup()
+
+		fnErr := errors.New("out of stock")
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		err := Pool.InTx(ctx, func(tx *SQLTx) error { return fnErr })
+
+		assert.Equal(t, fnErr, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("statement errors are wrapped", func(t *testing.T) {
+		mock, cleanup := newMockPool(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT").WillReturnError(errors.New("bad query"))
+		mock.ExpectRollback()
+
+		err := Pool.InTx(ctx, func(tx *SQLTx) error {
+			_, err := tx.Query(ctx, "SELECT 1")
+			return err
+		})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "DB Query failed")
+	})
+
+	t.Run("begin fails", func(t *testing.T) {
+		mock, cleanup := newMockPool(t)
+		defer cleanup()
+
+		mock.ExpectBegin().WillReturnError(errors.New("no connection"))
+
+		err := Pool.InTx(ctx, func(tx *SQLTx) error { return nil })
+		assert.Contains(t, err.Error(), "DB Begin failed")
+	})
+
+	t.Run("commit fails", func(t *testing.T) {
+		mock, cleanup := newMockPool(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectCommit().WillReturnError(errors.New("deadlock"))
+
+		err := Pool.InTx(ctx, func(tx *SQLTx) error { return nil })
+		assert.Contains(t, err.Error(), "DB Commit failed")
+	})
+}
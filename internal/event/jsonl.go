@@ -0,0 +1,159 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// SyncMode controls when the file sink fsyncs to disk
+type SyncMode string
+
+const (
+	SyncNever    SyncMode = "never"  // leave flushing to the OS
+	SyncOnRotate SyncMode = "rotate" // fsync when a file is rotated or closed
+	SyncAlways   SyncMode = "always" // fsync after every event
+)
+
+const fileTimeLayout = "20060102T150405.000000000Z"
+
+type FileSinkConfig struct {
+	Dir      string
+	Prefix   string        // file name prefix, defaults to "events"
+	MaxBytes int64         // rotate once a file would grow past this size, 0 disables
+	MaxAge   time.Duration // rotate files older than this, 0 disables
+	Sync     SyncMode
+}
+
+// FileSink is an EventPublisher appending events as JSON lines to rotating files
+// named <prefix>-<utc timestamp>.jsonl, so lexical order is chronological order.
+type FileSink struct {
+	mu       sync.Mutex
+	cfg      FileSinkConfig
+	f        *os.File
+	size     int64
+	openedAt time.Time
+	now      func() time.Time
+}
+
+func NewFileSink(cfg FileSinkConfig) (*FileSink, error) {
+	if cfg.Prefix == "" {
+		cfg.Prefix = "events"
+	}
+	switch cfg.Sync {
+	case "":
+		cfg.Sync = SyncOnRotate
+	case SyncNever, SyncOnRotate, SyncAlways:
+	default:
+		return nil, fmt.Errorf("unknown event log sync mode %q, expected %s, %s or %s", cfg.Sync, SyncNever, SyncOnRotate, SyncAlways)
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create event log dir: %w", err)
+	}
+	return &FileSink{cfg: cfg, now: time.Now}, nil
+}
+
+// Publish implements EventPublisher
+func (s *FileSink) Publish(ctx context.Context, evt Event) error {
+	line, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.shouldRotate(int64(len(line))) {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.f.Write(line)
+	s.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+	if s.cfg.Sync == SyncAlways {
+		return s.f.Sync()
+	}
+	return nil
+}
+
+func (s *FileSink) shouldRotate(next int64) bool {
+	if s.f == nil {
+		return true
+	}
+	if s.cfg.MaxBytes > 0 && s.size > 0 && s.size+next > s.cfg.MaxBytes {
+		return true
+	}
+	return s.cfg.MaxAge > 0 && s.now().Sub(s.openedAt) >= s.cfg.MaxAge
+}
+
+func (s *FileSink) rotate() error {
+	if err := s.closeFile(); err != nil {
+		return err
+	}
+
+	now := s.now().UTC()
+	name := fmt.Sprintf("%s-%s.jsonl", s.cfg.Prefix, now.Format(fileTimeLayout))
+	f, err := os.OpenFile(filepath.Join(s.cfg.Dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open event log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	s.f = f
+	s.size = info.Size()
+	s.openedAt = now
+	return nil
+}
+
+func (s *FileSink) closeFile() error {
+	if s.f == nil {
+		return nil
+	}
+	f := s.f
+	s.f = nil
+	if s.cfg.Sync != SyncNever {
+		if err := f.Sync(); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
+
+// Close flushes and closes the current file
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closeFile()
+}
+
+// WriterPublisher writes events as JSON lines to any io.Writer (e.g. stdout)
+type WriterPublisher struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func NewWriterPublisher(w io.Writer) *WriterPublisher {
+	return &WriterPublisher{enc: json.NewEncoder(w)}
+}
+
+// Publish implements EventPublisher
+func (w *WriterPublisher) Publish(ctx context.Context, evt Event) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.enc.Encode(evt)
+}
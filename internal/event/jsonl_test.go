@@ -0,0 +1,99 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSink_Publish(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("appends one JSON line per event", func(t *testing.T) {
+		dir := t.TempDir()
+		sink, err := NewFileSink(FileSinkConfig{Dir: dir, Sync: SyncAlways})
+		require.NoError(t, err)
+
+		require.NoError(t, sink.Publish(ctx, Event{Type: EventOrderCreated, OrderID: "o1"}))
+		require.NoError(t, sink.Publish(ctx, Event{Type: EventOrderPaid, OrderID: "o1"}))
+		require.NoError(t, sink.Close())
+
+		files, err := LogFiles(dir)
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		assert.True(t, strings.HasPrefix(filepath.Base(files[0]), "events-"))
+
+		data, err := os.ReadFile(files[0])
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		require.Len(t, lines, 2)
+		assert.Contains(t, lines[0], `"type":"order.created"`)
+		assert.Contains(t, lines[1], `"type":"order.paid"`)
+	})
+
+	t.Run("rotates on size", func(t *testing.T) {
+		dir := t.TempDir()
+		sink, err := NewFileSink(FileSinkConfig{Dir: dir, MaxBytes: 100, Sync: SyncNever})
+		require.NoError(t, err)
+		clock := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		sink.now = func() time.Time {
+			clock = clock.Add(time.Millisecond)
+			return clock
+		}
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, sink.Publish(ctx, Event{Type: EventOrderCreated, OrderID: "order-with-a-long-identifier"}))
+		}
+		require.NoError(t, sink.Close())
+
+		files, err := LogFiles(dir)
+		require.NoError(t, err)
+		assert.Len(t, files, 3)
+	})
+
+	t.Run("rotates on age", func(t *testing.T) {
+		dir := t.TempDir()
+		sink, err := NewFileSink(FileSinkConfig{Dir: dir, MaxAge: time.Hour})
+		require.NoError(t, err)
+		clock := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		sink.now = func() time.Time { return clock }
+
+		require.NoError(t, sink.Publish(ctx, Event{Type: EventOrderCreated}))
+		clock = clock.Add(30 * time.Minute)
+		require.NoError(t, sink.Publish(ctx, Event{Type: EventOrderCreated}))
+		clock = clock.Add(31 * time.Minute)
+		require.NoError(t, sink.Publish(ctx, Event{Type: EventOrderCreated}))
+		require.NoError(t, sink.Close())
+
+		files, err := LogFiles(dir)
+		require.NoError(t, err)
+		assert.Len(t, files, 2)
+	})
+
+	t.Run("unknown sync mode", func(t *testing.T) {
+		_, err := NewFileSink(FileSinkConfig{Dir: t.TempDir(), Sync: "sometimes"})
+		assert.EqualError(t, err, `unknown event log sync mode "sometimes", expected never, rotate or always`)
+	})
+
+	t.Run("close without writes", func(t *testing.T) {
+		sink, err := NewFileSink(FileSinkConfig{Dir: t.TempDir()})
+		require.NoError(t, err)
+		assert.NoError(t, sink.Close())
+	})
+}
+
+func TestWriterPublisher(t *testing.T) {
+	var buf bytes.Buffer
+	pub := NewWriterPublisher(&buf)
+
+	require.NoError(t, pub.Publish(context.Background(), Event{Type: EventOrderCancelled, OrderID: "o9"}))
+	assert.Contains(t, buf.String(), `"orderId":"o9"`)
+	assert.True(t, strings.HasSuffix(buf.String(), "\n"))
+}
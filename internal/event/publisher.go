@@ -1,6 +1,9 @@
 package event
 
-import "context"
+import (
+	"context"
+	"errors"
+)
 
 // EventPublisher defines the interface for any event publisher
 type EventPublisher interface {
@@ -21,3 +24,23 @@ func NewPublisher(impl EventPublisher) *Publisher {
 func (p *Publisher) PublishEvent(ctx context.Context, evt Event) error {
 	return p.impl.Publish(ctx, evt)
 }
+
+// MultiPublisher fans an event out to several publishers (e.g. SSE broker and file sink)
+type MultiPublisher struct {
+	impls []EventPublisher
+}
+
+func NewMultiPublisher(impls ...EventPublisher) EventPublisher {
+	return &MultiPublisher{impls: impls}
+}
+
+// Publish implements EventPublisher. Every publisher is tried, errors are joined.
+func (m *MultiPublisher) Publish(ctx context.Context, evt Event) error {
+	var errs []error
+	for _, p := range m.impls {
+		if err := p.Publish(ctx, evt); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
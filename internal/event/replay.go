@@ -0,0 +1,102 @@
+package event
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// ReplayFilter selects which logged events are replayed. Zero value matches everything.
+type ReplayFilter struct {
+	Types map[EventType]bool
+	Since time.Time // inclusive
+	Until time.Time // exclusive
+}
+
+func (f ReplayFilter) Match(evt Event) bool {
+	if len(f.Types) > 0 && !f.Types[evt.Type] {
+		return false
+	}
+	if !f.Since.IsZero() && evt.OccurredAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !evt.OccurredAt.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// LogFiles returns the JSONL files for path: the file itself, or every *.jsonl in
+// a directory in chronological (lexical) order.
+func LogFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(path, "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// ReadFile calls fn for every event in a JSONL file, in file order.
+func ReadFile(path string, fn func(Event) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var evt Event
+		if err := json.Unmarshal(scanner.Bytes(), &evt); err != nil {
+			return fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		if err := fn(evt); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// Replay publishes every matching event from files into dst and returns how many were published.
+func Replay(ctx context.Context, files []string, filter ReplayFilter, dst EventPublisher) (int, error) {
+	count := 0
+	for _, path := range files {
+		err := ReadFile(path, func(evt Event) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if !filter.Match(evt) {
+				return nil
+			}
+			if err := dst.Publish(ctx, evt); err != nil {
+				return err
+			}
+			count++
+			return nil
+		})
+		if err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
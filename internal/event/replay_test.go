@@ -0,0 +1,118 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type collector struct {
+	events []Event
+	err    error
+}
+
+func (c *collector) Publish(_ context.Context, evt Event) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.events = append(c.events, evt)
+	return nil
+}
+
+func writeLog(t *testing.T, dir string, events ...Event) {
+	t.Helper()
+	sink, err := NewFileSink(FileSinkConfig{Dir: dir})
+	require.NoError(t, err)
+	for _, evt := range events {
+		require.NoError(t, sink.Publish(context.Background(), evt))
+	}
+	require.NoError(t, sink.Close())
+}
+
+func TestReplay(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	dir := t.TempDir()
+	writeLog(t, dir,
+		Event{Type: EventOrderCreated, OrderID: "o1", OccurredAt: base},
+		Event{Type: EventOrderPaid, OrderID: "o1", OccurredAt: base.Add(time.Minute)},
+		Event{Type: EventOrderCreated, OrderID: "o2", OccurredAt: base.Add(2 * time.Minute)},
+	)
+	files, err := LogFiles(dir)
+	require.NoError(t, err)
+
+	t.Run("replays everything", func(t *testing.T) {
+		dst := &collector{}
+		n, err := Replay(ctx, files, ReplayFilter{}, dst)
+		require.NoError(t, err)
+		assert.Equal(t, 3, n)
+		assert.Equal(t, "o1", dst.events[0].OrderID)
+		assert.True(t, base.Equal(dst.events[0].OccurredAt))
+	})
+
+	t.Run("filters by type", func(t *testing.T) {
+		dst := &collector{}
+		n, err := Replay(ctx, files, ReplayFilter{Types: map[EventType]bool{EventOrderCreated: true}}, dst)
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+	})
+
+	t.Run("filters by time range", func(t *testing.T) {
+		dst := &collector{}
+		filter := ReplayFilter{Since: base.Add(time.Minute), Until: base.Add(2 * time.Minute)}
+		n, err := Replay(ctx, files, filter, dst)
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+		assert.Equal(t, EventOrderPaid, dst.events[0].Type)
+	})
+
+	t.Run("destination error stops replay", func(t *testing.T) {
+		dst := &collector{err: errors.New("down")}
+		n, err := Replay(ctx, files, ReplayFilter{}, dst)
+		assert.Error(t, err)
+		assert.Equal(t, 0, n)
+	})
+
+	t.Run("invalid line reports position", func(t *testing.T) {
+		bad := filepath.Join(t.TempDir(), "bad.jsonl")
+		require.NoError(t, os.WriteFile(bad, []byte("{\"type\":\"order.created\"}\nnot-json\n"), 0o644))
+
+		_, err := Replay(ctx, []string{bad}, ReplayFilter{}, &collector{})
+		assert.ErrorContains(t, err, "bad.jsonl:2")
+	})
+}
+
+func TestLogFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"events-2.jsonl", "events-1.jsonl", "notes.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o644))
+	}
+
+	files, err := LogFiles(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "events-1.jsonl"), filepath.Join(dir, "events-2.jsonl")}, files)
+
+	single, err := LogFiles(files[0])
+	require.NoError(t, err)
+	assert.Equal(t, files[:1], single)
+
+	_, err = LogFiles(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestMultiPublisher(t *testing.T) {
+	ok := &collector{}
+	failing := &collector{err: errors.New("down")}
+	pub := NewMultiPublisher(failing, ok)
+
+	err := pub.Publish(context.Background(), Event{Type: EventOrderCreated})
+	assert.ErrorContains(t, err, "down")
+	assert.Len(t, ok.events, 1, "remaining publishers still receive the event")
+}
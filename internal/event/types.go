@@ -1,5 +1,7 @@
 package event
 
+import "time"
+
 type EventType string
 
 const (
@@ -9,6 +11,10 @@ const (
 )
 
 type Event struct {
-	Type EventType
-	Data any
+	Type       EventType `json:"type"`
+	OrderID    string    `json:"orderId,omitempty"`
+	OccurredAt time.Time `json:"occurredAt"`
+	// RequestID is the X-Request-ID of the request that caused the event
+	RequestID string `json:"requestId,omitempty"`
+	Data      any    `json:"data,omitempty"`
 }
@@ -2,9 +2,21 @@ package health
 
 import "github.com/labstack/echo/v4"
 
+// Health-check routes
+const (
+	PathCheck = "/health"
+	PathPing  = "/health/ping"
+)
+
 // Register registers health-check endpoints on the provided Echo router.
 func Register(e *echo.Echo) {
 	h := NewHandler()
-	e.GET("/health", h.Check)
-	e.GET("/health/ping", h.Ping)
+	e.GET(PathCheck, h.Check)
+	e.GET(PathPing, h.Ping)
+}
+
+// IsHealthCheck reports whether path is one of the health-check routes, which
+// are open and left out of rate limiting.
+func IsHealthCheck(path string) bool {
+	return path == PathCheck || path == PathPing
 }
@@ -0,0 +1,17 @@
+package idempotency
+
+import (
+	"net/http"
+
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+)
+
+var (
+	ErrKeyInvalid  = apperrors.BadRequest("Idempotency-Key must be 1-255 characters", nil).WithCode(apperrors.CodeIdempotencyKeyInvalid)
+	ErrKeyReused   = apperrors.Wrap(http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request", apperrors.LevelWarn, nil).WithCode(apperrors.CodeIdempotencyKeyReused)
+	ErrKeyInFlight = apperrors.Wrap(http.StatusConflict, "a request with this Idempotency-Key is still being processed", apperrors.LevelWarn, nil).WithCode(apperrors.CodeIdempotencyKeyInFlight)
+
+	// ErrLeaseLost is never sent to a client, the request that took the key
+	// over answers the retry
+	ErrLeaseLost = apperrors.Wrap(http.StatusConflict, "Idempotency-Key reservation was taken over by another request", apperrors.LevelWarn, nil)
+)
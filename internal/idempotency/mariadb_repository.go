@@ -0,0 +1,149 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/db"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+)
+
+type MariaDBRepository struct{}
+
+func NewMariaDBRepository() Repository {
+	return &MariaDBRepository{}
+}
+
+func (r *MariaDBRepository) Reserve(ctx context.Context, actor, key, fingerprint, leaseID string, lockedUntil, expiresAt time.Time) (*Record, bool, error) {
+	// The primary key makes the insert the arbiter between concurrent duplicates:
+	// exactly one request claims the key, the others read the existing record.
+	insertQuery := `INSERT IGNORE INTO idempotency_keys (actor, idempotency_key, fingerprint, lease_id, status, locked_until, expires_at)
+	                VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	// Three attempts: later ones run after an expired record was removed or a
+	// stale reservation was taken over by someone else
+	for attempt := 0; attempt < 3; attempt++ {
+		res, err := db.Pool.Exec(ctx, insertQuery, actor, key, fingerprint, leaseID, StatusInProgress, lockedUntil, expiresAt)
+		if err != nil {
+			appErr := apperrors.Internal("failed to reserve idempotency key", err)
+			logger.Error(ctx, appErr.Message, "error", err.Error())
+			return nil, false, appErr
+		}
+		if n, err := res.RowsAffected(); err == nil && n == 1 {
+			return nil, true, nil
+		}
+
+		existing, err := r.get(ctx, actor, key)
+		if errors.Is(err, sql.ErrNoRows) {
+			// released between our insert and read, try to claim it again
+			continue
+		}
+		if err != nil {
+			appErr := apperrors.Internal("failed to read idempotency key", err)
+			logger.Error(ctx, appErr.Message, "error", err.Error())
+			return nil, false, appErr
+		}
+
+		now := time.Now().UTC()
+		if !existing.ExpiresAt.After(now) {
+			deleteQuery := `DELETE FROM idempotency_keys WHERE actor = ? AND idempotency_key = ? AND expires_at <= ?`
+			if _, err := db.Pool.Exec(ctx, deleteQuery, actor, key, now); err != nil {
+				appErr := apperrors.Internal("failed to remove expired idempotency key", err)
+				logger.Error(ctx, appErr.Message, "error", err.Error())
+				return nil, false, appErr
+			}
+			continue
+		}
+		if existing.Status != StatusInProgress || existing.LockedUntil.After(now) {
+			return existing, false, nil
+		}
+
+		// The request holding the key never finished, e.g. its instance died:
+		// take the reservation over, unless another retry just did
+		takeOverQuery := `UPDATE idempotency_keys SET fingerprint = ?, lease_id = ?, locked_until = ?, expires_at = ?
+		                  WHERE actor = ? AND idempotency_key = ? AND status = ? AND locked_until <= ?`
+		res, err = db.Pool.Exec(ctx, takeOverQuery, fingerprint, leaseID, lockedUntil, expiresAt, actor, key, StatusInProgress, now)
+		if err != nil {
+			appErr := apperrors.Internal("failed to take over idempotency key", err)
+			logger.Error(ctx, appErr.Message, "error", err.Error())
+			return nil, false, appErr
+		}
+		if n, err := res.RowsAffected(); err == nil && n == 1 {
+			logger.Warn(ctx, "took over stale idempotency key", "key", key)
+			return nil, true, nil
+		}
+	}
+
+	appErr := apperrors.Internal("failed to reserve idempotency key", nil)
+	logger.Error(ctx, appErr.Message, "key", key)
+	return nil, false, appErr
+}
+
+func (r *MariaDBRepository) get(ctx context.Context, actor, key string) (*Record, error) {
+	query := `SELECT fingerprint, status, response_code, content_type, response_body, locked_until, expires_at
+	          FROM idempotency_keys WHERE actor = ? AND idempotency_key = ?`
+
+	var (
+		rec         = Record{Actor: actor, Key: key}
+		code        sql.NullInt64
+		contentType sql.NullString
+	)
+	err := db.Pool.QueryRow(ctx, query, actor, key).
+		Scan(&rec.Fingerprint, &rec.Status, &code, &contentType, &rec.ResponseBody, &rec.LockedUntil, &rec.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	rec.ResponseCode = int(code.Int64)
+	rec.ContentType = contentType.String
+	return &rec, nil
+}
+
+func (r *MariaDBRepository) Complete(ctx context.Context, actor, key, leaseID string, code int, contentType string, body []byte) error {
+	query := `UPDATE idempotency_keys SET status = ?, response_code = ?, content_type = ?, response_body = ?
+	          WHERE actor = ? AND idempotency_key = ? AND status = ? AND lease_id = ?`
+
+	res, err := db.Pool.Exec(ctx, query, StatusCompleted, code, contentType, body, actor, key, StatusInProgress, leaseID)
+	if err != nil {
+		appErr := apperrors.Internal("failed to store idempotent response", err)
+		logger.Error(ctx, appErr.Message, "error", err.Error())
+		return appErr
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		appErr := apperrors.Internal("failed to read affected rows", err)
+		logger.Error(ctx, appErr.Message, "error", err.Error())
+		return appErr
+	}
+	if n == 0 {
+		// the lease ran out and a retry took the key over, its outcome wins
+		logger.Warn(ctx, ErrLeaseLost.Message, "key", key)
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+func (r *MariaDBRepository) Release(ctx context.Context, actor, key, leaseID string) error {
+	query := `DELETE FROM idempotency_keys WHERE actor = ? AND idempotency_key = ? AND status = ? AND lease_id = ?`
+
+	if _, err := db.Pool.Exec(ctx, query, actor, key, StatusInProgress, leaseID); err != nil {
+		appErr := apperrors.Internal("failed to release idempotency key", err)
+		logger.Error(ctx, appErr.Message, "error", err.Error())
+		return appErr
+	}
+	return nil
+}
+
+func (r *MariaDBRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	query := `DELETE FROM idempotency_keys WHERE expires_at <= ?`
+
+	res, err := db.Pool.Exec(ctx, query, now)
+	if err != nil {
+		appErr := apperrors.Internal("failed to delete expired idempotency keys", err)
+		logger.Error(ctx, appErr.Message, "error", err.Error())
+		return 0, appErr
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,145 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mohammadshabab/order-food-online/internal/db"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMariaDBRepository_Reserve(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	db.Pool = db.NewTestPool(sqlDB)
+
+	repo := NewMariaDBRepository()
+	locked := time.Now().UTC().Add(time.Minute)
+	expires := time.Now().UTC().Add(time.Hour)
+	cols := []string{"fingerprint", "status", "response_code", "content_type", "response_body", "locked_until", "expires_at"}
+
+	t.Run("claims new key", func(t *testing.T) {
+		mock.ExpectExec("INSERT IGNORE INTO idempotency_keys").
+			WithArgs("key:a", "k1", "fp", "l1", StatusInProgress, locked, expires).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		rec, reserved, err := repo.Reserve(ctx, "key:a", "k1", "fp", "l1", locked, expires)
+		require.NoError(t, err)
+		assert.True(t, reserved)
+		assert.Nil(t, rec)
+	})
+
+	t.Run("returns existing record", func(t *testing.T) {
+		mock.ExpectExec("INSERT IGNORE INTO idempotency_keys").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT fingerprint, status, response_code, content_type, response_body, locked_until, expires_at").
+			WithArgs("key:a", "k1").
+			WillReturnRows(sqlmock.NewRows(cols).AddRow("fp", "completed", 200, "application/json", []byte(`{}`), locked, expires))
+
+		rec, reserved, err := repo.Reserve(ctx, "key:a", "k1", "fp", "l1", locked, expires)
+		require.NoError(t, err)
+		assert.False(t, reserved)
+		assert.Equal(t, StatusCompleted, rec.Status)
+		assert.Equal(t, 200, rec.ResponseCode)
+		assert.Equal(t, []byte(`{}`), rec.ResponseBody)
+	})
+
+	t.Run("expired record is replaced", func(t *testing.T) {
+		mock.ExpectExec("INSERT IGNORE INTO idempotency_keys").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT fingerprint").
+			WillReturnRows(sqlmock.NewRows(cols).AddRow("old", "completed", 200, nil, nil, time.Now().Add(-time.Hour), time.Now().Add(-time.Minute)))
+		mock.ExpectExec("DELETE FROM idempotency_keys WHERE actor = \\? AND idempotency_key = \\? AND expires_at").
+			WithArgs("key:a", "k1", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT IGNORE INTO idempotency_keys").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		_, reserved, err := repo.Reserve(ctx, "key:a", "k1", "fp", "l1", locked, expires)
+		require.NoError(t, err)
+		assert.True(t, reserved)
+	})
+
+	t.Run("reservation within its lease is returned", func(t *testing.T) {
+		mock.ExpectExec("INSERT IGNORE INTO idempotency_keys").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT fingerprint").
+			WillReturnRows(sqlmock.NewRows(cols).AddRow("fp", "in_progress", nil, nil, nil, locked, expires))
+
+		rec, reserved, err := repo.Reserve(ctx, "key:a", "k1", "fp", "l1", locked, expires)
+		require.NoError(t, err)
+		assert.False(t, reserved)
+		assert.Equal(t, StatusInProgress, rec.Status)
+	})
+
+	t.Run("stale reservation is taken over", func(t *testing.T) {
+		mock.ExpectExec("INSERT IGNORE INTO idempotency_keys").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT fingerprint").
+			WillReturnRows(sqlmock.NewRows(cols).AddRow("fp", "in_progress", nil, nil, nil, time.Now().Add(-time.Second), expires))
+		mock.ExpectExec("UPDATE idempotency_keys SET fingerprint = \\?, lease_id = \\?, locked_until = \\?, expires_at = \\?").
+			WithArgs("fp", "l1", locked, expires, "key:a", "k1", StatusInProgress, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		rec, reserved, err := repo.Reserve(ctx, "key:a", "k1", "fp", "l1", locked, expires)
+		require.NoError(t, err)
+		assert.True(t, reserved)
+		assert.Nil(t, rec)
+	})
+
+	t.Run("insert fails", func(t *testing.T) {
+		mock.ExpectExec("INSERT IGNORE INTO idempotency_keys").
+			WillReturnError(errors.New("db down"))
+
+		_, _, err := repo.Reserve(ctx, "key:a", "k1", "fp", "l1", locked, expires)
+		assert.ErrorContains(t, err, "db down")
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMariaDBRepository_CompleteReleaseDelete(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	db.Pool = db.NewTestPool(sqlDB)
+
+	repo := NewMariaDBRepository()
+
+	mock.ExpectExec("UPDATE idempotency_keys SET status = \\?, response_code = \\?, content_type = \\?, response_body = \\?\\s+WHERE actor = \\? AND idempotency_key = \\? AND status = \\? AND lease_id = \\?").
+		WithArgs(StatusCompleted, 200, "application/json", []byte("{}"), "key:a", "k1", StatusInProgress, "l1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.Complete(ctx, "key:a", "k1", "l1", 200, "application/json", []byte("{}")))
+
+	// a retry took the key over after the lease ran out, its outcome is kept
+	mock.ExpectExec("UPDATE idempotency_keys SET status").
+		WithArgs(StatusCompleted, 201, "application/json", []byte("{}"), "key:a", "k1", StatusInProgress, "l1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.Equal(t, ErrLeaseLost, repo.Complete(ctx, "key:a", "k1", "l1", 201, "application/json", []byte("{}")))
+
+	mock.ExpectExec("DELETE FROM idempotency_keys WHERE actor = \\? AND idempotency_key = \\? AND status = \\? AND lease_id = \\?").
+		WithArgs("key:a", "k1", StatusInProgress, "l1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.Release(ctx, "key:a", "k1", "l1"))
+
+	mock.ExpectExec("DELETE FROM idempotency_keys WHERE expires_at").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	n, err := repo.DeleteExpired(ctx, time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+
+	mock.ExpectExec("UPDATE idempotency_keys").WillReturnError(errors.New("db down"))
+	assert.Error(t, repo.Complete(ctx, "key:a", "k1", "l1", 200, "", nil))
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
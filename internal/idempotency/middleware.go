@@ -0,0 +1,177 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/auth"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+)
+
+const (
+	HeaderKey      = "Idempotency-Key"
+	HeaderReplayed = "Idempotent-Replayed"
+	maxKeyLength   = 255
+
+	// lease is how long a reservation holds off other requests with the same
+	// key. It is well past the time an order takes, so a slow request is not run
+	// twice, but lets a retry take over the key of a request whose instance died.
+	lease = time.Minute
+)
+
+// Middleware makes a route safe to retry. Requests carrying an Idempotency-Key
+// are executed once; replays with the same key and body get the stored response
+// byte-for-byte, replays with a different body get a 422. Keys are scoped to
+// the caller, the API key or the user of the bearer token, so two callers
+// choosing the same key never see each other's responses.
+func Middleware(repo Repository, retention time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(HeaderKey)
+			if key == "" {
+				return next(c)
+			}
+
+			ctx := c.Request().Context()
+			if len(key) > maxKeyLength {
+				logger.Warn(ctx, ErrKeyInvalid.Message, "length", len(key))
+				return ErrKeyInvalid
+			}
+
+			body, err := io.ReadAll(c.Request().Body)
+			if err != nil {
+				appErr := apperrors.BadRequest("failed to read request body", err)
+				logger.Warn(ctx, appErr.Message, "error", err.Error())
+				return appErr
+			}
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+			actor, _ := auth.Actor(ctx)
+			fp := fingerprint(actor, c.Request(), body)
+			now := time.Now().UTC()
+			// the lease id tells this request apart from a retry that takes
+			// the key over once the lease has run out
+			leaseID := uuid.New().String()
+			existing, reserved, err := repo.Reserve(ctx, actor, key, fp, leaseID, now.Add(lease), now.Add(retention))
+			if err != nil {
+				appErr := apperrors.Internal("failed to check Idempotency-Key", err)
+				return appErr
+			}
+
+			if !reserved {
+				return replay(c, existing, fp)
+			}
+
+			// The outcome is stored even when the client has gone away, the
+			// retry it is about to send must find it
+			storeCtx := context.WithoutCancel(ctx)
+			defer func() {
+				// the recover middleware turns the panic into a 500, which is
+				// not final either
+				if r := recover(); r != nil {
+					release(storeCtx, repo, actor, key, leaseID)
+					panic(r)
+				}
+			}()
+
+			rec := &recorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = rec
+
+			if err := next(c); err != nil {
+				// write the error now, so rejected requests are replayed like
+				// any other response
+				c.Error(err)
+			}
+
+			status := c.Response().Status
+			if status >= http.StatusInternalServerError {
+				// Server side failures are not final, let the client retry with the same key
+				release(storeCtx, repo, actor, key, leaseID)
+				return nil
+			}
+
+			contentType := c.Response().Header().Get(echo.HeaderContentType)
+			err = repo.Complete(storeCtx, actor, key, leaseID, status, contentType, rec.body.Bytes())
+			if err != nil && !errors.Is(err, ErrLeaseLost) {
+				logger.Error(ctx, "failed to store idempotent response", "error", err.Error())
+			}
+			return nil
+		}
+	}
+}
+
+func replay(c echo.Context, existing *Record, fp string) error {
+	ctx := c.Request().Context()
+
+	if existing.Fingerprint != fp {
+		logger.Warn(ctx, ErrKeyReused.Message, "key", existing.Key)
+		return ErrKeyReused
+	}
+	if existing.Status != StatusCompleted {
+		logger.Warn(ctx, ErrKeyInFlight.Message, "key", existing.Key)
+		return ErrKeyInFlight
+	}
+
+	logger.Info(ctx, "replaying idempotent response", "key", existing.Key, "status", existing.ResponseCode)
+	c.Response().Header().Set(HeaderReplayed, "true")
+	return c.Blob(existing.ResponseCode, existing.ContentType, existing.ResponseBody)
+}
+
+func release(ctx context.Context, repo Repository, actor, key, leaseID string) {
+	if err := repo.Release(ctx, actor, key, leaseID); err != nil {
+		logger.Error(ctx, "failed to release Idempotency-Key", "error", err.Error())
+	}
+}
+
+func fingerprint(actor string, r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, actor)
+	io.WriteString(h, "\n")
+	io.WriteString(h, r.Method)
+	io.WriteString(h, "\n")
+	io.WriteString(h, r.URL.Path)
+	io.WriteString(h, "\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recorder tees the response body so it can be stored for replays
+type recorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// RunJanitor periodically deletes expired keys until ctx is cancelled
+func RunJanitor(ctx context.Context, repo Repository, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := repo.DeleteExpired(ctx, time.Now().UTC())
+			if err != nil {
+				continue
+			}
+			if n > 0 {
+				logger.Info(ctx, "deleted expired idempotency keys", "count", n)
+			}
+		}
+	}
+}
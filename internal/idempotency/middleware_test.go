@@ -0,0 +1,340 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/auth"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const body = `{"items":[{"productId":"p1","quantity":1}]}`
+
+func newRequest(key, payload string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/order", strings.NewReader(payload))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if key != "" {
+		req.Header.Set(HeaderKey, key)
+	}
+	return req
+}
+
+func TestMiddleware(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+	e.HTTPErrorHandler = apperrors.HTTPErrorHandler
+
+	okHandler := func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"id": "order1"})
+	}
+
+	t.Run("no key passes through", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(newRequest("", body), rec)
+
+		apperrors.Handle(Middleware(mockRepo, time.Hour)(okHandler), c)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("key too long", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(newRequest(strings.Repeat("k", 256), body), rec)
+
+		apperrors.Handle(Middleware(mockRepo, time.Hour)(okHandler), c)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("first request stores response", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(newRequest("k1", body), rec)
+
+		var lease string
+		mockRepo.EXPECT().Reserve(gomock.Any(), "", "k1", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _, _, leaseID string, _, _ time.Time) (*Record, bool, error) {
+				lease = leaseID
+				return nil, true, nil
+			})
+		mockRepo.EXPECT().
+			Complete(gomock.Any(), "", "k1", gomock.Any(), http.StatusOK, echo.MIMEApplicationJSON, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _, leaseID string, _ int, _ string, b []byte) error {
+				assert.NotEmpty(t, leaseID)
+				assert.Equal(t, lease, leaseID)
+				assert.JSONEq(t, `{"id":"order1"}`, string(b))
+				return nil
+			})
+
+		handler := func(c echo.Context) error {
+			// the body is still readable by the handler
+			var req map[string]any
+			require.NoError(t, c.Bind(&req))
+			return okHandler(c)
+		}
+		apperrors.Handle(Middleware(mockRepo, time.Hour)(handler), c)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("replay returns stored response byte-for-byte", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		rec := httptest.NewRecorder()
+		req := newRequest("k1", body)
+		c := e.NewContext(req, rec)
+
+		stored := []byte("{\"id\":\"order1\"}\n")
+		mockRepo.EXPECT().Reserve(gomock.Any(), "", "k1", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&Record{
+				Key:          "k1",
+				Fingerprint:  fingerprint("", req, []byte(body)),
+				Status:       StatusCompleted,
+				ResponseCode: http.StatusOK,
+				ContentType:  echo.MIMEApplicationJSON,
+				ResponseBody: stored,
+			}, false, nil)
+
+		called := false
+		handler := func(c echo.Context) error { called = true; return nil }
+		apperrors.Handle(Middleware(mockRepo, time.Hour)(handler), c)
+
+		assert.False(t, called)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, stored, rec.Body.Bytes())
+		assert.Equal(t, "true", rec.Header().Get(HeaderReplayed))
+	})
+
+	t.Run("same key with different body", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(newRequest("k1", `{"items":[]}`), rec)
+
+		mockRepo.EXPECT().Reserve(gomock.Any(), "", "k1", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&Record{Key: "k1", Fingerprint: "other", Status: StatusCompleted}, false, nil)
+
+		apperrors.Handle(Middleware(mockRepo, time.Hour)(okHandler), c)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("duplicate while first is in flight", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		rec := httptest.NewRecorder()
+		req := newRequest("k1", body)
+		c := e.NewContext(req, rec)
+
+		mockRepo.EXPECT().Reserve(gomock.Any(), "", "k1", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&Record{Key: "k1", Fingerprint: fingerprint("", req, []byte(body)), Status: StatusInProgress}, false, nil)
+
+		apperrors.Handle(Middleware(mockRepo, time.Hour)(okHandler), c)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("server error releases key", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(newRequest("k2", body), rec)
+
+		mockRepo.EXPECT().Reserve(gomock.Any(), "", "k2", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, true, nil)
+		mockRepo.EXPECT().Release(gomock.Any(), "", "k2", gomock.Any()).Return(nil)
+
+		handler := func(c echo.Context) error {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"message": "boom"})
+		}
+		apperrors.Handle(Middleware(mockRepo, time.Hour)(handler), c)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+
+	t.Run("returned client error is stored", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(newRequest("k4", body), rec)
+
+		mockRepo.EXPECT().Reserve(gomock.Any(), "", "k4", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, true, nil)
+		mockRepo.EXPECT().
+			Complete(gomock.Any(), "", "k4", gomock.Any(), http.StatusConflict, apperrors.MIMEProblemJSON, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _, _ string, _ int, _ string, b []byte) error {
+				assert.JSONEq(t, `{"type":"urn:order-food-online:problem:conflict","title":"Conflict","status":409,"detail":"out of stock","instance":"/order","code":"conflict"}`, string(b))
+				return nil
+			})
+
+		handler := func(c echo.Context) error {
+			return apperrors.Conflict("out of stock", nil)
+		}
+		apperrors.Handle(Middleware(mockRepo, time.Hour)(handler), c)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("response is stored after the client went away", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		rec := httptest.NewRecorder()
+		reqCtx, cancel := context.WithCancel(context.Background())
+		c := e.NewContext(newRequest("k5", body).WithContext(reqCtx), rec)
+
+		mockRepo.EXPECT().Reserve(gomock.Any(), "", "k5", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, true, nil)
+		mockRepo.EXPECT().
+			Complete(gomock.Any(), "", "k5", gomock.Any(), http.StatusOK, echo.MIMEApplicationJSON, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, _, _, _ string, _ int, _ string, _ []byte) error {
+				assert.NoError(t, ctx.Err())
+				return nil
+			})
+
+		handler := func(c echo.Context) error {
+			cancel()
+			return okHandler(c)
+		}
+		apperrors.Handle(Middleware(mockRepo, time.Hour)(handler), c)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("panic releases key", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		c := e.NewContext(newRequest("k6", body), httptest.NewRecorder())
+
+		mockRepo.EXPECT().Reserve(gomock.Any(), "", "k6", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, true, nil)
+		mockRepo.EXPECT().Release(gomock.Any(), "", "k6", gomock.Any()).Return(nil)
+
+		handler := func(c echo.Context) error { panic("boom") }
+		assert.PanicsWithValue(t, "boom", func() {
+			_ = Middleware(mockRepo, time.Hour)(handler)(c)
+		})
+	})
+
+	t.Run("keys are scoped to the caller", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		rec := httptest.NewRecorder()
+		req := newRequest("k7", body)
+		req = req.WithContext(auth.NewContext(req.Context(), &auth.Key{ID: "key2", Name: "kiosk"}))
+		c := e.NewContext(req, rec)
+
+		// the fingerprint of another caller's request with the same key and body differs
+		mockRepo.EXPECT().Reserve(gomock.Any(), "key:key2", "k7", fingerprint("key:key2", req, []byte(body)), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _, fp, _ string, lockedUntil, expiresAt time.Time) (*Record, bool, error) {
+				assert.NotEqual(t, fingerprint("key:key1", req, []byte(body)), fp)
+				assert.True(t, lockedUntil.Before(expiresAt), "the lease ends before the retention")
+				return nil, true, nil
+			})
+		mockRepo.EXPECT().Complete(gomock.Any(), "key:key2", "k7", gomock.Any(), http.StatusOK, echo.MIMEApplicationJSON, gomock.Any()).Return(nil)
+
+		apperrors.Handle(Middleware(mockRepo, time.Hour)(okHandler), c)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("reserve failure", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(newRequest("k3", body), rec)
+
+		mockRepo.EXPECT().Reserve(gomock.Any(), "", "k3", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, false, assert.AnError)
+
+		apperrors.Handle(Middleware(mockRepo, time.Hour)(okHandler), c)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+// memoryRepo mimics the primary key semantics of the MariaDB table
+type memoryRepo struct {
+	mu      sync.Mutex
+	records map[string]*Record
+}
+
+func (m *memoryRepo) Reserve(_ context.Context, actor, key, fp, _ string, lockedUntil, expiresAt time.Time) (*Record, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if rec, ok := m.records[actor+" "+key]; ok {
+		cp := *rec
+		return &cp, false, nil
+	}
+	m.records[actor+" "+key] = &Record{Actor: actor, Key: key, Fingerprint: fp, Status: StatusInProgress, LockedUntil: lockedUntil, ExpiresAt: expiresAt}
+	return nil, true, nil
+}
+
+func (m *memoryRepo) Complete(_ context.Context, actor, key, _ string, code int, ct string, b []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rec := m.records[actor+" "+key]
+	rec.Status, rec.ResponseCode, rec.ContentType, rec.ResponseBody = StatusCompleted, code, ct, b
+	return nil
+}
+
+func (m *memoryRepo) Release(_ context.Context, actor, key, _ string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.records, actor+" "+key)
+	return nil
+}
+
+func (m *memoryRepo) DeleteExpired(context.Context, time.Time) (int64, error) { return 0, nil }
+
+func TestMiddleware_ConcurrentDuplicates(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+	repo := &memoryRepo{records: map[string]*Record{}}
+
+	var executions int32
+	release := make(chan struct{})
+	handler := func(c echo.Context) error {
+		atomic.AddInt32(&executions, 1)
+		<-release
+		return c.JSON(http.StatusOK, map[string]string{"id": "order1"})
+	}
+	mw := Middleware(repo, time.Hour)(handler)
+
+	const n = 10
+	codes := make([]int, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			apperrors.Handle(mw, e.NewContext(newRequest("same", body), rec))
+			codes[i] = rec.Code
+		}(i)
+	}
+
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&executions) == 1 }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), executions, "handler must run once")
+	ok, conflict := 0, 0
+	for _, code := range codes {
+		switch code {
+		case http.StatusOK:
+			ok++
+		case http.StatusConflict:
+			conflict++
+		}
+	}
+	assert.Equal(t, 1, ok)
+	assert.Equal(t, n-1, conflict)
+
+	// once completed, a retry is answered from the store
+	rec := httptest.NewRecorder()
+	apperrors.Handle(mw, e.NewContext(newRequest("same", body), rec))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, int32(1), executions)
+}
@@ -0,0 +1,25 @@
+package idempotency
+
+import "time"
+
+type Status string
+
+const (
+	StatusInProgress Status = "in_progress"
+	StatusCompleted  Status = "completed"
+)
+
+// Record is a stored Idempotency-Key together with the response it produced.
+// Actor is the caller the key belongs to, as returned by auth.Actor.
+type Record struct {
+	Actor       string
+	Key         string
+	Fingerprint string
+	Status      Status
+	// LockedUntil is when an in progress reservation may be taken over
+	LockedUntil  time.Time
+	ResponseCode int
+	ContentType  string
+	ResponseBody []byte
+	ExpiresAt    time.Time
+}
@@ -0,0 +1,22 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+//go:generate mockgen -source=repository.go -destination=mock_repository.go -package=idempotency
+type Repository interface {
+	// Reserve claims the key of actor for a new request until lockedUntil,
+	// under leaseID. If the key is held by an unexpired record, that record is
+	// returned with reserved=false, unless it is a reservation past its
+	// lockedUntil, which is taken over.
+	Reserve(ctx context.Context, actor, key, fingerprint, leaseID string, lockedUntil, expiresAt time.Time) (existing *Record, reserved bool, err error)
+	// Complete stores the response of a key reserved under leaseID. It returns
+	// ErrLeaseLost when the reservation was taken over in the meantime.
+	Complete(ctx context.Context, actor, key, leaseID string, code int, contentType string, body []byte) error
+	// Release drops a reservation held under leaseID so the client can retry
+	Release(ctx context.Context, actor, key, leaseID string) error
+	// DeleteExpired removes records past their retention window
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
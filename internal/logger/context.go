@@ -3,6 +3,8 @@ package logger
 import (
 	"context"
 	"log/slog"
+	"runtime"
+	"time"
 )
 
 // Converts []slog.Attr to []any for variadic log.With
@@ -18,15 +20,32 @@ type ctxKey struct{}
 
 type Attributes map[string]any
 
+// RequestIDKey is the attribute holding the ID of the request a log line belongs to
+const RequestIDKey = "requestId"
+
 func AddAttrs(ctx context.Context, attrs Attributes) context.Context {
+	// copy, so attributes added to a derived context don't show up in its parent
 	existing, _ := ctx.Value(ctxKey{}).(Attributes)
-	if existing == nil {
-		existing = Attributes{}
+	merged := make(Attributes, len(existing)+len(attrs))
+	for k, v := range existing {
+		merged[k] = v
 	}
 	for k, v := range attrs {
-		existing[k] = v
+		merged[k] = v
 	}
-	return context.WithValue(ctx, ctxKey{}, existing)
+	return context.WithValue(ctx, ctxKey{}, merged)
+}
+
+// WithRequestID adds the request ID to the log attributes of ctx
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return AddAttrs(ctx, Attributes{RequestIDKey: id})
+}
+
+// RequestID returns the request ID of ctx, empty outside of a request
+func RequestID(ctx context.Context) string {
+	attrs, _ := ctx.Value(ctxKey{}).(Attributes)
+	id, _ := attrs[RequestIDKey].(string)
+	return id
 }
 
 func extractAttrs(ctx context.Context) []slog.Attr {
@@ -44,21 +63,31 @@ func extractAttrs(ctx context.Context) []slog.Attr {
 }
 
 func Info(ctx context.Context, msg string, kv ...any) {
-	kv = filterSensitive(kv)
-	log.With(attrsToAny(extractAttrs(ctx))...).Info(msg, kv...)
+	logAt(ctx, slog.LevelInfo, msg, kv)
 }
 
 func Debug(ctx context.Context, msg string, kv ...any) {
-	kv = filterSensitive(kv)
-	log.With(attrsToAny(extractAttrs(ctx))...).Debug(msg, kv...)
+	logAt(ctx, slog.LevelDebug, msg, kv)
 }
 
 func Warn(ctx context.Context, msg string, kv ...any) {
-	kv = filterSensitive(kv)
-	log.With(attrsToAny(extractAttrs(ctx))...).Warn(msg, kv...)
+	logAt(ctx, slog.LevelWarn, msg, kv)
 }
 
 func Error(ctx context.Context, msg string, kv ...any) {
-	kv = filterSensitive(kv)
-	log.With(attrsToAny(extractAttrs(ctx))...).Error(msg, kv...)
+	logAt(ctx, slog.LevelError, msg, kv)
+}
+
+// logAt builds the record itself so it carries the PC of the caller of
+// Info, Debug, Warn or Error, which per-package levels are matched against
+func logAt(ctx context.Context, level slog.Level, msg string, kv []any) {
+	if !log.Enabled(ctx, level) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:]) // skip runtime.Callers, logAt and the wrapper
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.AddAttrs(extractAttrs(ctx)...)
+	r.Add(filterSensitive(kv)...)
+	_ = log.Handler().Handle(ctx, r)
 }
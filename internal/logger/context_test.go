@@ -60,3 +60,19 @@ func TestLoggingFunctions(t *testing.T) {
 	assert.NotPanics(t, func() { Warn(ctx, "warn message", "secret", "123") })
 	assert.NotPanics(t, func() { Error(ctx, "error message", "err", nil) })
 }
+
+func TestAddAttrs_DoesNotChangeParent(t *testing.T) {
+	parent := AddAttrs(context.Background(), Attributes{"user": "john"})
+	child := AddAttrs(parent, Attributes{"role": "admin"})
+
+	assert.Len(t, extractAttrs(parent), 1)
+	assert.Len(t, extractAttrs(child), 2)
+}
+
+func TestRequestID(t *testing.T) {
+	assert.Equal(t, "", RequestID(context.Background()))
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	ctx = AddAttrs(ctx, Attributes{"userId": "user-42"})
+	assert.Equal(t, "req-1", RequestID(ctx))
+}
@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileWriter_RotatesAndPrunes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "api.log")
+
+	w, err := NewFileWriter(path, 10, 2)
+	require.NoError(t, err)
+	defer w.Close()
+	at := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	w.now = func() time.Time {
+		at = at.Add(time.Second)
+		return at
+	}
+
+	for _, line := range []string{"line one\n", "line two\n", "line three\n", "line four\n"} {
+		_, err := w.Write([]byte(line))
+		require.NoError(t, err)
+	}
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "line four\n", string(data))
+
+	backups, err := w.backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 2, "only the newest rotated files are kept")
+	assert.Equal(t, filepath.Join(dir, "api-20261019T120002.000000000Z.log"), backups[0])
+	data, err = os.ReadFile(backups[1])
+	require.NoError(t, err)
+	assert.Equal(t, "line three\n", string(data))
+}
+
+func TestFileWriter_AppendsToExisting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "api.log")
+
+	w, err := NewFileWriter(path, 0, 0)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("first\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	_, err = w.Write([]byte("closed\n"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+
+	w, err = NewFileWriter(path, 0, 0)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("second\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "first\nsecond\n", string(data))
+}
+
+func TestInitWithOptions(t *testing.T) {
+	defer Init("test-service", "test", slog.LevelInfo)
+	path := filepath.Join(t.TempDir(), "api.log")
+
+	err := InitWithOptions("test-service", "test", Options{
+		Level:    slog.LevelWarn,
+		Packages: map[string]slog.Level{"db": slog.LevelDebug},
+		Format:   FormatText,
+		File:     path,
+	})
+	require.NoError(t, err)
+	Log().Warn("to the file")
+	require.NoError(t, Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `level=WARN msg="to the file" service=test-service`)
+	assert.Equal(t, map[string]slog.Level{"db": slog.LevelDebug}, CurrentLevels().Packages)
+
+	err = InitWithOptions("test-service", "test", Options{Format: "xml"})
+	assert.EqualError(t, err, `unknown log format "xml", expected json or text`)
+}
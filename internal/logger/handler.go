@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+// levelHandler applies the global and per-package levels in front of the
+// JSON or text handler. The package of a record comes from its PC, set by
+// slog for Log() calls and by logAt for the context functions.
+type levelHandler struct {
+	next slog.Handler
+}
+
+func (h levelHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= levels.min.Level()
+}
+
+func (h levelHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !levels.enabled(r.PC, r.Level) {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return levelHandler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h levelHandler) WithGroup(name string) slog.Handler {
+	return levelHandler{next: h.next.WithGroup(name)}
+}
@@ -0,0 +1,246 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// levels is the level state of the logger: a global level and per-package
+// overrides, both changeable at runtime, optionally only for a while
+var levels = newLevelState()
+
+type levelState struct {
+	// global is the level of packages without an override
+	global slog.LevelVar
+	// min is the lowest of global and the overrides, calls below it are
+	// dropped before a record is built
+	min slog.LevelVar
+
+	mu       sync.RWMutex
+	packages map[string]slog.Level
+	reverts  map[string]*revert
+}
+
+// revert restores a level changed for a while. set is false when the
+// package had no override, which is then removed.
+type revert struct {
+	timer *time.Timer
+	at    time.Time
+	level slog.Level
+	set   bool
+}
+
+func newLevelState() *levelState {
+	return &levelState{packages: make(map[string]slog.Level), reverts: make(map[string]*revert)}
+}
+
+// Levels is a snapshot of the level state
+type Levels struct {
+	Level    slog.Level
+	Packages map[string]slog.Level
+	Reverts  []Revert
+}
+
+// Revert is a pending return to an earlier level. Package is empty for the
+// global level; Removed means the package override goes away.
+type Revert struct {
+	Package string
+	Level   slog.Level
+	Removed bool
+	At      time.Time
+}
+
+// ParseLevel parses debug, info, warn (or warning) and error, in any case
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", s)
+}
+
+// LevelName is the lower case name of a level, the form ParseLevel accepts
+func LevelName(l slog.Level) string {
+	return strings.ToLower(l.String())
+}
+
+// ParsePackageLevels parses per-package levels written as comma separated
+// <package>=<level> entries, e.g. "db=warn,promo=debug". A package is named
+// by the last element of its import path.
+func ParsePackageLevels(s string) (map[string]slog.Level, error) {
+	res := make(map[string]slog.Level)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		pkg, level, ok := strings.Cut(entry, "=")
+		pkg = strings.TrimSpace(pkg)
+		if !ok || pkg == "" {
+			return nil, fmt.Errorf("invalid package level %q, expected <package>=<level>", entry)
+		}
+		l, err := ParseLevel(level)
+		if err != nil {
+			return nil, fmt.Errorf("package %s: %w", pkg, err)
+		}
+		res[pkg] = l
+	}
+	return res, nil
+}
+
+// SetLevel sets the level of pkg, or the global level when pkg is empty. With
+// a positive d the level in effect before is restored after d; changing the
+// same level again in the meantime replaces the change but keeps restoring
+// the level from before the first one.
+func SetLevel(pkg string, level slog.Level, d time.Duration) {
+	levels.set(pkg, level, true, d)
+}
+
+// ResetLevel removes the override of pkg, its calls follow the global level again
+func ResetLevel(pkg string) {
+	levels.set(pkg, 0, false, 0)
+}
+
+// CurrentLevels returns the global level, the package overrides and the pending reverts
+func CurrentLevels() Levels {
+	return levels.snapshot()
+}
+
+// reset replaces the whole state, cancelling pending reverts
+func (s *levelState) reset(global slog.Level, packages map[string]slog.Level) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.reverts {
+		r.timer.Stop()
+	}
+	s.reverts = make(map[string]*revert)
+	s.packages = make(map[string]slog.Level, len(packages))
+	for pkg, l := range packages {
+		s.packages[pkg] = l
+	}
+	s.global.Set(global)
+	s.updateMin()
+}
+
+func (s *levelState) set(pkg string, level slog.Level, set bool, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev, prevSet := s.current(pkg)
+	if r, ok := s.reverts[pkg]; ok {
+		r.timer.Stop()
+		delete(s.reverts, pkg)
+		prev, prevSet = r.level, r.set
+	}
+	s.apply(pkg, level, set)
+
+	if d > 0 {
+		r := &revert{at: time.Now().Add(d), level: prev, set: prevSet}
+		r.timer = time.AfterFunc(d, func() { s.restore(pkg, r) })
+		s.reverts[pkg] = r
+	}
+}
+
+// restore applies r unless it was replaced or cancelled since
+func (s *levelState) restore(pkg string, r *revert) {
+	s.mu.Lock()
+	if s.reverts[pkg] != r {
+		s.mu.Unlock()
+		return
+	}
+	delete(s.reverts, pkg)
+	s.apply(pkg, r.level, r.set)
+	s.mu.Unlock()
+
+	if log != nil {
+		log.Info("log level reverted", "package", pkg, "level", LevelName(r.level), "override", r.set)
+	}
+}
+
+// current returns the level of pkg and whether it is set; the global level is always set
+func (s *levelState) current(pkg string) (slog.Level, bool) {
+	if pkg == "" {
+		return s.global.Level(), true
+	}
+	l, ok := s.packages[pkg]
+	return l, ok
+}
+
+// apply sets or removes a level, the caller holds the lock
+func (s *levelState) apply(pkg string, level slog.Level, set bool) {
+	switch {
+	case pkg == "":
+		s.global.Set(level)
+	case set:
+		s.packages[pkg] = level
+	default:
+		delete(s.packages, pkg)
+	}
+	s.updateMin()
+}
+
+func (s *levelState) updateMin() {
+	min := s.global.Level()
+	for _, l := range s.packages {
+		if l < min {
+			min = l
+		}
+	}
+	s.min.Set(min)
+}
+
+// enabled reports whether a record at level logged from pc passes the level of its package
+func (s *levelState) enabled(pc uintptr, level slog.Level) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.packages) > 0 && pc != 0 {
+		if l, ok := s.packages[packageOf(pc)]; ok {
+			return level >= l
+		}
+	}
+	return level >= s.global.Level()
+}
+
+func (s *levelState) snapshot() Levels {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	res := Levels{Level: s.global.Level(), Packages: make(map[string]slog.Level, len(s.packages))}
+	for pkg, l := range s.packages {
+		res.Packages[pkg] = l
+	}
+	for pkg, r := range s.reverts {
+		res.Reverts = append(res.Reverts, Revert{Package: pkg, Level: r.level, Removed: !r.set, At: r.at})
+	}
+	sort.Slice(res.Reverts, func(i, j int) bool { return res.Reverts[i].Package < res.Reverts[j].Package })
+	return res
+}
+
+// pcPackages caches the package of the program counters seen
+var pcPackages sync.Map
+
+// packageOf returns the last element of the import path of the function at
+// pc, e.g. db for github.com/.../internal/db.(*SQLPool).Exec
+func packageOf(pc uintptr) string {
+	if pkg, ok := pcPackages.Load(pc); ok {
+		return pkg.(string)
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	pkg := frame.Function[strings.LastIndex(frame.Function, "/")+1:]
+	if dot := strings.Index(pkg, "."); dot >= 0 {
+		pkg = pkg[:dot]
+	}
+	pcPackages.Store(pc, pkg)
+	return pkg
+}
@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureLevels logs into a buffer through the level handler, starting at level
+func captureLevels(t *testing.T, level slog.Level) *bytes.Buffer {
+	t.Helper()
+	oldLog := log
+	t.Cleanup(func() {
+		log = oldLog
+		levels.reset(slog.LevelInfo, nil)
+	})
+
+	var buf bytes.Buffer
+	levels.reset(level, nil)
+	log = slog.New(levelHandler{next: slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: &levels.min})})
+	return &buf
+}
+
+func TestParseLevel(t *testing.T) {
+	for in, want := range map[string]slog.Level{"debug": slog.LevelDebug, "INFO": slog.LevelInfo, " warn ": slog.LevelWarn, "warning": slog.LevelWarn, "error": slog.LevelError} {
+		l, err := ParseLevel(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, l, in)
+	}
+
+	_, err := ParseLevel("verbose")
+	assert.EqualError(t, err, `unknown log level "verbose", expected debug, info, warn or error`)
+}
+
+func TestParsePackageLevels(t *testing.T) {
+	got, err := ParsePackageLevels(" db=warn, promo=DEBUG,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]slog.Level{"db": slog.LevelWarn, "promo": slog.LevelDebug}, got)
+
+	got, err = ParsePackageLevels("")
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	_, err = ParsePackageLevels("db")
+	assert.EqualError(t, err, `invalid package level "db", expected <package>=<level>`)
+	_, err = ParsePackageLevels("db=loud")
+	assert.EqualError(t, err, `package db: unknown log level "loud", expected debug, info, warn or error`)
+}
+
+func TestSetLevel_Global(t *testing.T) {
+	buf := captureLevels(t, slog.LevelInfo)
+	ctx := context.Background()
+
+	Debug(ctx, "hidden")
+	SetLevel("", slog.LevelDebug, 0)
+	Debug(ctx, "shown")
+	SetLevel("", slog.LevelError, 0)
+	Warn(ctx, "hidden too")
+
+	assert.NotContains(t, buf.String(), "hidden")
+	assert.Contains(t, buf.String(), `"msg":"shown"`)
+	assert.Equal(t, slog.LevelError, CurrentLevels().Level)
+}
+
+func TestSetLevel_Package(t *testing.T) {
+	buf := captureLevels(t, slog.LevelInfo)
+	ctx := context.Background()
+
+	// this test logs from package logger
+	SetLevel("db", slog.LevelDebug, 0)
+	Debug(ctx, "other package")
+	assert.Empty(t, buf.String())
+
+	SetLevel("logger", slog.LevelDebug, 0)
+	Debug(ctx, "context debug")
+	Log().Debug("direct debug")
+	assert.Contains(t, buf.String(), "context debug")
+	assert.Contains(t, buf.String(), "direct debug")
+
+	buf.Reset()
+	SetLevel("logger", slog.LevelError, 0)
+	Warn(ctx, "below package level")
+	assert.Empty(t, buf.String())
+
+	ResetLevel("logger")
+	Warn(ctx, "global level again")
+	assert.Contains(t, buf.String(), "global level again")
+	assert.Equal(t, map[string]slog.Level{"db": slog.LevelDebug}, CurrentLevels().Packages)
+}
+
+func TestSetLevel_Reverts(t *testing.T) {
+	captureLevels(t, slog.LevelInfo)
+
+	SetLevel("", slog.LevelDebug, 50*time.Millisecond)
+	// a second change keeps restoring the level from before the first
+	SetLevel("", slog.LevelWarn, 50*time.Millisecond)
+	SetLevel("db", slog.LevelDebug, 50*time.Millisecond)
+
+	got := CurrentLevels()
+	assert.Equal(t, slog.LevelWarn, got.Level)
+	require.Len(t, got.Reverts, 2)
+	assert.Equal(t, "", got.Reverts[0].Package)
+	assert.Equal(t, slog.LevelInfo, got.Reverts[0].Level)
+	assert.False(t, got.Reverts[0].Removed)
+	assert.Equal(t, "db", got.Reverts[1].Package)
+	assert.True(t, got.Reverts[1].Removed)
+
+	assert.Eventually(t, func() bool {
+		l := CurrentLevels()
+		return l.Level == slog.LevelInfo && len(l.Packages) == 0 && len(l.Reverts) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	// a permanent change cancels the pending revert
+	SetLevel("", slog.LevelDebug, 20*time.Millisecond)
+	SetLevel("", slog.LevelError, 0)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, slog.LevelError, CurrentLevels().Level)
+}
+
+func TestMinLevel(t *testing.T) {
+	captureLevels(t, slog.LevelWarn)
+
+	SetLevel("promo", slog.LevelDebug, 0)
+	assert.True(t, Log().Enabled(context.Background(), slog.LevelDebug))
+
+	ResetLevel("promo")
+	assert.False(t, Log().Enabled(context.Background(), slog.LevelInfo))
+}
@@ -1,26 +1,85 @@
 package logger
 
 import (
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
 )
 
 var (
-	log *slog.Logger
-	env string
+	log  *slog.Logger
+	env  string
+	file *FileWriter
 )
 
+// Output formats
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Options configure the logger; the zero value logs JSON at info level to stdout only
+type Options struct {
+	Level    slog.Level
+	Packages map[string]slog.Level // per-package levels, by last import path element
+	Format   string                // FormatJSON (default) or FormatText
+	File     string                // also write to this file when set
+	MaxBytes int64                 // rotate the file past this size, 0 disables
+	MaxFiles int                   // rotated files to keep, 0 keeps all
+}
+
 func Init(serviceName, environment string, level slog.Level) {
-	env = environment
+	// cannot fail without a file or format
+	_ = InitWithOptions(serviceName, environment, Options{Level: level})
+}
 
-	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: level,
-	})
+// InitWithOptions sets up the logger. Levels set at runtime and pending reverts are reset.
+func InitWithOptions(serviceName, environment string, opts Options) error {
+	var out io.Writer = os.Stdout
+	var fw *FileWriter
+	if opts.File != "" {
+		var err error
+		if fw, err = NewFileWriter(opts.File, opts.MaxBytes, opts.MaxFiles); err != nil {
+			return err
+		}
+		out = io.MultiWriter(os.Stdout, fw)
+	}
 
-	log = slog.New(handler).With(
+	handlerOpts := &slog.HandlerOptions{Level: &levels.min}
+	var handler slog.Handler
+	switch opts.Format {
+	case "", FormatJSON:
+		handler = slog.NewJSONHandler(out, handlerOpts)
+	case FormatText:
+		handler = slog.NewTextHandler(out, handlerOpts)
+	default:
+		if fw != nil {
+			fw.Close()
+		}
+		return fmt.Errorf("unknown log format %q, expected %s or %s", opts.Format, FormatJSON, FormatText)
+	}
+
+	Close()
+	file = fw
+	env = environment
+	levels.reset(opts.Level, opts.Packages)
+
+	log = slog.New(levelHandler{next: handler}).With(
 		slog.String("service", serviceName),
 		slog.String("environment", environment),
 	)
+	return nil
+}
+
+// Close closes the log file, if any
+func Close() error {
+	if file == nil {
+		return nil
+	}
+	err := file.Close()
+	file = nil
+	return err
 }
 
 func Log() *slog.Logger {
@@ -0,0 +1,51 @@
+package loglevel
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+)
+
+// maxDurationSec caps temporary levels at a day, longer changes are permanent ones
+const maxDurationSec = 24 * 60 * 60
+
+var (
+	errInvalidLevel = apperrors.BadRequest("invalid log level request", nil).WithCode(apperrors.CodeValidationFailed)
+
+	packageName = regexp.MustCompile(`^[a-z0-9_]+$`)
+)
+
+// Validate checks the level, package name and duration, and returns the parsed level
+func (r *LevelReq) Validate() (slog.Level, *apperrors.AppError) {
+	v := &apperrors.Validation{}
+
+	var level slog.Level
+	if r.Level == "" {
+		v.Add(apperrors.Pointer("level"), apperrors.FieldRequired, "level is required")
+	} else if l, err := logger.ParseLevel(r.Level); err != nil {
+		v.Add(apperrors.Pointer("level"), apperrors.FieldInvalid, "level must be debug, info, warn or error")
+	} else {
+		level = l
+	}
+	if r.Package != "" {
+		if msg := validatePackage(r.Package); msg != "" {
+			v.Add(apperrors.Pointer("package"), apperrors.FieldInvalid, msg)
+		}
+	}
+	if r.DurationSec < 0 || r.DurationSec > maxDurationSec {
+		v.Add(apperrors.Pointer("durationSec"), apperrors.FieldOutOfRange, fmt.Sprintf("durationSec must be between 0 and %d", maxDurationSec))
+	}
+
+	return level, v.Err(errInvalidLevel)
+}
+
+// validatePackage returns why pkg is not a package name, empty when it is one
+func validatePackage(pkg string) string {
+	if !packageName.MatchString(pkg) {
+		return "package must be the last element of an import path, e.g. db"
+	}
+	return ""
+}
@@ -0,0 +1,78 @@
+package loglevel
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+)
+
+type Handler struct{}
+
+func NewHandler() *Handler {
+	return &Handler{}
+}
+
+// GetLevels serves GET /log-level
+func (h *Handler) GetLevels(c echo.Context) error {
+	return c.JSON(http.StatusOK, current())
+}
+
+// SetLevel serves PUT /log-level
+func (h *Handler) SetLevel(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req LevelReq
+	if err := c.Bind(&req); err != nil {
+		return apperrors.BadRequest("invalid log level request", err)
+	}
+	level, appErr := req.Validate()
+	if appErr != nil {
+		return appErr
+	}
+
+	d := time.Duration(req.DurationSec) * time.Second
+	logger.SetLevel(req.Package, level, d)
+	logger.Info(ctx, "log level changed", "package", req.Package, "level", logger.LevelName(level), "durationSec", req.DurationSec)
+
+	return c.JSON(http.StatusOK, current())
+}
+
+// ResetPackage serves DELETE /log-level/{package}, the package follows the global level again
+func (h *Handler) ResetPackage(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	pkg := c.Param("package")
+	if msg := validatePackage(pkg); msg != "" {
+		return apperrors.BadRequest(msg, nil)
+	}
+
+	logger.ResetLevel(pkg)
+	logger.Info(ctx, "log level reset", "package", pkg)
+
+	return c.JSON(http.StatusOK, current())
+}
+
+// current returns the levels of the logger with lower case level names
+func current() *Levels {
+	l := logger.CurrentLevels()
+
+	res := &Levels{
+		Level:    logger.LevelName(l.Level),
+		Packages: make(map[string]string, len(l.Packages)),
+		Reverts:  make([]Revert, 0, len(l.Reverts)),
+	}
+	for pkg, level := range l.Packages {
+		res.Packages[pkg] = logger.LevelName(level)
+	}
+	for _, r := range l.Reverts {
+		rev := Revert{Package: r.Package, Removed: r.Removed, At: r.At.UTC()}
+		if !r.Removed {
+			rev.Level = logger.LevelName(r.Level)
+		}
+		res.Reverts = append(res.Reverts, rev)
+	}
+	return res
+}
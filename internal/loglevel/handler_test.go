@@ -0,0 +1,85 @@
+package loglevel
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	logger.Init("test-service", "test", slog.LevelInfo)
+	defer logger.Init("test-service", "test", slog.LevelInfo)
+	e := echo.New()
+	h := NewHandler()
+
+	do := func(handler echo.HandlerFunc, method, body string, params ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/log-level", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		if len(params) == 2 {
+			c.SetParamNames(params[0])
+			c.SetParamValues(params[1])
+		}
+		apperrors.Handle(handler, c)
+		return rec
+	}
+
+	t.Run("get", func(t *testing.T) {
+		rec := do(h.GetLevels, http.MethodGet, "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"level":"info","packages":{},"reverts":[]}`, rec.Body.String())
+	})
+
+	t.Run("set global and package", func(t *testing.T) {
+		rec := do(h.SetLevel, http.MethodPut, `{"level":"warn"}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		rec = do(h.SetLevel, http.MethodPut, `{"level":"DEBUG","package":"db","durationSec":300}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"level":"warn","packages":{"db":"debug"},"reverts":[{"package":"db","removed":true,"at":`)
+
+		got := logger.CurrentLevels()
+		assert.Equal(t, slog.LevelWarn, got.Level)
+		assert.Equal(t, map[string]slog.Level{"db": slog.LevelDebug}, got.Packages)
+	})
+
+	t.Run("reset package", func(t *testing.T) {
+		rec := do(h.ResetPackage, http.MethodDelete, "", "package", "db")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"level":"warn","packages":{},"reverts":[]}`, rec.Body.String())
+
+		rec = do(h.ResetPackage, http.MethodDelete, "", "package", "DB!")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("invalid request lists every field", func(t *testing.T) {
+		rec := do(h.SetLevel, http.MethodPut, `{"level":"loud","package":"internal/db","durationSec":-1}`)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, apperrors.MIMEProblemJSON, rec.Header().Get(echo.HeaderContentType))
+		body := rec.Body.String()
+		assert.Contains(t, body, `"code":"validation_failed"`)
+		assert.Contains(t, body, `{"pointer":"/level","code":"invalid","detail":"level must be debug, info, warn or error"}`)
+		assert.Contains(t, body, `"pointer":"/package","code":"invalid"`)
+		assert.Contains(t, body, `{"pointer":"/durationSec","code":"out_of_range","detail":"durationSec must be between 0 and 86400"}`)
+	})
+
+	t.Run("missing level", func(t *testing.T) {
+		rec := do(h.SetLevel, http.MethodPut, `{}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"detail":"level is required"`)
+	})
+
+	t.Run("malformed body", func(t *testing.T) {
+		rec := do(h.SetLevel, http.MethodPut, `{`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
@@ -0,0 +1,28 @@
+package loglevel
+
+import "time"
+
+// LevelReq is the body of PUT /log-level. Without Package it sets the global
+// level; with DurationSec the level in effect before comes back after that many seconds.
+type LevelReq struct {
+	Level       string `json:"level"`
+	Package     string `json:"package,omitempty"`
+	DurationSec int    `json:"durationSec,omitempty"`
+}
+
+// Levels is the response of GET and PUT /log-level
+type Levels struct {
+	Level string `json:"level"`
+	// Packages are the per-package levels, by last import path element
+	Packages map[string]string `json:"packages"`
+	Reverts  []Revert          `json:"reverts"`
+}
+
+// Revert is a temporary level waiting to be undone. Package is empty for the
+// global level; Removed means the package override is removed, Level is then empty.
+type Revert struct {
+	Package string    `json:"package,omitempty"`
+	Level   string    `json:"level,omitempty"`
+	Removed bool      `json:"removed,omitempty"`
+	At      time.Time `json:"at"`
+}
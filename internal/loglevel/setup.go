@@ -0,0 +1,22 @@
+package loglevel
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/audit"
+	"github.com/mohammadshabab/order-food-online/internal/auth"
+)
+
+// Setup registers the log level routes, changes are audited
+func Setup(e *echo.Echo, rec *audit.Recorder) {
+	h := NewHandler()
+
+	admin := auth.RequireScope(auth.ScopeLogsAdmin)
+	before := func(c echo.Context) (any, error) { return current(), nil }
+	logged := func(name, idParam string) echo.MiddlewareFunc {
+		return rec.Middleware(audit.Action{Name: name, Resource: "log_level", IDParam: idParam, Before: before})
+	}
+
+	e.GET("/log-level", h.GetLevels, admin)
+	e.PUT("/log-level", h.SetLevel, admin, logged("log_level.set", ""))
+	e.DELETE("/log-level/:package", h.ResetPackage, admin, logged("log_level.reset", "package"))
+}
@@ -0,0 +1,29 @@
+package loglevel
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestSetup(t *testing.T) {
+	e := echo.New()
+	Setup(e, nil)
+
+	want := map[string]bool{
+		http.MethodGet + " /log-level":             false,
+		http.MethodPut + " /log-level":             false,
+		http.MethodDelete + " /log-level/:package": false,
+	}
+	for _, r := range e.Routes() {
+		if _, ok := want[r.Method+" "+r.Path]; ok {
+			want[r.Method+" "+r.Path] = true
+		}
+	}
+	for route, found := range want {
+		if !found {
+			t.Errorf("expected %s to be registered but it was not", route)
+		}
+	}
+}
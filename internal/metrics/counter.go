@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"io"
+)
+
+// Counter counts events, split by the values of its labels. It is safe for
+// concurrent use.
+type Counter struct {
+	desc
+	vec
+}
+
+// NewCounter creates a counter and registers it with the default registry
+func NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{desc: desc{name: name, help: help, typ: "counter", labels: labels}}
+	if len(labels) == 0 {
+		// without labels there is one series, exposed as 0 before the first event
+		c.Add(0)
+	}
+	Default.register(name, c)
+	return c
+}
+
+// Inc adds one to the series of the label values, given in label order
+func (c *Counter) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds n to the series of the label values, given in label order
+func (c *Counter) Add(n float64, values ...string) {
+	c.update(values, func(v float64) float64 { return v + n })
+}
+
+// Value returns the count of the series of the label values
+func (c *Counter) Value(values ...string) float64 {
+	return c.get(values)
+}
+
+func (c *Counter) write(w io.Writer) {
+	c.header(w)
+	c.each(func(values []string, v float64) {
+		c.sample(w, "", values, nil, v)
+	})
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounter(t *testing.T) {
+	c := &Counter{desc: desc{name: "test_total", typ: "counter", labels: []string{"route"}}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Inc("/order")
+		}()
+	}
+	wg.Wait()
+	c.Add(2.5, "/product")
+
+	assert.Equal(t, float64(10), c.Value("/order"))
+	assert.Equal(t, 2.5, c.Value("/product"))
+	assert.Equal(t, float64(0), c.Value("/health"))
+}
+
+func TestGauge(t *testing.T) {
+	g := &Gauge{desc: desc{name: "test_gauge", typ: "gauge"}}
+	g.Set(3)
+	g.Set(1.5)
+	assert.Equal(t, 1.5, g.Value())
+}
+
+func TestHistogram(t *testing.T) {
+	h := &Histogram{desc: desc{name: "test_seconds", typ: "histogram"}, buckets: []float64{0.1, 1}, series: make(map[string]*histogramSeries)}
+	h.Observe(0.05)
+	h.Observe(0.1)
+	h.Observe(0.5)
+	h.Observe(3)
+
+	s := h.series[seriesKey(nil)]
+	assert.Equal(t, []uint64{2, 1}, s.counts, "an observation on a bound falls in that bucket")
+	assert.Equal(t, uint64(4), h.Count())
+	assert.InDelta(t, 3.65, s.sum, 1e-9)
+}
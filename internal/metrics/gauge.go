@@ -0,0 +1,68 @@
+package metrics
+
+import "io"
+
+// Gauge is a value that goes up and down, split by the values of its labels
+type Gauge struct {
+	desc
+	vec
+}
+
+// NewGauge creates a gauge and registers it with the default registry
+func NewGauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{desc: desc{name: name, help: help, typ: "gauge", labels: labels}}
+	if len(labels) == 0 {
+		g.Set(0)
+	}
+	Default.register(name, g)
+	return g
+}
+
+// Set sets the series of the label values to v
+func (g *Gauge) Set(v float64, values ...string) {
+	g.update(values, func(float64) float64 { return v })
+}
+
+// Value returns the value of the series of the label values
+func (g *Gauge) Value(values ...string) float64 {
+	return g.get(values)
+}
+
+func (g *Gauge) write(w io.Writer) {
+	g.header(w)
+	g.each(func(values []string, v float64) {
+		g.sample(w, "", values, nil, v)
+	})
+}
+
+// Func is a metric without labels whose value is read when it is scraped,
+// for values kept elsewhere such as connection pool stats
+type Func struct {
+	desc
+	fn func() (float64, bool)
+}
+
+// NewGaugeFunc registers a gauge read from fn. When fn reports false the
+// metric has no value and is left out.
+func NewGaugeFunc(name, help string, fn func() (float64, bool)) *Func {
+	f := &Func{desc: desc{name: name, help: help, typ: "gauge"}, fn: fn}
+	Default.register(name, f)
+	return f
+}
+
+// NewCounterFunc registers a counter read from fn, which must only go up.
+// When fn reports false the metric has no value and is left out.
+func NewCounterFunc(name, help string, fn func() (float64, bool)) *Func {
+	f := &Func{desc: desc{name: name, help: help, typ: "counter"}, fn: fn}
+	Default.register(name, f)
+	return f
+}
+
+func (f *Func) write(w io.Writer) {
+	v, ok := f.fn()
+	if !ok {
+		return
+	}
+	f.header(w)
+	f.sample(w, "", nil, nil, v)
+}
@@ -0,0 +1,24 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ContentType is the content type of the Prometheus text format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Register adds GET /metrics, serving the default registry behind mw
+func Register(e *echo.Echo, mw ...echo.MiddlewareFunc) {
+	e.GET("/metrics", Handler(Default), mw...)
+}
+
+// Handler serves the metrics of r in the Prometheus text format
+func Handler(r *Registry) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		c.Response().Header().Set(echo.HeaderContentType, ContentType)
+		c.Response().WriteHeader(http.StatusOK)
+		return r.WriteText(c.Response())
+	}
+}
@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"io"
+	"sort"
+	"sync"
+)
+
+// DefBuckets are latency buckets in seconds, from 5ms to 10s
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Histogram counts observations, such as latencies, in cumulative buckets
+// split by the values of its labels
+type Histogram struct {
+	desc
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	values []string
+	counts []uint64 // per bucket, not cumulative
+	sum    float64
+	count  uint64
+}
+
+// NewHistogram creates a histogram with the upper bounds of its buckets in
+// increasing order and registers it with the default registry
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{
+		desc:    desc{name: name, help: help, typ: "histogram", labels: labels},
+		buckets: buckets,
+		series:  make(map[string]*histogramSeries),
+	}
+	Default.register(name, h)
+	return h
+}
+
+// Observe adds v to the series of the label values, given in label order
+func (h *Histogram) Observe(v float64, values ...string) {
+	i := sort.SearchFloat64s(h.buckets, v)
+	key := seriesKey(values)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{values: append([]string(nil), values...), counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	if i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.sum += v
+	s.count++
+}
+
+// Count returns the number of observations of the series of the label values
+func (h *Histogram) Count(values ...string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if s, ok := h.series[seriesKey(values)]; ok {
+		return s.count
+	}
+	return 0
+}
+
+func (h *Histogram) write(w io.Writer) {
+	h.mu.Lock()
+	keys := make([]string, 0, len(h.series))
+	for k := range h.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	snapshot := make([]histogramSeries, 0, len(keys))
+	for _, k := range keys {
+		s := *h.series[k]
+		s.counts = append([]uint64(nil), s.counts...)
+		snapshot = append(snapshot, s)
+	}
+	h.mu.Unlock()
+
+	h.header(w)
+	for _, s := range snapshot {
+		var cumulative uint64
+		for i, upper := range h.buckets {
+			cumulative += s.counts[i]
+			h.sample(w, "_bucket", s.values, []string{"le", formatFloat(upper)}, float64(cumulative))
+		}
+		h.sample(w, "_bucket", s.values, []string{"le", "+Inf"}, float64(s.count))
+		h.sample(w, "_sum", s.values, nil, s.sum)
+		h.sample(w, "_count", s.values, nil, float64(s.count))
+	}
+}
@@ -0,0 +1,65 @@
+// Package metrics keeps in-process counters, gauges and histograms for the
+// service and writes them in the Prometheus text exposition format. Metrics
+// are created by the packages that own them and registered under a unique
+// name.
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Default is the registry metrics created with the New functions belong to
+var Default = NewRegistry()
+
+// metric is a registered metric that writes its samples
+type metric interface {
+	write(w io.Writer)
+}
+
+// Registry holds metrics by name
+type Registry struct {
+	mu      sync.Mutex
+	metrics map[string]metric
+}
+
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]metric)}
+}
+
+// register adds m under name. Names are fixed at init time, a duplicate is a
+// programming error.
+func (r *Registry) register(name string, m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.metrics[name]; ok {
+		panic(fmt.Sprintf("metrics: %s registered twice", name))
+	}
+	r.metrics[name] = m
+}
+
+// WriteText writes every metric in the Prometheus text format (version
+// 0.0.4), sorted by name
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	metrics := make([]metric, 0, len(names))
+	sort.Strings(names)
+	for _, name := range names {
+		metrics = append(metrics, r.metrics[name])
+	}
+	r.mu.Unlock()
+
+	var buf bytes.Buffer
+	for _, m := range metrics {
+		m.write(&buf)
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	r := NewRegistry()
+
+	requests := &Counter{desc: desc{name: "http_requests_total", help: "Requests served.", typ: "counter", labels: []string{"route", "status"}}}
+	requests.Inc("/order", "201")
+	requests.Inc("/order", "201")
+	requests.Inc(`/a"b`, "500")
+	r.register(requests.name, requests)
+
+	latency := &Histogram{
+		desc:    desc{name: "http_request_duration_seconds", help: "Request latency.\nIn seconds.", typ: "histogram", labels: []string{"route"}},
+		buckets: []float64{0.1, 1},
+		series:  make(map[string]*histogramSeries),
+	}
+	latency.Observe(0.05, "/order")
+	latency.Observe(2, "/order")
+	r.register(latency.name, latency)
+
+	r.register("db_open_connections", &Func{desc: desc{name: "db_open_connections", help: "Open connections.", typ: "gauge"}, fn: func() (float64, bool) { return 4, true }})
+	r.register("db_absent", &Func{desc: desc{name: "db_absent", typ: "gauge"}, fn: func() (float64, bool) { return 0, false }})
+
+	var b strings.Builder
+	require.NoError(t, r.WriteText(&b))
+	assert.Equal(t, `# HELP db_open_connections Open connections.
+# TYPE db_open_connections gauge
+db_open_connections 4
+# HELP http_request_duration_seconds Request latency.\nIn seconds.
+# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{route="/order",le="0.1"} 1
+http_request_duration_seconds_bucket{route="/order",le="1"} 1
+http_request_duration_seconds_bucket{route="/order",le="+Inf"} 2
+http_request_duration_seconds_sum{route="/order"} 2.05
+http_request_duration_seconds_count{route="/order"} 2
+# HELP http_requests_total Requests served.
+# TYPE http_requests_total counter
+http_requests_total{route="/a\"b",status="500"} 1
+http_requests_total{route="/order",status="201"} 2
+`, b.String())
+}
+
+func TestRegistry_DuplicateName(t *testing.T) {
+	r := NewRegistry()
+	r.register("test_total", &Counter{})
+	assert.Panics(t, func() { r.register("test_total", &Counter{}) })
+}
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	c := &Counter{desc: desc{name: "orders_created_total", help: "Orders created.", typ: "counter"}}
+	c.Inc()
+	r.register(c.name, c)
+
+	rec := httptest.NewRecorder()
+	ctx := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/metrics", nil), rec)
+	require.NoError(t, Handler(r)(ctx))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, ContentType, rec.Header().Get(echo.HeaderContentType))
+	assert.Contains(t, rec.Body.String(), "orders_created_total 1\n")
+}
@@ -0,0 +1,124 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// desc is the name, help text, type and label names of a metric
+type desc struct {
+	name   string
+	help   string
+	typ    string
+	labels []string
+}
+
+// header writes the HELP and TYPE lines of the metric
+func (d *desc) header(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", d.name, helpEscaper.Replace(d.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", d.name, d.typ)
+}
+
+// sample writes one line: the metric name with suffix, the label pairs of
+// values followed by the extra pairs, and v
+func (d *desc) sample(w io.Writer, suffix string, values []string, extra []string, v float64) {
+	io.WriteString(w, d.name+suffix)
+	if len(values)+len(extra) > 0 {
+		pairs := make([]string, 0, len(values)+len(extra)/2)
+		for i, name := range d.labels {
+			value := ""
+			if i < len(values) {
+				value = values[i]
+			}
+			pairs = append(pairs, name+`="`+labelEscaper.Replace(value)+`"`)
+		}
+		for i := 0; i+1 < len(extra); i += 2 {
+			pairs = append(pairs, extra[i]+`="`+labelEscaper.Replace(extra[i+1])+`"`)
+		}
+		io.WriteString(w, "{"+strings.Join(pairs, ",")+"}")
+	}
+	io.WriteString(w, " "+formatFloat(v)+"\n")
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// vec holds one value per combination of label values
+type vec struct {
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	values []string
+	value  float64
+}
+
+// update replaces the value of the series of values with fn of the old one
+func (v *vec) update(values []string, fn func(float64) float64) {
+	key := seriesKey(values)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.series == nil {
+		v.series = make(map[string]*series)
+	}
+	s, ok := v.series[key]
+	if !ok {
+		s = &series{values: append([]string(nil), values...)}
+		v.series[key] = s
+	}
+	s.value = fn(s.value)
+}
+
+func (v *vec) get(values []string) float64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if s, ok := v.series[seriesKey(values)]; ok {
+		return s.value
+	}
+	return 0
+}
+
+// each calls fn for every series, sorted by label values so the output is stable
+func (v *vec) each(fn func(values []string, value float64)) {
+	v.mu.Lock()
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	snapshot := make([]series, 0, len(keys))
+	for _, k := range keys {
+		snapshot = append(snapshot, *v.series[k])
+	}
+	v.mu.Unlock()
+
+	for _, s := range snapshot {
+		fn(s.values, s.value)
+	}
+}
+
+// seriesKey joins label values with a byte that cannot appear in them
+func seriesKey(values []string) string {
+	return strings.Join(values, "\xff")
+}
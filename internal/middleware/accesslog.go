@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/auth"
+	"github.com/mohammadshabab/order-food-online/internal/health"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+)
+
+// NewAccessLogMiddleware logs one line per request with its method, route
+// template, status, latency, response size, client IP and API key name.
+// Successful health checks are sampled: only every healthSample-th one is
+// logged, 0 logs none of them. It should run right after the request ID
+// middleware, so the line carries the request ID and sees the key the
+// authentication middlewares resolved.
+func NewAccessLogMiddleware(healthSample int) echo.MiddlewareFunc {
+	var healthChecks atomic.Uint64
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			if err := next(c); err != nil {
+				// let the error handler write the response, so its status is logged
+				c.Error(err)
+			}
+
+			req, res := c.Request(), c.Response()
+			if health.IsHealthCheck(c.Path()) && res.Status < http.StatusBadRequest {
+				n := healthChecks.Add(1)
+				if healthSample <= 0 || (n-1)%uint64(healthSample) != 0 {
+					return nil
+				}
+			}
+
+			ctx := req.Context()
+			kv := []any{
+				"method", req.Method,
+				"route", c.Path(),
+				"status", res.Status,
+				"latencyMs", float64(time.Since(start).Microseconds()) / 1000,
+				"bytes", res.Size,
+				"ip", c.RealIP(),
+			}
+			if k := auth.FromContext(ctx); k != nil {
+				kv = append(kv, "apiKey", k.Name)
+			}
+
+			if res.Status >= http.StatusInternalServerError {
+				logger.Error(ctx, "request", kv...)
+				return nil
+			}
+			logger.Info(ctx, "request", kv...)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/auth"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureLogs returns the JSON log lines written while fn runs
+func captureLogs(t *testing.T, fn func()) []map[string]any {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = w
+	logger.Init("test-service", "test", 0)
+	os.Stdout = stdout
+	defer logger.Init("test-service", "test", 0)
+
+	fn()
+	require.NoError(t, w.Close())
+
+	var lines []map[string]any
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var line map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestAccessLogMiddleware(t *testing.T) {
+	serve := func(mw echo.MiddlewareFunc, path string, h echo.HandlerFunc) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "203.0.113.7:51234"
+		c := e.NewContext(req, httptest.NewRecorder())
+		c.SetPath(path)
+		require.NoError(t, mw(h)(c))
+	}
+
+	t.Run("logs the request with its key", func(t *testing.T) {
+		lines := captureLogs(t, func() {
+			serve(NewAccessLogMiddleware(1), "/product/:productId", func(c echo.Context) error {
+				// the API key middleware replaces the request, the log sees the new one
+				c.SetRequest(c.Request().WithContext(auth.NewContext(c.Request().Context(), &auth.Key{Name: "kiosk"})))
+				return c.String(http.StatusOK, "hello")
+			})
+		})
+
+		require.Len(t, lines, 1)
+		line := lines[0]
+		assert.Equal(t, "request", line["msg"])
+		assert.Equal(t, "INFO", line["level"])
+		assert.Equal(t, "GET", line["method"])
+		assert.Equal(t, "/product/:productId", line["route"])
+		assert.Equal(t, float64(200), line["status"])
+		assert.Equal(t, float64(5), line["bytes"])
+		assert.Equal(t, "203.0.113.7", line["ip"])
+		assert.Equal(t, "kiosk", line["apiKey"])
+		assert.Contains(t, line, "latencyMs")
+	})
+
+	t.Run("handler errors are logged with their status", func(t *testing.T) {
+		lines := captureLogs(t, func() {
+			serve(NewAccessLogMiddleware(1), "/product", func(c echo.Context) error {
+				return echo.NewHTTPError(http.StatusServiceUnavailable, "down")
+			})
+		})
+
+		require.Len(t, lines, 1)
+		assert.Equal(t, float64(503), lines[0]["status"])
+		assert.Equal(t, "ERROR", lines[0]["level"])
+	})
+
+	t.Run("successful health checks are sampled", func(t *testing.T) {
+		ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+		lines := captureLogs(t, func() {
+			mw := NewAccessLogMiddleware(3)
+			for i := 0; i < 7; i++ {
+				serve(mw, "/health", ok)
+			}
+		})
+		assert.Len(t, lines, 3)
+
+		lines = captureLogs(t, func() {
+			serve(NewAccessLogMiddleware(0), "/health", ok)
+			serve(NewAccessLogMiddleware(0), "/health", func(c echo.Context) error {
+				return c.NoContent(http.StatusServiceUnavailable)
+			})
+		})
+		require.Len(t, lines, 1, "failed health checks are always logged")
+		assert.Equal(t, float64(503), lines[0]["status"])
+	})
+}
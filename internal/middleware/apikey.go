@@ -1,25 +1,64 @@
 package middleware
 
 import (
-	"net/http"
+	"context"
+	"crypto/subtle"
+	"errors"
 
 	"github.com/labstack/echo/v4"
 	"github.com/mohammadshabab/order-food-online/config"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/auth"
+	"github.com/mohammadshabab/order-food-online/internal/health"
 	"github.com/mohammadshabab/order-food-online/internal/logger"
 )
 
-func NewAPIKeyMiddleware(cfg config.Config) echo.MiddlewareFunc {
+// Authenticator resolves the api_key header to a key, auth.Service implements it
+type Authenticator interface {
+	Authenticate(ctx context.Context, raw string) (*auth.Key, error)
+}
+
+// NewAPIKeyMiddleware authenticates every request but the health checks and
+// puts its key in the request context, where auth.RequireScope checks it.
+// Requests already authenticated with a bearer token pass through.
+// cfg.APIKey, when set, is a bootstrap key that can only manage keys, meant
+// to create the first ones; keys may be nil to accept only that one.
+func NewAPIKeyMiddleware(cfg config.Config, keys Authenticator) echo.MiddlewareFunc {
+	bootstrap := &auth.Key{Name: "bootstrap", Scopes: []string{auth.ScopeKeysAdmin}}
+
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			if c.Path() == "/health" || c.Path() == "/ping" {
+			if health.IsHealthCheck(c.Path()) {
+				return next(c)
+			}
+
+			// callers with a valid bearer token were authenticated by the JWT middleware
+			ctx := c.Request().Context()
+			if auth.UserFromContext(ctx) != nil {
 				return next(c)
 			}
 
-			key := c.Request().Header.Get("api_key")
-			if key == "" || key != cfg.APIKey {
+			raw := c.Request().Header.Get("api_key")
+
+			var key *auth.Key
+			switch {
+			case raw == "":
+			case cfg.APIKey != "" && subtle.ConstantTimeCompare([]byte(raw), []byte(cfg.APIKey)) == 1:
+				key = bootstrap
+			case keys != nil:
+				k, err := keys.Authenticate(ctx, raw)
+				if err != nil && !errors.Is(err, auth.ErrUnauthorized) {
+					return apperrors.Internal("failed to authenticate request", err)
+				}
+				key = k
+			}
+
+			if key == nil {
 				logger.Log().Warn("unauthorized request", "path", c.Path(), "method", c.Request().Method)
-				return c.JSON(http.StatusUnauthorized, map[string]string{"message": "unauthorized"})
+				return auth.ErrUnauthorized
 			}
+
+			c.SetRequest(c.Request().WithContext(auth.NewContext(ctx, key)))
 			return next(c)
 		}
 	}
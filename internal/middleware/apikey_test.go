@@ -2,14 +2,19 @@ package middleware
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/golang/mock/gomock"
 	"github.com/labstack/echo/v4"
 	"github.com/mohammadshabab/order-food-online/config"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/auth"
 	"github.com/mohammadshabab/order-food-online/internal/logger"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAPIKeyMiddleware(t *testing.T) {
@@ -26,54 +31,122 @@ func TestAPIKeyMiddleware(t *testing.T) {
 
 	t.Run("health path bypasses middleware", func(t *testing.T) {
 		e := echo.New()
-		mw := NewAPIKeyMiddleware(cfg)
+		mw := NewAPIKeyMiddleware(cfg, nil)
 
-		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		for _, path := range []string{"/health", "/health/ping"} {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath(path)
+
+			apperrors.Handle(mw(nextHandler), c)
+			assert.Equal(t, http.StatusOK, rec.Code, path)
+		}
+	})
+
+	t.Run("valid API key", func(t *testing.T) {
+		e := echo.New()
+		mw := NewAPIKeyMiddleware(cfg, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/some-path", nil)
+		req.Header.Set("api_key", "my-secret-key")
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
-		c.SetPath("/health")
+		c.SetPath("/some-path")
 
-		err := mw(nextHandler)(c)
+		var key *auth.Key
+		err := mw(func(c echo.Context) error {
+			key = auth.FromContext(c.Request().Context())
+			return nextHandler(c)
+		})(c)
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, rec.Code)
+		require.NotNil(t, key)
+		assert.Equal(t, []string{auth.ScopeKeysAdmin}, key.Scopes, "the bootstrap key only manages keys")
 	})
 
-	t.Run("valid API key", func(t *testing.T) {
+	t.Run("key from the database", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		keys := auth.NewMockService(ctrl)
+		stored := &auth.Key{ID: "key-1", Name: "kiosk", Scopes: []string{auth.ScopeOrdersWrite}}
+		keys.EXPECT().Authenticate(gomock.Any(), "ofo_0123456789ab_secret").Return(stored, nil)
+
 		e := echo.New()
-		mw := NewAPIKeyMiddleware(cfg)
+		mw := NewAPIKeyMiddleware(cfg, keys)
 
 		req := httptest.NewRequest(http.MethodGet, "/some-path", nil)
-		req.Header.Set("api_key", "my-secret-key")
+		req.Header.Set("api_key", "ofo_0123456789ab_secret")
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
 		c.SetPath("/some-path")
 
-		err := mw(nextHandler)(c)
+		var key *auth.Key
+		err := mw(func(c echo.Context) error {
+			key = auth.FromContext(c.Request().Context())
+			return nextHandler(c)
+		})(c)
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, stored, key)
+	})
+
+	t.Run("key rejected by the database", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		keys := auth.NewMockService(ctrl)
+		keys.EXPECT().Authenticate(gomock.Any(), "ofo_0123456789ab_revoked").Return(nil, auth.ErrUnauthorized)
+
+		e := echo.New()
+		mw := NewAPIKeyMiddleware(cfg, keys)
+
+		req := httptest.NewRequest(http.MethodGet, "/some-path", nil)
+		req.Header.Set("api_key", "ofo_0123456789ab_revoked")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/some-path")
+
+		apperrors.Handle(mw(nextHandler), c)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("database failure", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		keys := auth.NewMockService(ctrl)
+		keys.EXPECT().Authenticate(gomock.Any(), gomock.Any()).Return(nil, errors.New("db down"))
+
+		e := echo.New()
+		mw := NewAPIKeyMiddleware(cfg, keys)
+
+		req := httptest.NewRequest(http.MethodGet, "/some-path", nil)
+		req.Header.Set("api_key", "ofo_0123456789ab_secret")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/some-path")
+
+		apperrors.Handle(mw(nextHandler), c)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
 	})
 
 	t.Run("missing API key", func(t *testing.T) {
 		e := echo.New()
-		mw := NewAPIKeyMiddleware(cfg)
+		mw := NewAPIKeyMiddleware(cfg, nil)
 
 		req := httptest.NewRequest(http.MethodGet, "/some-path", nil)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
 		c.SetPath("/some-path")
 
-		err := mw(nextHandler)(c)
-		assert.NoError(t, err)
+		apperrors.Handle(mw(nextHandler), c)
 		assert.Equal(t, http.StatusUnauthorized, rec.Code)
 
-		var resp map[string]string
+		var resp map[string]any
 		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
-		assert.Equal(t, "unauthorized", resp["message"])
+		assert.Equal(t, "unauthorized", resp["detail"])
+		assert.Equal(t, apperrors.CodeUnauthorized, resp["code"])
 	})
 
 	t.Run("wrong API key", func(t *testing.T) {
 		e := echo.New()
-		mw := NewAPIKeyMiddleware(cfg)
+		mw := NewAPIKeyMiddleware(cfg, nil)
 
 		req := httptest.NewRequest(http.MethodGet, "/some-path", nil)
 		req.Header.Set("api_key", "wrong-key")
@@ -81,12 +154,12 @@ func TestAPIKeyMiddleware(t *testing.T) {
 		c := e.NewContext(req, rec)
 		c.SetPath("/some-path")
 
-		err := mw(nextHandler)(c)
-		assert.NoError(t, err)
+		apperrors.Handle(mw(nextHandler), c)
 		assert.Equal(t, http.StatusUnauthorized, rec.Code)
 
-		var resp map[string]string
+		var resp map[string]any
 		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
-		assert.Equal(t, "unauthorized", resp["message"])
+		assert.Equal(t, "unauthorized", resp["detail"])
+		assert.Equal(t, apperrors.CodeUnauthorized, resp["code"])
 	})
 }
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/auth"
+	"github.com/mohammadshabab/order-food-online/internal/health"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+)
+
+// TokenVerifier validates bearer tokens, auth.Verifier implements it
+type TokenVerifier interface {
+	Verify(token string) (*auth.User, error)
+}
+
+// NewJWTMiddleware authenticates requests carrying an Authorization: Bearer
+// token. The user goes into the request context, where auth.RequireScope
+// checks the scopes of its roles, and its subject and roles into the log
+// attributes. Requests without a bearer token are left to the API key
+// middleware, which must run after this one.
+func NewJWTMiddleware(verifier TokenVerifier) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if health.IsHealthCheck(c.Path()) {
+				return next(c)
+			}
+
+			token, ok := bearerToken(c.Request().Header.Get(echo.HeaderAuthorization))
+			if !ok {
+				return next(c)
+			}
+
+			ctx := c.Request().Context()
+			user, err := verifier.Verify(token)
+			if err != nil {
+				appErr := apperrors.Unauthorized(auth.ErrInvalidToken.Message, err)
+				logger.Warn(ctx, "rejected bearer token", "path", c.Path(), "method", c.Request().Method, "error", appErr.Error())
+				return appErr
+			}
+
+			ctx = auth.NewUserContext(ctx, user)
+			ctx = logger.AddAttrs(ctx, logger.Attributes{"userId": user.Subject, "roles": user.Roles})
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+// bearerToken returns the token of an Authorization header using the Bearer scheme
+func bearerToken(header string) (string, bool) {
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/config"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/auth"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// verifierFunc adapts a function to TokenVerifier
+type verifierFunc func(token string) (*auth.User, error)
+
+func (f verifierFunc) Verify(token string) (*auth.User, error) { return f(token) }
+
+func TestJWTMiddleware(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+
+	verifier := verifierFunc(func(token string) (*auth.User, error) {
+		if token != "good.token.sig" {
+			return nil, auth.ErrTokenExpired
+		}
+		return &auth.User{Subject: "user-42", Roles: []string{"customer"}}, nil
+	})
+
+	serve := func(header string, mw ...echo.MiddlewareFunc) (*httptest.ResponseRecorder, *auth.User) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/product", nil)
+		if header != "" {
+			req.Header.Set(echo.HeaderAuthorization, header)
+		}
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/product")
+
+		var user *auth.User
+		h := func(c echo.Context) error {
+			user = auth.UserFromContext(c.Request().Context())
+			return c.NoContent(http.StatusNoContent)
+		}
+		for i := len(mw) - 1; i >= 0; i-- {
+			h = mw[i](h)
+		}
+		apperrors.Handle(h, c)
+		return rec, user
+	}
+
+	t.Run("valid token", func(t *testing.T) {
+		rec, user := serve("Bearer good.token.sig", NewJWTMiddleware(verifier))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, "user-42", user.Subject)
+	})
+
+	t.Run("scheme is case insensitive", func(t *testing.T) {
+		rec, user := serve("bearer good.token.sig", NewJWTMiddleware(verifier))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.NotNil(t, user)
+	})
+
+	t.Run("rejected token", func(t *testing.T) {
+		rec, _ := serve("Bearer old.token.sig", NewJWTMiddleware(verifier))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.JSONEq(t, `{"type":"urn:order-food-online:problem:token_expired","title":"Bearer token expired","status":401,
+			"detail":"token expired","instance":"/product","code":"token_expired"}`, rec.Body.String())
+	})
+
+	t.Run("no bearer token is left to the next middleware", func(t *testing.T) {
+		rec, user := serve("Basic dXNlcjpwYXNz", NewJWTMiddleware(verifier))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Nil(t, user)
+	})
+
+	t.Run("bearer token satisfies the API key middleware", func(t *testing.T) {
+		rec, user := serve("Bearer good.token.sig", NewJWTMiddleware(verifier), NewAPIKeyMiddleware(config.Config{APIKey: "my-secret-key"}, nil))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.NotNil(t, user)
+	})
+
+	t.Run("without a token the API key is still required", func(t *testing.T) {
+		rec, _ := serve("", NewJWTMiddleware(verifier), NewAPIKeyMiddleware(config.Config{APIKey: "my-secret-key"}, nil))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
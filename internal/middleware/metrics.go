@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/metrics"
+)
+
+var (
+	requestsTotal = metrics.NewCounter("http_requests_total",
+		"Requests served, by method, route and status.", "method", "route", "status")
+	requestDuration = metrics.NewHistogram("http_request_duration_seconds",
+		"Time to serve a request in seconds, by method, route and status.", metrics.DefBuckets, "method", "route", "status")
+)
+
+// NewMetricsMiddleware counts requests and observes their latency per method,
+// route template and status. Requests that match no route are counted under
+// the route "unmatched" and non-standard methods under the method "other",
+// so clients cannot grow the number of series.
+func NewMetricsMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			if err := next(c); err != nil {
+				// let the error handler write the response, so its status is counted
+				c.Error(err)
+			}
+
+			route := c.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+			method, status := metricMethod(c.Request().Method), strconv.Itoa(c.Response().Status)
+			requestsTotal.Inc(method, route, status)
+			requestDuration.Observe(time.Since(start).Seconds(), method, route, status)
+			return nil
+		}
+	}
+}
+
+// metricMethod returns method if it is one of the methods net/http defines
+func metricMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+	return "other"
+}
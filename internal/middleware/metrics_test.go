@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	serveMethod := func(method, path string, h echo.HandlerFunc) {
+		e := echo.New()
+		e.HTTPErrorHandler = apperrors.HTTPErrorHandler
+		c := e.NewContext(httptest.NewRequest(method, "/", nil), httptest.NewRecorder())
+		c.SetPath(path)
+		require.NoError(t, NewMetricsMiddleware()(h)(c))
+	}
+	serve := func(path string, h echo.HandlerFunc) { serveMethod(http.MethodPost, path, h) }
+
+	t.Run("counts by route and status", func(t *testing.T) {
+		before := requestsTotal.Value(http.MethodPost, "/order/:orderId/cancel", "200")
+		serve("/order/:orderId/cancel", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+		serve("/order/:orderId/cancel", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+		assert.Equal(t, before+2, requestsTotal.Value(http.MethodPost, "/order/:orderId/cancel", "200"))
+		assert.GreaterOrEqual(t, requestDuration.Count(http.MethodPost, "/order/:orderId/cancel", "200"), uint64(2))
+	})
+
+	t.Run("returned errors are counted with their status", func(t *testing.T) {
+		before := requestsTotal.Value(http.MethodPost, "/order", "409")
+		serve("/order", func(echo.Context) error { return apperrors.Conflict("some items are out of stock", nil) })
+		assert.Equal(t, before+1, requestsTotal.Value(http.MethodPost, "/order", "409"))
+	})
+
+	t.Run("unknown routes share one series", func(t *testing.T) {
+		before := requestsTotal.Value(http.MethodPost, "unmatched", "404")
+		serve("", func(echo.Context) error { return echo.ErrNotFound })
+		assert.Equal(t, before+1, requestsTotal.Value(http.MethodPost, "unmatched", "404"))
+	})
+
+	t.Run("non-standard methods share one series", func(t *testing.T) {
+		before := requestsTotal.Value("other", "unmatched", "405")
+		serveMethod("PROPFIND", "", func(echo.Context) error { return echo.ErrMethodNotAllowed })
+		serveMethod("X-RANDOM-1", "", func(echo.Context) error { return echo.ErrMethodNotAllowed })
+		assert.Equal(t, before+2, requestsTotal.Value("other", "unmatched", "405"))
+		assert.Zero(t, requestsTotal.Value("PROPFIND", "unmatched", "405"))
+	})
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/mohammadshabab/order-food-online/internal/metrics"
+)
+
+// panicsTotal counts the panics recovered per route
+var panicsTotal = metrics.NewCounter("http_panics_total", "Panics recovered while serving a request.", "route")
+
+// NewRecoverMiddleware turns a panic in a handler or a later middleware into
+// a 500 AppError for the error handler. The panic is logged with its stack
+// and the request ID, and counted in http_panics_total. http.ErrAbortHandler
+// is passed on, it is the way to abort a response on purpose.
+func NewRecoverMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			defer func() {
+				r := recover()
+				if r == nil {
+					return
+				}
+				if e, ok := r.(error); ok && errors.Is(e, http.ErrAbortHandler) {
+					panic(r)
+				}
+
+				req := c.Request()
+				panicsTotal.Inc(c.Path())
+				logger.Error(req.Context(), "panic recovered",
+					"method", req.Method,
+					"route", c.Path(),
+					"path", req.URL.Path,
+					"panic", fmt.Sprint(r),
+					"stack", string(debug.Stack()),
+				)
+				err = apperrors.Internal(http.StatusText(http.StatusInternalServerError), fmt.Errorf("panic: %v", r))
+			}()
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/db"
+	"github.com/mohammadshabab/order-food-online/internal/health"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoverMiddleware(t *testing.T) {
+	newContext := func(path string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req = req.WithContext(logger.WithRequestID(req.Context(), "req-1"))
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.SetPath(path)
+		return c, rec
+	}
+
+	t.Run("nil pool in the health check", func(t *testing.T) {
+		pool := db.Pool
+		db.Pool = nil
+		defer func() { db.Pool = pool }()
+
+		before := panicsTotal.Value("/health")
+		var rec *httptest.ResponseRecorder
+		lines := captureLogs(t, func() {
+			var c echo.Context
+			c, rec = newContext("/health")
+			apperrors.Handle(NewRecoverMiddleware()(health.NewHandler().Check), c)
+		})
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, apperrors.CodeInternal, body["code"])
+		assert.Equal(t, "req-1", body["requestId"])
+		assert.Equal(t, before+1, panicsTotal.Value("/health"))
+
+		require.NotEmpty(t, lines)
+		assert.Equal(t, "panic recovered", lines[0]["msg"])
+		assert.Equal(t, "ERROR", lines[0]["level"])
+		assert.Equal(t, "req-1", lines[0][logger.RequestIDKey])
+		assert.Equal(t, "/health", lines[0]["route"])
+		assert.Contains(t, lines[0]["panic"], "nil pointer dereference")
+		assert.Contains(t, lines[0]["stack"], "health.(*Handler).Check")
+	})
+
+	t.Run("panic with a value", func(t *testing.T) {
+		c, rec := newContext("/order")
+		captureLogs(t, func() {
+			apperrors.Handle(NewRecoverMiddleware()(func(echo.Context) error { panic("boom") }), c)
+		})
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+
+	t.Run("no panic", func(t *testing.T) {
+		c, rec := newContext("/order")
+		err := NewRecoverMiddleware()(func(c echo.Context) error { return c.NoContent(http.StatusNoContent) })(c)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("aborted handler panics on", func(t *testing.T) {
+		c, _ := newContext("/events/stream")
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			_ = NewRecoverMiddleware()(func(echo.Context) error { panic(http.ErrAbortHandler) })(c)
+		})
+	})
+}
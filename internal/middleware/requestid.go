@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+)
+
+// requestIDPattern limits client supplied IDs to what is safe to log and echo
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// NewRequestIDMiddleware takes the X-Request-ID of the request, or generates
+// one when it is missing or unusable, and echoes it in the response. The ID
+// goes into the log attributes of the request context, so every log line of
+// the request, DB logs included, and the events it publishes carry it. It
+// should be the first middleware.
+func NewRequestIDMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			id := c.Request().Header.Get(echo.HeaderXRequestID)
+			if !requestIDPattern.MatchString(id) {
+				id = uuid.New().String()
+			}
+
+			c.Response().Header().Set(echo.HeaderXRequestID, id)
+			c.SetRequest(c.Request().WithContext(logger.WithRequestID(c.Request().Context(), id)))
+			return next(c)
+		}
+	}
+}
+
+// RequestIDTransport sets X-Request-ID on outgoing requests to the request ID
+// of their context, so a webhook or other call made while serving a request
+// can be traced back to it. Requests that already carry the header keep it.
+type RequestIDTransport struct {
+	// Next sends the request, http.DefaultTransport when nil
+	Next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t RequestIDTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if id := logger.RequestID(req.Context()); id != "" && req.Header.Get(echo.HeaderXRequestID) == "" {
+		// a RoundTripper must not modify the request it was given
+		req = req.Clone(req.Context())
+		req.Header.Set(echo.HeaderXRequestID, id)
+	}
+	return next.RoundTrip(req)
+}
+
+// NewHTTPClient returns a client for outgoing calls that passes the request ID
+// on; build requests with http.NewRequestWithContext and the request context.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: RequestIDTransport{}, Timeout: timeout}
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+
+	serve := func(header string) (*httptest.ResponseRecorder, string) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/product", nil)
+		if header != "" {
+			req.Header.Set(echo.HeaderXRequestID, header)
+		}
+		rec := httptest.NewRecorder()
+
+		var id string
+		err := NewRequestIDMiddleware()(func(c echo.Context) error {
+			id = logger.RequestID(c.Request().Context())
+			return c.NoContent(http.StatusNoContent)
+		})(e.NewContext(req, rec))
+		require.NoError(t, err)
+		return rec, id
+	}
+
+	t.Run("client ID is kept", func(t *testing.T) {
+		rec, id := serve("checkout-7f3a:1")
+		assert.Equal(t, "checkout-7f3a:1", id)
+		assert.Equal(t, "checkout-7f3a:1", rec.Header().Get(echo.HeaderXRequestID))
+	})
+
+	t.Run("missing ID is generated", func(t *testing.T) {
+		rec, id := serve("")
+		_, err := uuid.Parse(id)
+		assert.NoError(t, err)
+		assert.Equal(t, id, rec.Header().Get(echo.HeaderXRequestID))
+	})
+
+	t.Run("unusable ID is replaced", func(t *testing.T) {
+		_, id := serve("bad id\nwith newline")
+		_, err := uuid.Parse(id)
+		assert.NoError(t, err)
+	})
+}
+
+func TestHTTPClient_PropagatesRequestID(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get(echo.HeaderXRequestID))
+	}))
+	defer srv.Close()
+
+	client := NewHTTPClient(time.Second)
+	send := func(ctx context.Context, header string) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL, nil)
+		require.NoError(t, err)
+		if header != "" {
+			req.Header.Set(echo.HeaderXRequestID, header)
+		}
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		res.Body.Close()
+		if header == "" {
+			assert.Empty(t, req.Header.Get(echo.HeaderXRequestID), "the caller's request is not modified")
+		}
+	}
+
+	ctx := logger.WithRequestID(context.Background(), "req-42")
+	send(ctx, "")
+	send(ctx, "caller-set")
+	send(context.Background(), "")
+
+	assert.Equal(t, []string{"req-42", "caller-set", ""}, got)
+}
@@ -0,0 +1,26 @@
+package order
+
+import (
+	"context"
+	"time"
+
+	"github.com/mohammadshabab/order-food-online/internal/product"
+	"github.com/mohammadshabab/order-food-online/internal/schedule"
+)
+
+// Catalog is the product lookup the order service prices items with.
+// product.Service satisfies it.
+//
+//go:generate mockgen -source=catalog.go -destination=mock_catalog.go -package=order
+type Catalog interface {
+	GetProduct(ctx context.Context, id string) (*product.Product, error)
+	// EffectivePrice is the base price of p at an instant, after price rules
+	EffectivePrice(ctx context.Context, p *product.Product, at time.Time) (*product.PriceQuote, error)
+}
+
+// Schedule answers whether a restaurant takes orders and which items are
+// off the menu right now. schedule.Service satisfies it.
+type Schedule interface {
+	IsOpen(ctx context.Context, restaurantID string) (bool, error)
+	Unavailable(ctx context.Context, items []schedule.Item) ([]schedule.Item, error)
+}
@@ -1,35 +1,40 @@
 package order
 
-import "github.com/mohammadshabab/order-food-online/internal/apperrors"
+import (
+	"net/http"
+
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+)
 
 var (
-	ErrOrderNotFound   = apperrors.NotFound("order not found", nil)
-	ErrOrderInvalid    = apperrors.BadRequest("invalid order request", nil)
-	ErrOrderValidation = apperrors.Wrap(422, "validation failed", apperrors.LevelWarn, nil)
+	ErrOrderNotFound    = apperrors.NotFound("order not found", nil).WithCode(apperrors.CodeOrderNotFound)
+	ErrOrderInvalid     = apperrors.BadRequest("invalid order request", nil)
+	ErrOrderValidation  = apperrors.Wrap(http.StatusUnprocessableEntity, "validation failed", apperrors.LevelWarn, nil).WithCode(apperrors.CodeValidationFailed)
+	ErrOutOfStock       = apperrors.Conflict("some items are out of stock", nil).WithCode(apperrors.CodeOutOfStock)
+	ErrOrderCancelled   = apperrors.Conflict("order is already cancelled", nil).WithCode(apperrors.CodeOrderCancelled)
+	ErrMixedRestaurants = apperrors.Wrap(http.StatusUnprocessableEntity, "all items of an order must come from the same restaurant", apperrors.LevelWarn, nil).WithCode(apperrors.CodeMixedRestaurants)
 )
 
+// Validate checks the request and reports every invalid field, not just the first
 func (or *OrderReq) Validate() *apperrors.AppError {
-	if or.Items == nil {
-		return apperrors.Wrap(400, "items is required", apperrors.LevelWarn, nil)
-	}
-
-	if len(*or.Items) == 0 {
-		return apperrors.Wrap(422, "order must have at least one item", apperrors.LevelWarn, nil)
+	var v apperrors.Validation
+	switch {
+	case or.Items == nil:
+		v.Add(apperrors.Pointer("items"), apperrors.FieldRequired, "items is required")
+	case len(*or.Items) == 0:
+		v.Add(apperrors.Pointer("items"), apperrors.FieldOutOfRange, "order must have at least one item")
 	}
 
-	// Validate each item
-	for _, i := range *or.Items {
-
-		// productId missing or empty
-		if i.ProductID == "" {
-			return apperrors.Wrap(400, "product ID is required", apperrors.LevelWarn, nil)
-		}
-
-		// quantity missing or invalid
-		if i.Quantity <= 0 {
-			return apperrors.Wrap(400, "quantity must be greater than 0", apperrors.LevelWarn, nil)
+	if or.Items != nil {
+		for i, item := range *or.Items {
+			if item.ProductID == "" {
+				v.Add(apperrors.Pointer("items", i, "productId"), apperrors.FieldRequired, "product ID is required")
+			}
+			if item.Quantity <= 0 {
+				v.Add(apperrors.Pointer("items", i, "quantity"), apperrors.FieldOutOfRange, "quantity must be greater than 0")
+			}
 		}
 	}
 
-	return nil
+	return v.Err(ErrOrderValidation)
 }
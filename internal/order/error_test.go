@@ -12,8 +12,9 @@ func TestOrderReq_Validate(t *testing.T) {
 		order := &OrderReq{Items: nil}
 		err := order.Validate()
 		assert.NotNil(t, err)
-		assert.Equal(t, 400, err.Code)
+		assert.Equal(t, 422, err.Code)
 		assert.Equal(t, "items is required", err.Message)
+		assert.Equal(t, []apperrors.FieldError{{Pointer: "/items", Code: apperrors.FieldRequired, Detail: "items is required"}}, err.Errors)
 		assert.Equal(t, apperrors.LevelWarn, err.Level)
 	})
 
@@ -32,8 +33,9 @@ func TestOrderReq_Validate(t *testing.T) {
 		}}
 		err := order.Validate()
 		assert.NotNil(t, err)
-		assert.Equal(t, 400, err.Code)
+		assert.Equal(t, 422, err.Code)
 		assert.Equal(t, "product ID is required", err.Message)
+		assert.Equal(t, "/items/0/productId", err.Errors[0].Pointer)
 		assert.Equal(t, apperrors.LevelWarn, err.Level)
 	})
 
@@ -43,11 +45,30 @@ func TestOrderReq_Validate(t *testing.T) {
 		}}
 		err := order.Validate()
 		assert.NotNil(t, err)
-		assert.Equal(t, 400, err.Code)
+		assert.Equal(t, 422, err.Code)
 		assert.Equal(t, "quantity must be greater than 0", err.Message)
+		assert.Equal(t, "/items/0/quantity", err.Errors[0].Pointer)
 		assert.Equal(t, apperrors.LevelWarn, err.Level)
 	})
 
+	t.Run("collects every field error", func(t *testing.T) {
+		order := &OrderReq{Items: &[]OrderItem{
+			{ProductID: "prod1", Quantity: 1},
+			{ProductID: "", Quantity: 1},
+			{ProductID: "prod3", Quantity: -1},
+		}}
+		err := order.Validate()
+		assert.NotNil(t, err)
+		assert.Equal(t, 422, err.Code)
+		assert.Equal(t, apperrors.CodeValidationFailed, err.ErrorCode)
+		assert.Equal(t, "validation failed", err.Message)
+		assert.Equal(t, []apperrors.FieldError{
+			{Pointer: "/items/1/productId", Code: apperrors.FieldRequired, Detail: "product ID is required"},
+			{Pointer: "/items/2/quantity", Code: apperrors.FieldOutOfRange, Detail: "quantity must be greater than 0"},
+		}, err.Errors)
+		assert.Nil(t, ErrOrderValidation.Errors)
+	})
+
 	t.Run("valid order", func(t *testing.T) {
 		order := &OrderReq{Items: &[]OrderItem{
 			{ProductID: "prod1", Quantity: 2},
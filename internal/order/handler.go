@@ -3,9 +3,9 @@ package order
 import (
 	"net/http"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/mohammadshabab/order-food-online/internal/apperrors"
-	"github.com/mohammadshabab/order-food-online/internal/logger"
 )
 
 type Handler struct {
@@ -21,19 +21,34 @@ func (h *Handler) CreateOrder(c echo.Context) error {
 
 	var req OrderReq
 	if err := c.Bind(&req); err != nil {
-		logger.Warn(ctx, ErrOrderInvalid.Message, "error", err.Error())
-		return c.JSON(ErrOrderInvalid.Code, ErrOrderInvalid)
+		return apperrors.Wrap(ErrOrderInvalid.Code, ErrOrderInvalid.Message, ErrOrderInvalid.Level, err)
 	}
 
 	if appErr := req.Validate(); appErr != nil {
-		return c.JSON(appErr.Code, appErr)
+		return appErr
 	}
 
 	order, err := h.svc.CreateOrder(ctx, &req)
 	if err != nil {
-		appErr := apperrors.Internal("failed to create order", err)
-		return c.JSON(appErr.Code, appErr)
+		return err
 	}
 
 	return c.JSON(http.StatusOK, order)
 }
+
+// CancelOrder serves POST /order/{orderId}/cancel
+func (h *Handler) CancelOrder(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id := c.Param("orderId")
+	if _, err := uuid.Parse(id); err != nil {
+		return apperrors.BadRequest("invalid order ID", err)
+	}
+
+	res, err := h.svc.CancelOrder(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
 	"github.com/mohammadshabab/order-food-online/internal/logger"
 	"github.com/stretchr/testify/assert"
 )
@@ -30,8 +31,7 @@ func TestHandler_CreateOrder(t *testing.T) {
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
 
-		err := h.CreateOrder(c)
-		assert.NoError(t, err)
+		apperrors.Handle(h.CreateOrder, c)
 		assert.Equal(t, ErrOrderInvalid.Code, rec.Code)
 	})
 
@@ -44,8 +44,7 @@ func TestHandler_CreateOrder(t *testing.T) {
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
 
-		err := h.CreateOrder(c)
-		assert.NoError(t, err)
+		apperrors.Handle(h.CreateOrder, c)
 		assert.Equal(t, 422, rec.Code) // order must have at least one item
 	})
 
@@ -60,11 +59,31 @@ func TestHandler_CreateOrder(t *testing.T) {
 
 		mockSvc.EXPECT().CreateOrder(gomock.Any(), &body).Return(nil, errors.New("db error"))
 
-		err := h.CreateOrder(c)
-		assert.NoError(t, err)
+		apperrors.Handle(h.CreateOrder, c)
 		assert.Equal(t, http.StatusInternalServerError, rec.Code)
 	})
 
+	t.Run("service errors keep their status", func(t *testing.T) {
+		body := OrderReq{Items: &[]OrderItem{{ProductID: "p1", Quantity: 1}}}
+		b, _ := json.Marshal(body)
+
+		for _, want := range []*apperrors.AppError{
+			apperrors.BadRequest("invalid coupon code: NOPE1234", nil),
+			apperrors.NotFound("product not found with id p1", nil),
+		} {
+			req := httptest.NewRequest(http.MethodPost, "/orders", bytes.NewReader(b))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			mockSvc.EXPECT().CreateOrder(gomock.Any(), &body).Return(nil, want)
+
+			apperrors.Handle(h.CreateOrder, c)
+			assert.Equal(t, want.Code, rec.Code)
+			assert.Contains(t, rec.Body.String(), want.Message)
+		}
+	})
+
 	t.Run("success", func(t *testing.T) {
 		body := OrderReq{Items: &[]OrderItem{{ProductID: "p1", Quantity: 2}}}
 		b, _ := json.Marshal(body)
@@ -78,14 +97,87 @@ func TestHandler_CreateOrder(t *testing.T) {
 		expectedOrder := &Order{ID: "order1", Items: *body.Items}
 		mockSvc.EXPECT().CreateOrder(gomock.Any(), &body).Return(expectedOrder, nil)
 
-		err := h.CreateOrder(c)
-		assert.NoError(t, err)
+		apperrors.Handle(h.CreateOrder, c)
 		assert.Equal(t, http.StatusOK, rec.Code)
 
 		var resp Order
-		err = json.Unmarshal(rec.Body.Bytes(), &resp)
+		err := json.Unmarshal(rec.Body.Bytes(), &resp)
 		assert.NoError(t, err)
 		assert.Equal(t, expectedOrder.ID, resp.ID)
 		assert.Equal(t, expectedOrder.Items, resp.Items)
 	})
+
+	t.Run("out of stock lists the short items", func(t *testing.T) {
+		body := OrderReq{Items: &[]OrderItem{{ProductID: "p1", Quantity: 3}}}
+		b, _ := json.Marshal(body)
+
+		req := httptest.NewRequest(http.MethodPost, "/orders", bytes.NewReader(b))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		shortages := []StockShortage{{ProductID: "p1", Name: "Burger", Requested: 3, Available: 1}}
+		mockSvc.EXPECT().CreateOrder(gomock.Any(), &body).
+			Return(nil, ErrOutOfStock.WithDetails(map[string]any{"items": shortages}))
+
+		apperrors.Handle(h.CreateOrder, c)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.JSONEq(t, `{"type":"urn:order-food-online:problem:out_of_stock","title":"Out of stock","status":409,"detail":"some items are out of stock",
+			"instance":"/orders","code":"out_of_stock","details":{"items":[{"productId":"p1","name":"Burger","requested":3,"available":1}]}}`, rec.Body.String())
+	})
+}
+
+func TestHandler_CancelOrder(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+	validID := "3f6b5b2a-7f66-4b3f-9a1b-000000000000"
+
+	newContext := func(id string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/order/"+id+"/cancel", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("orderId")
+		c.SetParamValues(id)
+		return c, rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().CancelOrder(gomock.Any(), validID).Return(&OrderStatus{ID: validID, Status: StatusCancelled}, nil)
+
+		c, rec := newContext(validID)
+		apperrors.Handle(NewHandler(mockSvc).CancelOrder, c)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"id":"`+validID+`","status":"cancelled"}`, rec.Body.String())
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+
+		c, rec := newContext("42")
+		apperrors.Handle(NewHandler(mockSvc).CancelOrder, c)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("already cancelled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().CancelOrder(gomock.Any(), validID).Return(nil, ErrOrderCancelled)
+
+		c, rec := newContext(validID)
+		apperrors.Handle(NewHandler(mockSvc).CancelOrder, c)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().CancelOrder(gomock.Any(), validID).Return(nil, ErrOrderNotFound)
+
+		c, rec := newContext(validID)
+		apperrors.Handle(NewHandler(mockSvc).CancelOrder, c)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
 }
@@ -3,8 +3,11 @@ package order
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/mohammadshabab/order-food-online/internal/apperrors"
 	"github.com/mohammadshabab/order-food-online/internal/db"
 	"github.com/mohammadshabab/order-food-online/internal/logger"
@@ -16,53 +19,178 @@ func NewMariaDBRepository() Repository {
 	return &MariaDBRepository{}
 }
 
+// Create stores an order that has already been priced by the service, including
+// a snapshot of the selected modifiers of every item. Stock is checked and
+// decremented under row locks in the same transaction, so concurrent orders
+// cannot oversell.
 func (r *MariaDBRepository) Create(ctx context.Context, order *Order) (*Order, error) {
+	err := db.Pool.InTx(ctx, func(tx *db.SQLTx) error {
+		tracked, err := reserveStock(ctx, tx, order.Items)
+		if err != nil {
+			return err
+		}
+
+		// Insert order (UUID provided from service)
+		query := `INSERT INTO orders (id, status, coupon_code, restaurant_id, created_at) VALUES (?, ?, ?, ?, ?)`
+		if _, err := tx.Exec(ctx, query, order.ID, order.Status, order.CouponCode, order.RestaurantID, time.Now()); err != nil {
+			appErr := apperrors.Internal("failed to create order", err)
+			logger.Error(ctx, appErr.Message, "error", err.Error())
+			return appErr
+		}
+
+		itemQuery := `INSERT INTO order_items (id, order_id, product_id, quantity, unit_price, line_total, stock_reserved, created_at)
+		              VALUES (?, ?, ?, ?, ?, ?, ?, NOW())`
+		modifierQuery := `INSERT INTO order_item_modifiers (id, order_item_id, modifier_id, group_name, name, price_delta)
+		                  VALUES (?, ?, ?, ?, ?, ?)`
 
-	// Insert order (UUID provided from service)
-	query := `INSERT INTO orders (id, coupon_code, created_at) VALUES (?, ?, ?)`
-	_, err := db.Pool.Exec(ctx, query, order.ID, order.CouponCode, time.Now())
+		for _, item := range order.Items {
+			itemID := uuid.New().String()
+
+			_, err := tx.Exec(ctx, itemQuery, itemID, order.ID, item.ProductID, item.Quantity, item.UnitPrice, item.LineTotal, tracked[item.ProductID])
+			if err != nil {
+				appErr := apperrors.Internal("failed to insert order item", err)
+				logger.Error(ctx, appErr.Message, "error", err.Error())
+				return appErr
+			}
+
+			for _, m := range item.SelectedModifiers {
+				_, err := tx.Exec(ctx, modifierQuery, uuid.New().String(), itemID, m.ID, m.Group, m.Name, m.PriceDelta)
+				if err != nil {
+					appErr := apperrors.Internal("failed to insert order item modifier", err)
+					logger.Error(ctx, appErr.Message, "error", err.Error())
+					return appErr
+				}
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		appErr := apperrors.Internal("failed to create order", err)
-		logger.Error(ctx, appErr.Message, "error", err.Error())
-		return nil, appErr
+		return nil, err
 	}
 
-	// Insert items + collect productRefs
-	productRefs := make([]ProductRef, 0)
+	return order, nil
+}
 
-	for _, item := range order.Items {
-		// Fetch product details for response
-		var p ProductRef
-		productQuery := `SELECT id, name, category, price FROM products WHERE id = ?`
-		err := db.Pool.QueryRow(ctx, productQuery, item.ProductID).
-			Scan(&p.ID, &p.Name, &p.Category, &p.Price)
+// reserveStock locks the ordered products, checks availability and stock for
+// all of them and decrements tracked stock. It reports which products are
+// stock tracked so cancellations know what to restore.
+func reserveStock(ctx context.Context, tx *db.SQLTx, items []OrderItem) (map[string]bool, error) {
+	// the same product may appear on several lines
+	requested := make(map[string]int)
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		if _, ok := requested[item.ProductID]; !ok {
+			ids = append(ids, item.ProductID)
+		}
+		requested[item.ProductID] += item.Quantity
+	}
 
-		if err != nil {
-			if err == sql.ErrNoRows {
-				// Return 404 if product not found
-				appErr := apperrors.NotFound("product not found", nil)
-				logger.Warn(ctx, appErr.Message, "productId", item.ProductID)
-				return nil, appErr
-			}
-			appErr := apperrors.Internal("failed to fetch product details", err)
+	type stockRow struct {
+		name      string
+		available bool
+		stock     sql.NullInt64
+	}
+
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	query := `SELECT id, name, available, stock FROM products
+	          WHERE id IN (?` + strings.Repeat(", ?", len(ids)-1) + `) AND archived_at IS NULL
+	          FOR UPDATE`
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	found := make(map[string]stockRow, len(ids))
+	for rows.Next() {
+		var (
+			id  string
+			row stockRow
+		)
+		if err := rows.Scan(&id, &row.name, &row.available, &row.stock); err != nil {
+			appErr := apperrors.Internal("failed to read product stock", err)
 			logger.Error(ctx, appErr.Message, "error", err.Error())
 			return nil, appErr
 		}
+		found[id] = row
+	}
+	if err := rows.Err(); err != nil {
+		appErr := apperrors.Internal("failed to read product stock", err)
+		logger.Error(ctx, appErr.Message, "error", err.Error())
+		return nil, appErr
+	}
 
-		productRefs = append(productRefs, p)
-
-		// Insert order item
-		itemQuery := `INSERT INTO order_items (id, order_id, product_id, quantity, created_at)
-		              VALUES (UUID(), ?, ?, ?, NOW())`
+	var shortages []StockShortage
+	for _, id := range ids {
+		row, ok := found[id]
+		switch {
+		case !ok || !row.available:
+			// archived or switched off since the order was priced
+			shortages = append(shortages, StockShortage{ProductID: id, Name: row.name, Requested: requested[id]})
+		case row.stock.Valid && int(row.stock.Int64) < requested[id]:
+			shortages = append(shortages, StockShortage{ProductID: id, Name: row.name, Requested: requested[id], Available: int(row.stock.Int64)})
+		}
+	}
+	if len(shortages) > 0 {
+		logger.Warn(ctx, ErrOutOfStock.Message, "items", len(shortages))
+		return nil, ErrOutOfStock.WithDetails(map[string]any{"items": shortages})
+	}
 
-		_, err = db.Pool.Exec(ctx, itemQuery, order.ID, item.ProductID, item.Quantity)
-		if err != nil {
-			appErr := apperrors.Internal("failed to insert order item", err)
-			logger.Error(ctx, appErr.Message, "error", err.Error())
+	tracked := make(map[string]bool)
+	for _, id := range ids {
+		if !found[id].stock.Valid {
+			continue
+		}
+		if _, err := tx.Exec(ctx, `UPDATE products SET stock = stock - ? WHERE id = ?`, requested[id], id); err != nil {
+			appErr := apperrors.Internal("failed to decrement stock", err)
+			logger.Error(ctx, appErr.Message, "productId", id, "error", err.Error())
 			return nil, appErr
 		}
+		tracked[id] = true
 	}
+	return tracked, nil
+}
 
-	order.Products = productRefs
-	return order, nil
+func (r *MariaDBRepository) Cancel(ctx context.Context, id string) error {
+	return db.Pool.InTx(ctx, func(tx *db.SQLTx) error {
+		var status string
+		err := tx.QueryRow(ctx, `SELECT status FROM orders WHERE id = ? FOR UPDATE`, id).Scan(&status)
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn(ctx, ErrOrderNotFound.Message, "orderId", id)
+			return ErrOrderNotFound
+		}
+		if err != nil {
+			appErr := apperrors.Internal("failed to fetch order", err)
+			logger.Error(ctx, appErr.Message, "orderId", id, "error", err.Error())
+			return appErr
+		}
+		if status == StatusCancelled {
+			return ErrOrderCancelled
+		}
+
+		if _, err := tx.Exec(ctx, `UPDATE orders SET status = ?, cancelled_at = NOW() WHERE id = ?`, StatusCancelled, id); err != nil {
+			appErr := apperrors.Internal("failed to cancel order", err)
+			logger.Error(ctx, appErr.Message, "orderId", id, "error", err.Error())
+			return appErr
+		}
+
+		// only items that took stock give it back, and only while the product is still tracked
+		restoreQuery := `UPDATE products p
+		                 JOIN (SELECT product_id, SUM(quantity) AS qty FROM order_items
+		                       WHERE order_id = ? AND stock_reserved GROUP BY product_id) i ON i.product_id = p.id
+		                 SET p.stock = p.stock + i.qty
+		                 WHERE p.stock IS NOT NULL`
+		if _, err := tx.Exec(ctx, restoreQuery, id); err != nil {
+			appErr := apperrors.Internal("failed to restore stock", err)
+			logger.Error(ctx, appErr.Message, "orderId", id, "error", err.Error())
+			return appErr
+		}
+
+		logger.Info(ctx, "order cancelled", "orderId", id)
+		return nil
+	})
 }
@@ -9,10 +9,14 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/mohammadshabab/order-food-online/internal/apperrors"
 	"github.com/mohammadshabab/order-food-online/internal/db"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
 	"github.com/stretchr/testify/assert"
 )
 
+var stockCols = []string{"id", "name", "available", "stock"}
+
 func TestMariaDBRepository_Create(t *testing.T) {
+	logger.Init("test-service", "test", 0)
 	ctx := context.Background()
 
 	// Setup sqlmock
@@ -24,119 +28,232 @@ func TestMariaDBRepository_Create(t *testing.T) {
 
 	t.Run("success", func(t *testing.T) {
 		orderObj := &Order{
-			ID:         "order123",
-			CouponCode: nil,
+			ID:     "order123",
+			Status: StatusPlaced,
 			Items: []OrderItem{
-				{ProductID: "p1", Quantity: 2},
+				{ProductID: "p1", Quantity: 2, UnitPrice: 12, LineTotal: 24, SelectedModifiers: []SelectedModifier{
+					{ID: "m1", Group: "Size", Name: "Large", PriceDelta: 4},
+				}},
+				{ProductID: "p2", Quantity: 1, UnitPrice: 5, LineTotal: 5},
+				{ProductID: "p1", Quantity: 1, UnitPrice: 8, LineTotal: 8},
 			},
 		}
 
+		mock.ExpectBegin()
+
+		// Lock the products, p1 is stock tracked and p2 is not
+		mock.ExpectQuery("SELECT id, name, available, stock FROM products WHERE id IN \\(\\?, \\?\\) (.+) FOR UPDATE").
+			WithArgs("p1", "p2").
+			WillReturnRows(sqlmock.NewRows(stockCols).
+				AddRow("p1", "Burger", true, 3).
+				AddRow("p2", "Fries", true, nil))
+
+		// Both p1 lines are taken out of stock at once
+		mock.ExpectExec("UPDATE products SET stock = stock - \\?").
+			WithArgs(3, "p1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
 		// Insert order
 		mock.ExpectExec("INSERT INTO orders").
-			WithArgs(orderObj.ID, orderObj.CouponCode, sqlmock.AnyArg()).
+			WithArgs(orderObj.ID, StatusPlaced, orderObj.CouponCode, orderObj.RestaurantID, sqlmock.AnyArg()).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
-		// Fetch product
-		rows := sqlmock.NewRows([]string{"id", "name", "category", "price"}).
-			AddRow("p1", "Burger", "Food", 150)
-		mock.ExpectQuery("SELECT id, name, category, price FROM products").
-			WithArgs("p1").
-			WillReturnRows(rows)
+		// Insert order_items with the prices computed by the service
+		mock.ExpectExec("INSERT INTO order_items").
+			WithArgs(sqlmock.AnyArg(), orderObj.ID, "p1", 2, 12.0, 24.0, true).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		// Snapshot of the selected modifier
+		mock.ExpectExec("INSERT INTO order_item_modifiers").
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "m1", "Size", "Large", 4.0).
+			WillReturnResult(sqlmock.NewResult(1, 1))
 
-		// Insert order_items
 		mock.ExpectExec("INSERT INTO order_items").
-			WithArgs(orderObj.ID, "p1", 2).
+			WithArgs(sqlmock.AnyArg(), orderObj.ID, "p2", 1, 5.0, 5.0, false).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO order_items").
+			WithArgs(sqlmock.AnyArg(), orderObj.ID, "p1", 1, 8.0, 8.0, true).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
+		mock.ExpectCommit()
+
 		res, err := repo.Create(ctx, orderObj)
 		assert.NoError(t, err)
 		assert.NotNil(t, res)
-		assert.Equal(t, "p1", res.Products[0].ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("every short item is reported", func(t *testing.T) {
+		orderObj := &Order{
+			ID: "order123",
+			Items: []OrderItem{
+				{ProductID: "p1", Quantity: 5},
+				{ProductID: "p2", Quantity: 1},
+				{ProductID: "p3", Quantity: 1},
+				{ProductID: "p4", Quantity: 1},
+			},
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, name, available, stock FROM products").
+			WithArgs("p1", "p2", "p3", "p4").
+			WillReturnRows(sqlmock.NewRows(stockCols).
+				AddRow("p1", "Burger", true, 2).
+				AddRow("p2", "Fries", false, nil).
+				AddRow("p4", "Cola", true, 10))
+		mock.ExpectRollback()
+
+		res, err := repo.Create(ctx, orderObj)
+		assert.Nil(t, res)
+
+		appErr, ok := err.(*apperrors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, 409, appErr.Code)
+		assert.Equal(t, ErrOutOfStock.Message, appErr.Message)
+		assert.Equal(t, map[string]any{"items": []StockShortage{
+			{ProductID: "p1", Name: "Burger", Requested: 5, Available: 2},
+			{ProductID: "p2", Name: "Fries", Requested: 1, Available: 0},
+			{ProductID: "p3", Requested: 1, Available: 0},
+		}}, appErr.Details)
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
 	t.Run("order insert fails", func(t *testing.T) {
-		orderObj := &Order{ID: "order123"}
+		orderObj := &Order{ID: "order123", Items: []OrderItem{{ProductID: "p1", Quantity: 1}}}
 
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, name, available, stock FROM products").
+			WillReturnRows(sqlmock.NewRows(stockCols).AddRow("p1", "Burger", true, nil))
 		mock.ExpectExec("INSERT INTO orders").
 			WillReturnError(errors.New("db error"))
+		mock.ExpectRollback()
 
 		res, err := repo.Create(ctx, orderObj)
 		assert.Nil(t, res)
 		assert.Error(t, err)
 		// match your current implementation error
 		assert.Contains(t, err.Error(), "DB Exec failed")
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
-	t.Run("product not found", func(t *testing.T) {
-		orderObj := &Order{
-			ID: "order123",
-			Items: []OrderItem{
-				{ProductID: "p999", Quantity: 1},
-			},
-		}
-
-		mock.ExpectExec("INSERT INTO orders").
-			WithArgs(orderObj.ID, orderObj.CouponCode, sqlmock.AnyArg()).
-			WillReturnResult(sqlmock.NewResult(1, 1))
+	t.Run("stock query fails", func(t *testing.T) {
+		orderObj := &Order{ID: "order123", Items: []OrderItem{{ProductID: "p1", Quantity: 1}}}
 
-		mock.ExpectQuery("SELECT id, name, category, price FROM products").
-			WithArgs("p999").
-			WillReturnError(sql.ErrNoRows)
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, name, available, stock FROM products").
+			WillReturnError(errors.New("lock wait timeout"))
+		mock.ExpectRollback()
 
 		res, err := repo.Create(ctx, orderObj)
 		assert.Nil(t, res)
-		assert.Error(t, err)
-		assert.Equal(t, 404, err.(*apperrors.AppError).Code)
+		assert.Contains(t, err.Error(), "DB Query failed")
 	})
 
-	t.Run("product scan fails", func(t *testing.T) {
+	t.Run("insert order item fails", func(t *testing.T) {
 		orderObj := &Order{
 			ID: "order123",
 			Items: []OrderItem{
-				{ProductID: "p1", Quantity: 1},
+				{ProductID: "p1", Quantity: 2},
 			},
 		}
 
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, name, available, stock FROM products").
+			WillReturnRows(sqlmock.NewRows(stockCols).AddRow("p1", "Burger", true, nil))
 		mock.ExpectExec("INSERT INTO orders").
 			WillReturnResult(sqlmock.NewResult(1, 1))
-
-		// return invalid rows to cause Scan error
-		rows := sqlmock.NewRows([]string{"id", "name", "category", "price"}).
-			AddRow(nil, nil, nil, nil)
-		mock.ExpectQuery("SELECT id, name, category, price FROM products").
-			WithArgs("p1").
-			WillReturnRows(rows)
+		mock.ExpectExec("INSERT INTO order_items").
+			WillReturnError(errors.New("item insert error"))
+		mock.ExpectRollback()
 
 		res, err := repo.Create(ctx, orderObj)
 		assert.Nil(t, res)
 		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "failed to fetch product details")
+		// match actual error returned by your repo
+		assert.Contains(t, err.Error(), "DB Exec failed")
 	})
 
-	t.Run("insert order item fails", func(t *testing.T) {
+	t.Run("insert modifier fails", func(t *testing.T) {
 		orderObj := &Order{
 			ID: "order123",
 			Items: []OrderItem{
-				{ProductID: "p1", Quantity: 2},
+				{ProductID: "p1", Quantity: 1, SelectedModifiers: []SelectedModifier{{ID: "m1"}}},
 			},
 		}
 
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id, name, available, stock FROM products").
+			WillReturnRows(sqlmock.NewRows(stockCols).AddRow("p1", "Burger", true, nil))
 		mock.ExpectExec("INSERT INTO orders").
 			WillReturnResult(sqlmock.NewResult(1, 1))
-
-		rows := sqlmock.NewRows([]string{"id", "name", "category", "price"}).
-			AddRow("p1", "Burger", "Food", 150)
-		mock.ExpectQuery("SELECT id, name, category, price FROM products").
-			WithArgs("p1").
-			WillReturnRows(rows)
-
 		mock.ExpectExec("INSERT INTO order_items").
-			WillReturnError(errors.New("item insert error"))
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO order_item_modifiers").
+			WillReturnError(errors.New("modifier insert error"))
+		mock.ExpectRollback()
 
 		res, err := repo.Create(ctx, orderObj)
 		assert.Nil(t, res)
 		assert.Error(t, err)
-		// match actual error returned by your repo
-		assert.Contains(t, err.Error(), "DB Exec failed")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMariaDBRepository_Cancel(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	db.Pool = db.NewTestPool(sqlDB)
+
+	repo := NewMariaDBRepository()
+
+	t.Run("success restores reserved stock", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT status FROM orders WHERE id = \\? FOR UPDATE").
+			WithArgs("order123").
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(StatusPlaced))
+		mock.ExpectExec("UPDATE orders SET status = \\?, cancelled_at = NOW\\(\\)").
+			WithArgs(StatusCancelled, "order123").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE products p JOIN (.+) stock_reserved (.+) SET p.stock = p.stock \\+ i.qty").
+			WithArgs("order123").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		assert.NoError(t, repo.Cancel(ctx, "order123"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT status FROM orders").WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		assert.Equal(t, ErrOrderNotFound, repo.Cancel(ctx, "missing"))
+	})
+
+	t.Run("already cancelled", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT status FROM orders").
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(StatusCancelled))
+		mock.ExpectRollback()
+
+		assert.Equal(t, ErrOrderCancelled, repo.Cancel(ctx, "order123"))
+	})
+
+	t.Run("restore fails", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT status FROM orders").
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(StatusPlaced))
+		mock.ExpectExec("UPDATE orders SET status").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE products p").
+			WillReturnError(errors.New("deadlock"))
+		mock.ExpectRollback()
+
+		assert.Error(t, repo.Cancel(ctx, "order123"))
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
@@ -0,0 +1,121 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: catalog.go
+
+// Package order is a generated GoMock package.
+package order
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	product "github.com/mohammadshabab/order-food-online/internal/product"
+	schedule "github.com/mohammadshabab/order-food-online/internal/schedule"
+)
+
+// MockCatalog is a mock of Catalog interface.
+type MockCatalog struct {
+	ctrl     *gomock.Controller
+	recorder *MockCatalogMockRecorder
+}
+
+// MockCatalogMockRecorder is the mock recorder for MockCatalog.
+type MockCatalogMockRecorder struct {
+	mock *MockCatalog
+}
+
+// NewMockCatalog creates a new mock instance.
+func NewMockCatalog(ctrl *gomock.Controller) *MockCatalog {
+	mock := &MockCatalog{ctrl: ctrl}
+	mock.recorder = &MockCatalogMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCatalog) EXPECT() *MockCatalogMockRecorder {
+	return m.recorder
+}
+
+// EffectivePrice mocks base method.
+func (m *MockCatalog) EffectivePrice(ctx context.Context, p *product.Product, at time.Time) (*product.PriceQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EffectivePrice", ctx, p, at)
+	ret0, _ := ret[0].(*product.PriceQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EffectivePrice indicates an expected call of EffectivePrice.
+func (mr *MockCatalogMockRecorder) EffectivePrice(ctx, p, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EffectivePrice", reflect.TypeOf((*MockCatalog)(nil).EffectivePrice), ctx, p, at)
+}
+
+// GetProduct mocks base method.
+func (m *MockCatalog) GetProduct(ctx context.Context, id string) (*product.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProduct", ctx, id)
+	ret0, _ := ret[0].(*product.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProduct indicates an expected call of GetProduct.
+func (mr *MockCatalogMockRecorder) GetProduct(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProduct", reflect.TypeOf((*MockCatalog)(nil).GetProduct), ctx, id)
+}
+
+// MockSchedule is a mock of Schedule interface.
+type MockSchedule struct {
+	ctrl     *gomock.Controller
+	recorder *MockScheduleMockRecorder
+}
+
+// MockScheduleMockRecorder is the mock recorder for MockSchedule.
+type MockScheduleMockRecorder struct {
+	mock *MockSchedule
+}
+
+// NewMockSchedule creates a new mock instance.
+func NewMockSchedule(ctrl *gomock.Controller) *MockSchedule {
+	mock := &MockSchedule{ctrl: ctrl}
+	mock.recorder = &MockScheduleMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSchedule) EXPECT() *MockScheduleMockRecorder {
+	return m.recorder
+}
+
+// IsOpen mocks base method.
+func (m *MockSchedule) IsOpen(ctx context.Context, restaurantID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsOpen", ctx, restaurantID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsOpen indicates an expected call of IsOpen.
+func (mr *MockScheduleMockRecorder) IsOpen(ctx, restaurantID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsOpen", reflect.TypeOf((*MockSchedule)(nil).IsOpen), ctx, restaurantID)
+}
+
+// Unavailable mocks base method.
+func (m *MockSchedule) Unavailable(ctx context.Context, items []schedule.Item) ([]schedule.Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unavailable", ctx, items)
+	ret0, _ := ret[0].([]schedule.Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Unavailable indicates an expected call of Unavailable.
+func (mr *MockScheduleMockRecorder) Unavailable(ctx, items interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unavailable", reflect.TypeOf((*MockSchedule)(nil).Unavailable), ctx, items)
+}
@@ -8,18 +8,58 @@ type OrderReq struct {
 type OrderItem struct {
 	ProductID string `json:"productId"`
 	Quantity  int    `json:"quantity"`
+	// Modifiers holds the ids of the selected product modifiers
+	Modifiers []string `json:"modifiers,omitempty"`
+
+	// Set by the service when the order is priced
+	SelectedModifiers []SelectedModifier `json:"selectedModifiers,omitempty"`
+	UnitPrice         float64            `json:"unitPrice"`
+	LineTotal         float64            `json:"lineTotal"`
+}
+
+// SelectedModifier is a snapshot of a modifier at the time the order was placed
+type SelectedModifier struct {
+	ID         string  `json:"id"`
+	Group      string  `json:"group"`
+	Name       string  `json:"name"`
+	PriceDelta float64 `json:"priceDelta"`
 }
 
+const (
+	StatusPlaced    = "placed"
+	StatusCancelled = "cancelled"
+)
+
 type Order struct {
-	ID         string       `json:"id"`
-	Items      []OrderItem  `json:"items"`
-	Products   []ProductRef `json:"products"`
-	CouponCode *string      `json:"couponCode,omitempty"`
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	// RestaurantID is the restaurant all items come from, set when the order is priced
+	RestaurantID string       `json:"restaurantId"`
+	Items        []OrderItem  `json:"items"`
+	Products     []ProductRef `json:"products"`
+	CouponCode   *string      `json:"couponCode,omitempty"`
+	Total        float64      `json:"total"`
 }
 
 type ProductRef struct {
-	ID       string  `json:"id"`
-	Name     string  `json:"name"`
-	Category string  `json:"category"`
-	Price    float64 `json:"price"`
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	CategoryID string  `json:"categoryId,omitempty"`
+	Category   string  `json:"category"`
+	Price      float64 `json:"price"`
+}
+
+// OrderStatus is the response of status changes such as a cancellation
+type OrderStatus struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+// StockShortage describes an order line that cannot be fulfilled. Available is
+// what is left in stock, 0 for products that are switched off.
+type StockShortage struct {
+	ProductID string `json:"productId"`
+	Name      string `json:"name"`
+	Requested int    `json:"requested"`
+	Available int    `json:"available"`
 }
@@ -0,0 +1,71 @@
+package order
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/product"
+)
+
+// priceItem checks the modifier selection of item against the group rules of p
+// and fills in the selected modifiers, unit price and line total.
+func priceItem(p *product.Product, item *OrderItem) *apperrors.AppError {
+	type choice struct {
+		group    *product.ModifierGroup
+		modifier product.Modifier
+	}
+	choices := make(map[string]choice)
+	for gi := range p.ModifierGroups {
+		g := &p.ModifierGroups[gi]
+		for _, m := range g.Modifiers {
+			choices[m.ID] = choice{group: g, modifier: m}
+		}
+	}
+
+	selected := make([]SelectedModifier, 0, len(item.Modifiers))
+	perGroup := make(map[string]int)
+	seen := make(map[string]bool)
+	unit := p.Price
+
+	for _, id := range item.Modifiers {
+		c, ok := choices[id]
+		if !ok {
+			return apperrors.Wrap(http.StatusUnprocessableEntity, fmt.Sprintf("modifier %s is not available for product %s", id, p.ID), apperrors.LevelWarn, nil).WithCode(apperrors.CodeInvalidModifiers)
+		}
+		if seen[id] {
+			return apperrors.Wrap(http.StatusUnprocessableEntity, fmt.Sprintf("modifier %s is selected more than once", id), apperrors.LevelWarn, nil).WithCode(apperrors.CodeInvalidModifiers)
+		}
+		seen[id] = true
+		perGroup[c.group.ID]++
+
+		selected = append(selected, SelectedModifier{
+			ID:         id,
+			Group:      c.group.Name,
+			Name:       c.modifier.Name,
+			PriceDelta: c.modifier.PriceDelta,
+		})
+		unit += c.modifier.PriceDelta
+	}
+
+	for _, g := range p.ModifierGroups {
+		n := perGroup[g.ID]
+		if n < g.MinSelect || n > g.MaxSelect {
+			return apperrors.Wrap(http.StatusUnprocessableEntity, selectionMessage(p, g), apperrors.LevelWarn, nil).WithCode(apperrors.CodeInvalidModifiers)
+		}
+	}
+
+	if len(selected) > 0 {
+		item.SelectedModifiers = selected
+	}
+	item.UnitPrice = product.RoundCents(unit)
+	item.LineTotal = product.RoundCents(unit * float64(item.Quantity))
+	return nil
+}
+
+func selectionMessage(p *product.Product, g product.ModifierGroup) string {
+	if g.MinSelect == g.MaxSelect {
+		return fmt.Sprintf("%s: select exactly %d from %s", p.Name, g.MinSelect, g.Name)
+	}
+	return fmt.Sprintf("%s: select %d to %d from %s", p.Name, g.MinSelect, g.MaxSelect, g.Name)
+}
@@ -0,0 +1,40 @@
+package order
+
+import (
+	"testing"
+
+	"github.com/mohammadshabab/order-food-online/internal/product"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriceItem(t *testing.T) {
+	p := &product.Product{ID: "pz", Name: "Pizza", Price: 9.99, ModifierGroups: []product.ModifierGroup{
+		{ID: "toppings", Name: "Toppings", MinSelect: 1, MaxSelect: 2, Modifiers: []product.Modifier{
+			{ID: "olives", Name: "Olives", PriceDelta: 0.1},
+			{ID: "basil", Name: "Basil", PriceDelta: 0.2},
+			{ID: "ham", Name: "Ham", PriceDelta: 2},
+		}},
+	}}
+
+	t.Run("prices are rounded to cents", func(t *testing.T) {
+		item := OrderItem{ProductID: "pz", Quantity: 3, Modifiers: []string{"olives", "basil"}}
+		assert.Nil(t, priceItem(p, &item))
+		assert.Equal(t, 10.29, item.UnitPrice)
+		assert.Equal(t, 30.87, item.LineTotal)
+		assert.Len(t, item.SelectedModifiers, 2)
+	})
+
+	t.Run("range message", func(t *testing.T) {
+		item := OrderItem{ProductID: "pz", Quantity: 1, Modifiers: []string{"olives", "basil", "ham"}}
+		err := priceItem(p, &item)
+		assert.NotNil(t, err)
+		assert.Equal(t, "Pizza: select 1 to 2 from Toppings", err.Message)
+	})
+
+	t.Run("no modifiers on a plain product", func(t *testing.T) {
+		item := OrderItem{ProductID: "p1", Quantity: 2}
+		assert.Nil(t, priceItem(&product.Product{ID: "p1", Price: 5}, &item))
+		assert.Nil(t, item.SelectedModifiers)
+		assert.Equal(t, 10.0, item.LineTotal)
+	})
+}
@@ -4,5 +4,9 @@ import "context"
 
 //go:generate mockgen -source=repository.go -destination=mock_repository.go -package=order
 type Repository interface {
+	// Create stores the order and takes its items out of stock in one
+	// transaction. It fails with ErrOutOfStock listing every short item.
 	Create(ctx context.Context, order *Order) (*Order, error)
+	// Cancel marks the order cancelled and puts its reserved stock back
+	Cancel(ctx context.Context, id string) error
 }
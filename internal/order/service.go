@@ -2,23 +2,45 @@ package order
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/mohammadshabab/order-food-online/internal/event"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/mohammadshabab/order-food-online/internal/metrics"
+	"github.com/mohammadshabab/order-food-online/internal/product"
 	"github.com/mohammadshabab/order-food-online/internal/promo"
+	"github.com/mohammadshabab/order-food-online/internal/schedule"
 )
 
+// ordersCreated counts the orders stored, replays of an idempotent request are not counted again
+var ordersCreated = metrics.NewCounter("orders_created_total", "Orders created.")
+
 //go:generate mockgen -source=service.go -destination=mock_service.go -package=order
 type Service interface {
 	CreateOrder(ctx context.Context, req *OrderReq) (*Order, error)
+	CancelOrder(ctx context.Context, id string) (*OrderStatus, error)
 }
 
 type service struct {
-	repo  Repository
-	promo *promo.Validator
+	repo      Repository
+	catalog   Catalog
+	schedule  Schedule
+	clock     schedule.Clock
+	promo     *promo.Validator
+	publisher event.EventPublisher
 }
 
-func NewService(repo Repository, promoValidator *promo.Validator) Service {
-	return &service{repo: repo, promo: promoValidator}
+// NewService creates the order service. A nil schedule accepts orders at any
+// time. Items are priced at clock's time, the one sched checks hours against.
+func NewService(repo Repository, catalog Catalog, sched Schedule, clock schedule.Clock, promoValidator *promo.Validator, publisher event.EventPublisher) Service {
+	if publisher == nil {
+		publisher = event.NewNoOpPublisher()
+	}
+	if clock == nil {
+		clock = schedule.SystemClock{}
+	}
+	return &service{repo: repo, catalog: catalog, schedule: sched, clock: clock, promo: promoValidator, publisher: publisher}
 }
 
 func (s *service) CreateOrder(ctx context.Context, req *OrderReq) (*Order, error) {
@@ -26,18 +48,138 @@ func (s *service) CreateOrder(ctx context.Context, req *OrderReq) (*Order, error
 		return nil, err
 	}
 
-	// Validate coupon if provided
+	order := &Order{
+		ID:         uuid.New().String(),
+		Status:     StatusPlaced,
+		CouponCode: req.CouponCode,
+	}
+	if err := s.price(ctx, order, *req.Items); err != nil {
+		return nil, err
+	}
+
+	// Validate coupon if provided, scoped coupons need the restaurant of the order
 	if req.CouponCode != nil && s.promo != nil {
-		if appErr := s.promo.Validate(*req.CouponCode); appErr != nil {
-			return nil, appErr
+		if err := s.promo.ValidateFor(ctx, *req.CouponCode, order.RestaurantID); err != nil {
+			return nil, err
 		}
 	}
+	if err := s.checkSchedule(ctx, order); err != nil {
+		return nil, err
+	}
 
-	order := &Order{
-		ID:         uuid.New().String(),
-		Items:      *req.Items,
-		CouponCode: req.CouponCode,
+	created, err := s.repo.Create(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	ordersCreated.Inc()
+	s.publish(ctx, event.EventOrderCreated, created.ID, created)
+	return created, nil
+}
+
+// CancelOrder cancels a placed order; its stock is restored by the repository
+func (s *service) CancelOrder(ctx context.Context, id string) (*OrderStatus, error) {
+	if err := s.repo.Cancel(ctx, id); err != nil {
+		return nil, err
 	}
 
-	return s.repo.Create(ctx, order)
+	res := &OrderStatus{ID: id, Status: StatusCancelled}
+	s.publish(ctx, event.EventOrderCancelled, id, res)
+	return res, nil
+}
+
+// price resolves every item against the catalog, validates its modifier
+// selection and fills in the line totals and the order total. Products are
+// priced at their effective price now, scheduled changes and price rules
+// included. All products must belong to one restaurant, which the order takes.
+func (s *service) price(ctx context.Context, o *Order, items []OrderItem) error {
+	o.Items = make([]OrderItem, len(items))
+	o.Products = make([]ProductRef, 0, len(items))
+	now := s.clock.Now()
+
+	var total float64
+	for i, item := range items {
+		p, err := s.catalog.GetProduct(ctx, item.ProductID)
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			o.RestaurantID = p.RestaurantID
+		} else if p.RestaurantID != o.RestaurantID {
+			logger.Warn(ctx, ErrMixedRestaurants.Message, "productId", p.ID, "restaurantId", p.RestaurantID, "orderRestaurantId", o.RestaurantID)
+			return ErrMixedRestaurants
+		}
+
+		quote, err := s.catalog.EffectivePrice(ctx, p, now)
+		if err != nil {
+			return err
+		}
+		priced := *p
+		priced.Price = quote.Price
+		p = &priced
+
+		if appErr := priceItem(p, &item); appErr != nil {
+			logger.Warn(ctx, appErr.Message, "productId", item.ProductID)
+			return appErr
+		}
+
+		o.Items[i] = item
+		o.Products = append(o.Products, ProductRef{ID: p.ID, Name: p.Name, CategoryID: p.CategoryID, Category: p.Category, Price: p.Price})
+		total += item.LineTotal
+	}
+
+	o.Total = product.RoundCents(total)
+	return nil
+}
+
+// checkSchedule rejects the order when its restaurant is outside the opening
+// hours or any of its products is outside its menu hours
+func (s *service) checkSchedule(ctx context.Context, o *Order) error {
+	if s.schedule == nil {
+		return nil
+	}
+
+	open, err := s.schedule.IsOpen(ctx, o.RestaurantID)
+	if err != nil {
+		return err
+	}
+	if !open {
+		logger.Warn(ctx, schedule.ErrRestaurantClosed.Message, "restaurantId", o.RestaurantID)
+		return schedule.ErrRestaurantClosed
+	}
+
+	items := make([]schedule.Item, 0, len(o.Products))
+	seen := make(map[string]bool, len(o.Products))
+	for _, p := range o.Products {
+		if seen[p.ID] {
+			continue
+		}
+		seen[p.ID] = true
+		items = append(items, schedule.Item{ProductID: p.ID, Name: p.Name, CategoryID: p.CategoryID})
+	}
+
+	off, err := s.schedule.Unavailable(ctx, items)
+	if err != nil {
+		return err
+	}
+	if len(off) > 0 {
+		logger.Warn(ctx, "order has items outside their menu hours", "count", len(off))
+		return schedule.ErrItemsUnavailable.WithDetails(map[string]any{"items": off})
+	}
+	return nil
+}
+
+// publish emits an order event. Failures are logged but never fail the request,
+// the order is already persisted at this point.
+func (s *service) publish(ctx context.Context, typ event.EventType, orderID string, data any) {
+	evt := event.Event{
+		Type:       typ,
+		OrderID:    orderID,
+		OccurredAt: time.Now().UTC(),
+		RequestID:  logger.RequestID(ctx),
+		Data:       data,
+	}
+	if err := s.publisher.Publish(ctx, evt); err != nil {
+		logger.Warn(ctx, "failed to publish order event", "type", string(typ), "orderId", orderID, "error", err.Error())
+	}
 }
@@ -4,9 +4,14 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/event"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/mohammadshabab/order-food-online/internal/product"
+	"github.com/mohammadshabab/order-food-online/internal/schedule"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -15,7 +20,10 @@ func TestService_CreateOrder(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := NewMockRepository(ctrl)
-	svc := NewService(mockRepo, nil)
+	mockCatalog := NewMockCatalog(ctrl)
+	atListPrice(mockCatalog)
+	mockCatalog.EXPECT().GetProduct(gomock.Any(), "p1").Return(burger, nil).AnyTimes()
+	svc := NewService(mockRepo, mockCatalog, nil, nil, nil, nil)
 
 	t.Run("validation fails", func(t *testing.T) {
 		req := &OrderReq{Items: &[]OrderItem{}}
@@ -49,7 +57,7 @@ func TestService_CreateOrder(t *testing.T) {
 
 	t.Run("success", func(t *testing.T) {
 		req := &OrderReq{Items: &[]OrderItem{{ProductID: "p1", Quantity: 2}}}
-		expectedOrder := &Order{ID: "order1", Items: *req.Items}
+		expectedOrder := &Order{ID: "order1", Items: []OrderItem{{ProductID: "p1", Quantity: 2, UnitPrice: 8.5, LineTotal: 17}}}
 
 		mockRepo.EXPECT().
 			Create(gomock.Any(), gomock.Any()).
@@ -58,11 +66,356 @@ func TestService_CreateOrder(t *testing.T) {
 				return o, nil
 			})
 
+		created := ordersCreated.Value()
 		order, err := svc.CreateOrder(context.Background(), req)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, order)
+		assert.Equal(t, created+1, ordersCreated.Value())
 		assert.Equal(t, expectedOrder.ID, order.ID)
 		assert.Equal(t, expectedOrder.Items, order.Items)
+		assert.Equal(t, []ProductRef{{ID: "p1", Name: "Burger", CategoryID: "c1", Category: "Burgers", Price: 8.5}}, order.Products)
+		assert.Equal(t, 17.0, order.Total)
+		assert.Equal(t, StatusPlaced, order.Status)
+		assert.Equal(t, product.DefaultRestaurantID, order.RestaurantID)
+	})
+}
+
+func TestService_CreateOrder_Restaurants(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	pasta := &product.Product{ID: "p2", RestaurantID: "55555555-5555-4555-8555-555555555555", Name: "Pasta", Price: 11}
+	mockCatalog := NewMockCatalog(ctrl)
+	atListPrice(mockCatalog)
+	mockCatalog.EXPECT().GetProduct(ctx, "p1").Return(burger, nil)
+	mockCatalog.EXPECT().GetProduct(ctx, "p2").Return(pasta, nil)
+
+	// the repository is never reached
+	svc := NewService(NewMockRepository(ctrl), mockCatalog, nil, nil, nil, nil)
+	order, err := svc.CreateOrder(ctx, &OrderReq{Items: &[]OrderItem{{ProductID: "p1", Quantity: 1}, {ProductID: "p2", Quantity: 1}}})
+
+	assert.Nil(t, order)
+	assert.Equal(t, ErrMixedRestaurants, err)
+}
+
+var burger = &product.Product{ID: "p1", RestaurantID: product.DefaultRestaurantID, Name: "Burger", CategoryID: "c1", Category: "Burgers", Price: 8.5}
+
+// atListPrice makes the catalog quote every product at its own price
+func atListPrice(c *MockCatalog) {
+	c.EXPECT().EffectivePrice(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, p *product.Product, at time.Time) (*product.PriceQuote, error) {
+			return &product.PriceQuote{ProductID: p.ID, At: at, BasePrice: p.Price, Price: p.Price}, nil
+		}).AnyTimes()
+}
+
+func TestService_CreateOrder_Schedule(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+	req := func() *OrderReq {
+		return &OrderReq{Items: &[]OrderItem{{ProductID: "p1", Quantity: 1}, {ProductID: "p1", Quantity: 2}}}
+	}
+
+	t.Run("restaurant of the order closed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockCatalog := NewMockCatalog(ctrl)
+		atListPrice(mockCatalog)
+		mockCatalog.EXPECT().GetProduct(ctx, "p1").Return(burger, nil).Times(2)
+		mockSchedule := NewMockSchedule(ctrl)
+		mockSchedule.EXPECT().IsOpen(ctx, product.DefaultRestaurantID).Return(false, nil)
+
+		svc := NewService(NewMockRepository(ctrl), mockCatalog, mockSchedule, nil, nil, nil)
+		order, err := svc.CreateOrder(ctx, req())
+
+		assert.Nil(t, order)
+		assert.Equal(t, schedule.ErrRestaurantClosed, err)
+	})
+
+	t.Run("items outside their menu hours", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockCatalog := NewMockCatalog(ctrl)
+		atListPrice(mockCatalog)
+		mockCatalog.EXPECT().GetProduct(ctx, "p1").Return(burger, nil).Times(2)
+
+		item := schedule.Item{ProductID: "p1", Name: "Burger", CategoryID: "c1"}
+		mockSchedule := NewMockSchedule(ctrl)
+		mockSchedule.EXPECT().IsOpen(ctx, product.DefaultRestaurantID).Return(true, nil)
+		// a product ordered twice is checked once
+		mockSchedule.EXPECT().Unavailable(ctx, []schedule.Item{item}).Return([]schedule.Item{item}, nil)
+
+		svc := NewService(NewMockRepository(ctrl), mockCatalog, mockSchedule, nil, nil, nil)
+		order, err := svc.CreateOrder(ctx, req())
+
+		assert.Nil(t, order)
+		appErr, ok := err.(*apperrors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, 422, appErr.Code)
+		assert.Equal(t, map[string]any{"items": []schedule.Item{item}}, appErr.Details)
+		assert.Nil(t, schedule.ErrItemsUnavailable.Details, "sentinel must stay untouched")
+	})
+
+	t.Run("everything available", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockCatalog := NewMockCatalog(ctrl)
+		atListPrice(mockCatalog)
+		mockCatalog.EXPECT().GetProduct(ctx, "p1").Return(burger, nil).Times(2)
+
+		mockSchedule := NewMockSchedule(ctrl)
+		mockSchedule.EXPECT().IsOpen(ctx, product.DefaultRestaurantID).Return(true, nil)
+		mockSchedule.EXPECT().Unavailable(ctx, gomock.Any()).Return(nil, nil)
+
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, o *Order) (*Order, error) { return o, nil })
+
+		svc := NewService(mockRepo, mockCatalog, mockSchedule, nil, nil, nil)
+		order, err := svc.CreateOrder(ctx, req())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 25.5, order.Total)
+	})
+
+	t.Run("priced at the time the schedule is checked at", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		at := time.Date(2026, 10, 19, 7, 30, 0, 0, time.UTC)
+		mockCatalog := NewMockCatalog(ctrl)
+		mockCatalog.EXPECT().GetProduct(ctx, "p1").Return(burger, nil).Times(2)
+		mockCatalog.EXPECT().EffectivePrice(ctx, burger, at).Return(&product.PriceQuote{ProductID: "p1", At: at, BasePrice: 8.5, Price: 6}, nil).Times(2)
+
+		mockSchedule := NewMockSchedule(ctrl)
+		mockSchedule.EXPECT().IsOpen(ctx, product.DefaultRestaurantID).Return(true, nil)
+		mockSchedule.EXPECT().Unavailable(ctx, gomock.Any()).Return(nil, nil)
+
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, o *Order) (*Order, error) { return o, nil })
+
+		clock := schedule.ClockFunc(func() time.Time { return at })
+		order, err := NewService(mockRepo, mockCatalog, mockSchedule, clock, nil, nil).CreateOrder(ctx, req())
+		assert.NoError(t, err)
+		assert.Equal(t, 18.0, order.Total)
+	})
+
+	t.Run("schedule error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockCatalog := NewMockCatalog(ctrl)
+		atListPrice(mockCatalog)
+		mockCatalog.EXPECT().GetProduct(ctx, "p1").Return(burger, nil).Times(2)
+		mockSchedule := NewMockSchedule(ctrl)
+		mockSchedule.EXPECT().IsOpen(ctx, product.DefaultRestaurantID).Return(false, errors.New("db error"))
+
+		svc := NewService(NewMockRepository(ctrl), mockCatalog, mockSchedule, nil, nil, nil)
+		_, err := svc.CreateOrder(ctx, req())
+
+		assert.EqualError(t, err, "db error")
+	})
+}
+
+func TestService_CreateOrder_Modifiers(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pizza := &product.Product{ID: "pz", RestaurantID: product.DefaultRestaurantID, Name: "Pizza Margherita", Price: 10, ModifierGroups: []product.ModifierGroup{
+		{ID: "size", Name: "Size", MinSelect: 1, MaxSelect: 1, Modifiers: []product.Modifier{
+			{ID: "small", Name: "Small"},
+			{ID: "large", Name: "Large", PriceDelta: 4},
+		}},
+		{ID: "toppings", Name: "Extra toppings", MinSelect: 0, MaxSelect: 2, Modifiers: []product.Modifier{
+			{ID: "olives", Name: "Olives", PriceDelta: 1.25},
+			{ID: "basil", Name: "Basil", PriceDelta: 0.5},
+		}},
+	}}
+
+	mockRepo := NewMockRepository(ctrl)
+	mockCatalog := NewMockCatalog(ctrl)
+	atListPrice(mockCatalog)
+	mockCatalog.EXPECT().GetProduct(gomock.Any(), "pz").Return(pizza, nil).AnyTimes()
+	mockCatalog.EXPECT().GetProduct(gomock.Any(), "p1").Return(burger, nil).AnyTimes()
+	svc := NewService(mockRepo, mockCatalog, nil, nil, nil, nil)
+
+	t.Run("modifiers are priced into the line total", func(t *testing.T) {
+		mockRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, o *Order) (*Order, error) { return o, nil })
+
+		req := &OrderReq{Items: &[]OrderItem{
+			{ProductID: "pz", Quantity: 2, Modifiers: []string{"large", "olives"}},
+			{ProductID: "p1", Quantity: 1},
+		}}
+		order, err := svc.CreateOrder(context.Background(), req)
+		assert.NoError(t, err)
+
+		assert.Equal(t, 15.25, order.Items[0].UnitPrice)
+		assert.Equal(t, 30.5, order.Items[0].LineTotal)
+		assert.Equal(t, []SelectedModifier{
+			{ID: "large", Group: "Size", Name: "Large", PriceDelta: 4},
+			{ID: "olives", Group: "Extra toppings", Name: "Olives", PriceDelta: 1.25},
+		}, order.Items[0].SelectedModifiers)
+		assert.Equal(t, 39.0, order.Total)
+	})
+
+	for _, tt := range []struct {
+		name      string
+		modifiers []string
+		message   string
+	}{
+		{"required group missing", []string{"olives"}, "Pizza Margherita: select exactly 1 from Size"},
+		{"too many in group", []string{"small", "large"}, "Pizza Margherita: select exactly 1 from Size"},
+		{"unknown modifier", []string{"small", "pineapple"}, "modifier pineapple is not available for product pz"},
+		{"duplicate modifier", []string{"small", "olives", "olives"}, "modifier olives is selected more than once"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &OrderReq{Items: &[]OrderItem{{ProductID: "pz", Quantity: 1, Modifiers: tt.modifiers}}}
+			order, err := svc.CreateOrder(context.Background(), req)
+			assert.Nil(t, order)
+
+			appErr, ok := err.(*apperrors.AppError)
+			assert.True(t, ok)
+			assert.Equal(t, 422, appErr.Code)
+			assert.Equal(t, tt.message, appErr.Message)
+		})
+	}
+
+	t.Run("modifiers on a product without groups", func(t *testing.T) {
+		req := &OrderReq{Items: &[]OrderItem{{ProductID: "p1", Quantity: 1, Modifiers: []string{"large"}}}}
+		_, err := svc.CreateOrder(context.Background(), req)
+		assert.Equal(t, 422, err.(*apperrors.AppError).Code)
+	})
+
+	t.Run("unknown product", func(t *testing.T) {
+		mockCatalog.EXPECT().GetProduct(gomock.Any(), "p9").Return(nil, product.ErrProductNotFound)
+
+		req := &OrderReq{Items: &[]OrderItem{{ProductID: "p9", Quantity: 1}}}
+		_, err := svc.CreateOrder(context.Background(), req)
+		assert.Equal(t, product.ErrProductNotFound, err)
+	})
+}
+
+func TestService_CreateOrder_EffectivePrice(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	mockCatalog := NewMockCatalog(ctrl)
+	mockCatalog.EXPECT().GetProduct(gomock.Any(), "p1").Return(burger, nil).AnyTimes()
+	svc := NewService(mockRepo, mockCatalog, nil, nil, nil, nil)
+
+	t.Run("items are priced at the resolved price", func(t *testing.T) {
+		mockCatalog.EXPECT().EffectivePrice(gomock.Any(), burger, gomock.Any()).
+			Return(&product.PriceQuote{ProductID: "p1", BasePrice: 8.5, Price: 6.8}, nil)
+		mockRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, o *Order) (*Order, error) { return o, nil })
+
+		req := &OrderReq{Items: &[]OrderItem{{ProductID: "p1", Quantity: 2}}}
+		order, err := svc.CreateOrder(context.Background(), req)
+		assert.NoError(t, err)
+
+		assert.Equal(t, 6.8, order.Items[0].UnitPrice)
+		assert.Equal(t, 13.6, order.Total)
+		assert.Equal(t, 6.8, order.Products[0].Price)
+		assert.Equal(t, 8.5, burger.Price, "the catalog product is not modified")
+	})
+
+	t.Run("price resolution fails", func(t *testing.T) {
+		dbErr := apperrors.Internal("failed to read prices", errors.New("db down"))
+		mockCatalog.EXPECT().EffectivePrice(gomock.Any(), burger, gomock.Any()).Return(nil, dbErr)
+
+		req := &OrderReq{Items: &[]OrderItem{{ProductID: "p1", Quantity: 1}}}
+		order, err := svc.CreateOrder(context.Background(), req)
+		assert.Nil(t, order)
+		assert.Equal(t, dbErr, err)
+	})
+}
+
+type recordingPublisher struct {
+	events []event.Event
+	err    error
+}
+
+func (r *recordingPublisher) Publish(_ context.Context, evt event.Event) error {
+	r.events = append(r.events, evt)
+	return r.err
+}
+
+func TestService_CreateOrder_PublishesEvent(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	mockCatalog := NewMockCatalog(ctrl)
+	atListPrice(mockCatalog)
+	mockCatalog.EXPECT().GetProduct(gomock.Any(), "p1").Return(burger, nil).AnyTimes()
+	pub := &recordingPublisher{}
+	svc := NewService(mockRepo, mockCatalog, nil, nil, nil, pub)
+
+	t.Run("publishes order.created after persisting", func(t *testing.T) {
+		req := &OrderReq{Items: &[]OrderItem{{ProductID: "p1", Quantity: 1}}}
+		mockRepo.EXPECT().
+			Create(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, o *Order) (*Order, error) { return o, nil })
+
+		order, err := svc.CreateOrder(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Len(t, pub.events, 1)
+		assert.Equal(t, event.EventOrderCreated, pub.events[0].Type)
+		assert.Equal(t, order.ID, pub.events[0].OrderID)
+		assert.False(t, pub.events[0].OccurredAt.IsZero())
+	})
+
+	t.Run("publish failure does not fail the order", func(t *testing.T) {
+		pub.err = errors.New("broker down")
+		req := &OrderReq{Items: &[]OrderItem{{ProductID: "p1", Quantity: 1}}}
+		mockRepo.EXPECT().
+			Create(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, o *Order) (*Order, error) { return o, nil })
+
+		order, err := svc.CreateOrder(context.Background(), req)
+		assert.NoError(t, err)
+		assert.NotNil(t, order)
+	})
+
+	t.Run("repository error publishes nothing", func(t *testing.T) {
+		pub.events = nil
+		req := &OrderReq{Items: &[]OrderItem{{ProductID: "p1", Quantity: 1}}}
+		mockRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil, errors.New("db error"))
+
+		_, err := svc.CreateOrder(context.Background(), req)
+		assert.Error(t, err)
+		assert.Empty(t, pub.events)
+	})
+}
+
+func TestService_CancelOrder(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	pub := &recordingPublisher{}
+	svc := NewService(mockRepo, NewMockCatalog(ctrl), nil, nil, nil, pub)
+
+	t.Run("publishes order.cancelled", func(t *testing.T) {
+		mockRepo.EXPECT().Cancel(gomock.Any(), "order1").Return(nil)
+
+		ctx := logger.WithRequestID(context.Background(), "req-1")
+		res, err := svc.CancelOrder(ctx, "order1")
+		assert.NoError(t, err)
+		assert.Equal(t, &OrderStatus{ID: "order1", Status: StatusCancelled}, res)
+		assert.Len(t, pub.events, 1)
+		assert.Equal(t, event.EventOrderCancelled, pub.events[0].Type)
+		assert.Equal(t, "order1", pub.events[0].OrderID)
+		assert.Equal(t, "req-1", pub.events[0].RequestID)
+	})
+
+	t.Run("repository error publishes nothing", func(t *testing.T) {
+		pub.events = nil
+		mockRepo.EXPECT().Cancel(gomock.Any(), "order1").Return(ErrOrderCancelled)
+
+		res, err := svc.CancelOrder(context.Background(), "order1")
+		assert.Nil(t, res)
+		assert.Equal(t, ErrOrderCancelled, err)
+		assert.Empty(t, pub.events)
 	})
 }
@@ -2,12 +2,19 @@ package order
 
 import (
 	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/auth"
+	"github.com/mohammadshabab/order-food-online/internal/event"
 	"github.com/mohammadshabab/order-food-online/internal/promo"
+	"github.com/mohammadshabab/order-food-online/internal/schedule"
 )
 
-func Setup(e *echo.Echo, repo Repository, promoValidator *promo.Validator) {
-	svc := NewService(repo, promoValidator)
+// Setup registers the order routes: placing orders needs the orders:write
+// scope, cancelling them orders:cancel. createMiddleware is applied to POST /order only (e.g. idempotency handling).
+func Setup(e *echo.Echo, repo Repository, catalog Catalog, sched Schedule, clock schedule.Clock, promoValidator *promo.Validator, publisher event.EventPublisher, createMiddleware ...echo.MiddlewareFunc) {
+	svc := NewService(repo, catalog, sched, clock, promoValidator, publisher)
 	h := NewHandler(svc)
+	write := auth.RequireScope(auth.ScopeOrdersWrite)
 
-	e.POST("/order", h.CreateOrder)
+	e.POST("/order", h.CreateOrder, append([]echo.MiddlewareFunc{write}, createMiddleware...)...)
+	e.POST("/order/:orderId/cancel", h.CancelOrder, auth.RequireScope(auth.ScopeOrdersCancel))
 }
@@ -19,8 +19,8 @@ func TestSetup(t *testing.T) {
 		// echo instance
 		e := echo.New()
 
-		// pass nil for promo validator
-		Setup(e, mockRepo, nil)
+		// pass nil for promo validator and publisher
+		Setup(e, mockRepo, NewMockCatalog(ctrl), nil, nil, nil, nil)
 
 		// verify route
 		routes := e.Routes()
@@ -36,4 +36,22 @@ func TestSetup(t *testing.T) {
 			t.Errorf("expected POST /order to be registered but it was not")
 		}
 	})
+
+	t.Run("should register POST /order/:orderId/cancel route", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		e := echo.New()
+		Setup(e, NewMockRepository(ctrl), NewMockCatalog(ctrl), nil, nil, nil, nil)
+
+		found := false
+		for _, r := range e.Routes() {
+			if r.Method == http.MethodPost && r.Path == "/order/:orderId/cancel" {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected POST /order/:orderId/cancel to be registered but it was not")
+		}
+	})
 }
@@ -0,0 +1,273 @@
+package product
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/mohammadshabab/order-food-online/internal/schedule"
+)
+
+// maxCacheEntries bounds the number of cached reads. Listings are keyed by
+// their filters, so the cache is simply emptied when it fills up.
+const maxCacheEntries = 1000
+
+// CachedRepository is a read-through cache in front of another Repository.
+// Reads are kept for ttl and every write empties the cache, so this instance
+// sees its own writes at once and writes made elsewhere (other instances,
+// stock taken by orders) after at most ttl.
+type CachedRepository struct {
+	repo Repository
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	// gen is bumped by every invalidation so reads that started before a
+	// write don't store what they read
+	gen uint64
+}
+
+// freshKey marks a context whose reads skip the cache, see fresh
+type freshKey struct{}
+
+// fresh makes reads on ctx go to the database. Read-modify-write paths use it
+// so a stale cached version doesn't fail a write the client got right.
+func fresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, freshKey{}, true)
+}
+
+type cacheEntry struct {
+	value    any
+	loadedAt time.Time
+}
+
+// NewCachedRepository caches the reads of repo for ttl
+func NewCachedRepository(repo Repository, ttl time.Duration) *CachedRepository {
+	return &CachedRepository{repo: repo, ttl: ttl, entries: make(map[string]cacheEntry)}
+}
+
+// Invalidate drops every cached read
+func (r *CachedRepository) Invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = make(map[string]cacheEntry)
+	r.gen++
+}
+
+func (r *CachedRepository) List(ctx context.Context, params ListParams) ([]*Product, error) {
+	key, err := json.Marshal(params)
+	if err != nil {
+		return r.repo.List(ctx, params)
+	}
+	v, err := r.read(ctx, "list:"+string(key), func() (any, error) { return r.repo.List(ctx, params) })
+	if err != nil {
+		return nil, err
+	}
+	products := v.([]*Product)
+	if products == nil {
+		return nil, nil
+	}
+	out := make([]*Product, len(products))
+	for i, p := range products {
+		out[i] = copyProduct(p)
+	}
+	return out, nil
+}
+
+func (r *CachedRepository) GetByID(ctx context.Context, id string) (*Product, error) {
+	v, err := r.read(ctx, "product:"+id, func() (any, error) { return r.repo.GetByID(ctx, id) })
+	if err != nil {
+		return nil, err
+	}
+	return copyProduct(v.(*Product)), nil
+}
+
+func (r *CachedRepository) ModifierGroups(ctx context.Context, productID string) ([]ModifierGroup, error) {
+	v, err := r.read(ctx, "modifiers:"+productID, func() (any, error) { return r.repo.ModifierGroups(ctx, productID) })
+	if err != nil {
+		return nil, err
+	}
+	return copyGroups(v.([]ModifierGroup)), nil
+}
+
+func (r *CachedRepository) CategoryName(ctx context.Context, categoryID string) (string, error) {
+	v, err := r.read(ctx, "category:"+categoryID, func() (any, error) { return r.repo.CategoryName(ctx, categoryID) })
+	if err != nil {
+		return "", err
+	}
+	return v.(string), nil
+}
+
+func (r *CachedRepository) RestaurantExists(ctx context.Context, restaurantID string) error {
+	_, err := r.read(ctx, "restaurant:"+restaurantID, func() (any, error) { return true, r.repo.RestaurantExists(ctx, restaurantID) })
+	return err
+}
+
+func (r *CachedRepository) Categories(ctx context.Context) ([]CategoryRef, error) {
+	v, err := r.read(ctx, "categories", func() (any, error) { return r.repo.Categories(ctx) })
+	if err != nil {
+		return nil, err
+	}
+	return append([]CategoryRef(nil), v.([]CategoryRef)...), nil
+}
+
+func (r *CachedRepository) Create(ctx context.Context, p *Product) (*Product, error) {
+	defer r.Invalidate()
+	return r.repo.Create(ctx, p)
+}
+
+func (r *CachedRepository) Update(ctx context.Context, p *Product) (*Product, error) {
+	defer r.Invalidate()
+	return r.repo.Update(ctx, p)
+}
+
+func (r *CachedRepository) Archive(ctx context.Context, id string, version int) error {
+	defer r.Invalidate()
+	return r.repo.Archive(ctx, id, version)
+}
+
+func (r *CachedRepository) ReplaceModifierGroups(ctx context.Context, productID string, groups []ModifierGroup) error {
+	defer r.Invalidate()
+	return r.repo.ReplaceModifierGroups(ctx, productID, groups)
+}
+
+func (r *CachedRepository) ReplaceDietary(ctx context.Context, productID string, req *DietaryReq) error {
+	defer r.Invalidate()
+	return r.repo.ReplaceDietary(ctx, productID, req)
+}
+
+func (r *CachedRepository) ProductRestaurants(ctx context.Context, ids []string) (map[string]string, error) {
+	return r.repo.ProductRestaurants(ctx, ids)
+}
+
+func (r *CachedRepository) ApplyImport(ctx context.Context, plan *ImportPlan) error {
+	defer r.Invalidate()
+	return r.repo.ApplyImport(ctx, plan)
+}
+
+func (r *CachedRepository) AdjustStock(ctx context.Context, id string, req *StockReq) (*Product, error) {
+	defer r.Invalidate()
+	return r.repo.AdjustStock(ctx, id, req)
+}
+
+func (r *CachedRepository) PriceHistory(ctx context.Context, productID string) ([]PriceChange, error) {
+	v, err := r.read(ctx, "prices:"+productID, func() (any, error) { return r.repo.PriceHistory(ctx, productID) })
+	if err != nil {
+		return nil, err
+	}
+	return append([]PriceChange(nil), v.([]PriceChange)...), nil
+}
+
+func (r *CachedRepository) SchedulePrice(ctx context.Context, productID string, change *PriceChange) error {
+	defer r.Invalidate()
+	return r.repo.SchedulePrice(ctx, productID, change)
+}
+
+func (r *CachedRepository) DeleteScheduledPrice(ctx context.Context, productID, changeID string) error {
+	defer r.Invalidate()
+	return r.repo.DeleteScheduledPrice(ctx, productID, changeID)
+}
+
+// ApplyDuePrices only empties the cache when a price changed, it runs every minute
+func (r *CachedRepository) ApplyDuePrices(ctx context.Context) (int64, error) {
+	n, err := r.repo.ApplyDuePrices(ctx)
+	if n > 0 {
+		r.Invalidate()
+	}
+	return n, err
+}
+
+func (r *CachedRepository) PriceRules(ctx context.Context) ([]PriceRule, error) {
+	v, err := r.read(ctx, "price-rules", func() (any, error) { return r.repo.PriceRules(ctx) })
+	if err != nil {
+		return nil, err
+	}
+	rules := v.([]PriceRule)
+	out := make([]PriceRule, len(rules))
+	for i, rule := range rules {
+		out[i] = rule
+		out[i].Windows = append([]schedule.Window(nil), rule.Windows...)
+	}
+	return out, nil
+}
+
+func (r *CachedRepository) CreatePriceRule(ctx context.Context, rule *PriceRule) error {
+	defer r.Invalidate()
+	return r.repo.CreatePriceRule(ctx, rule)
+}
+
+func (r *CachedRepository) DeletePriceRule(ctx context.Context, id string) error {
+	defer r.Invalidate()
+	return r.repo.DeletePriceRule(ctx, id)
+}
+
+// read returns the cached value for key, loading it when missing or older
+// than the ttl. Errors are not cached.
+func (r *CachedRepository) read(ctx context.Context, key string, load func() (any, error)) (any, error) {
+	if ctx.Value(freshKey{}) != nil {
+		return load()
+	}
+
+	r.mu.Lock()
+	e, ok := r.entries[key]
+	gen := r.gen
+	r.mu.Unlock()
+	if ok && time.Since(e.loadedAt) < r.ttl {
+		return e.value, nil
+	}
+
+	v, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if gen == r.gen {
+		if len(r.entries) >= maxCacheEntries {
+			r.entries = make(map[string]cacheEntry)
+		}
+		r.entries[key] = cacheEntry{value: v, loadedAt: time.Now()}
+	}
+	return v, nil
+}
+
+// copyProduct returns a copy callers may change without touching the cache
+func copyProduct(p *Product) *Product {
+	if p == nil {
+		return nil
+	}
+	c := *p
+	if p.Stock != nil {
+		stock := *p.Stock
+		c.Stock = &stock
+	}
+	c.Allergens = copyStrings(p.Allergens)
+	c.Dietary = copyStrings(p.Dietary)
+	if p.Nutrition != nil {
+		n := *p.Nutrition
+		c.Nutrition = &n
+	}
+	c.ModifierGroups = copyGroups(p.ModifierGroups)
+	return &c
+}
+
+func copyGroups(groups []ModifierGroup) []ModifierGroup {
+	if groups == nil {
+		return nil
+	}
+	out := make([]ModifierGroup, len(groups))
+	for i, g := range groups {
+		out[i] = g
+		out[i].Modifiers = append([]Modifier(nil), g.Modifiers...)
+	}
+	return out
+}
+
+func copyStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	return append([]string{}, values...)
+}
@@ -0,0 +1,238 @@
+package product
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mohammadshabab/order-food-online/internal/schedule"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachedRepository_Reads(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	repo := NewCachedRepository(mockRepo, time.Minute)
+	ctx := context.Background()
+
+	t.Run("list is read once per params", func(t *testing.T) {
+		params := ListParams{Sort: SortName, Order: OrderAsc, Limit: 20}
+		mockRepo.EXPECT().List(ctx, params).Return([]*Product{{ID: "p1", Name: "Burger"}}, nil).Times(1)
+		mockRepo.EXPECT().List(ctx, ListParams{Sort: SortPrice, Order: OrderAsc, Limit: 20}).Return([]*Product{}, nil).Times(1)
+
+		for i := 0; i < 3; i++ {
+			products, err := repo.List(ctx, params)
+			assert.NoError(t, err)
+			assert.Equal(t, "Burger", products[0].Name)
+		}
+		_, err := repo.List(ctx, ListParams{Sort: SortPrice, Order: OrderAsc, Limit: 20})
+		assert.NoError(t, err)
+	})
+
+	t.Run("callers get copies", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(ctx, "p2").Return(&Product{ID: "p2", Name: "Pizza", Stock: intPtr(3), Allergens: []string{"gluten"}}, nil).Times(1)
+
+		p, err := repo.GetByID(ctx, "p2")
+		assert.NoError(t, err)
+		p.Name = "changed"
+		*p.Stock = 0
+		p.Allergens[0] = "milk"
+		p.ModifierGroups = []ModifierGroup{{Name: "Size"}}
+
+		p, err = repo.GetByID(ctx, "p2")
+		assert.NoError(t, err)
+		assert.Equal(t, &Product{ID: "p2", Name: "Pizza", Stock: intPtr(3), Allergens: []string{"gluten"}}, p)
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(ctx, "p9").Return(nil, ErrProductNotFound).Times(2)
+
+		_, err := repo.GetByID(ctx, "p9")
+		assert.Equal(t, ErrProductNotFound, err)
+		_, err = repo.GetByID(ctx, "p9")
+		assert.Equal(t, ErrProductNotFound, err)
+	})
+
+	t.Run("known restaurants are cached, unknown ones are not", func(t *testing.T) {
+		mockRepo.EXPECT().RestaurantExists(ctx, DefaultRestaurantID).Return(nil).Times(1)
+		mockRepo.EXPECT().RestaurantExists(ctx, "r9").Return(ErrUnknownRestaurant).Times(2)
+
+		for i := 0; i < 2; i++ {
+			assert.NoError(t, repo.RestaurantExists(ctx, DefaultRestaurantID))
+			assert.Equal(t, ErrUnknownRestaurant, repo.RestaurantExists(ctx, "r9"))
+		}
+	})
+
+	t.Run("fresh reads skip the cache", func(t *testing.T) {
+		mockRepo.EXPECT().ModifierGroups(ctx, "p1").Return([]ModifierGroup{{Name: "Size"}}, nil).Times(1)
+		mockRepo.EXPECT().ModifierGroups(fresh(ctx), "p1").Return([]ModifierGroup{{Name: "Extras"}}, nil).Times(1)
+
+		groups, err := repo.ModifierGroups(ctx, "p1")
+		assert.NoError(t, err)
+		assert.Equal(t, "Size", groups[0].Name)
+
+		groups, err = repo.ModifierGroups(fresh(ctx), "p1")
+		assert.NoError(t, err)
+		assert.Equal(t, "Extras", groups[0].Name)
+	})
+}
+
+func TestCachedRepository_Prices(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	repo := NewCachedRepository(mockRepo, time.Minute)
+	ctx := context.Background()
+
+	t.Run("history and rules are read once", func(t *testing.T) {
+		mockRepo.EXPECT().PriceHistory(ctx, "p1").Return([]PriceChange{{ID: "h1", Price: 9}}, nil).Times(1)
+		mockRepo.EXPECT().PriceRules(ctx).Return([]PriceRule{{ID: "r1", Windows: []schedule.Window{{Start: "17:00", End: "19:00"}}}}, nil).Times(1)
+
+		for i := 0; i < 3; i++ {
+			history, err := repo.PriceHistory(ctx, "p1")
+			assert.NoError(t, err)
+			history[0].Price = 0
+
+			rules, err := repo.PriceRules(ctx)
+			assert.NoError(t, err)
+			rules[0].Windows[0].Start = "00:00"
+		}
+
+		history, _ := repo.PriceHistory(ctx, "p1")
+		assert.Equal(t, 9.0, history[0].Price)
+		rules, _ := repo.PriceRules(ctx)
+		assert.Equal(t, "17:00", rules[0].Windows[0].Start)
+	})
+
+	t.Run("nothing due keeps the cache", func(t *testing.T) {
+		mockRepo.EXPECT().ApplyDuePrices(ctx).Return(int64(0), nil)
+
+		n, err := repo.ApplyDuePrices(ctx)
+		assert.NoError(t, err)
+		assert.Zero(t, n)
+		_, err = repo.PriceHistory(ctx, "p1")
+		assert.NoError(t, err)
+	})
+}
+
+func TestCachedRepository_TTL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	repo := NewCachedRepository(mockRepo, time.Millisecond)
+	ctx := context.Background()
+
+	mockRepo.EXPECT().CategoryName(ctx, "c1").Return("Pizza", nil)
+	mockRepo.EXPECT().CategoryName(ctx, "c1").Return("Pizzas", nil)
+
+	name, _ := repo.CategoryName(ctx, "c1")
+	assert.Equal(t, "Pizza", name)
+
+	time.Sleep(5 * time.Millisecond)
+	name, _ = repo.CategoryName(ctx, "c1")
+	assert.Equal(t, "Pizzas", name)
+}
+
+func TestCachedRepository_WritesInvalidate(t *testing.T) {
+	ctx := context.Background()
+	params := ListParams{Sort: SortName, Order: OrderAsc}
+
+	writes := map[string]func(r *CachedRepository, m *MockRepository){
+		"create": func(r *CachedRepository, m *MockRepository) {
+			m.EXPECT().Create(ctx, gomock.Any()).Return(&Product{ID: "p2"}, nil)
+			_, _ = r.Create(ctx, &Product{ID: "p2"})
+		},
+		"update": func(r *CachedRepository, m *MockRepository) {
+			m.EXPECT().Update(ctx, gomock.Any()).Return(&Product{ID: "p1"}, nil)
+			_, _ = r.Update(ctx, &Product{ID: "p1"})
+		},
+		"archive": func(r *CachedRepository, m *MockRepository) {
+			m.EXPECT().Archive(ctx, "p1", 1).Return(nil)
+			_ = r.Archive(ctx, "p1", 1)
+		},
+		"modifiers": func(r *CachedRepository, m *MockRepository) {
+			m.EXPECT().ReplaceModifierGroups(ctx, "p1", gomock.Any()).Return(nil)
+			_ = r.ReplaceModifierGroups(ctx, "p1", nil)
+		},
+		"dietary": func(r *CachedRepository, m *MockRepository) {
+			m.EXPECT().ReplaceDietary(ctx, "p1", gomock.Any()).Return(nil)
+			_ = r.ReplaceDietary(ctx, "p1", &DietaryReq{Allergens: []string{}})
+		},
+		"import": func(r *CachedRepository, m *MockRepository) {
+			m.EXPECT().ApplyImport(ctx, gomock.Any()).Return(nil)
+			_ = r.ApplyImport(ctx, &ImportPlan{})
+		},
+		"stock": func(r *CachedRepository, m *MockRepository) {
+			m.EXPECT().AdjustStock(ctx, "p1", gomock.Any()).Return(&Product{ID: "p1"}, nil)
+			_, _ = r.AdjustStock(ctx, "p1", &StockReq{Untracked: true})
+		},
+		"scheduled price": func(r *CachedRepository, m *MockRepository) {
+			m.EXPECT().SchedulePrice(ctx, "p1", gomock.Any()).Return(nil)
+			_ = r.SchedulePrice(ctx, "p1", &PriceChange{ID: "h1"})
+		},
+		"cancelled price": func(r *CachedRepository, m *MockRepository) {
+			m.EXPECT().DeleteScheduledPrice(ctx, "p1", "h1").Return(nil)
+			_ = r.DeleteScheduledPrice(ctx, "p1", "h1")
+		},
+		"due prices applied": func(r *CachedRepository, m *MockRepository) {
+			m.EXPECT().ApplyDuePrices(ctx).Return(int64(2), nil)
+			_, _ = r.ApplyDuePrices(ctx)
+		},
+		"price rule created": func(r *CachedRepository, m *MockRepository) {
+			m.EXPECT().CreatePriceRule(ctx, gomock.Any()).Return(nil)
+			_ = r.CreatePriceRule(ctx, &PriceRule{ID: "r1"})
+		},
+		"price rule deleted": func(r *CachedRepository, m *MockRepository) {
+			m.EXPECT().DeletePriceRule(ctx, "r1").Return(nil)
+			_ = r.DeletePriceRule(ctx, "r1")
+		},
+		"failed write": func(r *CachedRepository, m *MockRepository) {
+			m.EXPECT().Update(ctx, gomock.Any()).Return(nil, ErrVersionConflict)
+			_, _ = r.Update(ctx, &Product{ID: "p1"})
+		},
+	}
+
+	for name, write := range writes {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := NewMockRepository(ctrl)
+			repo := NewCachedRepository(mockRepo, time.Minute)
+
+			mockRepo.EXPECT().List(ctx, params).Return([]*Product{{ID: "p1"}}, nil).Times(2)
+			_, _ = repo.List(ctx, params)
+			_, _ = repo.List(ctx, params)
+
+			write(repo, mockRepo)
+
+			_, _ = repo.List(ctx, params)
+		})
+	}
+}
+
+func TestCachedRepository_ReadDuringWrite(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	repo := NewCachedRepository(mockRepo, time.Minute)
+	ctx := context.Background()
+
+	// a write lands while the read is in flight, what the read saw must not be kept
+	mockRepo.EXPECT().GetByID(ctx, "p1").DoAndReturn(func(context.Context, string) (*Product, error) {
+		repo.Invalidate()
+		return &Product{ID: "p1", Version: 1}, nil
+	})
+	mockRepo.EXPECT().GetByID(ctx, "p1").Return(&Product{ID: "p1", Version: 2}, nil)
+
+	p, _ := repo.GetByID(ctx, "p1")
+	assert.Equal(t, 1, p.Version)
+	p, _ = repo.GetByID(ctx, "p1")
+	assert.Equal(t, 2, p.Version)
+}
@@ -1,7 +1,369 @@
 package product
 
-import "github.com/mohammadshabab/order-food-online/internal/apperrors"
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/schedule"
+)
 
 var (
-	ErrProductNotFound = apperrors.NotFound("product not found", nil)
+	ErrProductNotFound    = apperrors.NotFound("product not found", nil).WithCode(apperrors.CodeProductNotFound)
+	ErrVersionConflict    = apperrors.Conflict("product was modified by another request, reload and retry", nil).WithCode(apperrors.CodeVersionConflict)
+	ErrStockUntracked     = apperrors.Conflict("product stock is not tracked, set a stock count first", nil).WithCode(apperrors.CodeStockUntracked)
+	ErrStockNegative      = apperrors.Conflict("stock cannot go below zero", nil).WithCode(apperrors.CodeStockNegative)
+	ErrUnknownCategory    = apperrors.BadRequest("categoryId does not match any category", nil).WithCode(apperrors.CodeUnknownCategory)
+	ErrUnknownRestaurant  = apperrors.BadRequest("restaurantId does not match any restaurant", nil).WithCode(apperrors.CodeUnknownRestaurant)
+	ErrRestaurantNotFound = apperrors.NotFound("restaurant not found", nil).WithCode(apperrors.CodeRestaurantNotFound)
+	ErrImportEmpty        = apperrors.BadRequest("import file has no products", nil).WithCode(apperrors.CodeImportEmpty)
+	ErrImportInvalid      = apperrors.Wrap(http.StatusUnprocessableEntity, "import file has invalid rows, nothing was imported", apperrors.LevelWarn, nil).WithCode(apperrors.CodeImportInvalid)
+
+	ErrPriceChangeNotFound = apperrors.NotFound("price change not found", nil).WithCode(apperrors.CodePriceChangeNotFound)
+	ErrPriceChangeApplied  = apperrors.Conflict("price change is already in effect, schedule a new change instead", nil).WithCode(apperrors.CodePriceChangeApplied)
+	ErrPriceRuleNotFound   = apperrors.NotFound("price rule not found", nil).WithCode(apperrors.CodePriceRuleNotFound)
+
+	// bases of the field errors of a request, the message is used when there are several
+	errInvalidProduct        = apperrors.BadRequest("invalid product request", nil).WithCode(apperrors.CodeValidationFailed)
+	errInvalidModifierGroups = apperrors.BadRequest("invalid modifier groups", nil).WithCode(apperrors.CodeValidationFailed)
+	errInvalidStock          = apperrors.BadRequest("invalid stock change", nil).WithCode(apperrors.CodeValidationFailed)
+	errInvalidDietary        = apperrors.BadRequest("invalid dietary information", nil).WithCode(apperrors.CodeValidationFailed)
+	errInvalidPriceChange    = apperrors.BadRequest("invalid price change", nil).WithCode(apperrors.CodeValidationFailed)
+	errInvalidPriceRule      = apperrors.BadRequest("invalid price rule", nil).WithCode(apperrors.CodeValidationFailed)
+)
+
+const (
+	maxNameLength        = 255
+	maxCategoryLength    = 100
+	maxDescriptionLength = 2000
+	maxSearchLength      = 200
+	maxModifierName      = 100
+	maxModifierGroups    = 20
+	maxModifiersPerGroup = 50
+	maxRuleName          = 100
+	maxRuleWindows       = 50
 )
+
+// ValidateCreate checks a POST body: every field is required
+func (r *ProductReq) ValidateCreate() *apperrors.AppError {
+	var v apperrors.Validation
+	r.validateFields(&v, true)
+	return v.Err(errInvalidProduct)
+}
+
+// ValidateReplace checks a PUT body: every field and the current version are required
+func (r *ProductReq) ValidateReplace() *apperrors.AppError {
+	var v apperrors.Validation
+	r.validateFields(&v, true)
+	r.validateVersion(&v)
+	return v.Err(errInvalidProduct)
+}
+
+// ValidatePatch checks a PATCH body: only the fields sent are validated, the version is required
+func (r *ProductReq) ValidatePatch() *apperrors.AppError {
+	if r.Name == nil && r.Price == nil && r.CategoryID == nil && r.Description == nil && r.Available == nil && r.RestaurantID == nil {
+		return apperrors.BadRequest("at least one of name, price, categoryId, description, available or restaurantId is required", nil)
+	}
+	var v apperrors.Validation
+	r.validateFields(&v, false)
+	r.validateVersion(&v)
+	return v.Err(errInvalidProduct)
+}
+
+func (r *ProductReq) validateFields(v *apperrors.Validation, requireAll bool) {
+	if r.Name == nil {
+		if requireAll {
+			v.Add(apperrors.Pointer("name"), apperrors.FieldRequired, "name is required")
+		}
+	} else if name := strings.TrimSpace(*r.Name); name == "" {
+		v.Add(apperrors.Pointer("name"), apperrors.FieldRequired, "name is required")
+	} else if len(name) > maxNameLength {
+		v.Add(apperrors.Pointer("name"), apperrors.FieldTooLong, "name must be at most 255 characters")
+	}
+
+	if r.Price == nil {
+		if requireAll {
+			v.Add(apperrors.Pointer("price"), apperrors.FieldRequired, "price is required")
+		}
+	} else if *r.Price <= 0 {
+		v.Add(apperrors.Pointer("price"), apperrors.FieldOutOfRange, "price must be greater than 0")
+	}
+
+	if r.CategoryID == nil {
+		if requireAll {
+			v.Add(apperrors.Pointer("categoryId"), apperrors.FieldRequired, "categoryId is required")
+		}
+	} else if _, err := uuid.Parse(*r.CategoryID); err != nil {
+		v.Add(apperrors.Pointer("categoryId"), apperrors.FieldInvalid, "categoryId must be a UUID")
+	}
+
+	// description and restaurant are always optional
+	if r.Description != nil && len(*r.Description) > maxDescriptionLength {
+		v.Add(apperrors.Pointer("description"), apperrors.FieldTooLong, "description must be at most 2000 characters")
+	}
+	if r.RestaurantID != nil {
+		if _, err := uuid.Parse(*r.RestaurantID); err != nil {
+			v.Add(apperrors.Pointer("restaurantId"), apperrors.FieldInvalid, "restaurantId must be a UUID")
+		}
+	}
+}
+
+func (r *ProductReq) validateVersion(v *apperrors.Validation) {
+	if r.Version == nil || *r.Version <= 0 {
+		v.Add(apperrors.Pointer("version"), apperrors.FieldRequired, "version is required")
+	}
+}
+
+// Validate checks the list query and fills in defaults
+func (p *ListParams) Validate() *apperrors.AppError {
+	if p.RestaurantID != "" {
+		if _, err := uuid.Parse(p.RestaurantID); err != nil {
+			return apperrors.BadRequest("restaurantId must be a UUID", err)
+		}
+	}
+
+	if p.Sort == "" {
+		p.Sort = SortName
+	}
+	if p.Sort != SortName && p.Sort != SortPrice {
+		return apperrors.BadRequest(fmt.Sprintf("sort must be one of %s, %s", SortName, SortPrice), nil)
+	}
+
+	if p.Order == "" {
+		p.Order = OrderAsc
+	}
+	if p.Order != OrderAsc && p.Order != OrderDesc {
+		return apperrors.BadRequest(fmt.Sprintf("order must be one of %s, %s", OrderAsc, OrderDesc), nil)
+	}
+
+	if p.Limit == 0 {
+		p.Limit = DefaultLimit
+	}
+	if p.Limit < 1 || p.Limit > MaxLimit {
+		return apperrors.BadRequest(fmt.Sprintf("limit must be between 1 and %d", MaxLimit), nil)
+	}
+
+	if p.MinPrice != nil && *p.MinPrice < 0 {
+		return apperrors.BadRequest("minPrice must not be negative", nil)
+	}
+	if p.MaxPrice != nil && *p.MaxPrice < 0 {
+		return apperrors.BadRequest("maxPrice must not be negative", nil)
+	}
+	if p.MinPrice != nil && p.MaxPrice != nil && *p.MinPrice > *p.MaxPrice {
+		return apperrors.BadRequest("minPrice must not be greater than maxPrice", nil)
+	}
+
+	if len(p.Category) > maxCategoryLength {
+		return apperrors.BadRequest("category must be at most 100 characters", nil)
+	}
+	if len(p.Name) > maxNameLength {
+		return apperrors.BadRequest("name must be at most 255 characters", nil)
+	}
+
+	for _, f := range []struct {
+		name    string
+		values  []string
+		allowed []string
+	}{{"excludeAllergens", p.ExcludeAllergens, Allergens}, {"dietary", p.Dietary, DietaryFlags}} {
+		for _, v := range f.values {
+			if !contains(f.allowed, v) {
+				return apperrors.BadRequest(fmt.Sprintf("%s must be a comma separated list of %s", f.name, strings.Join(f.allowed, ", ")), nil)
+			}
+		}
+	}
+
+	// A cursor is only valid for the sort it was issued for
+	if p.After != nil && (p.After.Sort != p.Sort || p.After.Order != p.Order) {
+		return apperrors.BadRequest("cursor does not match sort and order", nil)
+	}
+
+	return nil
+}
+
+// ValidateSearch checks the GET /product/search parameters. A zero limit means the default.
+func ValidateSearch(q string, limit int) *apperrors.AppError {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return apperrors.BadRequest("q is required", nil)
+	}
+	if len(q) > maxSearchLength {
+		return apperrors.BadRequest("q must be at most 200 characters", nil)
+	}
+	if limit < 0 || limit > MaxLimit {
+		return apperrors.BadRequest(fmt.Sprintf("limit must be between 1 and %d", MaxLimit), nil)
+	}
+	return nil
+}
+
+// Validate checks the modifier group rules: every group needs a name and at
+// least one modifier, and 0 <= minSelect <= maxSelect <= number of modifiers.
+func (r *ModifierGroupsReq) Validate() *apperrors.AppError {
+	if len(r.Groups) > maxModifierGroups {
+		return apperrors.BadRequest(fmt.Sprintf("at most %d modifier groups are allowed", maxModifierGroups), nil)
+	}
+
+	var v apperrors.Validation
+	for i, g := range r.Groups {
+		if name := strings.TrimSpace(g.Name); name == "" {
+			v.Add(apperrors.Pointer("groups", i, "name"), apperrors.FieldRequired, fmt.Sprintf("groups[%d].name is required", i))
+		} else if len(name) > maxModifierName {
+			v.Add(apperrors.Pointer("groups", i, "name"), apperrors.FieldTooLong, fmt.Sprintf("groups[%d].name must be at most 100 characters", i))
+		}
+		if len(g.Modifiers) == 0 || len(g.Modifiers) > maxModifiersPerGroup {
+			v.Add(apperrors.Pointer("groups", i, "modifiers"), apperrors.FieldOutOfRange, fmt.Sprintf("groups[%d] must have 1-%d modifiers", i, maxModifiersPerGroup))
+		} else if g.MaxSelect < 1 || g.MaxSelect < g.MinSelect || g.MaxSelect > len(g.Modifiers) {
+			v.Add(apperrors.Pointer("groups", i, "maxSelect"), apperrors.FieldOutOfRange, fmt.Sprintf("groups[%d].maxSelect must be between max(1, minSelect) and the number of modifiers", i))
+		}
+		if g.MinSelect < 0 {
+			v.Add(apperrors.Pointer("groups", i, "minSelect"), apperrors.FieldOutOfRange, fmt.Sprintf("groups[%d].minSelect must not be negative", i))
+		}
+
+		for j, m := range g.Modifiers {
+			if name := strings.TrimSpace(m.Name); name == "" {
+				v.Add(apperrors.Pointer("groups", i, "modifiers", j, "name"), apperrors.FieldRequired, fmt.Sprintf("groups[%d].modifiers[%d].name is required", i, j))
+			} else if len(name) > maxModifierName {
+				v.Add(apperrors.Pointer("groups", i, "modifiers", j, "name"), apperrors.FieldTooLong, fmt.Sprintf("groups[%d].modifiers[%d].name must be at most 100 characters", i, j))
+			}
+			if m.PriceDelta < 0 {
+				v.Add(apperrors.Pointer("groups", i, "modifiers", j, "priceDelta"), apperrors.FieldOutOfRange, fmt.Sprintf("groups[%d].modifiers[%d].priceDelta must not be negative", i, j))
+			}
+		}
+	}
+
+	return v.Err(errInvalidModifierGroups)
+}
+
+// Validate checks that exactly one stock operation is requested
+func (r *StockReq) Validate() *apperrors.AppError {
+	var v apperrors.Validation
+	n := 0
+	if r.Stock != nil {
+		n++
+		if *r.Stock < 0 {
+			v.Add(apperrors.Pointer("stock"), apperrors.FieldOutOfRange, "stock must not be negative")
+		}
+	}
+	if r.Delta != nil {
+		n++
+		if *r.Delta == 0 {
+			v.Add(apperrors.Pointer("delta"), apperrors.FieldOutOfRange, "delta must not be zero")
+		}
+	}
+	if r.Untracked {
+		n++
+	}
+	switch {
+	case n == 0:
+		// the pointer to the whole body, no single field is at fault
+		v.Add(apperrors.Pointer(), apperrors.FieldRequired, "exactly one of stock, delta or untracked is required")
+	case n > 1:
+		v.Add(apperrors.Pointer(), apperrors.FieldInvalid, "exactly one of stock, delta or untracked is required")
+	}
+	return v.Err(errInvalidStock)
+}
+
+// Validate checks the allergen and dietary codes, lowercasing them, and that
+// no nutrition value is negative. An empty nutrition object is dropped.
+func (r *DietaryReq) Validate() *apperrors.AppError {
+	var v apperrors.Validation
+	if r.Allergens == nil {
+		v.Add(apperrors.Pointer("allergens"), apperrors.FieldRequired, "allergens is required, send [] when the product has none")
+	}
+	r.Allergens = normalizeCodes(&v, "allergens", r.Allergens, Allergens)
+	r.Dietary = normalizeCodes(&v, "dietary", r.Dietary, DietaryFlags)
+
+	if n := r.Nutrition; n != nil {
+		empty := true
+		for _, nv := range n.values() {
+			if nv.value == nil {
+				continue
+			}
+			empty = false
+			if *nv.value < 0 {
+				v.Add(apperrors.Pointer("nutrition", nv.name), apperrors.FieldOutOfRange, fmt.Sprintf("nutrition.%s must not be negative", nv.name))
+			}
+		}
+		if empty {
+			r.Nutrition = nil
+		}
+	}
+	return v.Err(errInvalidDietary)
+}
+
+// normalizeCodes lowercases codes and checks each is in allowed and listed once
+func normalizeCodes(v *apperrors.Validation, field string, codes, allowed []string) []string {
+	out := make([]string, 0, len(codes))
+	for i, code := range codes {
+		code = strings.ToLower(strings.TrimSpace(code))
+		if !contains(allowed, code) {
+			v.Add(apperrors.Pointer(field, i), apperrors.FieldInvalid, fmt.Sprintf("%s[%d] must be one of %s", field, i, strings.Join(allowed, ", ")))
+			continue
+		}
+		if contains(out, code) {
+			v.Add(apperrors.Pointer(field, i), apperrors.FieldDuplicate, fmt.Sprintf("%s[%d] %q is listed twice", field, i, code))
+			continue
+		}
+		out = append(out, code)
+	}
+	return out
+}
+
+// Validate checks the price and that the change takes effect after now
+func (r *PriceChangeReq) Validate(now time.Time) *apperrors.AppError {
+	var v apperrors.Validation
+	if r.Price == nil {
+		v.Add(apperrors.Pointer("price"), apperrors.FieldRequired, "price is required")
+	} else if *r.Price <= 0 {
+		v.Add(apperrors.Pointer("price"), apperrors.FieldOutOfRange, "price must be greater than 0")
+	}
+	if r.EffectiveFrom == nil {
+		v.Add(apperrors.Pointer("effectiveFrom"), apperrors.FieldRequired, "effectiveFrom is required")
+	} else if !r.EffectiveFrom.After(now) {
+		v.Add(apperrors.Pointer("effectiveFrom"), apperrors.FieldOutOfRange, "effectiveFrom must be in the future, the current price is changed with PATCH /product/{productId}")
+	}
+	return v.Err(errInvalidPriceChange)
+}
+
+// Validate checks the rule target, the discount and the windows, trimming the name
+func (r *PriceRuleReq) Validate() *apperrors.AppError {
+	var v apperrors.Validation
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		v.Add(apperrors.Pointer("name"), apperrors.FieldRequired, "name is required")
+	} else if len(r.Name) > maxRuleName {
+		v.Add(apperrors.Pointer("name"), apperrors.FieldTooLong, fmt.Sprintf("name must be at most %d characters", maxRuleName))
+	}
+
+	if r.ProductID != "" && r.CategoryID != "" {
+		v.Add(apperrors.Pointer("categoryId"), apperrors.FieldInvalid, "set at most one of productId and categoryId")
+	}
+	if r.ProductID != "" {
+		if _, err := uuid.Parse(r.ProductID); err != nil {
+			v.Add(apperrors.Pointer("productId"), apperrors.FieldInvalid, "productId must be a UUID")
+		}
+	}
+	if r.CategoryID != "" {
+		if _, err := uuid.Parse(r.CategoryID); err != nil {
+			v.Add(apperrors.Pointer("categoryId"), apperrors.FieldInvalid, "categoryId must be a UUID")
+		}
+	}
+
+	if r.PercentOff <= 0 || r.PercentOff > 100 {
+		v.Add(apperrors.Pointer("percentOff"), apperrors.FieldOutOfRange, "percentOff must be greater than 0 and at most 100")
+	}
+
+	windows := r.Windows
+	switch {
+	case len(windows) == 0:
+		v.Add(apperrors.Pointer("windows"), apperrors.FieldRequired, "windows is required")
+	case len(windows) > maxRuleWindows:
+		v.Add(apperrors.Pointer("windows"), apperrors.FieldOutOfRange, fmt.Sprintf("at most %d windows are allowed", maxRuleWindows))
+		// one error for the list rather than one per window
+		windows = nil
+	}
+	schedule.ValidateWindows(&v, r.TimeZone, windows)
+	return v.Err(errInvalidPriceRule)
+}
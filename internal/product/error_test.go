@@ -0,0 +1,331 @@
+package product
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/schedule"
+	"github.com/stretchr/testify/assert"
+)
+
+func strPtr(s string) *string     { return &s }
+func floatPtr(f float64) *float64 { return &f }
+func intPtr(i int) *int           { return &i }
+
+const burgerCategoryID = "c1a2b3c4-d5e6-4f70-8a9b-0c1d2e3f4a5b"
+
+func TestProductReq_ValidateCreate(t *testing.T) {
+	valid := ProductReq{Name: strPtr("Burger"), Price: floatPtr(9.5), CategoryID: strPtr(burgerCategoryID)}
+
+	tests := []struct {
+		name    string
+		mutate  func(r *ProductReq)
+		message string
+	}{
+		{"valid", func(r *ProductReq) {}, ""},
+		{"missing name", func(r *ProductReq) { r.Name = nil }, "name is required"},
+		{"blank name", func(r *ProductReq) { r.Name = strPtr("  ") }, "name is required"},
+		{"long name", func(r *ProductReq) { r.Name = strPtr(strings.Repeat("a", 256)) }, "name must be at most 255 characters"},
+		{"missing price", func(r *ProductReq) { r.Price = nil }, "price is required"},
+		{"zero price", func(r *ProductReq) { r.Price = floatPtr(0) }, "price must be greater than 0"},
+		{"negative price", func(r *ProductReq) { r.Price = floatPtr(-1) }, "price must be greater than 0"},
+		{"missing category", func(r *ProductReq) { r.CategoryID = nil }, "categoryId is required"},
+		{"invalid category", func(r *ProductReq) { r.CategoryID = strPtr("burger") }, "categoryId must be a UUID"},
+		{"restaurant", func(r *ProductReq) { r.RestaurantID = strPtr(DefaultRestaurantID) }, ""},
+		{"invalid restaurant", func(r *ProductReq) { r.RestaurantID = strPtr("luigi") }, "restaurantId must be a UUID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid
+			tt.mutate(&req)
+			err := req.ValidateCreate()
+			if tt.message == "" {
+				assert.Nil(t, err)
+				return
+			}
+			assert.NotNil(t, err)
+			assert.Equal(t, 400, err.Code)
+			assert.Equal(t, tt.message, err.Message)
+		})
+	}
+}
+
+func TestProductReq_ValidateCreate_CollectsFieldErrors(t *testing.T) {
+	req := ProductReq{Name: strPtr(" "), CategoryID: strPtr("burger")}
+	err := req.ValidateCreate()
+	assert.NotNil(t, err)
+	assert.Equal(t, 400, err.Code)
+	assert.Equal(t, apperrors.CodeValidationFailed, err.ErrorCode)
+	assert.Equal(t, "invalid product request", err.Message)
+	assert.Equal(t, []apperrors.FieldError{
+		{Pointer: "/name", Code: apperrors.FieldRequired, Detail: "name is required"},
+		{Pointer: "/price", Code: apperrors.FieldRequired, Detail: "price is required"},
+		{Pointer: "/categoryId", Code: apperrors.FieldInvalid, Detail: "categoryId must be a UUID"},
+	}, err.Errors)
+}
+
+func TestProductReq_ValidateReplace(t *testing.T) {
+	req := ProductReq{Name: strPtr("Burger"), Price: floatPtr(9.5), CategoryID: strPtr(burgerCategoryID)}
+	err := req.ValidateReplace()
+	assert.NotNil(t, err)
+	assert.Equal(t, "version is required", err.Message)
+
+	req.Version = intPtr(2)
+	assert.Nil(t, req.ValidateReplace())
+}
+
+func TestProductReq_ValidatePatch(t *testing.T) {
+	t.Run("empty patch", func(t *testing.T) {
+		req := ProductReq{Version: intPtr(1)}
+		err := req.ValidatePatch()
+		assert.NotNil(t, err)
+		assert.Equal(t, 400, err.Code)
+	})
+
+	t.Run("only sent fields are validated", func(t *testing.T) {
+		req := ProductReq{Price: floatPtr(12), Version: intPtr(1)}
+		assert.Nil(t, req.ValidatePatch())
+
+		req.Price = floatPtr(0)
+		assert.Equal(t, "price must be greater than 0", req.ValidatePatch().Message)
+	})
+
+	t.Run("version required", func(t *testing.T) {
+		req := ProductReq{Price: floatPtr(12)}
+		assert.Equal(t, "version is required", req.ValidatePatch().Message)
+	})
+}
+
+func TestValidateSearch(t *testing.T) {
+	assert.Nil(t, ValidateSearch("chicken", 0))
+	assert.Nil(t, ValidateSearch("chicken", MaxLimit))
+	assert.Equal(t, "q is required", ValidateSearch("  ", 0).Message)
+	assert.Equal(t, "q must be at most 200 characters", ValidateSearch(strings.Repeat("a", 201), 0).Message)
+	assert.Equal(t, "limit must be between 1 and 100", ValidateSearch("chicken", 101).Message)
+}
+
+func TestProductReq_ValidateDescription(t *testing.T) {
+	req := ProductReq{Description: strPtr(strings.Repeat("a", 2001)), Version: intPtr(1)}
+	assert.Equal(t, "description must be at most 2000 characters", req.ValidatePatch().Message)
+
+	req.Description = strPtr("Grilled chicken breast")
+	assert.Nil(t, req.ValidatePatch())
+}
+
+func TestModifierGroupsReq_Validate(t *testing.T) {
+	valid := func() ModifierGroup {
+		return ModifierGroup{Name: "Toppings", MinSelect: 0, MaxSelect: 2, Modifiers: []Modifier{{Name: "Olives", PriceDelta: 1}, {Name: "Basil"}}}
+	}
+
+	req := ModifierGroupsReq{Groups: []ModifierGroup{valid()}}
+	assert.Nil(t, req.Validate())
+	assert.Nil(t, (&ModifierGroupsReq{}).Validate())
+
+	for _, tt := range []struct {
+		name    string
+		mutate  func(g *ModifierGroup)
+		message string
+	}{
+		{"empty name", func(g *ModifierGroup) { g.Name = " " }, "groups[0].name is required"},
+		{"long name", func(g *ModifierGroup) { g.Name = strings.Repeat("a", 101) }, "groups[0].name must be at most 100 characters"},
+		{"no modifiers", func(g *ModifierGroup) { g.Modifiers = nil }, "groups[0] must have 1-50 modifiers"},
+		{"negative min", func(g *ModifierGroup) { g.MinSelect = -1 }, "groups[0].minSelect must not be negative"},
+		{"max below min", func(g *ModifierGroup) { g.MinSelect = 2; g.MaxSelect = 1 }, "groups[0].maxSelect must be between max(1, minSelect) and the number of modifiers"},
+		{"max above modifiers", func(g *ModifierGroup) { g.MaxSelect = 3 }, "groups[0].maxSelect must be between max(1, minSelect) and the number of modifiers"},
+		{"modifier name", func(g *ModifierGroup) { g.Modifiers[1].Name = "" }, "groups[0].modifiers[1].name is required"},
+		{"long modifier name", func(g *ModifierGroup) { g.Modifiers[1].Name = strings.Repeat("a", 101) }, "groups[0].modifiers[1].name must be at most 100 characters"},
+		{"negative delta", func(g *ModifierGroup) { g.Modifiers[0].PriceDelta = -1 }, "groups[0].modifiers[0].priceDelta must not be negative"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			g := valid()
+			tt.mutate(&g)
+			err := (&ModifierGroupsReq{Groups: []ModifierGroup{g}}).Validate()
+			assert.NotNil(t, err)
+			assert.Equal(t, tt.message, err.Message)
+		})
+	}
+}
+
+func TestModifierGroupsReq_Validate_CollectsFieldErrors(t *testing.T) {
+	req := ModifierGroupsReq{Groups: []ModifierGroup{
+		{Name: "Size", MinSelect: 1, MaxSelect: 1, Modifiers: []Modifier{{Name: "Large"}}},
+		{Name: "", MinSelect: 0, MaxSelect: 1, Modifiers: []Modifier{{Name: "Olives"}, {Name: "", PriceDelta: -1}}},
+	}}
+	err := req.Validate()
+	assert.NotNil(t, err)
+	assert.Equal(t, "invalid modifier groups", err.Message)
+
+	pointers := make([]string, 0, len(err.Errors))
+	for _, fe := range err.Errors {
+		pointers = append(pointers, fe.Pointer)
+	}
+	assert.Equal(t, []string{"/groups/1/name", "/groups/1/modifiers/1/name", "/groups/1/modifiers/1/priceDelta"}, pointers)
+}
+
+func TestStockReq_Validate(t *testing.T) {
+	assert.Nil(t, (&StockReq{Stock: intPtr(0)}).Validate())
+	assert.Nil(t, (&StockReq{Delta: intPtr(-3)}).Validate())
+	assert.Nil(t, (&StockReq{Untracked: true}).Validate())
+
+	for _, tt := range []struct {
+		name    string
+		req     StockReq
+		message string
+	}{
+		{"empty", StockReq{}, "exactly one of stock, delta or untracked is required"},
+		{"two operations", StockReq{Stock: intPtr(1), Untracked: true}, "exactly one of stock, delta or untracked is required"},
+		{"negative stock", StockReq{Stock: intPtr(-1)}, "stock must not be negative"},
+		{"zero delta", StockReq{Delta: intPtr(0)}, "delta must not be zero"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.message, tt.req.Validate().Message)
+		})
+	}
+}
+
+func TestStockReq_Validate_Pointers(t *testing.T) {
+	err := (&StockReq{Stock: intPtr(-1)}).Validate()
+	assert.Equal(t, apperrors.CodeValidationFailed, err.ErrorCode)
+	assert.Equal(t, []apperrors.FieldError{
+		{Pointer: "/stock", Code: apperrors.FieldOutOfRange, Detail: "stock must not be negative"},
+	}, err.Errors)
+
+	err = (&StockReq{Stock: intPtr(-1), Delta: intPtr(0)}).Validate()
+	assert.Equal(t, "invalid stock change", err.Message)
+	assert.Equal(t, []string{"/stock", "/delta", ""}, fieldPointers(err))
+}
+
+func TestProductReq_ValidatePatch_Available(t *testing.T) {
+	available := false
+	req := ProductReq{Available: &available, Version: intPtr(1)}
+	assert.Nil(t, req.ValidatePatch())
+}
+
+func TestDietaryReq_Validate(t *testing.T) {
+	t.Run("codes are normalized", func(t *testing.T) {
+		req := DietaryReq{Allergens: []string{" Gluten", "nuts"}, Dietary: []string{"VEGAN"}, Nutrition: &Nutrition{Protein: floatPtr(0)}}
+		assert.Nil(t, req.Validate())
+		assert.Equal(t, []string{"gluten", "nuts"}, req.Allergens)
+		assert.Equal(t, []string{"vegan"}, req.Dietary)
+		assert.NotNil(t, req.Nutrition)
+	})
+
+	t.Run("no allergens and no flags", func(t *testing.T) {
+		req := DietaryReq{Allergens: []string{}, Nutrition: &Nutrition{}}
+		assert.Nil(t, req.Validate())
+		assert.Equal(t, []string{}, req.Dietary)
+		assert.Nil(t, req.Nutrition, "an empty nutrition object is dropped")
+	})
+
+	for _, tt := range []struct {
+		name    string
+		req     DietaryReq
+		message string
+	}{
+		{"allergens missing", DietaryReq{Dietary: []string{"vegan"}}, "allergens is required, send [] when the product has none"},
+		{"unknown allergen", DietaryReq{Allergens: []string{"milk", "wheat"}}, "allergens[1] must be one of celery, crustaceans, eggs, fish, gluten, lupin, milk, molluscs, mustard, nuts, peanuts, sesame, soya, sulphites"},
+		{"allergen twice", DietaryReq{Allergens: []string{"milk", "Milk"}}, `allergens[1] "milk" is listed twice`},
+		{"unknown flag", DietaryReq{Allergens: []string{}, Dietary: []string{"keto"}}, "dietary[0] must be one of halal, kosher, vegan, vegetarian"},
+		{"negative nutrition", DietaryReq{Allergens: []string{}, Nutrition: &Nutrition{SaturatedFat: floatPtr(-0.5)}}, "nutrition.saturatedFat must not be negative"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.message, tt.req.Validate().Message)
+		})
+	}
+}
+
+func TestDietaryReq_Validate_CollectsFieldErrors(t *testing.T) {
+	req := DietaryReq{Allergens: []string{"milk", "wheat", "MILK"}, Dietary: []string{"keto"}, Nutrition: &Nutrition{Salt: floatPtr(-1)}}
+	err := req.Validate()
+	assert.NotNil(t, err)
+	assert.Equal(t, "invalid dietary information", err.Message)
+	assert.Equal(t, []apperrors.FieldError{
+		{Pointer: "/allergens/1", Code: apperrors.FieldInvalid, Detail: "allergens[1] must be one of celery, crustaceans, eggs, fish, gluten, lupin, milk, molluscs, mustard, nuts, peanuts, sesame, soya, sulphites"},
+		{Pointer: "/allergens/2", Code: apperrors.FieldDuplicate, Detail: `allergens[2] "milk" is listed twice`},
+		{Pointer: "/dietary/0", Code: apperrors.FieldInvalid, Detail: "dietary[0] must be one of halal, kosher, vegan, vegetarian"},
+		{Pointer: "/nutrition/salt", Code: apperrors.FieldOutOfRange, Detail: "nutrition.salt must not be negative"},
+	}, err.Errors)
+}
+
+func TestPriceChangeReq_Validate(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+
+	assert.Nil(t, (&PriceChangeReq{Price: floatPtr(9.5), EffectiveFrom: &later}).Validate(now))
+
+	for _, tt := range []struct {
+		name    string
+		req     PriceChangeReq
+		message string
+	}{
+		{"price missing", PriceChangeReq{EffectiveFrom: &later}, "price is required"},
+		{"price not positive", PriceChangeReq{Price: floatPtr(0), EffectiveFrom: &later}, "price must be greater than 0"},
+		{"effectiveFrom missing", PriceChangeReq{Price: floatPtr(9.5)}, "effectiveFrom is required"},
+		{"effectiveFrom not in the future", PriceChangeReq{Price: floatPtr(9.5), EffectiveFrom: &now},
+			"effectiveFrom must be in the future, the current price is changed with PATCH /product/{productId}"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.message, tt.req.Validate(now).Message)
+		})
+	}
+
+	t.Run("collects field errors", func(t *testing.T) {
+		err := (&PriceChangeReq{Price: floatPtr(-1)}).Validate(now)
+		assert.Equal(t, "invalid price change", err.Message)
+		assert.Equal(t, []string{"/price", "/effectiveFrom"}, fieldPointers(err))
+	})
+}
+
+func TestPriceRuleReq_Validate(t *testing.T) {
+	happyHour := []schedule.Window{{Days: []string{"fri"}, Start: "17:00", End: "19:00"}}
+
+	t.Run("valid rule", func(t *testing.T) {
+		req := PriceRuleReq{Name: " Happy hour ", CategoryID: burgerCategoryID, PercentOff: 20, TimeZone: "Europe/Berlin", Windows: happyHour}
+		assert.Nil(t, req.Validate())
+		assert.Equal(t, "Happy hour", req.Name)
+	})
+
+	for _, tt := range []struct {
+		name    string
+		req     PriceRuleReq
+		message string
+	}{
+		{"name missing", PriceRuleReq{Name: " ", PercentOff: 20, Windows: happyHour}, "name is required"},
+		{"name too long", PriceRuleReq{Name: strings.Repeat("a", 101), PercentOff: 20, Windows: happyHour}, "name must be at most 100 characters"},
+		{"both targets", PriceRuleReq{Name: "x", ProductID: burgerCategoryID, CategoryID: burgerCategoryID, PercentOff: 20, Windows: happyHour},
+			"set at most one of productId and categoryId"},
+		{"bad product id", PriceRuleReq{Name: "x", ProductID: "p1", PercentOff: 20, Windows: happyHour}, "productId must be a UUID"},
+		{"bad category id", PriceRuleReq{Name: "x", CategoryID: "c1", PercentOff: 20, Windows: happyHour}, "categoryId must be a UUID"},
+		{"no discount", PriceRuleReq{Name: "x", Windows: happyHour}, "percentOff must be greater than 0 and at most 100"},
+		{"more than everything", PriceRuleReq{Name: "x", PercentOff: 120, Windows: happyHour}, "percentOff must be greater than 0 and at most 100"},
+		{"no windows", PriceRuleReq{Name: "x", PercentOff: 20}, "windows is required"},
+		{"bad time zone", PriceRuleReq{Name: "x", PercentOff: 20, TimeZone: "Mars/Olympus", Windows: happyHour}, `unknown time zone "Mars/Olympus"`},
+		{"bad window", PriceRuleReq{Name: "x", PercentOff: 20, Windows: []schedule.Window{{Start: "17:00", End: "7pm"}}},
+			`windows[0].end: "7pm" is not a HH:MM time`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.message, tt.req.Validate().Message)
+		})
+	}
+}
+
+func TestPriceRuleReq_Validate_CollectsFieldErrors(t *testing.T) {
+	req := PriceRuleReq{Name: "Happy hour", ProductID: "p1", PercentOff: 120, TimeZone: "Mars/Olympus",
+		Windows: []schedule.Window{{Days: []string{"fri"}, Start: "17:00", End: "7pm"}}}
+	err := req.Validate()
+	assert.NotNil(t, err)
+	assert.Equal(t, apperrors.CodeValidationFailed, err.ErrorCode)
+	assert.Equal(t, "invalid price rule", err.Message)
+	assert.Equal(t, []string{"/productId", "/percentOff", "/timeZone", "/windows/0/end"}, fieldPointers(err))
+}
+
+func fieldPointers(err *apperrors.AppError) []string {
+	pointers := make([]string, 0, len(err.Errors))
+	for _, fe := range err.Errors {
+		pointers = append(pointers, fe.Pointer)
+	}
+	return pointers
+}
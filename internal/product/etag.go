@@ -0,0 +1,67 @@
+package product
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	headerETag        = "ETag"
+	headerIfNoneMatch = "If-None-Match"
+
+	// maxTrackedLists bounds the listings Last-Modified is remembered for
+	maxTrackedLists = 1000
+)
+
+// etagOf is a strong ETag for a response body. It only depends on the body,
+// so every instance hands out the same tag for the same catalog.
+func etagOf(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match header lists etag. Weak tags
+// compare by their value, as If-None-Match requires.
+func etagMatches(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// listVersions remembers, per listing URL, the last ETag served and since
+// when, which becomes the Last-Modified of that listing
+type listVersions struct {
+	mu   sync.Mutex
+	seen map[string]listVersion
+}
+
+type listVersion struct {
+	etag  string
+	since time.Time
+}
+
+func newListVersions() *listVersions {
+	return &listVersions{seen: make(map[string]listVersion)}
+}
+
+// modifiedAt returns when the listing at key first had etag on this instance
+func (l *listVersions) modifiedAt(key, etag string, now time.Time) time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if v, ok := l.seen[key]; ok && v.etag == etag {
+		return v.since
+	}
+	if len(l.seen) >= maxTrackedLists {
+		l.seen = make(map[string]listVersion)
+	}
+	l.seen[key] = listVersion{etag: etag, since: now}
+	return now
+}
@@ -0,0 +1,37 @@
+package product
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEtagOf(t *testing.T) {
+	a := etagOf([]byte(`{"items":[]}`))
+	assert.Equal(t, a, etagOf([]byte(`{"items":[]}`)))
+	assert.NotEqual(t, a, etagOf([]byte(`{"items":[{}]}`)))
+	assert.Len(t, a, 34)
+}
+
+func TestEtagMatches(t *testing.T) {
+	etag := `"abc"`
+
+	assert.True(t, etagMatches(`"abc"`, etag))
+	assert.True(t, etagMatches(`"x", W/"abc"`, etag))
+	assert.True(t, etagMatches(`*`, etag))
+	assert.False(t, etagMatches(``, etag))
+	assert.False(t, etagMatches(`"abcd"`, etag))
+	assert.False(t, etagMatches(`abc`, etag))
+}
+
+func TestListVersions(t *testing.T) {
+	l := newListVersions()
+	t1 := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Minute)
+
+	assert.Equal(t, t1, l.modifiedAt("/product", `"a"`, t1))
+	assert.Equal(t, t1, l.modifiedAt("/product", `"a"`, t2), "unchanged listing keeps its time")
+	assert.Equal(t, t2, l.modifiedAt("/product", `"b"`, t2), "changed listing gets a new time")
+	assert.Equal(t, t2, l.modifiedAt("/product?limit=5", `"a"`, t2), "listings are tracked apart")
+}
@@ -0,0 +1,51 @@
+package product
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// writeExport writes products in the import file format, so an export can be
+// edited and imported again
+func writeExport(w io.Writer, format string, products []*Product, slugs map[string]string) error {
+	rows := make([]*ImportRow, 0, len(products))
+	for _, p := range products {
+		available := p.Available
+		rows = append(rows, &ImportRow{
+			ID:          p.ID,
+			Name:        p.Name,
+			Price:       p.Price,
+			Category:    slugs[p.CategoryID],
+			Description: p.Description,
+			Available:   &available,
+		})
+	}
+
+	if format == FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(importColumns); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		rec := []string{
+			r.ID,
+			r.Name,
+			strconv.FormatFloat(r.Price, 'f', -1, 64),
+			r.Category,
+			r.Description,
+			strconv.FormatBool(*r.Available),
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
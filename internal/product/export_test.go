@@ -0,0 +1,52 @@
+package product
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteExport(t *testing.T) {
+	products := []*Product{
+		{ID: existingID, Name: "Diavola, hot", Price: 11.5, CategoryID: pizzaCategoryID, Description: "Salami", Available: true},
+		{ID: goneID, Name: "Calzone", Price: 12, CategoryID: pizzaCategoryID, Available: false},
+	}
+	slugs := map[string]string{pizzaCategoryID: "pizza"}
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeExport(&buf, FormatCSV, products, slugs))
+		assert.Equal(t, "id,name,price,category,description,available\n"+
+			existingID+",\"Diavola, hot\",11.5,pizza,Salami,true\n"+
+			goneID+",Calzone,12,pizza,,false\n", buf.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeExport(&buf, FormatJSON, products[1:], slugs))
+		assert.JSONEq(t, `[{"id":"`+goneID+`","name":"Calzone","price":12,"category":"pizza","available":false}]`, buf.String())
+	})
+
+	t.Run("an export imports again unchanged", func(t *testing.T) {
+		for _, format := range []string{FormatCSV, FormatJSON} {
+			var buf bytes.Buffer
+			require.NoError(t, writeExport(&buf, format, products, slugs))
+
+			rows, errs, err := parseImport(format, &buf)
+			require.NoError(t, err)
+			assert.Empty(t, errs)
+
+			current := []*Product{
+				{ID: existingID, Name: "Diavola, hot", Price: 11.5, CategoryID: pizzaCategoryID, Category: "Pizza", Description: "Salami", Available: true},
+				{ID: goneID, Name: "Calzone", Price: 12, CategoryID: pizzaCategoryID, Category: "Pizza", Available: false},
+			}
+			plan, res := planImport(rows, current, importCategories, DefaultRestaurantID)
+			assert.Empty(t, plan.Create, format)
+			assert.Empty(t, plan.Update, format)
+			assert.Empty(t, plan.Archive, format)
+			assert.Equal(t, 2, res.Unchanged, format)
+		}
+	})
+}
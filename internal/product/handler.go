@@ -1,7 +1,13 @@
 package product
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
@@ -10,22 +16,87 @@ import (
 )
 
 type Handler struct {
-	svc Service
+	svc   Service
+	lists *listVersions
 }
 
 func NewHandler(svc Service) *Handler {
-	return &Handler{svc: svc}
+	return &Handler{svc: svc, lists: newListVersions()}
 }
 
 func (h *Handler) ListProducts(c echo.Context) error {
+	return h.listProducts(c, "")
+}
+
+// ListRestaurantProducts serves GET /restaurant/{restaurantId}/product with
+// the query parameters of GET /product
+func (h *Handler) ListRestaurantProducts(c echo.Context) error {
+	id := c.Param("restaurantId")
+	if _, err := uuid.Parse(id); err != nil {
+		return apperrors.BadRequest("invalid ID supplied", err)
+	}
+	return h.listProducts(c, id)
+}
+
+func (h *Handler) listProducts(c echo.Context, restaurantID string) error {
 	ctx := c.Request().Context()
-	logger.Info(ctx, "list products called")
+	logger.Info(ctx, "list products called", "restaurantId", restaurantID)
+
+	params, appErr := parseListParams(c)
+	if appErr != nil {
+		return appErr
+	}
+	params.RestaurantID = restaurantID
 
-	res, err := h.svc.ListProducts(ctx)
+	if appErr := params.Validate(); appErr != nil {
+		return appErr
+	}
+
+	res, err := h.svc.ListProducts(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(res)
 	if err != nil {
-		appErr := apperrors.Internal("failed to list products", err)
-		logger.Error(ctx, appErr.Message, "error", appErr.Error())
-		return c.JSON(appErr.Code, appErr)
+		return apperrors.Internal("failed to encode products", err)
+	}
+
+	// clients polling the menu revalidate with If-None-Match and get a 304
+	// until the listing changes
+	etag := etagOf(body)
+	modified := h.lists.modifiedAt(c.Request().URL.RequestURI(), etag, time.Now())
+	header := c.Response().Header()
+	header.Set(headerETag, etag)
+	header.Set(echo.HeaderLastModified, modified.UTC().Format(http.TimeFormat))
+	if etagMatches(c.Request().Header.Get(headerIfNoneMatch), etag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	return c.JSONBlob(http.StatusOK, body)
+}
+
+// SearchProducts serves GET /product/search?q=&limit=
+func (h *Handler) SearchProducts(c echo.Context) error {
+	ctx := c.Request().Context()
+	q := c.QueryParam("q")
+	logger.Info(ctx, "search products called", "q", q)
+
+	var limit int
+	if raw := c.QueryParam("limit"); raw != "" {
+		var err error
+		if limit, err = strconv.Atoi(raw); err != nil || limit < 1 {
+			return apperrors.BadRequest(fmt.Sprintf("limit must be between 1 and %d", MaxLimit), err)
+		}
+	}
+
+	if appErr := ValidateSearch(q, limit); appErr != nil {
+		return appErr
+	}
+
+	res, err := h.svc.SearchProducts(ctx, q, limit)
+	if err != nil {
+		return err
 	}
 
 	return c.JSON(http.StatusOK, res)
@@ -33,30 +104,477 @@ func (h *Handler) ListProducts(c echo.Context) error {
 
 func (h *Handler) GetProduct(c echo.Context) error {
 	ctx := c.Request().Context()
+
+	id, appErr := productID(c)
+	if appErr != nil {
+		return appErr
+	}
+
+	logger.Info(ctx, "get product called", "id", id)
+
+	res, err := h.svc.GetProduct(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) CreateProduct(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req ProductReq
+	if err := c.Bind(&req); err != nil {
+		return apperrors.BadRequest("invalid product request", err)
+	}
+
+	if appErr := req.ValidateCreate(); appErr != nil {
+		return appErr
+	}
+
+	res, err := h.svc.CreateProduct(ctx, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, res)
+}
+
+func (h *Handler) UpdateProduct(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, appErr := productID(c)
+	if appErr != nil {
+		return appErr
+	}
+
+	var req ProductReq
+	if err := c.Bind(&req); err != nil {
+		return apperrors.BadRequest("invalid product request", err)
+	}
+
+	if appErr := req.ValidateReplace(); appErr != nil {
+		return appErr
+	}
+
+	res, err := h.svc.ReplaceProduct(ctx, id, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) PatchProduct(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, appErr := productID(c)
+	if appErr != nil {
+		return appErr
+	}
+
+	var req ProductReq
+	if err := c.Bind(&req); err != nil {
+		return apperrors.BadRequest("invalid product request", err)
+	}
+
+	if appErr := req.ValidatePatch(); appErr != nil {
+		return appErr
+	}
+
+	res, err := h.svc.PatchProduct(ctx, id, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// SetModifierGroups serves PUT /product/{productId}/modifiers
+func (h *Handler) SetModifierGroups(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, appErr := productID(c)
+	if appErr != nil {
+		return appErr
+	}
+
+	var req ModifierGroupsReq
+	if err := c.Bind(&req); err != nil {
+		return apperrors.BadRequest("invalid modifier groups request", err)
+	}
+
+	if appErr := req.Validate(); appErr != nil {
+		return appErr
+	}
+
+	res, err := h.svc.SetModifierGroups(ctx, id, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// SetDietary serves PUT /product/{productId}/dietary
+func (h *Handler) SetDietary(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, appErr := productID(c)
+	if appErr != nil {
+		return appErr
+	}
+
+	var req DietaryReq
+	if err := c.Bind(&req); err != nil {
+		return apperrors.BadRequest("invalid dietary request", err)
+	}
+
+	if appErr := req.Validate(); appErr != nil {
+		return appErr
+	}
+
+	res, err := h.svc.SetDietary(ctx, id, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// AdjustStock serves POST /product/{productId}/stock
+func (h *Handler) AdjustStock(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, appErr := productID(c)
+	if appErr != nil {
+		return appErr
+	}
+
+	var req StockReq
+	if err := c.Bind(&req); err != nil {
+		return apperrors.BadRequest("invalid stock request", err)
+	}
+
+	if appErr := req.Validate(); appErr != nil {
+		return appErr
+	}
+
+	res, err := h.svc.AdjustStock(ctx, id, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// maxImportBytes caps the size of an uploaded import file
+const maxImportBytes = 10 << 20
+
+// ImportProducts serves POST /product/import. The format comes from ?format=
+// or the Content-Type; ?dryRun=true only reports what would change and
+// ?restaurantId= picks the restaurant whose catalog the file holds.
+func (h *Handler) ImportProducts(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	format := importFormat(c)
+	dryRun := false
+	if raw := c.QueryParam("dryRun"); raw != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(raw); err != nil {
+			return apperrors.BadRequest("dryRun must be true or false", err)
+		}
+	}
+
+	body := http.MaxBytesReader(c.Response(), c.Request().Body, maxImportBytes)
+	res, err := h.svc.ImportProducts(ctx, c.QueryParam("restaurantId"), format, body, dryRun)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// ExportProducts serves GET /product/export?format=csv|json (default json),
+// optionally for one ?restaurantId=, the default restaurant otherwise
+func (h *Handler) ExportProducts(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	format := strings.ToLower(c.QueryParam("format"))
+	if format == "" {
+		format = FormatJSON
+	}
+
+	var buf bytes.Buffer
+	if err := h.svc.ExportProducts(ctx, c.QueryParam("restaurantId"), format, &buf); err != nil {
+		return err
+	}
+
+	contentType := echo.MIMEApplicationJSONCharsetUTF8
+	if format == FormatCSV {
+		contentType = "text/csv; charset=utf-8"
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="products.%s"`, format))
+	return c.Blob(http.StatusOK, contentType, buf.Bytes())
+}
+
+func importFormat(c echo.Context) string {
+	if format := c.QueryParam("format"); format != "" {
+		return strings.ToLower(format)
+	}
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), "text/csv") {
+		return FormatCSV
+	}
+	return FormatJSON
+}
+
+// DeleteProduct archives a product. The current version is passed as ?version=
+func (h *Handler) DeleteProduct(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, appErr := productID(c)
+	if appErr != nil {
+		return appErr
+	}
+
+	version, err := strconv.Atoi(c.QueryParam("version"))
+	if err != nil || version <= 0 {
+		return apperrors.BadRequest("version query parameter is required", err)
+	}
+
+	if err := h.svc.ArchiveProduct(ctx, id, version); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// productID reads and validates the productId path parameter
+func productID(c echo.Context) (string, *apperrors.AppError) {
+	ctx := c.Request().Context()
 	id := c.Param("productId")
 
 	// OpenAPI validation: check if ID is provided
 	if id == "" {
 		appErr := apperrors.BadRequest("invalid ID supplied", nil)
 		logger.Warn(ctx, appErr.Message)
-		return c.JSON(appErr.Code, appErr)
+		return "", appErr
 	}
 
 	// Validate UUID format (OpenAPI requirement)
 	if _, err := uuid.Parse(id); err != nil {
 		appErr := apperrors.BadRequest("invalid ID supplied", err)
 		logger.Warn(ctx, appErr.Message, "id", id)
-		return c.JSON(appErr.Code, appErr)
+		return "", appErr
 	}
 
-	logger.Info(ctx, "get product called", "id", id)
+	return id, nil
+}
 
-	res, err := h.svc.GetProduct(ctx, id)
+// parseListParams reads the GET /product query parameters
+func parseListParams(c echo.Context) (ListParams, *apperrors.AppError) {
+	params := ListParams{
+		Category:         strings.TrimSpace(c.QueryParam("category")),
+		Name:             strings.TrimSpace(c.QueryParam("name")),
+		Sort:             c.QueryParam("sort"),
+		Order:            strings.ToLower(c.QueryParam("order")),
+		ExcludeAllergens: splitQueryList(c.QueryParam("excludeAllergens")),
+		Dietary:          splitQueryList(c.QueryParam("dietary")),
+	}
+
+	for _, f := range []struct {
+		name string
+		dst  **float64
+	}{{"minPrice", &params.MinPrice}, {"maxPrice", &params.MaxPrice}} {
+		raw := c.QueryParam(f.name)
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return params, apperrors.BadRequest(fmt.Sprintf("%s must be a number", f.name), err)
+		}
+		*f.dst = &v
+	}
+
+	if raw := c.QueryParam("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return params, apperrors.BadRequest(fmt.Sprintf("limit must be between 1 and %d", MaxLimit), err)
+		}
+		params.Limit = limit
+	}
+
+	if raw := c.QueryParam("availableNow"); raw != "" {
+		availableNow, err := strconv.ParseBool(raw)
+		if err != nil {
+			return params, apperrors.BadRequest("availableNow must be true or false", err)
+		}
+		params.AvailableNow = availableNow
+	}
+
+	if raw := c.QueryParam("cursor"); raw != "" {
+		cursor, err := DecodeCursor(raw)
+		if err != nil {
+			return params, apperrors.BadRequest("invalid cursor", err)
+		}
+		params.After = cursor
+	}
+
+	return params, nil
+}
+
+// splitQueryList splits a comma separated query value, lowercasing the entries
+func splitQueryList(raw string) []string {
+	var values []string
+	for _, v := range strings.Split(raw, ",") {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// GetPrice serves GET /product/{productId}/price, the price at ?at= (RFC 3339, default now)
+func (h *Handler) GetPrice(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, appErr := productID(c)
+	if appErr != nil {
+		return appErr
+	}
+
+	at := time.Now()
+	if raw := c.QueryParam("at"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return apperrors.BadRequest("at must be an RFC 3339 timestamp", err)
+		}
+		at = t.UTC()
+	}
+
+	p, err := h.svc.GetProduct(ctx, id)
 	if err != nil {
-		appErr := apperrors.NotFound("product not found", err)
-		logger.Warn(ctx, appErr.Message, "id", id)
-		return c.JSON(appErr.Code, appErr)
+		return err
+	}
+
+	res, err := h.svc.EffectivePrice(ctx, p, at)
+	if err != nil {
+		return err
 	}
 
 	return c.JSON(http.StatusOK, res)
 }
+
+// ListPrices serves GET /product/{productId}/prices
+func (h *Handler) ListPrices(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, appErr := productID(c)
+	if appErr != nil {
+		return appErr
+	}
+
+	res, err := h.svc.PriceHistory(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{"items": res})
+}
+
+// SchedulePrice serves POST /product/{productId}/prices
+func (h *Handler) SchedulePrice(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, appErr := productID(c)
+	if appErr != nil {
+		return appErr
+	}
+
+	var req PriceChangeReq
+	if err := c.Bind(&req); err != nil {
+		return apperrors.BadRequest("invalid price change request", err)
+	}
+
+	if appErr := req.Validate(time.Now()); appErr != nil {
+		return appErr
+	}
+
+	res, err := h.svc.SchedulePrice(ctx, id, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, res)
+}
+
+// CancelPriceChange serves DELETE /product/{productId}/prices/{priceId}
+func (h *Handler) CancelPriceChange(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, appErr := productID(c)
+	if appErr != nil {
+		return appErr
+	}
+
+	changeID := c.Param("priceId")
+	if _, err := uuid.Parse(changeID); err != nil {
+		return apperrors.BadRequest("invalid price change ID supplied", err)
+	}
+
+	if err := h.svc.CancelPriceChange(ctx, id, changeID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListPriceRules serves GET /price-rule
+func (h *Handler) ListPriceRules(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	res, err := h.svc.ListPriceRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{"items": res})
+}
+
+// CreatePriceRule serves POST /price-rule
+func (h *Handler) CreatePriceRule(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req PriceRuleReq
+	if err := c.Bind(&req); err != nil {
+		return apperrors.BadRequest("invalid price rule request", err)
+	}
+
+	if appErr := req.Validate(); appErr != nil {
+		return appErr
+	}
+
+	res, err := h.svc.CreatePriceRule(ctx, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, res)
+}
+
+// DeletePriceRule serves DELETE /price-rule/{ruleId}
+func (h *Handler) DeletePriceRule(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id := c.Param("ruleId")
+	if _, err := uuid.Parse(id); err != nil {
+		return apperrors.BadRequest("invalid price rule ID supplied", err)
+	}
+
+	if err := h.svc.DeletePriceRule(ctx, id); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
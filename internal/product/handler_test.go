@@ -1,15 +1,21 @@
 package product
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
 	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestHandler_ListProducts(t *testing.T) {
@@ -20,9 +26,9 @@ func TestHandler_ListProducts(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockSvc := NewMockService(ctrl)
-		expected := []*Product{{ID: "p1", Name: "Burger"}}
+		expected := &ProductPage{Items: []*Product{{ID: "p1", Name: "Burger"}}, Pagination: Pagination{Limit: DefaultLimit}}
 
-		mockSvc.EXPECT().ListProducts(gomock.Any()).Return(expected, nil)
+		mockSvc.EXPECT().ListProducts(gomock.Any(), gomock.Any()).Return(expected, nil)
 
 		h := NewHandler(mockSvc)
 
@@ -31,18 +37,15 @@ func TestHandler_ListProducts(t *testing.T) {
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
 
-		err := h.ListProducts(c)
-		if err != nil {
-			t.Fatalf("handler returned err: %v", err)
-		}
+		apperrors.Handle(h.ListProducts, c)
 
 		if rec.Code != http.StatusOK {
 			t.Fatalf("expected 200, got %d", rec.Code)
 		}
 
-		var resp []*Product
+		var resp ProductPage
 		json.Unmarshal(rec.Body.Bytes(), &resp)
-		if resp[0].ID != "p1" {
+		if resp.Items[0].ID != "p1" || resp.Pagination.Limit != DefaultLimit {
 			t.Errorf("unexpected response: %+v", resp)
 		}
 	})
@@ -52,7 +55,7 @@ func TestHandler_ListProducts(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockSvc := NewMockService(ctrl)
-		mockSvc.EXPECT().ListProducts(gomock.Any()).Return(nil, errors.New("db failed"))
+		mockSvc.EXPECT().ListProducts(gomock.Any(), gomock.Any()).Return(nil, errors.New("db failed"))
 
 		h := NewHandler(mockSvc)
 
@@ -61,10 +64,7 @@ func TestHandler_ListProducts(t *testing.T) {
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
 
-		err := h.ListProducts(c)
-		if err != nil {
-			t.Fatalf("handler returned err: %v", err)
-		}
+		apperrors.Handle(h.ListProducts, c)
 
 		if rec.Code != http.StatusInternalServerError {
 			t.Fatalf("expected 500, got %d", rec.Code)
@@ -72,6 +72,106 @@ func TestHandler_ListProducts(t *testing.T) {
 	})
 }
 
+func TestHandler_ListRestaurantProducts(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+
+	list := func(h *Handler, id string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/restaurant/"+id+"/product?sort=price", nil), rec)
+		c.SetParamNames("restaurantId")
+		c.SetParamValues(id)
+		apperrors.Handle(h.ListRestaurantProducts, c)
+		return rec
+	}
+
+	t.Run("filters by the restaurant in the path", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().ListProducts(gomock.Any(), ListParams{RestaurantID: restaurantID, Sort: SortPrice, Order: OrderAsc, Limit: DefaultLimit}).
+			Return(&ProductPage{Items: []*Product{}}, nil)
+
+		rec := list(NewHandler(mockSvc), restaurantID)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("unknown restaurant", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().ListProducts(gomock.Any(), gomock.Any()).Return(nil, ErrRestaurantNotFound)
+
+		rec := list(NewHandler(mockSvc), restaurantID)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		rec := list(NewHandler(NewMockService(ctrl)), "luigi")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestHandler_ListProducts_QueryParams(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+
+	t.Run("filters are passed to the service", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+
+		cursor := Cursor{Sort: SortPrice, Order: OrderDesc, Price: 150, ID: "p1"}
+		mockSvc.EXPECT().
+			ListProducts(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, p ListParams) (*ProductPage, error) {
+				assert.Equal(t, "pizza", p.Category)
+				assert.Equal(t, 100.0, *p.MinPrice)
+				assert.Equal(t, 200.0, *p.MaxPrice)
+				assert.Equal(t, "marg", p.Name)
+				assert.Equal(t, SortPrice, p.Sort)
+				assert.Equal(t, OrderDesc, p.Order)
+				assert.Equal(t, 5, p.Limit)
+				assert.Equal(t, &cursor, p.After)
+				assert.True(t, p.AvailableNow)
+				assert.Equal(t, []string{"gluten", "nuts"}, p.ExcludeAllergens)
+				assert.Equal(t, []string{"vegan"}, p.Dietary)
+				return &ProductPage{Items: []*Product{}}, nil
+			})
+
+		target := "/product?category=pizza&minPrice=100&maxPrice=200&name=marg&sort=price&order=DESC&limit=5&availableNow=true" +
+			"&excludeAllergens=Gluten,+nuts,&dietary=vegan&cursor=" + cursor.Encode()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		apperrors.Handle(NewHandler(mockSvc).ListProducts, e.NewContext(req, rec))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	for _, tt := range []struct{ query, message string }{
+		{"minPrice=abc", "minPrice must be a number"},
+		{"maxPrice=-1", "maxPrice must not be negative"},
+		{"minPrice=10&maxPrice=5", "minPrice must not be greater than maxPrice"},
+		{"sort=calories", "sort must be one of name, price"},
+		{"order=up", "order must be one of asc, desc"},
+		{"limit=0", "limit must be between 1 and 100"},
+		{"limit=101", "limit must be between 1 and 100"},
+		{"cursor=%25%25", "invalid cursor"},
+		{"availableNow=yes", "availableNow must be true or false"},
+		{"excludeAllergens=gluten,wheat", "excludeAllergens must be a comma separated list of celery, crustaceans"},
+		{"dietary=keto", "dietary must be a comma separated list of halal, kosher, vegan, vegetarian"},
+		{"sort=price&cursor=" + Cursor{Sort: SortName, Order: OrderAsc, ID: "p1"}.Encode(), "cursor does not match sort and order"},
+	} {
+		t.Run("rejects "+tt.query, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockSvc := NewMockService(ctrl)
+
+			req := httptest.NewRequest(http.MethodGet, "/product?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			apperrors.Handle(NewHandler(mockSvc).ListProducts, e.NewContext(req, rec))
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.message)
+		})
+	}
+}
+
 func TestHandler_GetProduct(t *testing.T) {
 	logger.Init("test-service", "test", 0)
 	t.Run("success", func(t *testing.T) {
@@ -97,10 +197,7 @@ func TestHandler_GetProduct(t *testing.T) {
 		c.SetParamNames("productId")
 		c.SetParamValues(validID)
 
-		err := h.GetProduct(c)
-		if err != nil {
-			t.Fatalf("handler returned err: %v", err)
-		}
+		apperrors.Handle(h.GetProduct, c)
 
 		if rec.Code != http.StatusOK {
 			t.Fatalf("expected 200, got %d", rec.Code)
@@ -120,10 +217,7 @@ func TestHandler_GetProduct(t *testing.T) {
 		c := e.NewContext(req, rec)
 
 		// No param
-		err := h.GetProduct(c)
-		if err != nil {
-			t.Fatalf("handler returned err: %v", err)
-		}
+		apperrors.Handle(h.GetProduct, c)
 
 		if rec.Code != http.StatusBadRequest {
 			t.Fatalf("expected 400, got %d", rec.Code)
@@ -146,13 +240,682 @@ func TestHandler_GetProduct(t *testing.T) {
 		c.SetParamValues("x1")
 
 		// Handler returns 400 before calling service
-		err := h.GetProduct(c)
-		if err != nil {
-			t.Fatalf("handler returned err: %v", err)
-		}
+		apperrors.Handle(h.GetProduct, c)
 
 		if rec.Code != http.StatusBadRequest {
 			t.Fatalf("expected 400, got %d", rec.Code)
 		}
 	})
+	t.Run("service errors keep their status", func(t *testing.T) {
+		validID := "3f6b5b2a-7f66-4b3f-9a1b-000000000000"
+		for _, tc := range []struct {
+			err  error
+			code int
+		}{
+			{apperrors.NotFound("product not found with id "+validID, nil), http.StatusNotFound},
+			{apperrors.Internal("failed to fetch product", errors.New("connection refused")), http.StatusInternalServerError},
+			{errors.New("connection refused"), http.StatusInternalServerError},
+		} {
+			ctrl := gomock.NewController(t)
+			mockSvc := NewMockService(ctrl)
+			mockSvc.EXPECT().GetProduct(gomock.Any(), validID).Return(nil, tc.err)
+
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/products/"+validID, nil), rec)
+			c.SetParamNames("productId")
+			c.SetParamValues(validID)
+
+			apperrors.Handle(NewHandler(mockSvc).GetProduct, c)
+			assert.Equal(t, tc.code, rec.Code, tc.err.Error())
+		}
+	})
+}
+
+func newJSONContext(e *echo.Echo, method, target, body string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	return e.NewContext(req, rec), rec
+}
+
+func TestHandler_CreateProduct(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+
+	t.Run("success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().CreateProduct(gomock.Any(), gomock.Any()).Return(&Product{ID: "p1", Version: 1}, nil)
+
+		c, rec := newJSONContext(e, http.MethodPost, "/product", `{"name":"Burger","price":9.5,"categoryId":"c1a2b3c4-d5e6-4f70-8a9b-0c1d2e3f4a5b"}`)
+		apperrors.Handle(NewHandler(mockSvc).CreateProduct, c)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("validation fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+
+		c, rec := newJSONContext(e, http.MethodPost, "/product", `{"name":"Burger","price":0,"categoryId":"c1a2b3c4-d5e6-4f70-8a9b-0c1d2e3f4a5b"}`)
+		apperrors.Handle(NewHandler(mockSvc).CreateProduct, c)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "price must be greater than 0")
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+
+		c, rec := newJSONContext(e, http.MethodPost, "/product", `{bad`)
+		apperrors.Handle(NewHandler(mockSvc).CreateProduct, c)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestHandler_UpdateProduct(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+	validID := "3f6b5b2a-7f66-4b3f-9a1b-000000000000"
+
+	t.Run("success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().ReplaceProduct(gomock.Any(), validID, gomock.Any()).Return(&Product{ID: validID, Version: 3}, nil)
+
+		c, rec := newJSONContext(e, http.MethodPut, "/product/"+validID, `{"name":"Burger","price":9.5,"categoryId":"c1a2b3c4-d5e6-4f70-8a9b-0c1d2e3f4a5b","version":2}`)
+		c.SetParamNames("productId")
+		c.SetParamValues(validID)
+		apperrors.Handle(NewHandler(mockSvc).UpdateProduct, c)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("version conflict", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().ReplaceProduct(gomock.Any(), validID, gomock.Any()).Return(nil, ErrVersionConflict)
+
+		c, rec := newJSONContext(e, http.MethodPut, "/product/"+validID, `{"name":"Burger","price":9.5,"categoryId":"c1a2b3c4-d5e6-4f70-8a9b-0c1d2e3f4a5b","version":1}`)
+		c.SetParamNames("productId")
+		c.SetParamValues(validID)
+		apperrors.Handle(NewHandler(mockSvc).UpdateProduct, c)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("missing version", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+
+		c, rec := newJSONContext(e, http.MethodPut, "/product/"+validID, `{"name":"Burger","price":9.5,"categoryId":"c1a2b3c4-d5e6-4f70-8a9b-0c1d2e3f4a5b"}`)
+		c.SetParamNames("productId")
+		c.SetParamValues(validID)
+		apperrors.Handle(NewHandler(mockSvc).UpdateProduct, c)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestHandler_PatchProduct(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+	validID := "3f6b5b2a-7f66-4b3f-9a1b-000000000000"
+
+	ctrl := gomock.NewController(t)
+	mockSvc := NewMockService(ctrl)
+	mockSvc.EXPECT().PatchProduct(gomock.Any(), validID, gomock.Any()).Return(&Product{ID: validID, Price: 12, Version: 2}, nil)
+
+	c, rec := newJSONContext(e, http.MethodPatch, "/product/"+validID, `{"price":12,"version":1}`)
+	c.SetParamNames("productId")
+	c.SetParamValues(validID)
+	apperrors.Handle(NewHandler(mockSvc).PatchProduct, c)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestHandler_DeleteProduct(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+	validID := "3f6b5b2a-7f66-4b3f-9a1b-000000000000"
+
+	t.Run("success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().ArchiveProduct(gomock.Any(), validID, 2).Return(nil)
+
+		c, rec := newJSONContext(e, http.MethodDelete, "/product/"+validID+"?version=2", "")
+		c.SetParamNames("productId")
+		c.SetParamValues(validID)
+		apperrors.Handle(NewHandler(mockSvc).DeleteProduct, c)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("missing version", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+
+		c, rec := newJSONContext(e, http.MethodDelete, "/product/"+validID, "")
+		c.SetParamNames("productId")
+		c.SetParamValues(validID)
+		apperrors.Handle(NewHandler(mockSvc).DeleteProduct, c)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().ArchiveProduct(gomock.Any(), validID, 2).Return(ErrProductNotFound)
+
+		c, rec := newJSONContext(e, http.MethodDelete, "/product/"+validID+"?version=2", "")
+		c.SetParamNames("productId")
+		c.SetParamValues(validID)
+		apperrors.Handle(NewHandler(mockSvc).DeleteProduct, c)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestHandler_SearchProducts(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+
+	t.Run("success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().SearchProducts(gomock.Any(), "chicken", 5).
+			Return(&SearchResults{Items: []SearchResult{{Product: &Product{ID: "p1", Name: "Chicken Burger", Allergens: []string{"gluten"}, Dietary: []string{}}, Score: 3}}}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/product/search?q=chicken&limit=5", nil)
+		rec := httptest.NewRecorder()
+		apperrors.Handle(NewHandler(mockSvc).SearchProducts, e.NewContext(req, rec))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"items":[{"id":"p1","restaurantId":"","name":"Chicken Burger","price":0,"category":"","available":false,"version":0,"allergens":["gluten"],"allergensDeclared":false,"dietary":[],"score":3}]}`, rec.Body.String())
+	})
+
+	for _, tt := range []struct{ query, message string }{
+		{"", "q is required"},
+		{"q=chicken&limit=abc", "limit must be between 1 and 100"},
+		{"q=chicken&limit=500", "limit must be between 1 and 100"},
+	} {
+		t.Run("rejects "+tt.query, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockSvc := NewMockService(ctrl)
+
+			req := httptest.NewRequest(http.MethodGet, "/product/search?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			apperrors.Handle(NewHandler(mockSvc).SearchProducts, e.NewContext(req, rec))
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.message)
+		})
+	}
+
+	t.Run("service error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().SearchProducts(gomock.Any(), "chicken", 0).Return(nil, errors.New("db error"))
+
+		req := httptest.NewRequest(http.MethodGet, "/product/search?q=chicken", nil)
+		rec := httptest.NewRecorder()
+		apperrors.Handle(NewHandler(mockSvc).SearchProducts, e.NewContext(req, rec))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestHandler_SetModifierGroups(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+	validID := "3f6b5b2a-7f66-4b3f-9a1b-000000000000"
+	body := `{"groups":[{"name":"Size","minSelect":1,"maxSelect":1,"modifiers":[{"name":"Small"},{"name":"Large","priceDelta":3}]}]}`
+
+	t.Run("success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().SetModifierGroups(gomock.Any(), validID, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, req *ModifierGroupsReq) (*Product, error) {
+				assert.Equal(t, 3.0, req.Groups[0].Modifiers[1].PriceDelta)
+				return &Product{ID: validID}, nil
+			})
+
+		c, rec := newJSONContext(e, http.MethodPut, "/product/"+validID+"/modifiers", body)
+		c.SetParamNames("productId")
+		c.SetParamValues(validID)
+		apperrors.Handle(NewHandler(mockSvc).SetModifierGroups, c)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("invalid rules", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+
+		c, rec := newJSONContext(e, http.MethodPut, "/product/"+validID+"/modifiers", `{"groups":[{"name":"Size","minSelect":2,"maxSelect":1,"modifiers":[{"name":"Small"}]}]}`)
+		c.SetParamNames("productId")
+		c.SetParamValues(validID)
+		apperrors.Handle(NewHandler(mockSvc).SetModifierGroups, c)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("product not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().SetModifierGroups(gomock.Any(), validID, gomock.Any()).Return(nil, ErrProductNotFound)
+
+		c, rec := newJSONContext(e, http.MethodPut, "/product/"+validID+"/modifiers", body)
+		c.SetParamNames("productId")
+		c.SetParamValues(validID)
+		apperrors.Handle(NewHandler(mockSvc).SetModifierGroups, c)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestHandler_AdjustStock(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+	validID := "3f6b5b2a-7f66-4b3f-9a1b-000000000000"
+
+	t.Run("success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().AdjustStock(gomock.Any(), validID, &StockReq{Delta: intPtr(5)}).
+			Return(&Product{ID: validID, Available: true, Stock: intPtr(15)}, nil)
+
+		c, rec := newJSONContext(e, http.MethodPost, "/product/"+validID+"/stock", `{"delta":5}`)
+		c.SetParamNames("productId")
+		c.SetParamValues(validID)
+		apperrors.Handle(NewHandler(mockSvc).AdjustStock, c)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"stock":15`)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+
+		c, rec := newJSONContext(e, http.MethodPost, "/product/"+validID+"/stock", `{"stock":1,"delta":1}`)
+		c.SetParamNames("productId")
+		c.SetParamValues(validID)
+		apperrors.Handle(NewHandler(mockSvc).AdjustStock, c)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("would go negative", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().AdjustStock(gomock.Any(), validID, gomock.Any()).
+			Return(nil, ErrStockNegative.WithDetails(map[string]int{"stock": 2}))
+
+		c, rec := newJSONContext(e, http.MethodPost, "/product/"+validID+"/stock", `{"delta":-5}`)
+		c.SetParamNames("productId")
+		c.SetParamValues(validID)
+		apperrors.Handle(NewHandler(mockSvc).AdjustStock, c)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.JSONEq(t, `{"type":"urn:order-food-online:problem:stock_negative","title":"Stock below zero","status":409,"detail":"stock cannot go below zero",
+			"instance":"/product/`+validID+`/stock","code":"stock_negative","details":{"stock":2}}`, rec.Body.String())
+	})
+}
+
+func TestHandler_ImportProducts(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+
+	t.Run("csv content type and dry run", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().ImportProducts(gomock.Any(), "", FormatCSV, gomock.Any(), true).Return(&ImportResult{DryRun: true, Unchanged: 3}, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/product/import?dryRun=true", strings.NewReader("name,price,category\n"))
+		req.Header.Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+		rec := httptest.NewRecorder()
+
+		apperrors.Handle(NewHandler(mockSvc).ImportProducts, e.NewContext(req, rec))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"unchanged":3`)
+	})
+
+	t.Run("format query parameter wins", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().ImportProducts(gomock.Any(), "", FormatJSON, gomock.Any(), false).Return(&ImportResult{}, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/product/import?format=JSON", strings.NewReader("[]"))
+		req.Header.Set(echo.HeaderContentType, "text/csv")
+		rec := httptest.NewRecorder()
+
+		apperrors.Handle(NewHandler(mockSvc).ImportProducts, e.NewContext(req, rec))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("invalid rows", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().ImportProducts(gomock.Any(), "", FormatJSON, gomock.Any(), false).
+			Return(nil, ErrImportInvalid.WithDetails(map[string]any{"errors": []RowError{{Line: 2, Field: "price", Message: "price must be greater than 0"}}}))
+
+		c, rec := newJSONContext(e, http.MethodPost, "/product/import", `[{"name":"Burger"}]`)
+		apperrors.Handle(NewHandler(mockSvc).ImportProducts, c)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"line":2`)
+	})
+
+	t.Run("invalid dryRun", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+
+		c, rec := newJSONContext(e, http.MethodPost, "/product/import?dryRun=maybe", `[]`)
+		apperrors.Handle(NewHandler(mockSvc).ImportProducts, c)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "dryRun must be true or false")
+	})
+}
+
+func TestHandler_ExportProducts(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+
+	t.Run("csv", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().ExportProducts(gomock.Any(), "", FormatCSV, gomock.Any()).DoAndReturn(func(_ context.Context, _, _ string, w io.Writer) error {
+			_, err := io.WriteString(w, "id,name\n")
+			return err
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/product/export?format=csv", nil)
+		rec := httptest.NewRecorder()
+
+		apperrors.Handle(NewHandler(mockSvc).ExportProducts, e.NewContext(req, rec))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "id,name\n", rec.Body.String())
+		assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get(echo.HeaderContentType))
+		assert.Equal(t, `attachment; filename="products.csv"`, rec.Header().Get(echo.HeaderContentDisposition))
+	})
+
+	t.Run("defaults to json", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().ExportProducts(gomock.Any(), "", FormatJSON, gomock.Any()).Return(nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/product/export", nil)
+		rec := httptest.NewRecorder()
+
+		apperrors.Handle(NewHandler(mockSvc).ExportProducts, e.NewContext(req, rec))
+		assert.Equal(t, `attachment; filename="products.json"`, rec.Header().Get(echo.HeaderContentDisposition))
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().ExportProducts(gomock.Any(), "", "xml", gomock.Any()).Return(ValidateFormat("xml"))
+
+		req := httptest.NewRequest(http.MethodGet, "/product/export?format=xml", nil)
+		rec := httptest.NewRecorder()
+
+		apperrors.Handle(NewHandler(mockSvc).ExportProducts, e.NewContext(req, rec))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Empty(t, rec.Header().Get(echo.HeaderContentDisposition))
+	})
+}
+
+func TestHandler_ListProducts_ETag(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+
+	ctrl := gomock.NewController(t)
+	mockSvc := NewMockService(ctrl)
+	page := &ProductPage{Items: []*Product{{ID: "p1", Name: "Burger"}}, Pagination: Pagination{Limit: DefaultLimit}}
+	mockSvc.EXPECT().ListProducts(gomock.Any(), gomock.Any()).Return(page, nil).Times(3)
+	h := NewHandler(mockSvc)
+
+	list := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/product", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set(headerIfNoneMatch, ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		apperrors.Handle(h.ListProducts, e.NewContext(req, rec))
+		return rec
+	}
+
+	first := list("")
+	assert.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get(headerETag)
+	assert.Equal(t, etagOf(first.Body.Bytes()), etag)
+	lastModified := first.Header().Get(echo.HeaderLastModified)
+	_, err := http.ParseTime(lastModified)
+	assert.NoError(t, err)
+
+	notModified := list(etag)
+	assert.Equal(t, http.StatusNotModified, notModified.Code)
+	assert.Empty(t, notModified.Body.String())
+	assert.Equal(t, etag, notModified.Header().Get(headerETag))
+	assert.Equal(t, lastModified, notModified.Header().Get(echo.HeaderLastModified))
+
+	assert.Equal(t, http.StatusOK, list(`"stale"`).Code)
+}
+
+func TestHandler_SetDietary(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+
+	t.Run("success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().SetDietary(gomock.Any(), "3f6b5b2a-7f66-4b3f-9a1b-111111111111", &DietaryReq{
+			Allergens: []string{"gluten", "milk"},
+			Dietary:   []string{"vegetarian"},
+			Nutrition: &Nutrition{EnergyKcal: floatPtr(820), Salt: floatPtr(3.1)},
+		}).Return(&Product{ID: "3f6b5b2a-7f66-4b3f-9a1b-111111111111", Allergens: []string{"gluten", "milk"}}, nil)
+
+		c, rec := newJSONContext(e, http.MethodPut, "/product/3f6b5b2a-7f66-4b3f-9a1b-111111111111/dietary",
+			`{"allergens":["Gluten","milk"],"dietary":["vegetarian"],"nutrition":{"energyKcal":820,"salt":3.1}}`)
+		c.SetParamNames("productId")
+		c.SetParamValues("3f6b5b2a-7f66-4b3f-9a1b-111111111111")
+
+		apperrors.Handle(NewHandler(mockSvc).SetDietary, c)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"allergens":["gluten","milk"]`)
+	})
+
+	for _, tt := range []struct{ body, message string }{
+		{`{"dietary":["vegan"]}`, "allergens is required, send [] when the product has none"},
+		{`{"allergens":["shellfish"]}`, "allergens[0] must be one of celery"},
+		{`{"allergens":[],"nutrition":{"fat":-1}}`, "nutrition.fat must not be negative"},
+		{`{"allergens":"gluten"}`, "invalid dietary request"},
+	} {
+		t.Run("rejects "+tt.body, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockSvc := NewMockService(ctrl)
+
+			c, rec := newJSONContext(e, http.MethodPut, "/product/3f6b5b2a-7f66-4b3f-9a1b-111111111111/dietary", tt.body)
+			c.SetParamNames("productId")
+			c.SetParamValues("3f6b5b2a-7f66-4b3f-9a1b-111111111111")
+
+			apperrors.Handle(NewHandler(mockSvc).SetDietary, c)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.message)
+		})
+	}
+}
+
+func TestHandler_GetPrice(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+	validID := "3f6b5b2a-7f66-4b3f-9a1b-000000000000"
+	burger := &Product{ID: validID, Price: 9}
+
+	t.Run("price at an instant", func(t *testing.T) {
+		at := time.Date(2026, 10, 23, 17, 30, 0, 0, time.UTC)
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().GetProduct(gomock.Any(), validID).Return(burger, nil)
+		mockSvc.EXPECT().EffectivePrice(gomock.Any(), burger, at).
+			Return(&PriceQuote{ProductID: validID, At: at, BasePrice: 9, Price: 7.2, Rule: &RuleRef{ID: "r1", Name: "Happy hour", PercentOff: 20}}, nil)
+
+		c, rec := newJSONContext(e, http.MethodGet, "/product/"+validID+"/price?at=2026-10-23T19:30:00%2B02:00", "")
+		c.SetParamNames("productId")
+		c.SetParamValues(validID)
+
+		apperrors.Handle(NewHandler(mockSvc).GetPrice, c)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"basePrice":9,"price":7.2,"rule":{"id":"r1","name":"Happy hour","percentOff":20}`)
+	})
+
+	t.Run("bad instant", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+
+		c, rec := newJSONContext(e, http.MethodGet, "/product/"+validID+"/price?at=tomorrow", "")
+		c.SetParamNames("productId")
+		c.SetParamValues(validID)
+
+		apperrors.Handle(NewHandler(mockSvc).GetPrice, c)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "at must be an RFC 3339 timestamp")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().GetProduct(gomock.Any(), validID).Return(nil, ErrProductNotFound)
+
+		c, rec := newJSONContext(e, http.MethodGet, "/product/"+validID+"/price", "")
+		c.SetParamNames("productId")
+		c.SetParamValues(validID)
+
+		apperrors.Handle(NewHandler(mockSvc).GetPrice, c)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestHandler_Prices(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+	validID := "3f6b5b2a-7f66-4b3f-9a1b-000000000000"
+	changeID := "3f6b5b2a-7f66-4b3f-9a1b-222222222222"
+	from := time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("list", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().PriceHistory(gomock.Any(), validID).
+			Return([]PriceChange{{ID: changeID, Price: 10.5, EffectiveFrom: from, Scheduled: true}}, nil)
+
+		c, rec := newJSONContext(e, http.MethodGet, "/product/"+validID+"/prices", "")
+		c.SetParamNames("productId")
+		c.SetParamValues(validID)
+
+		apperrors.Handle(NewHandler(mockSvc).ListPrices, c)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"items":[{"id":"`+changeID+`","price":10.5,"effectiveFrom":"2099-01-01T00:00:00Z","scheduled":true}]}`, rec.Body.String())
+	})
+
+	t.Run("schedule", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().SchedulePrice(gomock.Any(), validID, &PriceChangeReq{Price: floatPtr(10.5), EffectiveFrom: &from}).
+			Return(&PriceChange{ID: changeID, Price: 10.5, EffectiveFrom: from, Scheduled: true}, nil)
+
+		c, rec := newJSONContext(e, http.MethodPost, "/product/"+validID+"/prices", `{"price":10.5,"effectiveFrom":"2099-01-01T00:00:00Z"}`)
+		c.SetParamNames("productId")
+		c.SetParamValues(validID)
+
+		apperrors.Handle(NewHandler(mockSvc).SchedulePrice, c)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("schedule in the past", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+
+		c, rec := newJSONContext(e, http.MethodPost, "/product/"+validID+"/prices", `{"price":10.5,"effectiveFrom":"2020-01-01T00:00:00Z"}`)
+		c.SetParamNames("productId")
+		c.SetParamValues(validID)
+
+		apperrors.Handle(NewHandler(mockSvc).SchedulePrice, c)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "effectiveFrom must be in the future")
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().CancelPriceChange(gomock.Any(), validID, changeID).Return(nil)
+
+		c, rec := newJSONContext(e, http.MethodDelete, "/product/"+validID+"/prices/"+changeID, "")
+		c.SetParamNames("productId", "priceId")
+		c.SetParamValues(validID, changeID)
+
+		apperrors.Handle(NewHandler(mockSvc).CancelPriceChange, c)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("cancel a change in effect", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().CancelPriceChange(gomock.Any(), validID, changeID).Return(ErrPriceChangeApplied)
+
+		c, rec := newJSONContext(e, http.MethodDelete, "/product/"+validID+"/prices/"+changeID, "")
+		c.SetParamNames("productId", "priceId")
+		c.SetParamValues(validID, changeID)
+
+		apperrors.Handle(NewHandler(mockSvc).CancelPriceChange, c)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+}
+
+func TestHandler_PriceRules(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+	ruleID := "3f6b5b2a-7f66-4b3f-9a1b-333333333333"
+
+	t.Run("list", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().ListPriceRules(gomock.Any()).Return([]PriceRule{{ID: ruleID, Name: "Happy hour", PercentOff: 20}}, nil)
+
+		c, rec := newJSONContext(e, http.MethodGet, "/price-rule", "")
+		apperrors.Handle(NewHandler(mockSvc).ListPriceRules, c)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"name":"Happy hour"`)
+	})
+
+	t.Run("create", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().CreatePriceRule(gomock.Any(), gomock.Any()).Return(&PriceRule{ID: ruleID, Name: "Happy hour", PercentOff: 20}, nil)
+
+		c, rec := newJSONContext(e, http.MethodPost, "/price-rule",
+			`{"name":"Happy hour","percentOff":20,"timeZone":"Europe/Berlin","windows":[{"days":["fri"],"start":"17:00","end":"19:00"}]}`)
+		apperrors.Handle(NewHandler(mockSvc).CreatePriceRule, c)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("create rejects a bad window", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+
+		c, rec := newJSONContext(e, http.MethodPost, "/price-rule",
+			`{"name":"Happy hour","percentOff":20,"windows":[{"days":["friday"],"start":"17:00","end":"19:00"}]}`)
+		apperrors.Handle(NewHandler(mockSvc).CreatePriceRule, c)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"pointer":"/windows/0/days/0"`)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().DeletePriceRule(gomock.Any(), ruleID).Return(nil)
+
+		c, rec := newJSONContext(e, http.MethodDelete, "/price-rule/"+ruleID, "")
+		c.SetParamNames("ruleId")
+		c.SetParamValues(ruleID)
+		apperrors.Handle(NewHandler(mockSvc).DeletePriceRule, c)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("delete unknown rule", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().DeletePriceRule(gomock.Any(), ruleID).Return(ErrPriceRuleNotFound)
+
+		c, rec := newJSONContext(e, http.MethodDelete, "/price-rule/"+ruleID, "")
+		c.SetParamNames("ruleId")
+		c.SetParamValues(ruleID)
+		apperrors.Handle(NewHandler(mockSvc).DeletePriceRule, c)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
 }
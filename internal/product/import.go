@@ -0,0 +1,437 @@
+package product
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+
+	maxImportRows   = 5000
+	maxImportErrors = 100
+)
+
+// importColumns is the CSV header of imports and exports. Only name, price
+// and category are required in an import file, columns may come in any order.
+var importColumns = []string{"id", "name", "price", "category", "description", "available"}
+
+// ImportRow is one product of an import or export file. Category is the
+// category slug, which is easier to maintain in a spreadsheet than an id.
+type ImportRow struct {
+	ID          string  `json:"id,omitempty"`
+	Name        string  `json:"name"`
+	Price       float64 `json:"price"`
+	Category    string  `json:"category"`
+	Description string  `json:"description,omitempty"`
+	// Available defaults to true when the column is empty
+	Available *bool `json:"available,omitempty"`
+
+	line int
+}
+
+// RowError points at a problem in the import file. Line is the line in the
+// file, counting the CSV header as line 1.
+type RowError struct {
+	Line    int    `json:"line"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportResult describes what an import changed, or would change in a dry run
+type ImportResult struct {
+	DryRun    bool           `json:"dryRun"`
+	Created   []ImportChange `json:"created"`
+	Updated   []ImportChange `json:"updated"`
+	Archived  []ImportChange `json:"archived"`
+	Unchanged int            `json:"unchanged"`
+}
+
+// ImportChange is a single product in an ImportResult. Changes lists the
+// fields an update touches.
+type ImportChange struct {
+	Line    int           `json:"line,omitempty"`
+	ID      string        `json:"id"`
+	Name    string        `json:"name"`
+	Changes []FieldChange `json:"changes,omitempty"`
+}
+
+type FieldChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+// ImportPlan is the set of writes an import applies in one transaction.
+// Updated products carry the version they were read at.
+type ImportPlan struct {
+	Create  []*Product
+	Update  []*Product
+	Archive []*Product
+}
+
+// CategoryRef is the id, slug and name of a category as imports and exports use it
+type CategoryRef struct {
+	ID   string
+	Slug string
+	Name string
+}
+
+// ValidateFormat checks an import or export format
+func ValidateFormat(format string) *apperrors.AppError {
+	if format != FormatCSV && format != FormatJSON {
+		return apperrors.BadRequest(fmt.Sprintf("format must be one of %s, %s", FormatCSV, FormatJSON), nil)
+	}
+	return nil
+}
+
+// parseImport reads the rows of an import file. A file that cannot be read at
+// all fails with 400; bad values in single rows are returned as RowErrors.
+func parseImport(format string, r io.Reader) ([]*ImportRow, []RowError, error) {
+	if format == FormatCSV {
+		return parseCSV(r)
+	}
+	return parseJSON(r)
+}
+
+func parseCSV(r io.Reader) ([]*ImportRow, []RowError, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, ErrImportEmpty
+		}
+		return nil, nil, readFailed("invalid CSV header", err)
+	}
+
+	cols := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !contains(importColumns, name) {
+			return nil, nil, apperrors.BadRequest(fmt.Sprintf("unknown CSV column %q, use %s", name, strings.Join(importColumns, ", ")), nil)
+		}
+		cols[name] = i
+	}
+	for _, required := range []string{"name", "price", "category"} {
+		if _, ok := cols[required]; !ok {
+			return nil, nil, apperrors.BadRequest(fmt.Sprintf("CSV column %q is required", required), nil)
+		}
+	}
+
+	field := func(rec []string, name string) string {
+		if i, ok := cols[name]; ok && i < len(rec) {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+
+	var (
+		rows []*ImportRow
+		errs []RowError
+	)
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var perr *csv.ParseError
+			if errors.As(err, &perr) && errors.Is(perr.Err, csv.ErrFieldCount) {
+				errs = append(errs, RowError{Line: perr.Line, Message: "row does not have the same number of columns as the header"})
+				continue
+			}
+			return nil, nil, readFailed("invalid CSV", err)
+		}
+		if len(rows) >= maxImportRows {
+			return nil, nil, apperrors.BadRequest(fmt.Sprintf("at most %d products can be imported at once", maxImportRows), nil)
+		}
+
+		line, _ := cr.FieldPos(0)
+		row := &ImportRow{
+			ID:          field(rec, "id"),
+			Name:        field(rec, "name"),
+			Category:    field(rec, "category"),
+			Description: field(rec, "description"),
+			line:        line,
+		}
+
+		if raw := field(rec, "price"); raw != "" {
+			price, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				errs = append(errs, RowError{Line: line, Field: "price", Message: "price must be a number"})
+			}
+			row.Price = price
+		}
+		if raw := field(rec, "available"); raw != "" {
+			available, ok := parseYesNo(raw)
+			if !ok {
+				errs = append(errs, RowError{Line: line, Field: "available", Message: "available must be true or false"})
+			}
+			row.Available = &available
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, errs, nil
+}
+
+func parseJSON(r io.Reader) ([]*ImportRow, []RowError, error) {
+	// the whole body is kept to turn decoder offsets into line numbers
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, readFailed("failed to read import body", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return nil, nil, apperrors.BadRequest("JSON import must be an array of products", err)
+	}
+
+	var (
+		rows []*ImportRow
+		errs []RowError
+	)
+	for dec.More() {
+		if len(rows) >= maxImportRows {
+			return nil, nil, apperrors.BadRequest(fmt.Sprintf("at most %d products can be imported at once", maxImportRows), nil)
+		}
+
+		line := lineAt(data, dec.InputOffset())
+		var row ImportRow
+		if err := dec.Decode(&row); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if !errors.As(err, &typeErr) {
+				return nil, nil, apperrors.BadRequest(fmt.Sprintf("invalid JSON near line %d", line), err)
+			}
+			errs = append(errs, RowError{Line: line, Field: typeErr.Field, Message: fmt.Sprintf("%s must be a %s", typeErr.Field, typeErr.Type)})
+		}
+		row.ID = strings.TrimSpace(row.ID)
+		row.Name = strings.TrimSpace(row.Name)
+		row.Category = strings.TrimSpace(row.Category)
+		row.Description = strings.TrimSpace(row.Description)
+		row.line = line
+		rows = append(rows, &row)
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, nil, apperrors.BadRequest("invalid JSON, the array is not closed", err)
+	}
+	return rows, errs, nil
+}
+
+// readFailed is the error for an import body that could not be read, 413 when
+// it is over the size limit the handler set and 400 otherwise
+func readFailed(msg string, err error) *apperrors.AppError {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return apperrors.Wrap(http.StatusRequestEntityTooLarge, fmt.Sprintf("import file is larger than %d bytes", tooLarge.Limit), apperrors.LevelWarn, err).
+			WithCode(apperrors.CodePayloadTooLarge)
+	}
+	return apperrors.BadRequest(msg, err)
+}
+
+// lineAt returns the line of the first non blank byte at or after offset,
+// skipping the comma between array elements
+func lineAt(data []byte, offset int64) int {
+	i := int(offset)
+	for i < len(data) && strings.ContainsRune(" \t\r\n,", rune(data[i])) {
+		i++
+	}
+	return bytes.Count(data[:i], []byte("\n")) + 1
+}
+
+func parseYesNo(s string) (bool, bool) {
+	switch strings.ToLower(s) {
+	case "yes", "y":
+		return true, true
+	case "no", "n":
+		return false, true
+	}
+	b, err := strconv.ParseBool(s)
+	return b, err == nil
+}
+
+func contains(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// validateRows applies the rules of ProductReq to every row and resolves
+// category slugs; all problems are collected so ops can fix a file in one go
+func validateRows(rows []*ImportRow, categories map[string]CategoryRef, errs []RowError) []RowError {
+	// fields the parser already rejected hold zero values, don't report them twice
+	parsed := make(map[RowError]bool, len(errs))
+	for _, e := range errs {
+		parsed[RowError{Line: e.Line, Field: e.Field}] = true
+	}
+	seen := make(map[string]int, len(rows))
+	add := func(row *ImportRow, field, msg string) {
+		if parsed[RowError{Line: row.line, Field: field}] {
+			return
+		}
+		errs = append(errs, RowError{Line: row.line, Field: field, Message: msg})
+	}
+
+	for _, row := range rows {
+		if row.ID != "" {
+			if _, err := uuid.Parse(row.ID); err != nil {
+				add(row, "id", "id must be a UUID")
+			} else if first, dup := seen[strings.ToLower(row.ID)]; dup {
+				add(row, "id", fmt.Sprintf("id already used on line %d", first))
+			} else {
+				seen[strings.ToLower(row.ID)] = row.line
+			}
+		}
+		if row.Name == "" || len(row.Name) > maxNameLength {
+			add(row, "name", "name must be 1-255 characters")
+		}
+		if row.Price <= 0 {
+			add(row, "price", "price must be greater than 0")
+		}
+		if row.Category == "" {
+			add(row, "category", "category is required")
+		} else if _, ok := categories[strings.ToLower(row.Category)]; !ok {
+			add(row, "category", fmt.Sprintf("unknown category %q", row.Category))
+		}
+		if len(row.Description) > maxDescriptionLength {
+			add(row, "description", "description must be at most 2000 characters")
+		}
+	}
+	return errs
+}
+
+// unknownIDs returns the ids of rows that are not current products of the
+// restaurant, these are created with that id unless the id is already taken
+func unknownIDs(rows []*ImportRow, current []*Product) []string {
+	byID := make(map[string]bool, len(current))
+	for _, p := range current {
+		byID[strings.ToLower(p.ID)] = true
+	}
+	var ids []string
+	for _, row := range rows {
+		if row.ID != "" && !byID[strings.ToLower(row.ID)] {
+			ids = append(ids, row.ID)
+		}
+	}
+	return ids
+}
+
+// takenIDs reports rows whose id belongs to an archived product or to a
+// product of another restaurant; owners maps lower case ids to their restaurant
+func takenIDs(rows []*ImportRow, current []*Product, owners map[string]string, restaurantID string) []RowError {
+	byID := make(map[string]bool, len(current))
+	for _, p := range current {
+		byID[strings.ToLower(p.ID)] = true
+	}
+	var errs []RowError
+	for _, row := range rows {
+		id := strings.ToLower(row.ID)
+		owner, ok := owners[id]
+		if row.ID == "" || byID[id] || !ok {
+			continue
+		}
+		msg := "id belongs to a product of another restaurant"
+		if owner == restaurantID {
+			msg = "id belongs to an archived product, import it without an id to create a new one"
+		}
+		errs = append(errs, RowError{Line: row.line, Field: "id", Message: msg})
+	}
+	return errs
+}
+
+// planImport compares the rows with the current catalog of a restaurant. Rows
+// with the id of a current product update it, other rows create a product of
+// the restaurant and current products missing from the file are archived.
+func planImport(rows []*ImportRow, current []*Product, categories map[string]CategoryRef, restaurantID string) (*ImportPlan, *ImportResult) {
+	byID := make(map[string]*Product, len(current))
+	for _, p := range current {
+		byID[strings.ToLower(p.ID)] = p
+	}
+
+	plan := &ImportPlan{}
+	res := &ImportResult{Created: []ImportChange{}, Updated: []ImportChange{}, Archived: []ImportChange{}}
+	kept := make(map[string]bool, len(rows))
+
+	for _, row := range rows {
+		cat := categories[strings.ToLower(row.Category)]
+		next := &Product{
+			RestaurantID: restaurantID,
+			Name:         row.Name,
+			Price:        row.Price,
+			CategoryID:   cat.ID,
+			Category:     cat.Name,
+			Description:  row.Description,
+			Available:    row.Available == nil || *row.Available,
+		}
+
+		cur, ok := byID[strings.ToLower(row.ID)]
+		if !ok {
+			next.ID = row.ID
+			if next.ID == "" {
+				next.ID = uuid.New().String()
+			}
+			next.Version = 1
+			next.Allergens, next.Dietary = []string{}, []string{}
+			plan.Create = append(plan.Create, next)
+			res.Created = append(res.Created, ImportChange{Line: row.line, ID: next.ID, Name: next.Name})
+			continue
+		}
+
+		kept[cur.ID] = true
+		next.ID, next.Version, next.Stock = cur.ID, cur.Version, cur.Stock
+		// imports leave the dietary information alone
+		next.Allergens, next.AllergensDeclared, next.Dietary, next.Nutrition = cur.Allergens, cur.AllergensDeclared, cur.Dietary, cur.Nutrition
+		changes := diffProducts(cur, next)
+		if len(changes) == 0 {
+			res.Unchanged++
+			continue
+		}
+		plan.Update = append(plan.Update, next)
+		res.Updated = append(res.Updated, ImportChange{Line: row.line, ID: cur.ID, Name: next.Name, Changes: changes})
+	}
+
+	for _, p := range current {
+		if !kept[p.ID] {
+			plan.Archive = append(plan.Archive, p)
+			res.Archived = append(res.Archived, ImportChange{ID: p.ID, Name: p.Name})
+		}
+	}
+	return plan, res
+}
+
+func diffProducts(from, to *Product) []FieldChange {
+	var changes []FieldChange
+	if from.Name != to.Name {
+		changes = append(changes, FieldChange{Field: "name", From: from.Name, To: to.Name})
+	}
+	if from.Price != to.Price {
+		changes = append(changes, FieldChange{Field: "price", From: from.Price, To: to.Price})
+	}
+	if from.CategoryID != to.CategoryID {
+		changes = append(changes, FieldChange{Field: "category", From: from.Category, To: to.Category})
+	}
+	if from.Description != to.Description {
+		changes = append(changes, FieldChange{Field: "description", From: from.Description, To: to.Description})
+	}
+	if from.Available != to.Available {
+		changes = append(changes, FieldChange{Field: "available", From: from.Available, To: to.Available})
+	}
+	return changes
+}
@@ -0,0 +1,193 @@
+package product
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	pizzaCategoryID = "9b2e0f4c-1a2b-4c3d-8e9f-0a1b2c3d4e5f"
+	existingID      = "3f6b5b2a-7f66-4b3f-9a1b-111111111111"
+	goneID          = "7a9d8c3f-3333-4444-5555-333333333333"
+	restaurantID    = "55555555-5555-4555-8555-555555555555"
+)
+
+var importCategories = map[string]CategoryRef{
+	"pizza": {ID: pizzaCategoryID, Slug: "pizza", Name: "Pizza"},
+}
+
+func TestParseCSV(t *testing.T) {
+	t.Run("columns in any order", func(t *testing.T) {
+		in := "\ufeffName,Price,category,available,id\n" +
+			"Margherita,9.5,pizza,yes," + existingID + "\n" +
+			"\"Diavola, hot\",11,pizza,,\n"
+
+		rows, errs, err := parseImport(FormatCSV, strings.NewReader(in))
+		require.NoError(t, err)
+		assert.Empty(t, errs)
+		require.Len(t, rows, 2)
+
+		assert.Equal(t, existingID, rows[0].ID)
+		assert.Equal(t, 9.5, rows[0].Price)
+		assert.True(t, *rows[0].Available)
+		assert.Equal(t, 2, rows[0].line)
+
+		assert.Equal(t, "Diavola, hot", rows[1].Name)
+		assert.Nil(t, rows[1].Available)
+		assert.Equal(t, 3, rows[1].line)
+	})
+
+	t.Run("row errors carry line numbers", func(t *testing.T) {
+		in := "name,price,category,available\n" +
+			"Margherita,cheap,pizza,maybe\n" +
+			"Diavola,11\n"
+
+		rows, errs, err := parseImport(FormatCSV, strings.NewReader(in))
+		require.NoError(t, err)
+		assert.Len(t, rows, 1)
+		assert.Equal(t, []RowError{
+			{Line: 2, Field: "price", Message: "price must be a number"},
+			{Line: 2, Field: "available", Message: "available must be true or false"},
+			{Line: 3, Message: "row does not have the same number of columns as the header"},
+		}, errs)
+	})
+
+	for name, in := range map[string]string{
+		"unknown column": "name,price,category,colour\n",
+		"missing column": "name,price\n",
+		"broken quoting": "name,price,category\n\"Margherita,9,pizza\n",
+		"empty file":     "",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := parseImport(FormatCSV, strings.NewReader(in))
+			appErr, ok := err.(*apperrors.AppError)
+			require.True(t, ok, "got %v", err)
+			assert.Equal(t, 400, appErr.Code)
+		})
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	t.Run("line numbers of array elements", func(t *testing.T) {
+		in := `[
+  {"name": "Margherita", "price": 9.5, "category": "pizza"},
+  {
+    "id": "` + existingID + `",
+    "name": " Diavola ",
+    "price": 11,
+    "category": "pizza",
+    "available": false
+  }
+]`
+		rows, errs, err := parseImport(FormatJSON, strings.NewReader(in))
+		require.NoError(t, err)
+		assert.Empty(t, errs)
+		require.Len(t, rows, 2)
+		assert.Equal(t, 2, rows[0].line)
+		assert.Equal(t, 3, rows[1].line)
+		assert.Equal(t, "Diavola", rows[1].Name)
+		assert.False(t, *rows[1].Available)
+	})
+
+	t.Run("wrong types are row errors", func(t *testing.T) {
+		in := "[\n{\"name\": \"Margherita\", \"price\": \"9.5\", \"category\": \"pizza\"}\n]"
+
+		rows, errs, err := parseImport(FormatJSON, strings.NewReader(in))
+		require.NoError(t, err)
+		assert.Len(t, rows, 1)
+		assert.Equal(t, []RowError{{Line: 2, Field: "price", Message: "price must be a float64"}}, errs)
+	})
+
+	for name, in := range map[string]string{
+		"not an array": `{"name": "Margherita"}`,
+		"broken json":  `[{"name": "Margherita",}]`,
+		"unclosed":     `[{"name": "Margherita"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := parseImport(FormatJSON, strings.NewReader(in))
+			appErr, ok := err.(*apperrors.AppError)
+			require.True(t, ok, "got %v", err)
+			assert.Equal(t, 400, appErr.Code)
+		})
+	}
+}
+
+func TestValidateRows(t *testing.T) {
+	rows := []*ImportRow{
+		{line: 2, ID: existingID, Name: "Margherita", Price: 9.5, Category: "Pizza"},
+		{line: 3, ID: existingID, Name: "Copy", Price: 9.5, Category: "pizza"},
+		{line: 4, ID: "42", Name: "", Price: 0, Category: "pasta"},
+		{line: 5, Name: "No category", Price: 1, Description: strings.Repeat("x", maxDescriptionLength+1)},
+	}
+
+	errs := validateRows(rows, importCategories, nil)
+	assert.Equal(t, []RowError{
+		{Line: 3, Field: "id", Message: "id already used on line 2"},
+		{Line: 4, Field: "id", Message: "id must be a UUID"},
+		{Line: 4, Field: "name", Message: "name must be 1-255 characters"},
+		{Line: 4, Field: "price", Message: "price must be greater than 0"},
+		{Line: 4, Field: "category", Message: `unknown category "pasta"`},
+		{Line: 5, Field: "category", Message: "category is required"},
+		{Line: 5, Field: "description", Message: "description must be at most 2000 characters"},
+	}, errs)
+}
+
+func TestPlanImport(t *testing.T) {
+	no := false
+	current := []*Product{
+		{ID: existingID, Name: "Margherita", Price: 9, CategoryID: pizzaCategoryID, Category: "Pizza", Available: true, Version: 4},
+		{ID: goneID, Name: "Calzone", Price: 12, CategoryID: pizzaCategoryID, Category: "Pizza", Available: true, Version: 1},
+		{ID: "e2c1a9b0-1d2e-4c3a-9f44-222222222222", Name: "Marinara", Price: 7, CategoryID: pizzaCategoryID, Category: "Pizza", Available: true, Version: 2},
+	}
+	rows := []*ImportRow{
+		{line: 2, ID: strings.ToUpper(existingID), Name: "Margherita", Price: 9.5, Category: "pizza", Available: &no},
+		{line: 3, ID: "e2c1a9b0-1d2e-4c3a-9f44-222222222222", Name: "Marinara", Price: 7, Category: "PIZZA"},
+		{line: 4, Name: "Diavola", Price: 11, Category: "pizza"},
+	}
+
+	plan, res := planImport(rows, current, importCategories, DefaultRestaurantID)
+
+	require.Len(t, plan.Create, 1)
+	assert.NotEmpty(t, plan.Create[0].ID)
+	assert.Equal(t, DefaultRestaurantID, plan.Create[0].RestaurantID)
+	assert.Equal(t, pizzaCategoryID, plan.Create[0].CategoryID)
+	assert.True(t, plan.Create[0].Available)
+
+	require.Len(t, plan.Update, 1)
+	assert.Equal(t, existingID, plan.Update[0].ID)
+	assert.Equal(t, 4, plan.Update[0].Version)
+
+	require.Len(t, plan.Archive, 1)
+	assert.Equal(t, goneID, plan.Archive[0].ID)
+
+	assert.Equal(t, []ImportChange{{Line: 4, ID: plan.Create[0].ID, Name: "Diavola"}}, res.Created)
+	assert.Equal(t, []ImportChange{{Line: 2, ID: existingID, Name: "Margherita", Changes: []FieldChange{
+		{Field: "price", From: 9.0, To: 9.5},
+		{Field: "available", From: true, To: false},
+	}}}, res.Updated)
+	assert.Equal(t, []ImportChange{{ID: goneID, Name: "Calzone"}}, res.Archived)
+	assert.Equal(t, 1, res.Unchanged)
+}
+
+func TestParseImport_TooLarge(t *testing.T) {
+	for format, in := range map[string]string{
+		FormatCSV:  "name,price,category\n" + strings.Repeat("Margherita,9,pizza\n", 10),
+		FormatJSON: "[" + strings.Repeat(`{"name":"Margherita","price":9,"category":"pizza"},`, 10) + "]",
+	} {
+		t.Run(format, func(t *testing.T) {
+			body := http.MaxBytesReader(httptest.NewRecorder(), io.NopCloser(strings.NewReader(in)), 64)
+			_, _, err := parseImport(format, body)
+			appErr, ok := err.(*apperrors.AppError)
+			require.True(t, ok, "got %v", err)
+			assert.Equal(t, http.StatusRequestEntityTooLarge, appErr.Code)
+			assert.Equal(t, apperrors.CodePayloadTooLarge, appErr.ErrorCode)
+		})
+	}
+}
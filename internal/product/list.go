@@ -0,0 +1,94 @@
+package product
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/mohammadshabab/order-food-online/internal/schedule"
+)
+
+const (
+	SortName  = "name"
+	SortPrice = "price"
+
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// ListParams filters, sorts and pages GET /product
+type ListParams struct {
+	// RestaurantID keeps the products of one restaurant, set from the path of
+	// GET /restaurant/{restaurantId}/product
+	RestaurantID string
+	Category     string
+	MinPrice     *float64
+	MaxPrice     *float64
+	Name         string // case-insensitive substring
+	// ExcludeAllergens drops products containing any of these allergens
+	ExcludeAllergens []string
+	// Dietary keeps products carrying all of these flags
+	Dietary []string
+	Sort    string
+	Order   string
+	Limit   int     // 0 means no limit (internal callers only)
+	After   *Cursor // decoded from the request cursor
+
+	// AvailableNow keeps only products that can be ordered at this moment
+	AvailableNow bool
+	// Exclusions is filled in by the service from the menu schedules when AvailableNow is set
+	Exclusions *schedule.Exclusions
+}
+
+// Cursor is the keyset position of the last item of a page
+type Cursor struct {
+	Sort  string  `json:"s"`
+	Order string  `json:"o"`
+	Name  string  `json:"n,omitempty"`
+	Price float64 `json:"p,omitempty"`
+	ID    string  `json:"id"`
+}
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func DecodeCursor(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.ID == "" {
+		return nil, errInvalidCursor
+	}
+	return &c, nil
+}
+
+func cursorFor(p *Product, params ListParams) Cursor {
+	return Cursor{Sort: params.Sort, Order: params.Order, Name: p.Name, Price: p.Price, ID: p.ID}
+}
+
+type Pagination struct {
+	Limit      int    `json:"limit"`
+	HasMore    bool   `json:"hasMore"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// ProductPage is the response of GET /product
+type ProductPage struct {
+	Items      []*Product `json:"items"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// escapeLike escapes LIKE wildcards so user input matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
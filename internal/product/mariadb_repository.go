@@ -3,26 +3,88 @@ package product
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
+	"github.com/google/uuid"
 	"github.com/mohammadshabab/order-food-online/internal/apperrors"
 	"github.com/mohammadshabab/order-food-online/internal/db"
 	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/mohammadshabab/order-food-online/internal/schedule"
 )
 
 type MariaDBRepository struct{}
 
+// productColumns is the select list matching scanProduct. The legacy free
+// text products.category column is no longer read, the name comes from categories.
+// Allergens and dietary flags arrive comma separated, nutrition as a JSON object.
+var productColumns = `id, name, price, COALESCE(category_id, ''), ` +
+	`COALESCE((SELECT c.name FROM categories c WHERE c.id = products.category_id), ''), ` +
+	`COALESCE(description, ''), available, stock, version, ` +
+	`COALESCE((SELECT GROUP_CONCAT(a.allergen ORDER BY a.allergen) FROM product_allergens a WHERE a.product_id = products.id), ''), ` +
+	`COALESCE((SELECT GROUP_CONCAT(f.flag ORDER BY f.flag) FROM product_dietary_flags f WHERE f.product_id = products.id), ''), ` +
+	`(SELECT JSON_OBJECT(` + nutritionJSON() + `) FROM product_nutrition n WHERE n.product_id = products.id), ` +
+	`restaurant_id, allergens_declared_at IS NOT NULL`
+
+// nutritionJSON lists the JSON_OBJECT arguments that turn a product_nutrition row into Nutrition
+func nutritionJSON() string {
+	var args []string
+	for _, v := range (&Nutrition{}).values() {
+		args = append(args, fmt.Sprintf("'%s', n.%s", v.name, v.column))
+	}
+	return strings.Join(args, ", ")
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanProduct(row rowScanner) (*Product, error) {
+	var (
+		p                  Product
+		stock              sql.NullInt64
+		allergens, dietary string
+		nutrition          sql.NullString
+	)
+	if err := row.Scan(&p.ID, &p.Name, &p.Price, &p.CategoryID, &p.Category, &p.Description, &p.Available, &stock, &p.Version,
+		&allergens, &dietary, &nutrition, &p.RestaurantID, &p.AllergensDeclared); err != nil {
+		return nil, err
+	}
+	if stock.Valid {
+		n := int(stock.Int64)
+		p.Stock = &n
+	}
+	p.Allergens = splitCodes(allergens)
+	p.Dietary = splitCodes(dietary)
+	if nutrition.Valid {
+		p.Nutrition = &Nutrition{}
+		if err := json.Unmarshal([]byte(nutrition.String), p.Nutrition); err != nil {
+			return nil, err
+		}
+	}
+	return &p, nil
+}
+
+// splitCodes splits a GROUP_CONCAT list, an empty list gives an empty slice
+func splitCodes(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, ",")
+}
+
 func NewMariaDBRepository() Repository {
 	return &MariaDBRepository{}
 }
 
-func (r *MariaDBRepository) List(ctx context.Context) ([]*Product, error) {
-	query := `SELECT id, name, price, category FROM products`
+func (r *MariaDBRepository) List(ctx context.Context, params ListParams) ([]*Product, error) {
+	query, args := buildListQuery(params)
 
-	logger.Info(ctx, "DB Query start", "query", query)
+	logger.Info(ctx, "DB Query start", "query", query, "args", args)
 
-	rows, err := db.Pool.Query(ctx, query)
+	rows, err := db.Pool.Query(ctx, query, args...)
 	if err != nil {
 		appErr := apperrors.Internal("failed to list products", err)
 		logger.Error(ctx, appErr.Message, "query", query, "error", err.Error())
@@ -32,29 +94,136 @@ func (r *MariaDBRepository) List(ctx context.Context) ([]*Product, error) {
 
 	var products []*Product
 	for rows.Next() {
-		var p Product
-		if err := rows.Scan(&p.ID, &p.Name, &p.Price, &p.Category); err != nil {
+		p, err := scanProduct(rows)
+		if err != nil {
 			appErr := apperrors.Internal("failed to scan product row", err)
 			logger.Error(ctx, appErr.Message, "query", query, "error", err.Error())
 			return nil, appErr
 		}
-		products = append(products, &p)
+		products = append(products, p)
+	}
+	if err := rows.Err(); err != nil {
+		appErr := apperrors.Internal("failed to read product rows", err)
+		logger.Error(ctx, appErr.Message, "query", query, "error", err.Error())
+		return nil, appErr
 	}
 
 	logger.Info(ctx, "DB Query completed", "query", query, "rows", len(products))
 	return products, nil
 }
 
+// buildListQuery turns params into a keyset paginated query. Sort columns come
+// from a fixed whitelist, every user value is passed as an argument.
+func buildListQuery(params ListParams) (string, []any) {
+	var (
+		where = []string{"archived_at IS NULL"}
+		args  []any
+	)
+
+	if params.RestaurantID != "" {
+		where = append(where, "restaurant_id = ?")
+		args = append(args, params.RestaurantID)
+	}
+	if params.Category != "" {
+		where = append(where, "category_id IN (SELECT id FROM categories WHERE slug = ?)")
+		args = append(args, strings.ToLower(params.Category))
+	}
+	if params.MinPrice != nil {
+		where = append(where, "price >= ?")
+		args = append(args, *params.MinPrice)
+	}
+	if params.MaxPrice != nil {
+		where = append(where, "price <= ?")
+		args = append(args, *params.MaxPrice)
+	}
+	if params.Name != "" {
+		where = append(where, "name LIKE ?")
+		args = append(args, "%"+escapeLike(params.Name)+"%")
+	}
+	if len(params.ExcludeAllergens) > 0 {
+		// a product without a declaration may contain anything, it never passes
+		where = append(where, "allergens_declared_at IS NOT NULL")
+		where = append(where, "id NOT IN (SELECT product_id FROM product_allergens WHERE allergen IN ("+placeholders(len(params.ExcludeAllergens))+"))")
+		args = appendStrings(args, params.ExcludeAllergens)
+	}
+	// a product must carry every requested flag
+	for _, flag := range params.Dietary {
+		where = append(where, "id IN (SELECT product_id FROM product_dietary_flags WHERE flag = ?)")
+		args = append(args, flag)
+	}
+	if params.AvailableNow {
+		where = append(where, "available = TRUE")
+	}
+	if ex := params.Exclusions; ex != nil {
+		if len(ex.Closed) > 0 {
+			where = append(where, "restaurant_id NOT IN ("+placeholders(len(ex.Closed))+")")
+			args = appendStrings(args, ex.Closed)
+		}
+		if len(ex.ProductIDs) > 0 {
+			where = append(where, "id NOT IN ("+placeholders(len(ex.ProductIDs))+")")
+			args = appendStrings(args, ex.ProductIDs)
+		}
+		if len(ex.Categories) > 0 {
+			cond := "COALESCE(category_id, '') NOT IN (" + placeholders(len(ex.Categories)) + ")"
+			if len(ex.Overrides) > 0 {
+				// products with their own active schedule ignore the category schedule
+				cond = "(id IN (" + placeholders(len(ex.Overrides)) + ") OR " + cond + ")"
+				args = appendStrings(args, ex.Overrides)
+			}
+			where = append(where, cond)
+			args = appendStrings(args, ex.Categories)
+		}
+	}
+
+	column := "name"
+	if params.Sort == SortPrice {
+		column = "price"
+	}
+	cmp, dir := ">", "ASC"
+	if params.Order == OrderDesc {
+		cmp, dir = "<", "DESC"
+	}
+
+	if params.After != nil {
+		var v any = params.After.Name
+		if params.Sort == SortPrice {
+			v = params.After.Price
+		}
+		where = append(where, fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?))", column, cmp))
+		args = append(args, v, v, params.After.ID)
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM products WHERE %s ORDER BY %s %s, id %s",
+		productColumns, strings.Join(where, " AND "), column, dir, dir)
+	if params.Limit > 0 {
+		// one extra row tells whether there is a next page
+		query += " LIMIT ?"
+		args = append(args, params.Limit+1)
+	}
+	return query, args
+}
+
+// placeholders returns "?, ?, ..." for an IN list of n values
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func appendStrings(args []any, values []string) []any {
+	for _, v := range values {
+		args = append(args, v)
+	}
+	return args
+}
+
 func (r *MariaDBRepository) GetByID(ctx context.Context, id string) (*Product, error) {
-	query := `SELECT id, name, price, category FROM products WHERE id=?`
+	query := `SELECT ` + productColumns + ` FROM products WHERE id=? AND archived_at IS NULL`
 	args := []any{id}
 
 	logger.Info(ctx, "DB QueryRow start", "query", query, "args", args)
 
 	row := db.Pool.QueryRow(ctx, query, args...)
 
-	var p Product
-	err := row.Scan(&p.ID, &p.Name, &p.Price, &p.Category)
+	p, err := scanProduct(row)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			appErr := apperrors.NotFound(fmt.Sprintf("product not found with id %s", id), err)
@@ -68,5 +237,658 @@ func (r *MariaDBRepository) GetByID(ctx context.Context, id string) (*Product, e
 	}
 
 	logger.Info(ctx, "DB QueryRow completed", "id", id)
-	return &p, nil
+	return p, nil
+}
+
+// insertPriceQuery starts the price history of a new product
+const insertPriceQuery = `INSERT INTO product_prices (id, product_id, price, effective_from) VALUES (?, ?, ?, UTC_TIMESTAMP(6))`
+
+// recordPriceQuery adds the new price of an existing product to its history,
+// effective now. It runs before the product row is written and only inserts
+// a row when the price actually changes.
+const recordPriceQuery = `INSERT INTO product_prices (id, product_id, price, effective_from)
+	SELECT ?, id, ?, UTC_TIMESTAMP(6) FROM products WHERE id = ? AND price <> ? AND archived_at IS NULL`
+
+func (r *MariaDBRepository) Create(ctx context.Context, p *Product) (*Product, error) {
+	query := `INSERT INTO products (id, restaurant_id, name, price, category_id, description, available, version) VALUES (?, ?, ?, ?, ?, ?, ?, 1)`
+
+	err := db.Pool.InTx(ctx, func(tx *db.SQLTx) error {
+		if _, err := tx.Exec(ctx, query, p.ID, p.RestaurantID, p.Name, p.Price, p.CategoryID, p.Description, p.Available); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, insertPriceQuery, uuid.New().String(), p.ID, p.Price)
+		return err
+	})
+	if err != nil {
+		appErr := apperrors.Internal("failed to create product", err)
+		logger.Error(ctx, appErr.Message, "id", p.ID, "error", err.Error())
+		return nil, appErr
+	}
+
+	p.Version = 1
+	logger.Info(ctx, "product created", "id", p.ID)
+	return p, nil
+}
+
+func (r *MariaDBRepository) Update(ctx context.Context, p *Product) (*Product, error) {
+	query := `UPDATE products SET name=?, price=?, category_id=?, description=?, available=?, restaurant_id=?, version=version+1, updated_at=NOW()
+	          WHERE id=? AND version=? AND archived_at IS NULL`
+
+	err := db.Pool.InTx(ctx, func(tx *db.SQLTx) error {
+		if _, err := tx.Exec(ctx, recordPriceQuery, uuid.New().String(), p.Price, p.ID, p.Price); err != nil {
+			appErr := apperrors.Internal("failed to record price", err)
+			logger.Error(ctx, appErr.Message, "id", p.ID, "error", err.Error())
+			return appErr
+		}
+
+		res, err := tx.Exec(ctx, query, p.Name, p.Price, p.CategoryID, p.Description, p.Available, p.RestaurantID, p.ID, p.Version)
+		if err != nil {
+			appErr := apperrors.Internal("failed to update product", err)
+			logger.Error(ctx, appErr.Message, "id", p.ID, "error", err.Error())
+			return appErr
+		}
+		return r.checkVersioned(ctx, res, p.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p.Version++
+	logger.Info(ctx, "product updated", "id", p.ID, "version", p.Version)
+	return p, nil
+}
+
+func (r *MariaDBRepository) Archive(ctx context.Context, id string, version int) error {
+	query := `UPDATE products SET archived_at=NOW(), version=version+1, updated_at=NOW()
+	          WHERE id=? AND version=? AND archived_at IS NULL`
+
+	res, err := db.Pool.Exec(ctx, query, id, version)
+	if err != nil {
+		appErr := apperrors.Internal("failed to archive product", err)
+		logger.Error(ctx, appErr.Message, "id", id, "error", err.Error())
+		return appErr
+	}
+
+	if err := r.checkVersioned(ctx, res, id); err != nil {
+		return err
+	}
+
+	logger.Info(ctx, "product archived", "id", id)
+	return nil
+}
+
+func (r *MariaDBRepository) CategoryName(ctx context.Context, categoryID string) (string, error) {
+	var name string
+	err := db.Pool.QueryRow(ctx, `SELECT name FROM categories WHERE id = ?`, categoryID).Scan(&name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn(ctx, ErrUnknownCategory.Message, "categoryId", categoryID)
+			return "", ErrUnknownCategory
+		}
+		appErr := apperrors.Internal("failed to fetch category", err)
+		logger.Error(ctx, appErr.Message, "categoryId", categoryID, "error", err.Error())
+		return "", appErr
+	}
+	return name, nil
+}
+
+func (r *MariaDBRepository) RestaurantExists(ctx context.Context, restaurantID string) error {
+	var one int
+	err := db.Pool.QueryRow(ctx, `SELECT 1 FROM restaurants WHERE id = ?`, restaurantID).Scan(&one)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn(ctx, ErrUnknownRestaurant.Message, "restaurantId", restaurantID)
+			return ErrUnknownRestaurant
+		}
+		appErr := apperrors.Internal("failed to fetch restaurant", err)
+		logger.Error(ctx, appErr.Message, "restaurantId", restaurantID, "error", err.Error())
+		return appErr
+	}
+	return nil
+}
+
+func (r *MariaDBRepository) Categories(ctx context.Context) ([]CategoryRef, error) {
+	rows, err := db.Pool.Query(ctx, `SELECT id, slug, name FROM categories`)
+	if err != nil {
+		appErr := apperrors.Internal("failed to list categories", err)
+		logger.Error(ctx, appErr.Message, "error", err.Error())
+		return nil, appErr
+	}
+	defer rows.Close()
+
+	var categories []CategoryRef
+	for rows.Next() {
+		var c CategoryRef
+		if err := rows.Scan(&c.ID, &c.Slug, &c.Name); err != nil {
+			appErr := apperrors.Internal("failed to scan category row", err)
+			logger.Error(ctx, appErr.Message, "error", err.Error())
+			return nil, appErr
+		}
+		categories = append(categories, c)
+	}
+	if err := rows.Err(); err != nil {
+		appErr := apperrors.Internal("failed to read category rows", err)
+		logger.Error(ctx, appErr.Message, "error", err.Error())
+		return nil, appErr
+	}
+	return categories, nil
+}
+
+func (r *MariaDBRepository) ProductRestaurants(ctx context.Context, ids []string) (map[string]string, error) {
+	owners := make(map[string]string, len(ids))
+	if len(ids) == 0 {
+		return owners, nil
+	}
+
+	query := `SELECT id, restaurant_id FROM products WHERE id IN (` + placeholders(len(ids)) + `)`
+	rows, err := db.Pool.Query(ctx, query, appendStrings(nil, ids)...)
+	if err != nil {
+		appErr := apperrors.Internal("failed to look up products", err)
+		logger.Error(ctx, appErr.Message, "error", err.Error())
+		return nil, appErr
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, restaurantID string
+		if err := rows.Scan(&id, &restaurantID); err != nil {
+			appErr := apperrors.Internal("failed to scan product row", err)
+			logger.Error(ctx, appErr.Message, "error", err.Error())
+			return nil, appErr
+		}
+		owners[strings.ToLower(id)] = restaurantID
+	}
+	if err := rows.Err(); err != nil {
+		appErr := apperrors.Internal("failed to read product rows", err)
+		logger.Error(ctx, appErr.Message, "error", err.Error())
+		return nil, appErr
+	}
+	return owners, nil
+}
+
+func (r *MariaDBRepository) ApplyImport(ctx context.Context, plan *ImportPlan) error {
+	return db.Pool.InTx(ctx, func(tx *db.SQLTx) error {
+		insert := `INSERT INTO products (id, restaurant_id, name, price, category_id, description, available, version) VALUES (?, ?, ?, ?, ?, ?, ?, 1)`
+		for _, p := range plan.Create {
+			_, err := tx.Exec(ctx, insert, p.ID, p.RestaurantID, p.Name, p.Price, p.CategoryID, p.Description, p.Available)
+			if err == nil {
+				_, err = tx.Exec(ctx, insertPriceQuery, uuid.New().String(), p.ID, p.Price)
+			}
+			if err != nil {
+				appErr := apperrors.Internal("failed to import product", err)
+				logger.Error(ctx, appErr.Message, "id", p.ID, "error", err.Error())
+				return appErr
+			}
+		}
+
+		update := `UPDATE products SET name=?, price=?, category_id=?, description=?, available=?, version=version+1, updated_at=NOW()
+		           WHERE id=? AND version=? AND archived_at IS NULL`
+		for _, p := range plan.Update {
+			if _, err := tx.Exec(ctx, recordPriceQuery, uuid.New().String(), p.Price, p.ID, p.Price); err != nil {
+				appErr := apperrors.Internal("failed to import product", err)
+				logger.Error(ctx, appErr.Message, "id", p.ID, "error", err.Error())
+				return appErr
+			}
+			res, err := tx.Exec(ctx, update, p.Name, p.Price, p.CategoryID, p.Description, p.Available, p.ID, p.Version)
+			if err := importApplied(ctx, res, err, p.ID); err != nil {
+				return err
+			}
+		}
+
+		archive := `UPDATE products SET archived_at=NOW(), version=version+1, updated_at=NOW()
+		            WHERE id=? AND version=? AND archived_at IS NULL`
+		for _, p := range plan.Archive {
+			res, err := tx.Exec(ctx, archive, p.ID, p.Version)
+			if err := importApplied(ctx, res, err, p.ID); err != nil {
+				return err
+			}
+		}
+
+		logger.Info(ctx, "products imported", "created", len(plan.Create), "updated", len(plan.Update), "archived", len(plan.Archive))
+		return nil
+	})
+}
+
+// importApplied checks a versioned import write; the product changing since
+// the import was planned rolls the whole import back
+func importApplied(ctx context.Context, res sql.Result, err error, id string) error {
+	if err != nil {
+		appErr := apperrors.Internal("failed to import product", err)
+		logger.Error(ctx, appErr.Message, "id", id, "error", err.Error())
+		return appErr
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		appErr := apperrors.Internal("failed to read affected rows", err)
+		logger.Error(ctx, appErr.Message, "id", id, "error", err.Error())
+		return appErr
+	}
+	if n == 0 {
+		logger.Warn(ctx, ErrVersionConflict.Message, "id", id)
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// checkVersioned turns a versioned write that touched no row into 404 or 409
+func (r *MariaDBRepository) checkVersioned(ctx context.Context, res sql.Result, id string) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		appErr := apperrors.Internal("failed to read affected rows", err)
+		logger.Error(ctx, appErr.Message, "id", id, "error", err.Error())
+		return appErr
+	}
+	if n > 0 {
+		return nil
+	}
+
+	// Either the product is gone or the version is stale
+	if _, err := r.GetByID(ctx, id); err != nil {
+		return err
+	}
+	logger.Warn(ctx, ErrVersionConflict.Message, "id", id)
+	return ErrVersionConflict
+}
+
+func (r *MariaDBRepository) ModifierGroups(ctx context.Context, productID string) ([]ModifierGroup, error) {
+	query := `SELECT g.id, g.name, g.min_select, g.max_select, m.id, m.name, m.price_delta
+	          FROM modifier_groups g LEFT JOIN modifiers m ON m.group_id = g.id
+	          WHERE g.product_id = ?
+	          ORDER BY g.position, g.id, m.position, m.id`
+
+	rows, err := db.Pool.Query(ctx, query, productID)
+	if err != nil {
+		appErr := apperrors.Internal("failed to list modifier groups", err)
+		logger.Error(ctx, appErr.Message, "productId", productID, "error", err.Error())
+		return nil, appErr
+	}
+	defer rows.Close()
+
+	var groups []ModifierGroup
+	for rows.Next() {
+		var (
+			g          ModifierGroup
+			modID      sql.NullString
+			modName    sql.NullString
+			priceDelta sql.NullFloat64
+		)
+		if err := rows.Scan(&g.ID, &g.Name, &g.MinSelect, &g.MaxSelect, &modID, &modName, &priceDelta); err != nil {
+			appErr := apperrors.Internal("failed to scan modifier row", err)
+			logger.Error(ctx, appErr.Message, "productId", productID, "error", err.Error())
+			return nil, appErr
+		}
+
+		// rows arrive grouped, start a new group whenever the id changes
+		if len(groups) == 0 || groups[len(groups)-1].ID != g.ID {
+			g.Modifiers = []Modifier{}
+			groups = append(groups, g)
+		}
+		if modID.Valid {
+			last := &groups[len(groups)-1]
+			last.Modifiers = append(last.Modifiers, Modifier{ID: modID.String, Name: modName.String, PriceDelta: priceDelta.Float64})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		appErr := apperrors.Internal("failed to read modifier rows", err)
+		logger.Error(ctx, appErr.Message, "productId", productID, "error", err.Error())
+		return nil, appErr
+	}
+
+	return groups, nil
+}
+
+// ReplaceModifierGroups swaps all modifier groups of a product in one
+// transaction, so a failed insert leaves the previous groups in place
+func (r *MariaDBRepository) ReplaceModifierGroups(ctx context.Context, productID string, groups []ModifierGroup) error {
+	return db.Pool.InTx(ctx, func(tx *db.SQLTx) error {
+		exec := func(msg, query string, args ...any) error {
+			if _, err := tx.Exec(ctx, query, args...); err != nil {
+				appErr := apperrors.Internal(msg, err)
+				logger.Error(ctx, appErr.Message, "productId", productID, "error", err.Error())
+				return appErr
+			}
+			return nil
+		}
+
+		// modifiers go with their group through ON DELETE CASCADE
+		if err := exec("failed to remove modifier groups", `DELETE FROM modifier_groups WHERE product_id = ?`, productID); err != nil {
+			return err
+		}
+
+		groupQuery := `INSERT INTO modifier_groups (id, product_id, name, min_select, max_select, position) VALUES (?, ?, ?, ?, ?, ?)`
+		modifierQuery := `INSERT INTO modifiers (id, group_id, name, price_delta, position) VALUES (?, ?, ?, ?, ?)`
+
+		for i, g := range groups {
+			if err := exec("failed to insert modifier group", groupQuery, g.ID, productID, g.Name, g.MinSelect, g.MaxSelect, i); err != nil {
+				return err
+			}
+			for j, m := range g.Modifiers {
+				if err := exec("failed to insert modifier", modifierQuery, m.ID, g.ID, m.Name, m.PriceDelta, j); err != nil {
+					return err
+				}
+			}
+		}
+
+		logger.Info(ctx, "modifier groups replaced", "productId", productID, "groups", len(groups))
+		return nil
+	})
+}
+
+// ReplaceDietary swaps the allergens, dietary flags and nutrition facts of a
+// product in one transaction
+func (r *MariaDBRepository) ReplaceDietary(ctx context.Context, productID string, req *DietaryReq) error {
+	return db.Pool.InTx(ctx, func(tx *db.SQLTx) error {
+		exec := func(msg, query string, args ...any) error {
+			if _, err := tx.Exec(ctx, query, args...); err != nil {
+				appErr := apperrors.Internal(msg, err)
+				logger.Error(ctx, appErr.Message, "productId", productID, "error", err.Error())
+				return appErr
+			}
+			return nil
+		}
+
+		// allergens is required, so the product is declared even when it has none
+		if err := exec("failed to mark allergens declared", `UPDATE products SET allergens_declared_at = UTC_TIMESTAMP() WHERE id = ?`, productID); err != nil {
+			return err
+		}
+
+		for _, table := range []string{"product_allergens", "product_dietary_flags", "product_nutrition"} {
+			if err := exec("failed to remove dietary information", `DELETE FROM `+table+` WHERE product_id = ?`, productID); err != nil {
+				return err
+			}
+		}
+
+		for _, codes := range []struct {
+			table, column string
+			values        []string
+		}{
+			{"product_allergens", "allergen", req.Allergens},
+			{"product_dietary_flags", "flag", req.Dietary},
+		} {
+			if len(codes.values) == 0 {
+				continue
+			}
+			query := `INSERT INTO ` + codes.table + ` (product_id, ` + codes.column + `) VALUES ` +
+				strings.TrimSuffix(strings.Repeat("(?, ?), ", len(codes.values)), ", ")
+			args := make([]any, 0, 2*len(codes.values))
+			for _, v := range codes.values {
+				args = append(args, productID, v)
+			}
+			if err := exec("failed to insert "+codes.column+"s", query, args...); err != nil {
+				return err
+			}
+		}
+
+		if req.Nutrition != nil {
+			columns := []string{"product_id"}
+			args := []any{productID}
+			for _, v := range req.Nutrition.values() {
+				columns = append(columns, v.column)
+				args = append(args, v.value)
+			}
+			query := `INSERT INTO product_nutrition (` + strings.Join(columns, ", ") + `) VALUES (` + placeholders(len(columns)) + `)`
+			if err := exec("failed to insert nutrition facts", query, args...); err != nil {
+				return err
+			}
+		}
+
+		logger.Info(ctx, "dietary information replaced", "productId", productID, "allergens", len(req.Allergens), "dietary", len(req.Dietary))
+		return nil
+	})
+}
+
+// AdjustStock applies a stock operation. Stock is not versioned: it changes with
+// every order, so admin adjustments must not conflict with customers ordering.
+func (r *MariaDBRepository) AdjustStock(ctx context.Context, id string, req *StockReq) (*Product, error) {
+	var (
+		query string
+		args  []any
+	)
+	switch {
+	case req.Stock != nil:
+		query = `UPDATE products SET stock=?, updated_at=NOW() WHERE id=? AND archived_at IS NULL`
+		args = []any{*req.Stock, id}
+	case req.Delta != nil:
+		query = `UPDATE products SET stock=stock+?, updated_at=NOW()
+		         WHERE id=? AND archived_at IS NULL AND stock IS NOT NULL AND stock+? >= 0`
+		args = []any{*req.Delta, id, *req.Delta}
+	default:
+		query = `UPDATE products SET stock=NULL, updated_at=NOW() WHERE id=? AND archived_at IS NULL`
+		args = []any{id}
+	}
+
+	res, err := db.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		appErr := apperrors.Internal("failed to adjust stock", err)
+		logger.Error(ctx, appErr.Message, "id", id, "error", err.Error())
+		return nil, appErr
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		appErr := apperrors.Internal("failed to read affected rows", err)
+		logger.Error(ctx, appErr.Message, "id", id, "error", err.Error())
+		return nil, appErr
+	}
+
+	// RowsAffected is also 0 when the value did not change, so always reload
+	// and work out from the current row what happened
+	p, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 && req.Delta != nil {
+		if p.Stock == nil {
+			return nil, ErrStockUntracked
+		}
+		return nil, ErrStockNegative.WithDetails(map[string]int{"stock": *p.Stock})
+	}
+
+	logger.Info(ctx, "product stock adjusted", "id", id)
+	return p, nil
+}
+
+func (r *MariaDBRepository) PriceHistory(ctx context.Context, productID string) ([]PriceChange, error) {
+	query := `SELECT id, price, effective_from FROM product_prices WHERE product_id = ? ORDER BY effective_from, id`
+
+	rows, err := db.Pool.Query(ctx, query, productID)
+	if err != nil {
+		appErr := apperrors.Internal("failed to list price history", err)
+		logger.Error(ctx, appErr.Message, "productId", productID, "error", err.Error())
+		return nil, appErr
+	}
+	defer rows.Close()
+
+	history := []PriceChange{}
+	for rows.Next() {
+		var c PriceChange
+		if err := rows.Scan(&c.ID, &c.Price, &c.EffectiveFrom); err != nil {
+			appErr := apperrors.Internal("failed to scan price row", err)
+			logger.Error(ctx, appErr.Message, "productId", productID, "error", err.Error())
+			return nil, appErr
+		}
+		history = append(history, c)
+	}
+	if err := rows.Err(); err != nil {
+		appErr := apperrors.Internal("failed to read price rows", err)
+		logger.Error(ctx, appErr.Message, "productId", productID, "error", err.Error())
+		return nil, appErr
+	}
+
+	return history, nil
+}
+
+func (r *MariaDBRepository) SchedulePrice(ctx context.Context, productID string, change *PriceChange) error {
+	query := `INSERT INTO product_prices (id, product_id, price, effective_from) VALUES (?, ?, ?, ?)`
+
+	if _, err := db.Pool.Exec(ctx, query, change.ID, productID, change.Price, change.EffectiveFrom.UTC()); err != nil {
+		appErr := apperrors.Internal("failed to schedule price change", err)
+		logger.Error(ctx, appErr.Message, "productId", productID, "error", err.Error())
+		return appErr
+	}
+
+	logger.Info(ctx, "price change scheduled", "productId", productID, "id", change.ID, "effectiveFrom", change.EffectiveFrom)
+	return nil
+}
+
+func (r *MariaDBRepository) DeleteScheduledPrice(ctx context.Context, productID, changeID string) error {
+	query := `DELETE FROM product_prices WHERE id = ? AND product_id = ? AND effective_from > UTC_TIMESTAMP(6)`
+
+	res, err := db.Pool.Exec(ctx, query, changeID, productID)
+	if err != nil {
+		appErr := apperrors.Internal("failed to delete price change", err)
+		logger.Error(ctx, appErr.Message, "productId", productID, "id", changeID, "error", err.Error())
+		return appErr
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		appErr := apperrors.Internal("failed to read affected rows", err)
+		logger.Error(ctx, appErr.Message, "productId", productID, "id", changeID, "error", err.Error())
+		return appErr
+	}
+	if n > 0 {
+		logger.Info(ctx, "scheduled price change deleted", "productId", productID, "id", changeID)
+		return nil
+	}
+
+	// Either there is no such change or it is already in effect
+	var exists int
+	err = db.Pool.QueryRow(ctx, `SELECT 1 FROM product_prices WHERE id = ? AND product_id = ?`, changeID, productID).Scan(&exists)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		logger.Warn(ctx, ErrPriceChangeNotFound.Message, "productId", productID, "id", changeID)
+		return ErrPriceChangeNotFound
+	case err != nil:
+		appErr := apperrors.Internal("failed to fetch price change", err)
+		logger.Error(ctx, appErr.Message, "productId", productID, "id", changeID, "error", err.Error())
+		return appErr
+	}
+	logger.Warn(ctx, ErrPriceChangeApplied.Message, "productId", productID, "id", changeID)
+	return ErrPriceChangeApplied
+}
+
+// ApplyDuePrices bumps the version of every product it changes, so an admin
+// edit based on the old price fails with ErrVersionConflict
+func (r *MariaDBRepository) ApplyDuePrices(ctx context.Context) (int64, error) {
+	query := `UPDATE products p
+	          JOIN product_prices pp ON pp.product_id = p.id AND pp.effective_from = (
+	            SELECT MAX(x.effective_from) FROM product_prices x
+	            WHERE x.product_id = p.id AND x.effective_from <= UTC_TIMESTAMP(6))
+	          SET p.price = pp.price, p.version = p.version + 1, p.updated_at = NOW()
+	          WHERE p.price <> pp.price AND p.archived_at IS NULL`
+
+	res, err := db.Pool.Exec(ctx, query)
+	if err != nil {
+		appErr := apperrors.Internal("failed to apply scheduled prices", err)
+		logger.Error(ctx, appErr.Message, "error", err.Error())
+		return 0, appErr
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		appErr := apperrors.Internal("failed to read affected rows", err)
+		logger.Error(ctx, appErr.Message, "error", err.Error())
+		return 0, appErr
+	}
+	return n, nil
+}
+
+func (r *MariaDBRepository) PriceRules(ctx context.Context) ([]PriceRule, error) {
+	query := `SELECT r.id, r.name, COALESCE(r.product_id, ''), COALESCE(r.category_id, ''), r.percent_off, r.timezone,
+	                 w.days, w.start_time, w.end_time
+	          FROM price_rules r JOIN price_rule_windows w ON w.rule_id = r.id
+	          ORDER BY r.created_at, r.id, w.position`
+
+	rows, err := db.Pool.Query(ctx, query)
+	if err != nil {
+		appErr := apperrors.Internal("failed to list price rules", err)
+		logger.Error(ctx, appErr.Message, "error", err.Error())
+		return nil, appErr
+	}
+	defer rows.Close()
+
+	rules := []PriceRule{}
+	for rows.Next() {
+		var (
+			rule PriceRule
+			w    schedule.Window
+			days string
+		)
+		if err := rows.Scan(&rule.ID, &rule.Name, &rule.ProductID, &rule.CategoryID, &rule.PercentOff, &rule.TimeZone,
+			&days, &w.Start, &w.End); err != nil {
+			appErr := apperrors.Internal("failed to scan price rule row", err)
+			logger.Error(ctx, appErr.Message, "error", err.Error())
+			return nil, appErr
+		}
+		if days != "" {
+			w.Days = strings.Split(days, ",")
+		}
+
+		// rows arrive grouped, start a new rule whenever the id changes
+		if len(rules) == 0 || rules[len(rules)-1].ID != rule.ID {
+			rules = append(rules, rule)
+		}
+		last := &rules[len(rules)-1]
+		last.Windows = append(last.Windows, w)
+	}
+	if err := rows.Err(); err != nil {
+		appErr := apperrors.Internal("failed to read price rule rows", err)
+		logger.Error(ctx, appErr.Message, "error", err.Error())
+		return nil, appErr
+	}
+
+	return rules, nil
+}
+
+func (r *MariaDBRepository) CreatePriceRule(ctx context.Context, rule *PriceRule) error {
+	return db.Pool.InTx(ctx, func(tx *db.SQLTx) error {
+		query := `INSERT INTO price_rules (id, name, product_id, category_id, percent_off, timezone) VALUES (?, ?, ?, ?, ?, ?)`
+		if _, err := tx.Exec(ctx, query, rule.ID, rule.Name, nullString(rule.ProductID), nullString(rule.CategoryID),
+			rule.PercentOff, rule.TimeZone); err != nil {
+			appErr := apperrors.Internal("failed to create price rule", err)
+			logger.Error(ctx, appErr.Message, "id", rule.ID, "error", err.Error())
+			return appErr
+		}
+
+		query = `INSERT INTO price_rule_windows (rule_id, position, days, start_time, end_time) VALUES ` +
+			strings.TrimSuffix(strings.Repeat("(?, ?, ?, ?, ?), ", len(rule.Windows)), ", ")
+		args := make([]any, 0, 5*len(rule.Windows))
+		for i, w := range rule.Windows {
+			args = append(args, rule.ID, i, strings.ToLower(strings.Join(w.Days, ",")), w.Start, w.End)
+		}
+		if _, err := tx.Exec(ctx, query, args...); err != nil {
+			appErr := apperrors.Internal("failed to insert price rule windows", err)
+			logger.Error(ctx, appErr.Message, "id", rule.ID, "error", err.Error())
+			return appErr
+		}
+
+		logger.Info(ctx, "price rule created", "id", rule.ID, "windows", len(rule.Windows))
+		return nil
+	})
+}
+
+func (r *MariaDBRepository) DeletePriceRule(ctx context.Context, id string) error {
+	res, err := db.Pool.Exec(ctx, `DELETE FROM price_rules WHERE id = ?`, id)
+	if err != nil {
+		appErr := apperrors.Internal("failed to delete price rule", err)
+		logger.Error(ctx, appErr.Message, "id", id, "error", err.Error())
+		return appErr
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		appErr := apperrors.Internal("failed to read affected rows", err)
+		logger.Error(ctx, appErr.Message, "id", id, "error", err.Error())
+		return appErr
+	}
+	if n == 0 {
+		logger.Warn(ctx, ErrPriceRuleNotFound.Message, "id", id)
+		return ErrPriceRuleNotFound
+	}
+
+	logger.Info(ctx, "price rule deleted", "id", id)
+	return nil
+}
+
+// nullString stores an empty optional id as NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
 }
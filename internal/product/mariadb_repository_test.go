@@ -4,12 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/mohammadshabab/order-food-online/internal/apperrors"
 	"github.com/mohammadshabab/order-food-online/internal/db"
 	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/mohammadshabab/order-food-online/internal/schedule"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -24,24 +27,24 @@ func TestMariaDBRepository_List(t *testing.T) {
 	repo := NewMariaDBRepository()
 
 	t.Run("success", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"id", "name", "price", "category"}).
-			AddRow("p1", "Burger", 150, "Food").
-			AddRow("p2", "Pizza", 200, "Food")
+		rows := sqlmock.NewRows([]string{"id", "name", "price", "category_id", "category", "description", "available", "stock", "version", "allergens", "dietary", "nutrition", "restaurant_id", "allergens_declared"}).
+			AddRow("p1", "Burger", 150, "c1", "Food", "", true, nil, 1, "", "", nil, DefaultRestaurantID, false).
+			AddRow("p2", "Pizza", 200, "c1", "Food", "", true, nil, 1, "", "", nil, DefaultRestaurantID, false)
 
-		mock.ExpectQuery("SELECT id, name, price, category FROM products").
+		mock.ExpectQuery("SELECT (.+) FROM products WHERE archived_at IS NULL").
 			WillReturnRows(rows)
 
-		products, err := repo.List(ctx)
+		products, err := repo.List(ctx, ListParams{})
 		assert.NoError(t, err)
 		assert.Len(t, products, 2)
 		assert.Equal(t, "p1", products[0].ID)
 	})
 
 	t.Run("query fails", func(t *testing.T) {
-		mock.ExpectQuery("SELECT id, name, price, category FROM products").
+		mock.ExpectQuery("SELECT (.+) FROM products WHERE archived_at IS NULL").
 			WillReturnError(errors.New("db failed"))
 
-		products, err := repo.List(ctx)
+		products, err := repo.List(ctx, ListParams{})
 		assert.Nil(t, products)
 
 		appErr, ok := err.(*apperrors.AppError)
@@ -51,13 +54,13 @@ func TestMariaDBRepository_List(t *testing.T) {
 
 	t.Run("scan fails", func(t *testing.T) {
 		// NULL values force scan error
-		rows := sqlmock.NewRows([]string{"id", "name", "price", "category"}).
-			AddRow(nil, nil, nil, nil)
+		rows := sqlmock.NewRows([]string{"id", "name", "price", "category_id", "category", "description", "available", "stock", "version", "allergens", "dietary", "nutrition", "restaurant_id", "allergens_declared"}).
+			AddRow(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 
-		mock.ExpectQuery("SELECT id, name, price, category FROM products").
+		mock.ExpectQuery("SELECT (.+) FROM products WHERE archived_at IS NULL").
 			WillReturnRows(rows)
 
-		products, err := repo.List(ctx)
+		products, err := repo.List(ctx, ListParams{})
 		assert.Nil(t, products)
 
 		appErr, ok := err.(*apperrors.AppError)
@@ -67,6 +70,92 @@ func TestMariaDBRepository_List(t *testing.T) {
 		// Updated: sqlmock returns this new message format
 		assert.Contains(t, appErr.Err.Error(), "converting NULL")
 	})
+
+	t.Run("row iteration fails", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "name", "price", "category_id", "category", "description", "available", "stock", "version", "allergens", "dietary", "nutrition", "restaurant_id", "allergens_declared"}).
+			AddRow("p1", "Burger", 150, "c1", "Food", "", true, nil, 1, "", "", nil, DefaultRestaurantID, false).
+			RowError(0, errors.New("connection reset"))
+
+		mock.ExpectQuery("SELECT (.+) FROM products WHERE archived_at IS NULL").
+			WillReturnRows(rows)
+
+		products, err := repo.List(ctx, ListParams{})
+		assert.Nil(t, products)
+
+		appErr, ok := err.(*apperrors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, "failed to read product rows", appErr.Message)
+	})
+}
+
+func TestBuildListQuery(t *testing.T) {
+	minPrice, maxPrice := 100.0, 200.0
+
+	t.Run("defaults", func(t *testing.T) {
+		query, args := buildListQuery(ListParams{Sort: SortName, Order: OrderAsc})
+		assert.Equal(t, "SELECT "+productColumns+" FROM products WHERE archived_at IS NULL ORDER BY name ASC, id ASC", query)
+		assert.Empty(t, args)
+	})
+
+	t.Run("filters and limit", func(t *testing.T) {
+		query, args := buildListQuery(ListParams{
+			Category: "Pizza", MinPrice: &minPrice, MaxPrice: &maxPrice, Name: "50%_off",
+			Sort: SortPrice, Order: OrderDesc, Limit: 10,
+		})
+		assert.Equal(t, "SELECT "+productColumns+" FROM products WHERE archived_at IS NULL AND category_id IN (SELECT id FROM categories WHERE slug = ?) AND price >= ? AND price <= ? AND name LIKE ? ORDER BY price DESC, id DESC LIMIT ?", query)
+		assert.Equal(t, []any{"pizza", 100.0, 200.0, `%50\%\_off%`, 11}, args)
+	})
+
+	t.Run("restaurant", func(t *testing.T) {
+		query, args := buildListQuery(ListParams{RestaurantID: DefaultRestaurantID, Category: "pizza", Sort: SortName, Order: OrderAsc})
+		assert.Contains(t, query, "WHERE archived_at IS NULL AND restaurant_id = ? AND category_id IN")
+		assert.Equal(t, []any{DefaultRestaurantID, "pizza"}, args)
+	})
+
+	t.Run("cursor continues after last row", func(t *testing.T) {
+		query, args := buildListQuery(ListParams{
+			Sort: SortPrice, Order: OrderAsc, Limit: 2,
+			After: &Cursor{Sort: SortPrice, Order: OrderAsc, Price: 150, ID: "p1"},
+		})
+		assert.Contains(t, query, "(price > ? OR (price = ? AND id > ?))")
+		assert.Equal(t, []any{150.0, 150.0, "p1", 3}, args)
+	})
+
+	t.Run("available now with schedule exclusions", func(t *testing.T) {
+		query, args := buildListQuery(ListParams{
+			Sort: SortName, Order: OrderAsc, AvailableNow: true,
+			Exclusions: &schedule.Exclusions{
+				Closed:     []string{restaurantID},
+				ProductIDs: []string{"p1", "p2"},
+				Categories: []string{"breakfast"},
+				Overrides:  []string{"p3"},
+			},
+		})
+		assert.Contains(t, query, "WHERE archived_at IS NULL AND available = TRUE AND restaurant_id NOT IN (?) AND id NOT IN (?, ?) AND (id IN (?) OR COALESCE(category_id, '') NOT IN (?)) ORDER BY")
+		assert.Equal(t, []any{restaurantID, "p1", "p2", "p3", "breakfast"}, args)
+	})
+
+	t.Run("allergens and dietary flags", func(t *testing.T) {
+		query, args := buildListQuery(ListParams{
+			Sort: SortName, Order: OrderAsc,
+			ExcludeAllergens: []string{"gluten", "nuts"}, Dietary: []string{"vegan", "halal"},
+		})
+		assert.Contains(t, query, "WHERE archived_at IS NULL"+
+			" AND allergens_declared_at IS NOT NULL"+
+			" AND id NOT IN (SELECT product_id FROM product_allergens WHERE allergen IN (?, ?))"+
+			" AND id IN (SELECT product_id FROM product_dietary_flags WHERE flag = ?)"+
+			" AND id IN (SELECT product_id FROM product_dietary_flags WHERE flag = ?) ORDER BY")
+		assert.Equal(t, []any{"gluten", "nuts", "vegan", "halal"}, args)
+	})
+
+	t.Run("category exclusions without overrides", func(t *testing.T) {
+		query, args := buildListQuery(ListParams{
+			Sort: SortName, Order: OrderAsc, AvailableNow: true,
+			Exclusions: &schedule.Exclusions{Categories: []string{"breakfast", "drinks"}},
+		})
+		assert.Contains(t, query, "AND available = TRUE AND COALESCE(category_id, '') NOT IN (?, ?) ORDER BY")
+		assert.Equal(t, []any{"breakfast", "drinks"}, args)
+	})
 }
 
 func TestMariaDBRepository_GetByID(t *testing.T) {
@@ -80,10 +169,10 @@ func TestMariaDBRepository_GetByID(t *testing.T) {
 	repo := NewMariaDBRepository()
 
 	t.Run("success", func(t *testing.T) {
-		row := sqlmock.NewRows([]string{"id", "name", "price", "category"}).
-			AddRow("p1", "Burger", 150, "Food")
+		row := sqlmock.NewRows([]string{"id", "name", "price", "category_id", "category", "description", "available", "stock", "version", "allergens", "dietary", "nutrition", "restaurant_id", "allergens_declared"}).
+			AddRow("p1", "Burger", 150, "c1", "Food", "", true, nil, 1, "", "", nil, DefaultRestaurantID, false)
 
-		mock.ExpectQuery("SELECT id, name, price, category FROM products WHERE id=?").
+		mock.ExpectQuery("SELECT (.+) FROM products WHERE id=\\? AND archived_at IS NULL").
 			WithArgs("p1").
 			WillReturnRows(row)
 
@@ -93,8 +182,24 @@ func TestMariaDBRepository_GetByID(t *testing.T) {
 		assert.Equal(t, "p1", product.ID)
 	})
 
+	t.Run("allergens, flags and nutrition", func(t *testing.T) {
+		row := sqlmock.NewRows([]string{"id", "name", "price", "category_id", "category", "description", "available", "stock", "version", "allergens", "dietary", "nutrition", "restaurant_id", "allergens_declared"}).
+			AddRow("p1", "Burger", 150, "c1", "Food", "", true, nil, 1, "gluten,milk", "halal", `{"servingSizeG": 250.00, "energyKcal": 540.00, "fat": null}`, DefaultRestaurantID, true)
+
+		mock.ExpectQuery("SELECT (.+) FROM products WHERE id=\\? AND archived_at IS NULL").
+			WithArgs("p1").
+			WillReturnRows(row)
+
+		product, err := repo.GetByID(ctx, "p1")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"gluten", "milk"}, product.Allergens)
+		assert.True(t, product.AllergensDeclared)
+		assert.Equal(t, []string{"halal"}, product.Dietary)
+		assert.Equal(t, &Nutrition{ServingSizeG: floatPtr(250), EnergyKcal: floatPtr(540)}, product.Nutrition)
+	})
+
 	t.Run("not found", func(t *testing.T) {
-		mock.ExpectQuery("SELECT id, name, price, category FROM products WHERE id=?").
+		mock.ExpectQuery("SELECT (.+) FROM products WHERE id=\\? AND archived_at IS NULL").
 			WithArgs("p999").
 			WillReturnError(sql.ErrNoRows)
 
@@ -111,3 +216,711 @@ func TestMariaDBRepository_GetByID(t *testing.T) {
 		assert.Contains(t, appErr.Err.Error(), "sql: no rows")
 	})
 }
+
+func TestMariaDBRepository_Create(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	db.Pool = db.NewTestPool(sqlDB)
+
+	repo := NewMariaDBRepository()
+
+	t.Run("success starts the price history", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO products").
+			WithArgs("p1", DefaultRestaurantID, "Burger", 150.0, "c1", "", true).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO product_prices \\(id, product_id, price, effective_from\\) VALUES").
+			WithArgs(sqlmock.AnyArg(), "p1", 150.0).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		p, err := repo.Create(ctx, &Product{ID: "p1", RestaurantID: DefaultRestaurantID, Name: "Burger", Price: 150, CategoryID: "c1", Available: true})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, p.Version)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("insert fails", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO products").WillReturnError(errors.New("db failed"))
+		mock.ExpectRollback()
+
+		p, err := repo.Create(ctx, &Product{ID: "p1"})
+		assert.Nil(t, p)
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMariaDBRepository_Update(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	db.Pool = db.NewTestPool(sqlDB)
+
+	repo := NewMariaDBRepository()
+	cols := []string{"id", "name", "price", "category_id", "category", "description", "available", "stock", "version", "allergens", "dietary", "nutrition", "restaurant_id", "allergens_declared"}
+
+	t.Run("success records a changed price and bumps version", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO product_prices (.+) SELECT (.+) FROM products WHERE id = \\? AND price <> \\?").
+			WithArgs(sqlmock.AnyArg(), 160.0, "p1", 160.0).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("UPDATE products SET name=\\?, price=\\?, category_id=\\?, description=\\?, available=\\?, restaurant_id=\\?, version=version\\+1").
+			WithArgs("Burger", 160.0, "c1", "", true, DefaultRestaurantID, "p1", 2).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		p, err := repo.Update(ctx, &Product{ID: "p1", RestaurantID: DefaultRestaurantID, Name: "Burger", Price: 160, CategoryID: "c1", Available: true, Version: 2})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, p.Version)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("stale version rolls the price back", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO product_prices").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("UPDATE products SET name").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT (.+) FROM products WHERE id").
+			WithArgs("p1").
+			WillReturnRows(sqlmock.NewRows(cols).AddRow("p1", "Burger", 160, "c1", "Food", "", true, nil, 3, "", "", nil, DefaultRestaurantID, false))
+		mock.ExpectRollback()
+
+		p, err := repo.Update(ctx, &Product{ID: "p1", Version: 2})
+		assert.Nil(t, p)
+		assert.Equal(t, ErrVersionConflict, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("product missing or archived", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO product_prices").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("UPDATE products SET name").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT (.+) FROM products WHERE id").
+			WithArgs("p9").
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		_, err := repo.Update(ctx, &Product{ID: "p9", Version: 1})
+		appErr, ok := err.(*apperrors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, 404, appErr.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMariaDBRepository_CategoryName(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	db.Pool = db.NewTestPool(sqlDB)
+
+	repo := NewMariaDBRepository()
+
+	t.Run("found", func(t *testing.T) {
+		mock.ExpectQuery("SELECT name FROM categories WHERE id = \\?").
+			WithArgs("c1").
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Burgers"))
+
+		name, err := repo.CategoryName(ctx, "c1")
+		assert.NoError(t, err)
+		assert.Equal(t, "Burgers", name)
+	})
+
+	t.Run("unknown category", func(t *testing.T) {
+		mock.ExpectQuery("SELECT name FROM categories").WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.CategoryName(ctx, "c9")
+		assert.Equal(t, ErrUnknownCategory, err)
+	})
+
+	t.Run("query fails", func(t *testing.T) {
+		mock.ExpectQuery("SELECT name FROM categories").WillReturnError(errors.New("db failed"))
+
+		_, err := repo.CategoryName(ctx, "c1")
+		appErr, ok := err.(*apperrors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, 500, appErr.Code)
+	})
+}
+
+func TestMariaDBRepository_RestaurantExists(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	db.Pool = db.NewTestPool(sqlDB)
+
+	repo := NewMariaDBRepository()
+
+	mock.ExpectQuery("SELECT 1 FROM restaurants WHERE id = \\?").
+		WithArgs(DefaultRestaurantID).
+		WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+	assert.NoError(t, repo.RestaurantExists(ctx, DefaultRestaurantID))
+
+	mock.ExpectQuery("SELECT 1 FROM restaurants").WillReturnError(sql.ErrNoRows)
+	assert.Equal(t, ErrUnknownRestaurant, repo.RestaurantExists(ctx, "r9"))
+
+	mock.ExpectQuery("SELECT 1 FROM restaurants").WillReturnError(errors.New("db failed"))
+	appErr, ok := repo.RestaurantExists(ctx, "r1").(*apperrors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, 500, appErr.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMariaDBRepository_Archive(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	db.Pool = db.NewTestPool(sqlDB)
+
+	repo := NewMariaDBRepository()
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectExec("UPDATE products SET archived_at=NOW\\(\\)").
+			WithArgs("p1", 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.Archive(ctx, "p1", 3))
+	})
+
+	t.Run("exec fails", func(t *testing.T) {
+		mock.ExpectExec("UPDATE products SET archived_at").WillReturnError(errors.New("db failed"))
+
+		assert.Error(t, repo.Archive(ctx, "p1", 3))
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMariaDBRepository_ModifierGroups(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	db.Pool = db.NewTestPool(sqlDB)
+
+	repo := NewMariaDBRepository()
+	cols := []string{"g.id", "g.name", "g.min_select", "g.max_select", "m.id", "m.name", "m.price_delta"}
+
+	t.Run("groups rows by group", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM modifier_groups g LEFT JOIN modifiers m").
+			WithArgs("p1").
+			WillReturnRows(sqlmock.NewRows(cols).
+				AddRow("g1", "Size", 1, 1, "m1", "Small", 0).
+				AddRow("g1", "Size", 1, 1, "m2", "Large", 3).
+				AddRow("g2", "Extras", 0, 2, nil, nil, nil))
+
+		groups, err := repo.ModifierGroups(ctx, "p1")
+		assert.NoError(t, err)
+		assert.Equal(t, []ModifierGroup{
+			{ID: "g1", Name: "Size", MinSelect: 1, MaxSelect: 1, Modifiers: []Modifier{
+				{ID: "m1", Name: "Small"},
+				{ID: "m2", Name: "Large", PriceDelta: 3},
+			}},
+			{ID: "g2", Name: "Extras", MinSelect: 0, MaxSelect: 2, Modifiers: []Modifier{}},
+		}, groups)
+	})
+
+	t.Run("query fails", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM modifier_groups").WillReturnError(errors.New("db down"))
+
+		groups, err := repo.ModifierGroups(ctx, "p1")
+		assert.Nil(t, groups)
+
+		appErr, ok := err.(*apperrors.AppError)
+		assert.True(t, ok)
+		assert.Contains(t, appErr.Err.Error(), "db down")
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMariaDBRepository_ReplaceModifierGroups(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	db.Pool = db.NewTestPool(sqlDB)
+
+	repo := NewMariaDBRepository()
+	groups := []ModifierGroup{{ID: "g1", Name: "Size", MinSelect: 1, MaxSelect: 1, Modifiers: []Modifier{
+		{ID: "m1", Name: "Small"},
+		{ID: "m2", Name: "Large", PriceDelta: 3},
+	}}}
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("DELETE FROM modifier_groups WHERE product_id = \\?").
+			WithArgs("p1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO modifier_groups").
+			WithArgs("g1", "p1", "Size", 1, 1, 0).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO modifiers").
+			WithArgs("m1", "g1", "Small", 0.0, 0).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO modifiers").
+			WithArgs("m2", "g1", "Large", 3.0, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, repo.ReplaceModifierGroups(ctx, "p1", groups))
+	})
+
+	t.Run("group insert fails rolls back", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("DELETE FROM modifier_groups").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO modifier_groups").WillReturnError(errors.New("db down"))
+		mock.ExpectRollback()
+
+		assert.ErrorContains(t, repo.ReplaceModifierGroups(ctx, "p1", groups), "db down")
+	})
+
+	t.Run("modifier insert fails rolls back", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("DELETE FROM modifier_groups").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO modifier_groups").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO modifiers").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO modifiers").WillReturnError(errors.New("duplicate modifier"))
+		mock.ExpectRollback()
+
+		assert.ErrorContains(t, repo.ReplaceModifierGroups(ctx, "p1", groups), "duplicate modifier")
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMariaDBRepository_AdjustStock(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	db.Pool = db.NewTestPool(sqlDB)
+
+	repo := NewMariaDBRepository()
+	cols := []string{"id", "name", "price", "category_id", "category", "description", "available", "stock", "version", "allergens", "dietary", "nutrition", "restaurant_id", "allergens_declared"}
+	stock := func(n int) *int { return &n }
+
+	t.Run("set", func(t *testing.T) {
+		mock.ExpectExec("UPDATE products SET stock=\\?").
+			WithArgs(12, "p1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("SELECT (.+) FROM products WHERE id").
+			WithArgs("p1").
+			WillReturnRows(sqlmock.NewRows(cols).AddRow("p1", "Burger", 150, "c1", "Food", "", true, 12, 1, "", "", nil, DefaultRestaurantID, false))
+
+		p, err := repo.AdjustStock(ctx, "p1", &StockReq{Stock: stock(12)})
+		assert.NoError(t, err)
+		assert.Equal(t, 12, *p.Stock)
+	})
+
+	t.Run("delta", func(t *testing.T) {
+		mock.ExpectExec("UPDATE products SET stock=stock\\+\\?").
+			WithArgs(-2, "p1", -2).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("SELECT (.+) FROM products WHERE id").
+			WithArgs("p1").
+			WillReturnRows(sqlmock.NewRows(cols).AddRow("p1", "Burger", 150, "c1", "Food", "", true, 10, 1, "", "", nil, DefaultRestaurantID, false))
+
+		p, err := repo.AdjustStock(ctx, "p1", &StockReq{Delta: stock(-2)})
+		assert.NoError(t, err)
+		assert.Equal(t, 10, *p.Stock)
+	})
+
+	t.Run("delta below zero", func(t *testing.T) {
+		mock.ExpectExec("UPDATE products SET stock=stock").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT (.+) FROM products WHERE id").
+			WillReturnRows(sqlmock.NewRows(cols).AddRow("p1", "Burger", 150, "c1", "Food", "", true, 1, 1, "", "", nil, DefaultRestaurantID, false))
+
+		_, err := repo.AdjustStock(ctx, "p1", &StockReq{Delta: stock(-2)})
+		appErr, ok := err.(*apperrors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, 409, appErr.Code)
+		assert.Equal(t, ErrStockNegative.Message, appErr.Message)
+		assert.Equal(t, map[string]int{"stock": 1}, appErr.Details)
+	})
+
+	t.Run("delta on untracked product", func(t *testing.T) {
+		mock.ExpectExec("UPDATE products SET stock=stock").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT (.+) FROM products WHERE id").
+			WillReturnRows(sqlmock.NewRows(cols).AddRow("p1", "Burger", 150, "c1", "Food", "", true, nil, 1, "", "", nil, DefaultRestaurantID, false))
+
+		_, err := repo.AdjustStock(ctx, "p1", &StockReq{Delta: stock(5)})
+		assert.Equal(t, ErrStockUntracked, err)
+	})
+
+	t.Run("untrack", func(t *testing.T) {
+		mock.ExpectExec("UPDATE products SET stock=NULL").
+			WithArgs("p1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("SELECT (.+) FROM products WHERE id").
+			WillReturnRows(sqlmock.NewRows(cols).AddRow("p1", "Burger", 150, "c1", "Food", "", true, nil, 1, "", "", nil, DefaultRestaurantID, false))
+
+		p, err := repo.AdjustStock(ctx, "p1", &StockReq{Untracked: true})
+		assert.NoError(t, err)
+		assert.Nil(t, p.Stock)
+	})
+
+	t.Run("product missing", func(t *testing.T) {
+		mock.ExpectExec("UPDATE products SET stock=\\?").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT (.+) FROM products WHERE id").
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.AdjustStock(ctx, "p9", &StockReq{Stock: stock(1)})
+		assert.Equal(t, 404, err.(*apperrors.AppError).Code)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMariaDBRepository_ProductRestaurants(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	db.Pool = db.NewTestPool(sqlDB)
+
+	repo := NewMariaDBRepository()
+
+	owners, err := repo.ProductRestaurants(ctx, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, owners)
+
+	mock.ExpectQuery("SELECT id, restaurant_id FROM products WHERE id IN \\(\\?, \\?\\)").
+		WithArgs(goneID, "p9").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "restaurant_id"}).AddRow(strings.ToUpper(goneID), DefaultRestaurantID))
+	owners, err = repo.ProductRestaurants(ctx, []string{goneID, "p9"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{goneID: DefaultRestaurantID}, owners)
+
+	mock.ExpectQuery("SELECT id, restaurant_id FROM products").WillReturnError(errors.New("db failed"))
+	_, err = repo.ProductRestaurants(ctx, []string{"p1"})
+	appErr, ok := err.(*apperrors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, 500, appErr.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMariaDBRepository_Categories(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	db.Pool = db.NewTestPool(sqlDB)
+
+	repo := NewMariaDBRepository()
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, slug, name FROM categories").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "slug", "name"}).AddRow("c1", "pizza", "Pizza"))
+
+		categories, err := repo.Categories(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []CategoryRef{{ID: "c1", Slug: "pizza", Name: "Pizza"}}, categories)
+	})
+
+	t.Run("query fails", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, slug, name FROM categories").WillReturnError(errors.New("db down"))
+
+		_, err := repo.Categories(ctx)
+		assert.ErrorContains(t, err, "db down")
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMariaDBRepository_ApplyImport(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	db.Pool = db.NewTestPool(sqlDB)
+
+	repo := NewMariaDBRepository()
+	plan := &ImportPlan{
+		Create:  []*Product{{ID: "p3", RestaurantID: DefaultRestaurantID, Name: "Diavola", Price: 11, CategoryID: "c1", Available: true}},
+		Update:  []*Product{{ID: "p1", Name: "Margherita", Price: 9.5, CategoryID: "c1", Available: false, Version: 4}},
+		Archive: []*Product{{ID: "p2", Version: 1}},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO products").
+			WithArgs("p3", DefaultRestaurantID, "Diavola", 11.0, "c1", "", true).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO product_prices (.+) VALUES").
+			WithArgs(sqlmock.AnyArg(), "p3", 11.0).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO product_prices (.+) SELECT").
+			WithArgs(sqlmock.AnyArg(), 9.5, "p1", 9.5).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE products SET name=\\?").
+			WithArgs("Margherita", 9.5, "c1", "", false, "p1", 4).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE products SET archived_at=NOW\\(\\)").
+			WithArgs("p2", 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, repo.ApplyImport(ctx, plan))
+	})
+
+	t.Run("product changed since planning", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO products").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO product_prices").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO product_prices").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("UPDATE products SET name=\\?").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		assert.Equal(t, ErrVersionConflict, repo.ApplyImport(ctx, plan))
+	})
+
+	t.Run("insert fails", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO products").WillReturnError(errors.New("duplicate entry"))
+		mock.ExpectRollback()
+
+		assert.ErrorContains(t, repo.ApplyImport(ctx, plan), "duplicate entry")
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMariaDBRepository_ReplaceDietary(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	db.Pool = db.NewTestPool(sqlDB)
+
+	repo := NewMariaDBRepository()
+	deleted := func() {
+		mock.ExpectExec("UPDATE products SET allergens_declared_at = UTC_TIMESTAMP\\(\\) WHERE id = \\?").WithArgs("p1").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("DELETE FROM product_allergens").WithArgs("p1").WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("DELETE FROM product_dietary_flags").WithArgs("p1").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("DELETE FROM product_nutrition").WithArgs("p1").WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectBegin()
+		deleted()
+		mock.ExpectExec("INSERT INTO product_allergens \\(product_id, allergen\\) VALUES \\(\\?, \\?\\), \\(\\?, \\?\\)").
+			WithArgs("p1", "gluten", "p1", "milk").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("INSERT INTO product_dietary_flags").
+			WithArgs("p1", "vegetarian").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO product_nutrition \\(product_id, serving_size_g, energy_kcal, fat_g").
+			WithArgs("p1", nil, 820.0, nil, nil, nil, nil, nil, nil, nil).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repo.ReplaceDietary(ctx, "p1", &DietaryReq{
+			Allergens: []string{"gluten", "milk"},
+			Dietary:   []string{"vegetarian"},
+			Nutrition: &Nutrition{EnergyKcal: floatPtr(820)},
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("clear everything", func(t *testing.T) {
+		mock.ExpectBegin()
+		deleted()
+		mock.ExpectCommit()
+
+		assert.NoError(t, repo.ReplaceDietary(ctx, "p1", &DietaryReq{Allergens: []string{}, Dietary: []string{}}))
+	})
+
+	t.Run("insert fails", func(t *testing.T) {
+		mock.ExpectBegin()
+		deleted()
+		mock.ExpectExec("INSERT INTO product_allergens").WillReturnError(errors.New("db down"))
+		mock.ExpectRollback()
+
+		assert.ErrorContains(t, repo.ReplaceDietary(ctx, "p1", &DietaryReq{Allergens: []string{"eggs"}}), "db down")
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMariaDBRepository_PriceHistory(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	db.Pool = db.NewTestPool(sqlDB)
+
+	repo := NewMariaDBRepository()
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("history in order", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, price, effective_from FROM product_prices WHERE product_id = \\? ORDER BY effective_from").
+			WithArgs("p1").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "price", "effective_from"}).
+				AddRow("h1", 9.5, from).
+				AddRow("h2", 10.5, from.AddDate(0, 1, 0)))
+
+		history, err := repo.PriceHistory(ctx, "p1")
+		assert.NoError(t, err)
+		assert.Equal(t, []PriceChange{
+			{ID: "h1", Price: 9.5, EffectiveFrom: from},
+			{ID: "h2", Price: 10.5, EffectiveFrom: from.AddDate(0, 1, 0)},
+		}, history)
+	})
+
+	t.Run("history query fails", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, price, effective_from FROM product_prices").WillReturnError(errors.New("db down"))
+
+		history, err := repo.PriceHistory(ctx, "p1")
+		assert.Nil(t, history)
+
+		appErr, ok := err.(*apperrors.AppError)
+		assert.True(t, ok)
+		assert.Contains(t, appErr.Err.Error(), "db down")
+	})
+
+	t.Run("schedule", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO product_prices \\(id, product_id, price, effective_from\\) VALUES \\(\\?, \\?, \\?, \\?\\)").
+			WithArgs("h3", "p1", 11.0, from).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.SchedulePrice(ctx, "p1", &PriceChange{ID: "h3", Price: 11, EffectiveFrom: from}))
+	})
+
+	t.Run("delete scheduled change", func(t *testing.T) {
+		mock.ExpectExec("DELETE FROM product_prices WHERE id = \\? AND product_id = \\? AND effective_from > UTC_TIMESTAMP").
+			WithArgs("h3", "p1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.DeleteScheduledPrice(ctx, "p1", "h3"))
+	})
+
+	t.Run("delete change already in effect", func(t *testing.T) {
+		mock.ExpectExec("DELETE FROM product_prices").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT 1 FROM product_prices WHERE id = \\? AND product_id = \\?").
+			WithArgs("h1", "p1").
+			WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+
+		assert.Equal(t, ErrPriceChangeApplied, repo.DeleteScheduledPrice(ctx, "p1", "h1"))
+	})
+
+	t.Run("delete unknown change", func(t *testing.T) {
+		mock.ExpectExec("DELETE FROM product_prices").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT 1 FROM product_prices").WillReturnError(sql.ErrNoRows)
+
+		assert.Equal(t, ErrPriceChangeNotFound, repo.DeleteScheduledPrice(ctx, "p1", "h9"))
+	})
+
+	t.Run("apply due prices", func(t *testing.T) {
+		mock.ExpectExec("UPDATE products p\\s+JOIN product_prices pp").
+			WillReturnResult(sqlmock.NewResult(0, 3))
+
+		n, err := repo.ApplyDuePrices(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+	})
+
+	t.Run("apply due prices fails", func(t *testing.T) {
+		mock.ExpectExec("UPDATE products p").WillReturnError(errors.New("lock wait timeout"))
+
+		_, err := repo.ApplyDuePrices(ctx)
+		assert.ErrorContains(t, err, "lock wait timeout")
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMariaDBRepository_PriceRules(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	db.Pool = db.NewTestPool(sqlDB)
+
+	repo := NewMariaDBRepository()
+	cols := []string{"id", "name", "product_id", "category_id", "percent_off", "timezone", "days", "start_time", "end_time"}
+
+	t.Run("rules with their windows", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM price_rules r JOIN price_rule_windows w").
+			WillReturnRows(sqlmock.NewRows(cols).
+				AddRow("r1", "Happy hour", "", "c1", 20, "Europe/Berlin", "mon,tue", "17:00", "19:00").
+				AddRow("r1", "Happy hour", "", "c1", 20, "Europe/Berlin", "", "22:00", "23:00").
+				AddRow("r2", "Lunch deal", "p1", "", 10, "UTC", "", "12:00", "14:00"))
+
+		rules, err := repo.PriceRules(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []PriceRule{
+			{ID: "r1", Name: "Happy hour", CategoryID: "c1", PercentOff: 20, TimeZone: "Europe/Berlin", Windows: []schedule.Window{
+				{Days: []string{"mon", "tue"}, Start: "17:00", End: "19:00"},
+				{Start: "22:00", End: "23:00"},
+			}},
+			{ID: "r2", Name: "Lunch deal", ProductID: "p1", PercentOff: 10, TimeZone: "UTC", Windows: []schedule.Window{
+				{Start: "12:00", End: "14:00"},
+			}},
+		}, rules)
+	})
+
+	t.Run("rules query fails", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM price_rules").WillReturnError(errors.New("db down"))
+
+		rules, err := repo.PriceRules(ctx)
+		assert.Nil(t, rules)
+
+		appErr, ok := err.(*apperrors.AppError)
+		assert.True(t, ok)
+		assert.Contains(t, appErr.Err.Error(), "db down")
+	})
+
+	t.Run("create", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO price_rules").
+			WithArgs("r3", "Happy hour", nil, "c1", 25.0, "UTC").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO price_rule_windows \\(rule_id, position, days, start_time, end_time\\) VALUES \\(\\?, \\?, \\?, \\?, \\?\\), \\(").
+			WithArgs("r3", 0, "fri", "17:00", "19:00", "r3", 1, "", "22:00", "23:00").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		err := repo.CreatePriceRule(ctx, &PriceRule{ID: "r3", Name: "Happy hour", CategoryID: "c1", PercentOff: 25, TimeZone: "UTC",
+			Windows: []schedule.Window{{Days: []string{"Fri"}, Start: "17:00", End: "19:00"}, {Start: "22:00", End: "23:00"}}})
+		assert.NoError(t, err)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		mock.ExpectExec("DELETE FROM price_rules WHERE id = \\?").WithArgs("r3").WillReturnResult(sqlmock.NewResult(0, 1))
+		assert.NoError(t, repo.DeletePriceRule(ctx, "r3"))
+	})
+
+	t.Run("delete unknown rule", func(t *testing.T) {
+		mock.ExpectExec("DELETE FROM price_rules").WithArgs("r9").WillReturnResult(sqlmock.NewResult(0, 0))
+		assert.Equal(t, ErrPriceRuleNotFound, repo.DeletePriceRule(ctx, "r9"))
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -34,6 +34,151 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AdjustStock mocks base method.
+func (m *MockRepository) AdjustStock(ctx context.Context, id string, req *StockReq) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustStock", ctx, id, req)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdjustStock indicates an expected call of AdjustStock.
+func (mr *MockRepositoryMockRecorder) AdjustStock(ctx, id, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustStock", reflect.TypeOf((*MockRepository)(nil).AdjustStock), ctx, id, req)
+}
+
+// ApplyDuePrices mocks base method.
+func (m *MockRepository) ApplyDuePrices(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyDuePrices", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApplyDuePrices indicates an expected call of ApplyDuePrices.
+func (mr *MockRepositoryMockRecorder) ApplyDuePrices(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyDuePrices", reflect.TypeOf((*MockRepository)(nil).ApplyDuePrices), ctx)
+}
+
+// ApplyImport mocks base method.
+func (m *MockRepository) ApplyImport(ctx context.Context, plan *ImportPlan) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyImport", ctx, plan)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApplyImport indicates an expected call of ApplyImport.
+func (mr *MockRepositoryMockRecorder) ApplyImport(ctx, plan interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyImport", reflect.TypeOf((*MockRepository)(nil).ApplyImport), ctx, plan)
+}
+
+// Archive mocks base method.
+func (m *MockRepository) Archive(ctx context.Context, id string, version int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Archive", ctx, id, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Archive indicates an expected call of Archive.
+func (mr *MockRepositoryMockRecorder) Archive(ctx, id, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Archive", reflect.TypeOf((*MockRepository)(nil).Archive), ctx, id, version)
+}
+
+// Categories mocks base method.
+func (m *MockRepository) Categories(ctx context.Context) ([]CategoryRef, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Categories", ctx)
+	ret0, _ := ret[0].([]CategoryRef)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Categories indicates an expected call of Categories.
+func (mr *MockRepositoryMockRecorder) Categories(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Categories", reflect.TypeOf((*MockRepository)(nil).Categories), ctx)
+}
+
+// CategoryName mocks base method.
+func (m *MockRepository) CategoryName(ctx context.Context, categoryID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CategoryName", ctx, categoryID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CategoryName indicates an expected call of CategoryName.
+func (mr *MockRepositoryMockRecorder) CategoryName(ctx, categoryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CategoryName", reflect.TypeOf((*MockRepository)(nil).CategoryName), ctx, categoryID)
+}
+
+// Create mocks base method.
+func (m *MockRepository) Create(ctx context.Context, p *Product) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, p)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockRepositoryMockRecorder) Create(ctx, p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), ctx, p)
+}
+
+// CreatePriceRule mocks base method.
+func (m *MockRepository) CreatePriceRule(ctx context.Context, rule *PriceRule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePriceRule", ctx, rule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePriceRule indicates an expected call of CreatePriceRule.
+func (mr *MockRepositoryMockRecorder) CreatePriceRule(ctx, rule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePriceRule", reflect.TypeOf((*MockRepository)(nil).CreatePriceRule), ctx, rule)
+}
+
+// DeletePriceRule mocks base method.
+func (m *MockRepository) DeletePriceRule(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePriceRule", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePriceRule indicates an expected call of DeletePriceRule.
+func (mr *MockRepositoryMockRecorder) DeletePriceRule(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePriceRule", reflect.TypeOf((*MockRepository)(nil).DeletePriceRule), ctx, id)
+}
+
+// DeleteScheduledPrice mocks base method.
+func (m *MockRepository) DeleteScheduledPrice(ctx context.Context, productID, changeID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteScheduledPrice", ctx, productID, changeID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteScheduledPrice indicates an expected call of DeleteScheduledPrice.
+func (mr *MockRepositoryMockRecorder) DeleteScheduledPrice(ctx, productID, changeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteScheduledPrice", reflect.TypeOf((*MockRepository)(nil).DeleteScheduledPrice), ctx, productID, changeID)
+}
+
 // GetByID mocks base method.
 func (m *MockRepository) GetByID(ctx context.Context, id string) (*Product, error) {
 	m.ctrl.T.Helper()
@@ -50,16 +195,147 @@ func (mr *MockRepositoryMockRecorder) GetByID(ctx, id interface{}) *gomock.Call
 }
 
 // List mocks base method.
-func (m *MockRepository) List(ctx context.Context) ([]*Product, error) {
+func (m *MockRepository) List(ctx context.Context, params ListParams) ([]*Product, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", ctx)
+	ret := m.ctrl.Call(m, "List", ctx, params)
 	ret0, _ := ret[0].([]*Product)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // List indicates an expected call of List.
-func (mr *MockRepositoryMockRecorder) List(ctx interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) List(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRepository)(nil).List), ctx, params)
+}
+
+// ModifierGroups mocks base method.
+func (m *MockRepository) ModifierGroups(ctx context.Context, productID string) ([]ModifierGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ModifierGroups", ctx, productID)
+	ret0, _ := ret[0].([]ModifierGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ModifierGroups indicates an expected call of ModifierGroups.
+func (mr *MockRepositoryMockRecorder) ModifierGroups(ctx, productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModifierGroups", reflect.TypeOf((*MockRepository)(nil).ModifierGroups), ctx, productID)
+}
+
+// PriceHistory mocks base method.
+func (m *MockRepository) PriceHistory(ctx context.Context, productID string) ([]PriceChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PriceHistory", ctx, productID)
+	ret0, _ := ret[0].([]PriceChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PriceHistory indicates an expected call of PriceHistory.
+func (mr *MockRepositoryMockRecorder) PriceHistory(ctx, productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PriceHistory", reflect.TypeOf((*MockRepository)(nil).PriceHistory), ctx, productID)
+}
+
+// PriceRules mocks base method.
+func (m *MockRepository) PriceRules(ctx context.Context) ([]PriceRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PriceRules", ctx)
+	ret0, _ := ret[0].([]PriceRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PriceRules indicates an expected call of PriceRules.
+func (mr *MockRepositoryMockRecorder) PriceRules(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PriceRules", reflect.TypeOf((*MockRepository)(nil).PriceRules), ctx)
+}
+
+// ProductRestaurants mocks base method.
+func (m *MockRepository) ProductRestaurants(ctx context.Context, ids []string) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProductRestaurants", ctx, ids)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProductRestaurants indicates an expected call of ProductRestaurants.
+func (mr *MockRepositoryMockRecorder) ProductRestaurants(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProductRestaurants", reflect.TypeOf((*MockRepository)(nil).ProductRestaurants), ctx, ids)
+}
+
+// ReplaceDietary mocks base method.
+func (m *MockRepository) ReplaceDietary(ctx context.Context, productID string, req *DietaryReq) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceDietary", ctx, productID, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceDietary indicates an expected call of ReplaceDietary.
+func (mr *MockRepositoryMockRecorder) ReplaceDietary(ctx, productID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceDietary", reflect.TypeOf((*MockRepository)(nil).ReplaceDietary), ctx, productID, req)
+}
+
+// ReplaceModifierGroups mocks base method.
+func (m *MockRepository) ReplaceModifierGroups(ctx context.Context, productID string, groups []ModifierGroup) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceModifierGroups", ctx, productID, groups)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceModifierGroups indicates an expected call of ReplaceModifierGroups.
+func (mr *MockRepositoryMockRecorder) ReplaceModifierGroups(ctx, productID, groups interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceModifierGroups", reflect.TypeOf((*MockRepository)(nil).ReplaceModifierGroups), ctx, productID, groups)
+}
+
+// RestaurantExists mocks base method.
+func (m *MockRepository) RestaurantExists(ctx context.Context, restaurantID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestaurantExists", ctx, restaurantID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestaurantExists indicates an expected call of RestaurantExists.
+func (mr *MockRepositoryMockRecorder) RestaurantExists(ctx, restaurantID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestaurantExists", reflect.TypeOf((*MockRepository)(nil).RestaurantExists), ctx, restaurantID)
+}
+
+// SchedulePrice mocks base method.
+func (m *MockRepository) SchedulePrice(ctx context.Context, productID string, change *PriceChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SchedulePrice", ctx, productID, change)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SchedulePrice indicates an expected call of SchedulePrice.
+func (mr *MockRepositoryMockRecorder) SchedulePrice(ctx, productID, change interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SchedulePrice", reflect.TypeOf((*MockRepository)(nil).SchedulePrice), ctx, productID, change)
+}
+
+// Update mocks base method.
+func (m *MockRepository) Update(ctx context.Context, p *Product) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, p)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockRepositoryMockRecorder) Update(ctx, p interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRepository)(nil).List), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRepository)(nil).Update), ctx, p)
 }
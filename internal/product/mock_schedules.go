@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: schedules.go
+
+// Package product is a generated GoMock package.
+package product
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	schedule "github.com/mohammadshabab/order-food-online/internal/schedule"
+)
+
+// MockSchedules is a mock of Schedules interface.
+type MockSchedules struct {
+	ctrl     *gomock.Controller
+	recorder *MockSchedulesMockRecorder
+}
+
+// MockSchedulesMockRecorder is the mock recorder for MockSchedules.
+type MockSchedulesMockRecorder struct {
+	mock *MockSchedules
+}
+
+// NewMockSchedules creates a new mock instance.
+func NewMockSchedules(ctrl *gomock.Controller) *MockSchedules {
+	mock := &MockSchedules{ctrl: ctrl}
+	mock.recorder = &MockSchedulesMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSchedules) EXPECT() *MockSchedulesMockRecorder {
+	return m.recorder
+}
+
+// Exclusions mocks base method.
+func (m *MockSchedules) Exclusions(ctx context.Context) (*schedule.Exclusions, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Exclusions", ctx)
+	ret0, _ := ret[0].(*schedule.Exclusions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Exclusions indicates an expected call of Exclusions.
+func (mr *MockSchedulesMockRecorder) Exclusions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exclusions", reflect.TypeOf((*MockSchedules)(nil).Exclusions), ctx)
+}
@@ -6,7 +6,9 @@ package product
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -34,6 +36,137 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// AdjustStock mocks base method.
+func (m *MockService) AdjustStock(ctx context.Context, id string, req *StockReq) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustStock", ctx, id, req)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdjustStock indicates an expected call of AdjustStock.
+func (mr *MockServiceMockRecorder) AdjustStock(ctx, id, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustStock", reflect.TypeOf((*MockService)(nil).AdjustStock), ctx, id, req)
+}
+
+// ApplyDuePrices mocks base method.
+func (m *MockService) ApplyDuePrices(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyDuePrices", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApplyDuePrices indicates an expected call of ApplyDuePrices.
+func (mr *MockServiceMockRecorder) ApplyDuePrices(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyDuePrices", reflect.TypeOf((*MockService)(nil).ApplyDuePrices), ctx)
+}
+
+// ArchiveProduct mocks base method.
+func (m *MockService) ArchiveProduct(ctx context.Context, id string, version int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ArchiveProduct", ctx, id, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ArchiveProduct indicates an expected call of ArchiveProduct.
+func (mr *MockServiceMockRecorder) ArchiveProduct(ctx, id, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveProduct", reflect.TypeOf((*MockService)(nil).ArchiveProduct), ctx, id, version)
+}
+
+// CancelPriceChange mocks base method.
+func (m *MockService) CancelPriceChange(ctx context.Context, id, changeID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelPriceChange", ctx, id, changeID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelPriceChange indicates an expected call of CancelPriceChange.
+func (mr *MockServiceMockRecorder) CancelPriceChange(ctx, id, changeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelPriceChange", reflect.TypeOf((*MockService)(nil).CancelPriceChange), ctx, id, changeID)
+}
+
+// CreatePriceRule mocks base method.
+func (m *MockService) CreatePriceRule(ctx context.Context, req *PriceRuleReq) (*PriceRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePriceRule", ctx, req)
+	ret0, _ := ret[0].(*PriceRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePriceRule indicates an expected call of CreatePriceRule.
+func (mr *MockServiceMockRecorder) CreatePriceRule(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePriceRule", reflect.TypeOf((*MockService)(nil).CreatePriceRule), ctx, req)
+}
+
+// CreateProduct mocks base method.
+func (m *MockService) CreateProduct(ctx context.Context, req *ProductReq) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProduct", ctx, req)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateProduct indicates an expected call of CreateProduct.
+func (mr *MockServiceMockRecorder) CreateProduct(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProduct", reflect.TypeOf((*MockService)(nil).CreateProduct), ctx, req)
+}
+
+// DeletePriceRule mocks base method.
+func (m *MockService) DeletePriceRule(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePriceRule", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePriceRule indicates an expected call of DeletePriceRule.
+func (mr *MockServiceMockRecorder) DeletePriceRule(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePriceRule", reflect.TypeOf((*MockService)(nil).DeletePriceRule), ctx, id)
+}
+
+// EffectivePrice mocks base method.
+func (m *MockService) EffectivePrice(ctx context.Context, p *Product, at time.Time) (*PriceQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EffectivePrice", ctx, p, at)
+	ret0, _ := ret[0].(*PriceQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EffectivePrice indicates an expected call of EffectivePrice.
+func (mr *MockServiceMockRecorder) EffectivePrice(ctx, p, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EffectivePrice", reflect.TypeOf((*MockService)(nil).EffectivePrice), ctx, p, at)
+}
+
+// ExportProducts mocks base method.
+func (m *MockService) ExportProducts(ctx context.Context, restaurantID, format string, w io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportProducts", ctx, restaurantID, format, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportProducts indicates an expected call of ExportProducts.
+func (mr *MockServiceMockRecorder) ExportProducts(ctx, restaurantID, format, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportProducts", reflect.TypeOf((*MockService)(nil).ExportProducts), ctx, restaurantID, format, w)
+}
+
 // GetProduct mocks base method.
 func (m *MockService) GetProduct(ctx context.Context, id string) (*Product, error) {
 	m.ctrl.T.Helper()
@@ -49,17 +182,167 @@ func (mr *MockServiceMockRecorder) GetProduct(ctx, id interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProduct", reflect.TypeOf((*MockService)(nil).GetProduct), ctx, id)
 }
 
-// ListProducts mocks base method.
-func (m *MockService) ListProducts(ctx context.Context) ([]*Product, error) {
+// ImportProducts mocks base method.
+func (m *MockService) ImportProducts(ctx context.Context, restaurantID, format string, r io.Reader, dryRun bool) (*ImportResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListProducts", ctx)
+	ret := m.ctrl.Call(m, "ImportProducts", ctx, restaurantID, format, r, dryRun)
+	ret0, _ := ret[0].(*ImportResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportProducts indicates an expected call of ImportProducts.
+func (mr *MockServiceMockRecorder) ImportProducts(ctx, restaurantID, format, r, dryRun interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportProducts", reflect.TypeOf((*MockService)(nil).ImportProducts), ctx, restaurantID, format, r, dryRun)
+}
+
+// ListAll mocks base method.
+func (m *MockService) ListAll(ctx context.Context, params ListParams) ([]*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAll", ctx, params)
 	ret0, _ := ret[0].([]*Product)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
+// ListAll indicates an expected call of ListAll.
+func (mr *MockServiceMockRecorder) ListAll(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAll", reflect.TypeOf((*MockService)(nil).ListAll), ctx, params)
+}
+
+// ListPriceRules mocks base method.
+func (m *MockService) ListPriceRules(ctx context.Context) ([]PriceRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPriceRules", ctx)
+	ret0, _ := ret[0].([]PriceRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPriceRules indicates an expected call of ListPriceRules.
+func (mr *MockServiceMockRecorder) ListPriceRules(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPriceRules", reflect.TypeOf((*MockService)(nil).ListPriceRules), ctx)
+}
+
+// ListProducts mocks base method.
+func (m *MockService) ListProducts(ctx context.Context, params ListParams) (*ProductPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProducts", ctx, params)
+	ret0, _ := ret[0].(*ProductPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // ListProducts indicates an expected call of ListProducts.
-func (mr *MockServiceMockRecorder) ListProducts(ctx interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) ListProducts(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProducts", reflect.TypeOf((*MockService)(nil).ListProducts), ctx, params)
+}
+
+// PatchProduct mocks base method.
+func (m *MockService) PatchProduct(ctx context.Context, id string, req *ProductReq) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchProduct", ctx, id, req)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchProduct indicates an expected call of PatchProduct.
+func (mr *MockServiceMockRecorder) PatchProduct(ctx, id, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchProduct", reflect.TypeOf((*MockService)(nil).PatchProduct), ctx, id, req)
+}
+
+// PriceHistory mocks base method.
+func (m *MockService) PriceHistory(ctx context.Context, id string) ([]PriceChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PriceHistory", ctx, id)
+	ret0, _ := ret[0].([]PriceChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PriceHistory indicates an expected call of PriceHistory.
+func (mr *MockServiceMockRecorder) PriceHistory(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PriceHistory", reflect.TypeOf((*MockService)(nil).PriceHistory), ctx, id)
+}
+
+// ReplaceProduct mocks base method.
+func (m *MockService) ReplaceProduct(ctx context.Context, id string, req *ProductReq) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceProduct", ctx, id, req)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplaceProduct indicates an expected call of ReplaceProduct.
+func (mr *MockServiceMockRecorder) ReplaceProduct(ctx, id, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceProduct", reflect.TypeOf((*MockService)(nil).ReplaceProduct), ctx, id, req)
+}
+
+// SchedulePrice mocks base method.
+func (m *MockService) SchedulePrice(ctx context.Context, id string, req *PriceChangeReq) (*PriceChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SchedulePrice", ctx, id, req)
+	ret0, _ := ret[0].(*PriceChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SchedulePrice indicates an expected call of SchedulePrice.
+func (mr *MockServiceMockRecorder) SchedulePrice(ctx, id, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SchedulePrice", reflect.TypeOf((*MockService)(nil).SchedulePrice), ctx, id, req)
+}
+
+// SearchProducts mocks base method.
+func (m *MockService) SearchProducts(ctx context.Context, q string, limit int) (*SearchResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchProducts", ctx, q, limit)
+	ret0, _ := ret[0].(*SearchResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchProducts indicates an expected call of SearchProducts.
+func (mr *MockServiceMockRecorder) SearchProducts(ctx, q, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProducts", reflect.TypeOf((*MockService)(nil).SearchProducts), ctx, q, limit)
+}
+
+// SetDietary mocks base method.
+func (m *MockService) SetDietary(ctx context.Context, id string, req *DietaryReq) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDietary", ctx, id, req)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetDietary indicates an expected call of SetDietary.
+func (mr *MockServiceMockRecorder) SetDietary(ctx, id, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDietary", reflect.TypeOf((*MockService)(nil).SetDietary), ctx, id, req)
+}
+
+// SetModifierGroups mocks base method.
+func (m *MockService) SetModifierGroups(ctx context.Context, id string, req *ModifierGroupsReq) (*Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetModifierGroups", ctx, id, req)
+	ret0, _ := ret[0].(*Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetModifierGroups indicates an expected call of SetModifierGroups.
+func (mr *MockServiceMockRecorder) SetModifierGroups(ctx, id, req interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProducts", reflect.TypeOf((*MockService)(nil).ListProducts), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetModifierGroups", reflect.TypeOf((*MockService)(nil).SetModifierGroups), ctx, id, req)
 }
@@ -1,8 +1,129 @@
 package product
 
+import "github.com/mohammadshabab/order-food-online/internal/restaurant/defaults"
+
+// Allergens are the 14 allergens EU food law (Regulation 1169/2011) requires
+// to be declared, by the codes the API uses for them
+var Allergens = []string{
+	"celery", "crustaceans", "eggs", "fish", "gluten", "lupin", "milk",
+	"molluscs", "mustard", "nuts", "peanuts", "sesame", "soya", "sulphites",
+}
+
+// DietaryFlags are the diets a product can be marked as suitable for
+var DietaryFlags = []string{"halal", "kosher", "vegan", "vegetarian"}
+
+// DefaultRestaurantID is the restaurant products belong to when none is
+// named. Migration 0013 creates it and assigns every older product to it.
+const DefaultRestaurantID = defaults.RestaurantID
+
 type Product struct {
-	ID       string  `json:"id"`
-	Name     string  `json:"name"`
-	Price    float64 `json:"price"`
-	Category string  `json:"category"`
+	ID           string  `json:"id"`
+	RestaurantID string  `json:"restaurantId"`
+	Name         string  `json:"name"`
+	Price        float64 `json:"price"`
+	CategoryID   string  `json:"categoryId,omitempty"`
+	Category     string  `json:"category"` // display name of the category, read only
+	Description  string  `json:"description,omitempty"`
+	Available    bool    `json:"available"`
+	// Stock is nil when the product is not stock tracked
+	Stock   *int `json:"stock,omitempty"`
+	Version int  `json:"version"`
+
+	// Allergens are codes from Allergens, Dietary codes from DietaryFlags.
+	// Both are always sent. AllergensDeclared tells an empty Allergens list of a
+	// product declared free of allergens from one nobody declared yet.
+	Allergens         []string   `json:"allergens"`
+	AllergensDeclared bool       `json:"allergensDeclared"`
+	Dietary           []string   `json:"dietary"`
+	Nutrition         *Nutrition `json:"nutrition,omitempty"`
+
+	// ModifierGroups is only loaded for single product reads
+	ModifierGroups []ModifierGroup `json:"modifierGroups,omitempty"`
+}
+
+// ModifierGroup is a set of options for a product, e.g. "Size" or "Extra toppings".
+// A customer picks between MinSelect and MaxSelect of its modifiers.
+type ModifierGroup struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	MinSelect int        `json:"minSelect"`
+	MaxSelect int        `json:"maxSelect"`
+	Modifiers []Modifier `json:"modifiers"`
+}
+
+// Modifier is a single option whose PriceDelta is added to the product price
+type Modifier struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	PriceDelta float64 `json:"priceDelta"`
+}
+
+// ProductReq is the body of POST, PUT and PATCH /product.
+// Fields are pointers so PATCH can tell "not sent" from "empty".
+type ProductReq struct {
+	// RestaurantID is optional, new products go to DefaultRestaurantID
+	RestaurantID *string  `json:"restaurantId,omitempty"`
+	Name         *string  `json:"name"`
+	Price        *float64 `json:"price"`
+	CategoryID   *string  `json:"categoryId"`
+	Description  *string  `json:"description,omitempty"`
+	Available    *bool    `json:"available,omitempty"`
+	Version      *int     `json:"version,omitempty"`
+}
+
+// Nutrition facts per serving. Every value is optional; weights are in grams.
+type Nutrition struct {
+	ServingSizeG  *float64 `json:"servingSizeG,omitempty"`
+	EnergyKcal    *float64 `json:"energyKcal,omitempty"`
+	Fat           *float64 `json:"fat,omitempty"`
+	SaturatedFat  *float64 `json:"saturatedFat,omitempty"`
+	Carbohydrates *float64 `json:"carbohydrates,omitempty"`
+	Sugars        *float64 `json:"sugars,omitempty"`
+	Fibre         *float64 `json:"fibre,omitempty"`
+	Protein       *float64 `json:"protein,omitempty"`
+	Salt          *float64 `json:"salt,omitempty"`
+}
+
+type nutritionValue struct {
+	name   string // JSON name, also used in messages
+	column string
+	value  *float64
+}
+
+// values lists every nutrition fact with its product_nutrition column
+func (n *Nutrition) values() []nutritionValue {
+	return []nutritionValue{
+		{"servingSizeG", "serving_size_g", n.ServingSizeG},
+		{"energyKcal", "energy_kcal", n.EnergyKcal},
+		{"fat", "fat_g", n.Fat},
+		{"saturatedFat", "saturated_fat_g", n.SaturatedFat},
+		{"carbohydrates", "carbohydrates_g", n.Carbohydrates},
+		{"sugars", "sugars_g", n.Sugars},
+		{"fibre", "fibre_g", n.Fibre},
+		{"protein", "protein_g", n.Protein},
+		{"salt", "salt_g", n.Salt},
+	}
+}
+
+// DietaryReq is the body of PUT /product/{productId}/dietary. It replaces the
+// allergens, dietary flags and nutrition facts of the product; allergens must
+// be sent even when empty so a missing declaration is never stored by accident.
+type DietaryReq struct {
+	Allergens []string   `json:"allergens"`
+	Dietary   []string   `json:"dietary"`
+	Nutrition *Nutrition `json:"nutrition"`
+}
+
+// ModifierGroupsReq is the body of PUT /product/{productId}/modifiers.
+// It replaces all modifier groups of the product; ids are assigned by the server.
+type ModifierGroupsReq struct {
+	Groups []ModifierGroup `json:"groups"`
+}
+
+// StockReq is the body of POST /product/{productId}/stock. Exactly one of the
+// fields is used: set an absolute count, adjust by a delta, or stop tracking.
+type StockReq struct {
+	Stock     *int `json:"stock,omitempty"`
+	Delta     *int `json:"delta,omitempty"`
+	Untracked bool `json:"untracked,omitempty"`
 }
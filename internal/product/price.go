@@ -0,0 +1,130 @@
+package product
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/mohammadshabab/order-food-online/internal/schedule"
+)
+
+// PriceChange is a row of the price history. Price applies from EffectiveFrom
+// until the next change; changes that are still in the future are scheduled.
+type PriceChange struct {
+	ID            string    `json:"id"`
+	Price         float64   `json:"price"`
+	EffectiveFrom time.Time `json:"effectiveFrom"`
+	Scheduled     bool      `json:"scheduled"`
+}
+
+// PriceChangeReq is the body of POST /product/{productId}/prices
+type PriceChangeReq struct {
+	Price         *float64   `json:"price"`
+	EffectiveFrom *time.Time `json:"effectiveFrom"`
+}
+
+// PriceRule takes PercentOff the price while any of its windows is on, e.g. a
+// happy hour. It applies to one product, one category or, with neither set,
+// to the whole menu. Windows work like menu schedules.
+type PriceRule struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	ProductID  string            `json:"productId,omitempty"`
+	CategoryID string            `json:"categoryId,omitempty"`
+	PercentOff float64           `json:"percentOff"`
+	TimeZone   string            `json:"timeZone"`
+	Windows    []schedule.Window `json:"windows"`
+}
+
+// PriceRuleReq is the body of POST /price-rule
+type PriceRuleReq struct {
+	Name       string            `json:"name"`
+	ProductID  string            `json:"productId,omitempty"`
+	CategoryID string            `json:"categoryId,omitempty"`
+	PercentOff float64           `json:"percentOff"`
+	TimeZone   string            `json:"timeZone"`
+	Windows    []schedule.Window `json:"windows"`
+}
+
+// PriceQuote is the price of a product at an instant. BasePrice comes from
+// the price history, Price is after the best matching price rule. Modifier
+// price deltas are not discounted.
+type PriceQuote struct {
+	ProductID string    `json:"productId"`
+	At        time.Time `json:"at"`
+	BasePrice float64   `json:"basePrice"`
+	Price     float64   `json:"price"`
+	Rule      *RuleRef  `json:"rule,omitempty"`
+}
+
+// RuleRef names the price rule a quote was discounted by
+type RuleRef struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	PercentOff float64 `json:"percentOff"`
+}
+
+// priceAt returns the price of the history entry in effect at t. history is
+// sorted by EffectiveFrom; before its first entry current applies.
+func priceAt(history []PriceChange, current float64, t time.Time) float64 {
+	i := sort.Search(len(history), func(i int) bool { return history[i].EffectiveFrom.After(t) })
+	if i == 0 {
+		return current
+	}
+	return history[i-1].Price
+}
+
+// bestRule returns the rule with the biggest discount that applies to p at t,
+// nil if none does. Rules whose windows no longer compile are skipped.
+func bestRule(ctx context.Context, rules []PriceRule, p *Product, t time.Time) *PriceRule {
+	var best *PriceRule
+	for i := range rules {
+		r := &rules[i]
+		if (r.ProductID != "" && r.ProductID != p.ID) || (r.CategoryID != "" && r.CategoryID != p.CategoryID) {
+			continue
+		}
+		if best != nil && r.PercentOff <= best.PercentOff {
+			continue
+		}
+
+		windows, err := schedule.CompileWindows(r.TimeZone, r.Windows)
+		if err != nil {
+			logger.Warn(ctx, "skipping invalid price rule", "ruleId", r.ID, "error", err.Error())
+			continue
+		}
+		if windows.ActiveAt(t) {
+			best = r
+		}
+	}
+	return best
+}
+
+// RoundCents rounds v to whole cents, the precision prices are stored with.
+func RoundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// RunPriceUpdates periodically moves products.price to scheduled changes that
+// came due, until ctx is cancelled. Orders resolve prices from the history and
+// don't wait for it; it keeps listings, sorting and filters current.
+func RunPriceUpdates(ctx context.Context, svc Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := svc.ApplyDuePrices(ctx)
+			if err != nil {
+				continue
+			}
+			if n > 0 {
+				logger.Info(ctx, "applied scheduled price changes", "count", n)
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package product
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/mohammadshabab/order-food-online/internal/schedule"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriceAt(t *testing.T) {
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	history := []PriceChange{
+		{ID: "h1", Price: 9, EffectiveFrom: from},
+		{ID: "h2", Price: 10, EffectiveFrom: from.AddDate(0, 0, 14)},
+		{ID: "h3", Price: 11, EffectiveFrom: from.AddDate(0, 1, 0)},
+	}
+
+	tests := []struct {
+		name  string
+		at    time.Time
+		price float64
+	}{
+		{"before the history starts", from.Add(-time.Second), 12},
+		{"from is inclusive", from, 9},
+		{"between changes", from.AddDate(0, 0, 20), 10},
+		{"scheduled change in effect", from.AddDate(0, 2, 0), 11},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.price, priceAt(history, 12, tt.at))
+		})
+	}
+
+	assert.Equal(t, 12.0, priceAt(nil, 12, from), "without history the current price applies")
+}
+
+func TestBestRule(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	// 2026-10-23 is a Friday
+	friday := time.Date(2026, 10, 23, 17, 30, 0, 0, time.UTC)
+	evenings := []schedule.Window{{Days: []string{"fri"}, Start: "17:00", End: "19:00"}}
+	burger := &Product{ID: "p1", CategoryID: "c1", Price: 10}
+
+	rules := []PriceRule{
+		{ID: "menu", PercentOff: 10, Windows: evenings},
+		{ID: "burgers", CategoryID: "c1", PercentOff: 20, Windows: evenings},
+		{ID: "pizza", ProductID: "p2", PercentOff: 50, Windows: evenings},
+		{ID: "broken", PercentOff: 90, TimeZone: "Mars/Olympus", Windows: evenings},
+		{ID: "mornings", PercentOff: 30, Windows: []schedule.Window{{Start: "08:00", End: "10:00"}}},
+	}
+
+	t.Run("biggest matching discount wins", func(t *testing.T) {
+		assert.Equal(t, "burgers", bestRule(ctx, rules, burger, friday).ID)
+	})
+
+	t.Run("other categories get the menu rule", func(t *testing.T) {
+		assert.Equal(t, "menu", bestRule(ctx, rules, &Product{ID: "p3", CategoryID: "c9"}, friday).ID)
+	})
+
+	t.Run("product rule", func(t *testing.T) {
+		assert.Equal(t, "pizza", bestRule(ctx, rules, &Product{ID: "p2", CategoryID: "c9"}, friday).ID)
+	})
+
+	t.Run("outside every window", func(t *testing.T) {
+		assert.Nil(t, bestRule(ctx, rules, burger, friday.Add(2*time.Hour)))
+	})
+}
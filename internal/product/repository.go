@@ -4,6 +4,48 @@ import "context"
 
 //go:generate mockgen -source=repository.go -destination=mock_repository.go -package=product
 type Repository interface {
-	List(ctx context.Context) ([]*Product, error)
+	// List returns products matching params. With a limit, up to Limit+1 rows
+	// are returned so callers can tell whether another page exists.
+	List(ctx context.Context, params ListParams) ([]*Product, error)
 	GetByID(ctx context.Context, id string) (*Product, error)
+	// Create inserts a product and starts its price history
+	Create(ctx context.Context, p *Product) (*Product, error)
+	// Update overwrites a product if p.Version is still current and bumps the
+	// version. A new price is added to the price history, effective now.
+	Update(ctx context.Context, p *Product) (*Product, error)
+	// Archive soft deletes a product if version is still current
+	Archive(ctx context.Context, id string, version int) error
+	// ModifierGroups returns the modifier groups of a product in display order
+	ModifierGroups(ctx context.Context, productID string) ([]ModifierGroup, error)
+	// ReplaceModifierGroups swaps all modifier groups of a product for groups
+	ReplaceModifierGroups(ctx context.Context, productID string, groups []ModifierGroup) error
+	// ReplaceDietary swaps the allergens, dietary flags and nutrition facts of a product
+	ReplaceDietary(ctx context.Context, productID string, req *DietaryReq) error
+	// CategoryName returns the display name of a category, ErrUnknownCategory if there is none
+	CategoryName(ctx context.Context, categoryID string) (string, error)
+	// RestaurantExists returns ErrUnknownRestaurant if there is no such restaurant
+	RestaurantExists(ctx context.Context, restaurantID string) error
+	// Categories returns every category, for resolving the slugs of import and export files
+	Categories(ctx context.Context) ([]CategoryRef, error)
+	// ProductRestaurants returns the restaurant of every product in ids that
+	// exists, archived ones included
+	ProductRestaurants(ctx context.Context, ids []string) (map[string]string, error)
+	// ApplyImport writes all changes of an import in one transaction. A
+	// product changed since the import was planned fails it with ErrVersionConflict.
+	ApplyImport(ctx context.Context, plan *ImportPlan) error
+	// PriceHistory returns every price of a product, past and scheduled, by EffectiveFrom
+	PriceHistory(ctx context.Context, productID string) ([]PriceChange, error)
+	// SchedulePrice adds a future price change to the history of a product
+	SchedulePrice(ctx context.Context, productID string, change *PriceChange) error
+	// DeleteScheduledPrice removes a price change that is not in effect yet
+	DeleteScheduledPrice(ctx context.Context, productID, changeID string) error
+	// ApplyDuePrices sets products.price to the history entry now in effect
+	// wherever they differ and returns the number of products changed
+	ApplyDuePrices(ctx context.Context) (int64, error)
+	// PriceRules returns every price rule with its windows
+	PriceRules(ctx context.Context) ([]PriceRule, error)
+	CreatePriceRule(ctx context.Context, rule *PriceRule) error
+	DeletePriceRule(ctx context.Context, id string) error
+	// AdjustStock sets, changes or clears the stock count and returns the updated product
+	AdjustStock(ctx context.Context, id string, req *StockReq) (*Product, error)
 }
@@ -0,0 +1,15 @@
+package product
+
+import (
+	"context"
+
+	"github.com/mohammadshabab/order-food-online/internal/schedule"
+)
+
+// Schedules tells which products are off the menu right now.
+// schedule.Service satisfies it.
+//
+//go:generate mockgen -source=schedules.go -destination=mock_schedules.go -package=product
+type Schedules interface {
+	Exclusions(ctx context.Context) (*schedule.Exclusions, error)
+}
@@ -0,0 +1,292 @@
+package product
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Field weights: a hit in the name matters more than one in the description
+const (
+	weightName        = 3.0
+	weightCategory    = 2.0
+	weightDescription = 1.0
+)
+
+// Match quality multipliers for a query token against an indexed term
+const (
+	scoreExact  = 1.0
+	scorePrefix = 0.7
+	scoreFuzzy  = 0.5
+
+	minPrefixLength = 2
+	minFuzzyLength  = 3
+)
+
+// SearchResult is a product with its relevance score
+type SearchResult struct {
+	*Product
+	Score float64 `json:"score"`
+}
+
+type SearchResults struct {
+	Items []SearchResult `json:"items"`
+}
+
+// Index is an in-memory inverted index over product name, category and
+// description. It is safe for concurrent use and is kept up to date with
+// Upsert and Remove as products change.
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[string]*Product
+	postings map[string]map[string]float64 // term -> product id -> field weight
+	terms    []string                      // sorted keys of postings, for prefix lookups
+}
+
+func NewIndex() *Index {
+	return &Index{
+		docs:     make(map[string]*Product),
+		postings: make(map[string]map[string]float64),
+	}
+}
+
+// Rebuild replaces the whole index with products
+func (idx *Index) Rebuild(products []*Product) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.docs = make(map[string]*Product, len(products))
+	idx.postings = make(map[string]map[string]float64)
+	idx.terms = nil
+	for _, p := range products {
+		idx.add(p)
+	}
+}
+
+// Upsert indexes p, replacing any previous version of it
+func (idx *Index) Upsert(p *Product) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(p.ID)
+	idx.add(p)
+}
+
+// Remove drops a product from the index
+func (idx *Index) Remove(id string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(id)
+}
+
+// Reprice updates the price and version of indexed products from products.
+// Prices are not indexed terms, so the postings stay as they are.
+func (idx *Index) Reprice(products []*Product) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for _, p := range products {
+		if doc, ok := idx.docs[p.ID]; ok && (doc.Price != p.Price || doc.Version != p.Version) {
+			// Search copies documents under the read lock, swap rather than mutate
+			next := *doc
+			next.Price, next.Version = p.Price, p.Version
+			idx.docs[p.ID] = &next
+		}
+	}
+}
+
+// Len returns the number of indexed products
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+func (idx *Index) add(p *Product) {
+	doc := *p
+	// stock changes with every order, the index would only ever hold a stale count
+	doc.Stock = nil
+	idx.docs[p.ID] = &doc
+
+	for term, weight := range documentTerms(&doc) {
+		ids, ok := idx.postings[term]
+		if !ok {
+			ids = make(map[string]float64)
+			idx.postings[term] = ids
+			idx.insertTerm(term)
+		}
+		ids[p.ID] = weight
+	}
+}
+
+func (idx *Index) remove(id string) {
+	doc, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+	delete(idx.docs, id)
+
+	for term := range documentTerms(doc) {
+		ids := idx.postings[term]
+		delete(ids, id)
+		if len(ids) == 0 {
+			delete(idx.postings, term)
+			idx.deleteTerm(term)
+		}
+	}
+}
+
+func (idx *Index) insertTerm(term string) {
+	i := sort.SearchStrings(idx.terms, term)
+	idx.terms = append(idx.terms, "")
+	copy(idx.terms[i+1:], idx.terms[i:])
+	idx.terms[i] = term
+}
+
+func (idx *Index) deleteTerm(term string) {
+	i := sort.SearchStrings(idx.terms, term)
+	if i < len(idx.terms) && idx.terms[i] == term {
+		idx.terms = append(idx.terms[:i], idx.terms[i+1:]...)
+	}
+}
+
+// Search ranks products against q. Every query token is matched exactly, as a
+// prefix and with one typo; a product's score is the sum of its best match per
+// token, scaled by the share of query tokens it matched.
+func (idx *Index) Search(q string, limit int) []SearchResult {
+	tokens := tokenize(q)
+	if len(tokens) == 0 {
+		return []SearchResult{}
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	scores := make(map[string]float64)
+	matched := make(map[string]int)
+	for _, token := range tokens {
+		for id, score := range idx.matchToken(token) {
+			scores[id] += score
+			matched[id]++
+		}
+	}
+
+	results := make([]SearchResult, 0, len(scores))
+	for id, score := range scores {
+		coverage := float64(matched[id]) / float64(len(tokens))
+		results = append(results, SearchResult{Product: idx.docs[id], Score: score * coverage})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ID < b.ID
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	// hand out copies so callers cannot mutate indexed documents
+	for i := range results {
+		doc := *results[i].Product
+		results[i].Product = &doc
+	}
+	return results
+}
+
+// matchToken returns the best score per product for a single query token
+func (idx *Index) matchToken(token string) map[string]float64 {
+	best := make(map[string]float64)
+	collect := func(term string, quality float64) {
+		for id, weight := range idx.postings[term] {
+			if s := quality * weight; s > best[id] {
+				best[id] = s
+			}
+		}
+	}
+
+	collect(token, scoreExact)
+
+	if len(token) >= minPrefixLength {
+		for i := sort.SearchStrings(idx.terms, token); i < len(idx.terms) && strings.HasPrefix(idx.terms[i], token); i++ {
+			if idx.terms[i] != token {
+				collect(idx.terms[i], scorePrefix)
+			}
+		}
+	}
+
+	if len(token) >= minFuzzyLength {
+		for _, term := range idx.terms {
+			if term != token && withinOneEdit(token, term) {
+				collect(term, scoreFuzzy)
+			}
+		}
+	}
+
+	return best
+}
+
+// documentTerms maps every term of p to the weight of the strongest field it occurs in
+func documentTerms(p *Product) map[string]float64 {
+	terms := make(map[string]float64)
+	for _, f := range []struct {
+		text   string
+		weight float64
+	}{
+		{p.Name, weightName},
+		{p.Category, weightCategory},
+		{p.Description, weightDescription},
+	} {
+		for _, term := range tokenize(f.text) {
+			if f.weight > terms[term] {
+				terms[term] = f.weight
+			}
+		}
+	}
+	return terms
+}
+
+// tokenize lowercases s and splits it on anything that is not a letter or digit
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// withinOneEdit reports whether a and b differ by at most one insertion,
+// deletion or substitution
+func withinOneEdit(a, b string) bool {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) > len(rb) {
+		ra, rb = rb, ra
+	}
+	if len(rb)-len(ra) > 1 {
+		return false
+	}
+
+	i, j, edits := 0, 0, 0
+	for i < len(ra) && j < len(rb) {
+		if ra[i] == rb[j] {
+			i++
+			j++
+			continue
+		}
+		edits++
+		if edits > 1 {
+			return false
+		}
+		if len(ra) == len(rb) {
+			i++
+		}
+		j++
+	}
+	return edits+(len(rb)-j)+(len(ra)-i) <= 1
+}
@@ -0,0 +1,127 @@
+package product
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func searchIDs(results []SearchResult) []string {
+	ids := make([]string, 0, len(results))
+	for _, r := range results {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
+
+func newTestIndex() *Index {
+	idx := NewIndex()
+	idx.Rebuild([]*Product{
+		{ID: "p1", Name: "Chicken Burger", Category: "Burger", Description: "Grilled chicken breast"},
+		{ID: "p2", Name: "Spaghetti Carbonara", Category: "Pasta", Description: "Egg, pecorino and guanciale"},
+		{ID: "p3", Name: "Caesar Salad", Category: "Salad", Description: "Romaine with chicken and croutons"},
+		{ID: "p4", Name: "Cheeseburger", Category: "Burger"},
+	})
+	return idx
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx := newTestIndex()
+
+	t.Run("exact match ranks name above description", func(t *testing.T) {
+		assert.Equal(t, []string{"p1", "p3"}, searchIDs(idx.Search("chicken", 0)))
+	})
+
+	t.Run("case and punctuation are ignored", func(t *testing.T) {
+		assert.Equal(t, []string{"p2"}, searchIDs(idx.Search("  CARBONARA! ", 0)))
+	})
+
+	t.Run("prefix match", func(t *testing.T) {
+		assert.Equal(t, []string{"p2"}, searchIDs(idx.Search("carbo", 0)))
+	})
+
+	t.Run("typo tolerance", func(t *testing.T) {
+		assert.Equal(t, []string{"p2"}, searchIDs(idx.Search("carbonra", 0)))
+		assert.Equal(t, []string{"p1", "p3"}, searchIDs(idx.Search("chiken", 0)))
+	})
+
+	t.Run("exact beats prefix beats typo", func(t *testing.T) {
+		idx := NewIndex()
+		idx.Rebuild([]*Product{
+			{ID: "exact", Name: "Pizza"},
+			{ID: "prefix", Name: "Pizzas"},
+			{ID: "typo", Name: "Pizzo"},
+		})
+		res := idx.Search("pizza", 0)
+		assert.Equal(t, []string{"exact", "prefix", "typo"}, searchIDs(res))
+		assert.Greater(t, res[0].Score, res[1].Score)
+		assert.Greater(t, res[1].Score, res[2].Score)
+	})
+
+	t.Run("products matching more tokens rank higher", func(t *testing.T) {
+		assert.Equal(t, "p1", idx.Search("chicken burger", 0)[0].ID)
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		assert.Len(t, idx.Search("burger", 1), 1)
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		res := idx.Search("sushi", 0)
+		assert.NotNil(t, res)
+		assert.Empty(t, res)
+	})
+
+	t.Run("empty query", func(t *testing.T) {
+		assert.Empty(t, idx.Search(" - ", 0))
+	})
+}
+
+func TestIndex_Incremental(t *testing.T) {
+	idx := newTestIndex()
+
+	idx.Upsert(&Product{ID: "p5", Name: "Margherita Pizza", Category: "Pizza"})
+	assert.Equal(t, []string{"p5"}, searchIDs(idx.Search("margherita", 0)))
+	assert.Equal(t, 5, idx.Len())
+
+	// renaming drops the old terms
+	idx.Upsert(&Product{ID: "p5", Name: "Diavola Pizza", Category: "Pizza"})
+	assert.Empty(t, idx.Search("margherita", 0))
+	assert.Equal(t, []string{"p5"}, searchIDs(idx.Search("diavola", 0)))
+	assert.Equal(t, 5, idx.Len())
+
+	idx.Remove("p5")
+	assert.Empty(t, idx.Search("pizza", 0))
+	assert.Equal(t, 4, idx.Len())
+	assert.NotContains(t, idx.terms, "diavola")
+
+	// removing an unknown id is a no-op
+	idx.Remove("missing")
+	assert.Equal(t, 4, idx.Len())
+}
+
+func TestIndex_SearchReturnsCopies(t *testing.T) {
+	idx := newTestIndex()
+	idx.Search("chicken", 0)[0].Name = "changed"
+	assert.Equal(t, "Chicken Burger", idx.Search("chicken", 0)[0].Name)
+}
+
+func TestWithinOneEdit(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want bool
+	}{
+		{"pizza", "pizza", true},
+		{"pizza", "pizzo", true},
+		{"pizza", "piza", true},
+		{"piza", "pizza", true},
+		{"pizza", "pizzas", true},
+		{"pizza", "xpizza", true},
+		{"pizza", "pzzia", false},
+		{"pizza", "pasta", false},
+		{"pizza", "pi", false},
+		{"crème", "creme", true},
+	} {
+		assert.Equal(t, tt.want, withinOneEdit(tt.a, tt.b), "%s vs %s", tt.a, tt.b)
+	}
+}
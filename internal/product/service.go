@@ -1,25 +1,636 @@
 package product
 
-import "context"
+import (
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+)
 
 //go:generate mockgen -source=service.go -destination=mock_service.go -package=product
 type Service interface {
-	ListProducts(ctx context.Context) ([]*Product, error)
+	ListProducts(ctx context.Context, params ListParams) (*ProductPage, error)
+	// ListAll returns every product matching params in one slice, Limit and
+	// After are ignored. It backs views of the whole catalog such as the menu.
+	ListAll(ctx context.Context, params ListParams) ([]*Product, error)
 	GetProduct(ctx context.Context, id string) (*Product, error)
+	CreateProduct(ctx context.Context, req *ProductReq) (*Product, error)
+	ReplaceProduct(ctx context.Context, id string, req *ProductReq) (*Product, error)
+	PatchProduct(ctx context.Context, id string, req *ProductReq) (*Product, error)
+	ArchiveProduct(ctx context.Context, id string, version int) error
+	SearchProducts(ctx context.Context, q string, limit int) (*SearchResults, error)
+	SetModifierGroups(ctx context.Context, id string, req *ModifierGroupsReq) (*Product, error)
+	AdjustStock(ctx context.Context, id string, req *StockReq) (*Product, error)
+	// SetDietary replaces the allergens, dietary flags and nutrition facts of a product
+	SetDietary(ctx context.Context, id string, req *DietaryReq) (*Product, error)
+	// ImportProducts syncs the catalog with a CSV or JSON file: rows update or
+	// create products and products missing from the file are archived. With
+	// dryRun nothing is written and the result shows what would change.
+	// The file holds the catalog of one restaurant, the default one when
+	// restaurantID is empty; other restaurants are left alone.
+	ImportProducts(ctx context.Context, restaurantID, format string, r io.Reader, dryRun bool) (*ImportResult, error)
+	// ExportProducts writes the catalog of a restaurant in the import file format
+	ExportProducts(ctx context.Context, restaurantID, format string, w io.Writer) error
+
+	// EffectivePrice resolves the price of p at an instant: the price history
+	// entry in effect then, less the best price rule active then
+	EffectivePrice(ctx context.Context, p *Product, at time.Time) (*PriceQuote, error)
+	// PriceHistory returns the past and scheduled prices of a product
+	PriceHistory(ctx context.Context, id string) ([]PriceChange, error)
+	// SchedulePrice adds a price change that takes effect in the future
+	SchedulePrice(ctx context.Context, id string, req *PriceChangeReq) (*PriceChange, error)
+	// CancelPriceChange removes a scheduled price change before it takes effect
+	CancelPriceChange(ctx context.Context, id, changeID string) error
+	// ApplyDuePrices moves products.price to the scheduled changes that came due
+	ApplyDuePrices(ctx context.Context) (int64, error)
+	ListPriceRules(ctx context.Context) ([]PriceRule, error)
+	CreatePriceRule(ctx context.Context, req *PriceRuleReq) (*PriceRule, error)
+	DeletePriceRule(ctx context.Context, id string) error
 }
 
 type service struct {
-	repo Repository
+	repo      Repository
+	schedules Schedules
+
+	// index is loaded from the repository on the first search and then kept
+	// current by the write paths below. Writes hold indexMu too, so one that
+	// lands while the index is being built is applied after the rebuild rather
+	// than wiped by it. Writes made elsewhere (other instances, cmd/catalog)
+	// show up once the index is older than indexTTL and rebuilt.
+	index     *Index
+	indexTTL  time.Duration
+	indexMu   sync.Mutex
+	indexDone bool
+	indexedAt time.Time
+}
+
+// NewService creates the product service. schedules may be nil, then
+// availableNow only filters on the product availability flag. The search
+// index is rebuilt from repo on the first search after indexTTL.
+func NewService(repo Repository, schedules Schedules, indexTTL time.Duration) Service {
+	return &service{repo: repo, schedules: schedules, index: NewIndex(), indexTTL: indexTTL}
+}
+
+func (s *service) ListProducts(ctx context.Context, params ListParams) (*ProductPage, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	if params.RestaurantID != "" {
+		if err := s.restaurantFound(ctx, params.RestaurantID); err != nil {
+			return nil, err
+		}
+	}
+
+	closed, err := s.applySchedules(ctx, &params)
+	if err != nil {
+		return nil, err
+	}
+	if closed {
+		return &ProductPage{Items: []*Product{}, Pagination: Pagination{Limit: params.Limit}}, nil
+	}
+
+	products, err := s.repo.List(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &ProductPage{Items: products, Pagination: Pagination{Limit: params.Limit}}
+	if len(products) > params.Limit {
+		page.Items = products[:params.Limit]
+		page.Pagination.HasMore = true
+		page.Pagination.NextCursor = cursorFor(page.Items[len(page.Items)-1], params).Encode()
+	}
+	if page.Items == nil {
+		page.Items = []*Product{}
+	}
+	return page, nil
 }
 
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+func (s *service) ListAll(ctx context.Context, params ListParams) ([]*Product, error) {
+	params.Limit, params.After = 0, nil
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	params.Limit = 0
+
+	closed, err := s.applySchedules(ctx, &params)
+	if err != nil || closed {
+		return []*Product{}, err
+	}
+
+	products, err := s.repo.List(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if products == nil {
+		products = []*Product{}
+	}
+	return products, nil
 }
 
-func (s *service) ListProducts(ctx context.Context) ([]*Product, error) {
-	return s.repo.List(ctx)
+// applySchedules fills in the schedule exclusions for an availableNow listing
+// and reports whether the restaurant listed is closed, in which case nothing is
+// listed. Listings across restaurants leave out the products of closed ones.
+func (s *service) applySchedules(ctx context.Context, params *ListParams) (bool, error) {
+	if !params.AvailableNow || s.schedules == nil {
+		return false, nil
+	}
+
+	ex, err := s.schedules.Exclusions(ctx)
+	if err != nil {
+		return false, err
+	}
+	params.Exclusions = ex
+	return params.RestaurantID != "" && contains(ex.Closed, params.RestaurantID), nil
 }
 
+// GetProduct returns a product together with its modifier groups
 func (s *service) GetProduct(ctx context.Context, id string) (*Product, error) {
-	return s.repo.GetByID(ctx, id)
+	p, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.ModifierGroups, err = s.repo.ModifierGroups(ctx, id); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *service) CreateProduct(ctx context.Context, req *ProductReq) (*Product, error) {
+	if err := req.ValidateCreate(); err != nil {
+		return nil, err
+	}
+
+	p := &Product{ID: uuid.New().String(), RestaurantID: DefaultRestaurantID, Available: true, Allergens: []string{}, Dietary: []string{}}
+	if err := s.applyReq(ctx, p, req); err != nil {
+		return nil, err
+	}
+	return s.indexed(s.repo.Create(ctx, p))
+}
+
+func (s *service) ReplaceProduct(ctx context.Context, id string, req *ProductReq) (*Product, error) {
+	if err := req.ValidateReplace(); err != nil {
+		return nil, err
+	}
+
+	// PUT replaces the product fields only, allergens and nutrition are kept
+	// and so is the restaurant unless the body names one
+	current, err := s.repo.GetByID(fresh(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Product{ID: id, RestaurantID: current.RestaurantID, Available: true, Version: *req.Version,
+		Allergens: current.Allergens, Dietary: current.Dietary, Nutrition: current.Nutrition}
+	if err := s.applyReq(ctx, p, req); err != nil {
+		return nil, err
+	}
+	return s.indexed(s.repo.Update(ctx, p))
+}
+
+func (s *service) PatchProduct(ctx context.Context, id string, req *ProductReq) (*Product, error) {
+	if err := req.ValidatePatch(); err != nil {
+		return nil, err
+	}
+
+	p, err := s.repo.GetByID(fresh(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	if p.Version != *req.Version {
+		return nil, ErrVersionConflict
+	}
+
+	if err := s.applyReq(ctx, p, req); err != nil {
+		return nil, err
+	}
+	return s.indexed(s.repo.Update(ctx, p))
+}
+
+func (s *service) ArchiveProduct(ctx context.Context, id string, version int) error {
+	if err := s.repo.Archive(ctx, id, version); err != nil {
+		return err
+	}
+	s.indexWrite(nil, []string{id})
+	return nil
+}
+
+func (s *service) SearchProducts(ctx context.Context, q string, limit int) (*SearchResults, error) {
+	if err := ValidateSearch(q, limit); err != nil {
+		return nil, err
+	}
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+	if err := s.loadIndex(ctx); err != nil {
+		return nil, err
+	}
+	return &SearchResults{Items: s.index.Search(q, limit)}, nil
+}
+
+func (s *service) SetModifierGroups(ctx context.Context, id string, req *ModifierGroupsReq) (*Product, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	p, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]ModifierGroup, 0, len(req.Groups))
+	for _, g := range req.Groups {
+		group := ModifierGroup{
+			ID:        uuid.New().String(),
+			Name:      strings.TrimSpace(g.Name),
+			MinSelect: g.MinSelect,
+			MaxSelect: g.MaxSelect,
+			Modifiers: make([]Modifier, 0, len(g.Modifiers)),
+		}
+		for _, m := range g.Modifiers {
+			group.Modifiers = append(group.Modifiers, Modifier{
+				ID:         uuid.New().String(),
+				Name:       strings.TrimSpace(m.Name),
+				PriceDelta: m.PriceDelta,
+			})
+		}
+		groups = append(groups, group)
+	}
+
+	if err := s.repo.ReplaceModifierGroups(ctx, id, groups); err != nil {
+		return nil, err
+	}
+
+	p.ModifierGroups = groups
+	return p, nil
+}
+
+func (s *service) SetDietary(ctx context.Context, id string, req *DietaryReq) (*Product, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	p, err := s.repo.GetByID(fresh(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceDietary(ctx, id, req); err != nil {
+		return nil, err
+	}
+
+	p.Allergens, p.Dietary, p.Nutrition = req.Allergens, req.Dietary, req.Nutrition
+	p.AllergensDeclared = true
+	s.indexWrite([]*Product{p}, nil)
+	return p, nil
+}
+
+func (s *service) AdjustStock(ctx context.Context, id string, req *StockReq) (*Product, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	return s.repo.AdjustStock(ctx, id, req)
+}
+
+func (s *service) EffectivePrice(ctx context.Context, p *Product, at time.Time) (*PriceQuote, error) {
+	history, err := s.repo.PriceHistory(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.repo.PriceRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	base := priceAt(history, p.Price, at)
+	q := &PriceQuote{ProductID: p.ID, At: at.UTC(), BasePrice: base, Price: base}
+	if r := bestRule(ctx, rules, p, at); r != nil {
+		q.Price = RoundCents(base * (100 - r.PercentOff) / 100)
+		q.Rule = &RuleRef{ID: r.ID, Name: r.Name, PercentOff: r.PercentOff}
+	}
+	return q, nil
+}
+
+func (s *service) PriceHistory(ctx context.Context, id string) ([]PriceChange, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	history, err := s.repo.PriceHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range history {
+		history[i].Scheduled = history[i].EffectiveFrom.After(now)
+	}
+	return history, nil
+}
+
+func (s *service) SchedulePrice(ctx context.Context, id string, req *PriceChangeReq) (*PriceChange, error) {
+	if err := req.Validate(time.Now()); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	change := &PriceChange{
+		ID:            uuid.New().String(),
+		Price:         *req.Price,
+		EffectiveFrom: req.EffectiveFrom.UTC(),
+		Scheduled:     true,
+	}
+	if err := s.repo.SchedulePrice(ctx, id, change); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+func (s *service) CancelPriceChange(ctx context.Context, id, changeID string) error {
+	return s.repo.DeleteScheduledPrice(ctx, id, changeID)
+}
+
+func (s *service) ApplyDuePrices(ctx context.Context) (int64, error) {
+	n, err := s.repo.ApplyDuePrices(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if n > 0 {
+		s.repriceIndex(ctx)
+	}
+	return n, nil
+}
+
+// repriceIndex brings the prices search results carry up to date. An index
+// that was not built yet reads current prices when it is.
+func (s *service) repriceIndex(ctx context.Context) {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
+	if !s.indexDone {
+		return
+	}
+	products, err := s.repo.List(fresh(ctx), ListParams{Sort: SortName, Order: OrderAsc})
+	if err != nil {
+		// leave it to the next search to rebuild the index
+		s.indexDone = false
+		return
+	}
+	s.index.Reprice(products)
+}
+
+func (s *service) ListPriceRules(ctx context.Context) ([]PriceRule, error) {
+	return s.repo.PriceRules(ctx)
+}
+
+func (s *service) CreatePriceRule(ctx context.Context, req *PriceRuleReq) (*PriceRule, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if req.ProductID != "" {
+		if _, err := s.repo.GetByID(ctx, req.ProductID); err != nil {
+			return nil, err
+		}
+	}
+	if req.CategoryID != "" {
+		if _, err := s.repo.CategoryName(ctx, req.CategoryID); err != nil {
+			return nil, err
+		}
+	}
+
+	rule := &PriceRule{
+		ID:         uuid.New().String(),
+		Name:       req.Name,
+		ProductID:  req.ProductID,
+		CategoryID: req.CategoryID,
+		PercentOff: req.PercentOff,
+		TimeZone:   req.TimeZone,
+		Windows:    req.Windows,
+	}
+	if rule.TimeZone == "" {
+		rule.TimeZone = "UTC"
+	}
+	if err := s.repo.CreatePriceRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *service) DeletePriceRule(ctx context.Context, id string) error {
+	return s.repo.DeletePriceRule(ctx, id)
+}
+
+// loadIndex fills the search index with every live product the first time it
+// is needed, and again once it is older than indexTTL. The products are read
+// past the read cache, which could be up to its own ttl old.
+func (s *service) loadIndex(ctx context.Context) error {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
+	if s.indexDone && time.Since(s.indexedAt) < s.indexTTL {
+		return nil
+	}
+
+	products, err := s.repo.List(fresh(ctx), ListParams{Sort: SortName, Order: OrderAsc})
+	if err != nil {
+		return err
+	}
+	s.index.Rebuild(products)
+	s.indexDone = true
+	s.indexedAt = time.Now()
+	logger.Info(ctx, "product search index built", "products", len(products))
+	return nil
+}
+
+func (s *service) ImportProducts(ctx context.Context, restaurantID, format string, r io.Reader, dryRun bool) (*ImportResult, error) {
+	if err := ValidateFormat(format); err != nil {
+		return nil, err
+	}
+	restaurantID, err := s.importRestaurant(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, rowErrs, err := parseImport(format, r)
+	if err != nil {
+		return nil, err
+	}
+	// an empty file would archive the whole catalog, which is never what ops want
+	if len(rows) == 0 && len(rowErrs) == 0 {
+		return nil, ErrImportEmpty
+	}
+
+	categories, err := s.categoriesBySlug(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if rowErrs = validateRows(rows, categories, rowErrs); len(rowErrs) > 0 {
+		return nil, importInvalid(ctx, rowErrs)
+	}
+
+	current, err := s.repo.List(fresh(ctx), ListParams{RestaurantID: restaurantID, Sort: SortName, Order: OrderAsc})
+	if err != nil {
+		return nil, err
+	}
+	owners, err := s.repo.ProductRestaurants(ctx, unknownIDs(rows, current))
+	if err != nil {
+		return nil, err
+	}
+	if rowErrs = takenIDs(rows, current, owners, restaurantID); len(rowErrs) > 0 {
+		return nil, importInvalid(ctx, rowErrs)
+	}
+
+	plan, res := planImport(rows, current, categories, restaurantID)
+	res.DryRun = dryRun
+	if dryRun {
+		return res, nil
+	}
+
+	if err := s.repo.ApplyImport(ctx, plan); err != nil {
+		return nil, err
+	}
+
+	upserts := append([]*Product{}, plan.Create...)
+	for _, p := range plan.Update {
+		p.Version++
+		upserts = append(upserts, p)
+	}
+	removals := make([]string, 0, len(plan.Archive))
+	for _, p := range plan.Archive {
+		removals = append(removals, p.ID)
+	}
+	s.indexWrite(upserts, removals)
+	return res, nil
+}
+
+// importInvalid lists the row errors of an import by line
+func importInvalid(ctx context.Context, rowErrs []RowError) error {
+	sort.SliceStable(rowErrs, func(i, j int) bool { return rowErrs[i].Line < rowErrs[j].Line })
+	if len(rowErrs) > maxImportErrors {
+		rowErrs = rowErrs[:maxImportErrors]
+	}
+	logger.Warn(ctx, ErrImportInvalid.Message, "errors", len(rowErrs))
+	return ErrImportInvalid.WithDetails(map[string]any{"errors": rowErrs})
+}
+
+func (s *service) ExportProducts(ctx context.Context, restaurantID, format string, w io.Writer) error {
+	if err := ValidateFormat(format); err != nil {
+		return err
+	}
+	restaurantID, err := s.importRestaurant(ctx, restaurantID)
+	if err != nil {
+		return err
+	}
+
+	categories, err := s.repo.Categories(ctx)
+	if err != nil {
+		return err
+	}
+	slugs := make(map[string]string, len(categories))
+	for _, c := range categories {
+		slugs[c.ID] = c.Slug
+	}
+
+	products, err := s.repo.List(ctx, ListParams{RestaurantID: restaurantID, Sort: SortName, Order: OrderAsc})
+	if err != nil {
+		return err
+	}
+	return writeExport(w, format, products, slugs)
+}
+
+// importRestaurant resolves the restaurant an import or export is for
+func (s *service) importRestaurant(ctx context.Context, restaurantID string) (string, error) {
+	if restaurantID == "" {
+		return DefaultRestaurantID, nil
+	}
+	if _, err := uuid.Parse(restaurantID); err != nil {
+		return "", apperrors.BadRequest("restaurantId must be a UUID", err)
+	}
+	return restaurantID, s.restaurantFound(ctx, restaurantID)
+}
+
+// restaurantFound turns an unknown restaurant named in the path into a 404
+func (s *service) restaurantFound(ctx context.Context, restaurantID string) error {
+	err := s.repo.RestaurantExists(ctx, restaurantID)
+	if err == ErrUnknownRestaurant {
+		return ErrRestaurantNotFound
+	}
+	return err
+}
+
+func (s *service) categoriesBySlug(ctx context.Context) (map[string]CategoryRef, error) {
+	categories, err := s.repo.Categories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bySlug := make(map[string]CategoryRef, len(categories))
+	for _, c := range categories {
+		bySlug[strings.ToLower(c.Slug)] = c
+	}
+	return bySlug, nil
+}
+
+// indexed passes a repository write result through, updating the search index on success
+func (s *service) indexed(p *Product, err error) (*Product, error) {
+	if err != nil {
+		return nil, err
+	}
+	s.indexWrite([]*Product{p}, nil)
+	return p, nil
+}
+
+// indexWrite applies written products to the search index. Until the index is
+// built there is nothing to update, the build reads them from the repository.
+func (s *service) indexWrite(upserts []*Product, removals []string) {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
+	if !s.indexDone {
+		return
+	}
+	for _, p := range upserts {
+		s.index.Upsert(p)
+	}
+	for _, id := range removals {
+		s.index.Remove(id)
+	}
+}
+
+// applyReq copies the fields present in req onto p. A new category is looked
+// up so unknown ids are rejected and the response carries the category name,
+// a new restaurant is checked the same way.
+func (s *service) applyReq(ctx context.Context, p *Product, req *ProductReq) error {
+	if req.RestaurantID != nil && *req.RestaurantID != p.RestaurantID {
+		if err := s.repo.RestaurantExists(ctx, *req.RestaurantID); err != nil {
+			return err
+		}
+		p.RestaurantID = *req.RestaurantID
+	}
+	if req.Name != nil {
+		p.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Price != nil {
+		p.Price = *req.Price
+	}
+	if req.CategoryID != nil && *req.CategoryID != p.CategoryID {
+		name, err := s.repo.CategoryName(ctx, *req.CategoryID)
+		if err != nil {
+			return err
+		}
+		p.CategoryID, p.Category = *req.CategoryID, name
+	}
+	if req.Description != nil {
+		p.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Available != nil {
+		p.Available = *req.Available
+	}
+	return nil
 }
@@ -1,12 +1,19 @@
 package product
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/mohammadshabab/order-food-online/internal/schedule"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestService_ListProducts(t *testing.T) {
@@ -14,7 +21,7 @@ func TestService_ListProducts(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := NewMockRepository(ctrl)
-	svc := NewService(mockRepo)
+	svc := NewService(mockRepo, nil, time.Hour)
 
 	ctx := context.Background()
 
@@ -25,45 +32,215 @@ func TestService_ListProducts(t *testing.T) {
 		}
 
 		mockRepo.EXPECT().
-			List(ctx).
+			List(ctx, ListParams{Sort: SortName, Order: OrderAsc, Limit: DefaultLimit}).
 			Return(expected, nil)
 
-		res, err := svc.ListProducts(ctx)
+		res, err := svc.ListProducts(ctx, ListParams{})
 		assert.NoError(t, err)
-		assert.Equal(t, expected, res)
+		assert.Equal(t, expected, res.Items)
+		assert.False(t, res.Pagination.HasMore)
+		assert.Empty(t, res.Pagination.NextCursor)
+	})
+
+	t.Run("products of a restaurant", func(t *testing.T) {
+		mockRepo.EXPECT().RestaurantExists(ctx, restaurantID).Return(nil)
+		mockRepo.EXPECT().
+			List(ctx, ListParams{RestaurantID: restaurantID, Sort: SortName, Order: OrderAsc, Limit: DefaultLimit}).
+			Return(nil, nil)
+
+		res, err := svc.ListProducts(ctx, ListParams{RestaurantID: restaurantID})
+		assert.NoError(t, err)
+		assert.Empty(t, res.Items)
+	})
+
+	t.Run("unknown restaurant", func(t *testing.T) {
+		mockRepo.EXPECT().RestaurantExists(ctx, restaurantID).Return(ErrUnknownRestaurant)
+
+		_, err := svc.ListProducts(ctx, ListParams{RestaurantID: restaurantID})
+		assert.Equal(t, ErrRestaurantNotFound, err)
+	})
+
+	t.Run("extra row yields next cursor", func(t *testing.T) {
+		rows := []*Product{
+			{ID: "p1", Name: "A", Price: 1},
+			{ID: "p2", Name: "B", Price: 2},
+			{ID: "p3", Name: "C", Price: 3},
+		}
+		mockRepo.EXPECT().List(ctx, gomock.Any()).Return(rows, nil)
+
+		res, err := svc.ListProducts(ctx, ListParams{Sort: SortPrice, Limit: 2})
+		assert.NoError(t, err)
+		assert.Len(t, res.Items, 2)
+		assert.True(t, res.Pagination.HasMore)
+
+		cursor, err := DecodeCursor(res.Pagination.NextCursor)
+		assert.NoError(t, err)
+		assert.Equal(t, &Cursor{Sort: SortPrice, Order: OrderAsc, Name: "B", Price: 2, ID: "p2"}, cursor)
+	})
+
+	t.Run("empty result is an empty array", func(t *testing.T) {
+		mockRepo.EXPECT().List(ctx, gomock.Any()).Return(nil, nil)
+
+		res, err := svc.ListProducts(ctx, ListParams{})
+		assert.NoError(t, err)
+		assert.NotNil(t, res.Items)
+		assert.Empty(t, res.Items)
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		res, err := svc.ListProducts(ctx, ListParams{Sort: "calories"})
+		assert.Nil(t, res)
+		assert.Error(t, err)
 	})
 
 	t.Run("repo error", func(t *testing.T) {
 		mockRepo.EXPECT().
-			List(ctx).
+			List(ctx, gomock.Any()).
 			Return(nil, errors.New("db error"))
 
-		res, err := svc.ListProducts(ctx)
+		res, err := svc.ListProducts(ctx, ListParams{})
 		assert.Nil(t, res)
 		assert.Error(t, err)
 		assert.Equal(t, "db error", err.Error())
 	})
 }
 
+func TestService_ListProducts_AvailableNow(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("exclusions are passed to the repository", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockSchedules := NewMockSchedules(ctrl)
+
+		ex := &schedule.Exclusions{ProductIDs: []string{"p9"}}
+		mockSchedules.EXPECT().Exclusions(ctx).Return(ex, nil)
+		mockRepo.EXPECT().
+			List(ctx, ListParams{Sort: SortName, Order: OrderAsc, Limit: DefaultLimit, AvailableNow: true, Exclusions: ex}).
+			Return([]*Product{{ID: "p1"}}, nil)
+
+		res, err := NewService(mockRepo, mockSchedules, time.Hour).ListProducts(ctx, ListParams{AvailableNow: true})
+		assert.NoError(t, err)
+		assert.Len(t, res.Items, 1)
+	})
+
+	t.Run("closed restaurant lists nothing", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockSchedules := NewMockSchedules(ctrl)
+
+		mockRepo.EXPECT().RestaurantExists(ctx, restaurantID).Return(nil)
+		mockSchedules.EXPECT().Exclusions(ctx).Return(&schedule.Exclusions{Closed: []string{restaurantID}}, nil)
+
+		res, err := NewService(mockRepo, mockSchedules, time.Hour).ListProducts(ctx, ListParams{RestaurantID: restaurantID, AvailableNow: true})
+		assert.NoError(t, err)
+		assert.NotNil(t, res.Items)
+		assert.Empty(t, res.Items)
+		assert.False(t, res.Pagination.HasMore)
+	})
+
+	t.Run("closed restaurants are left out of listings across restaurants", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockSchedules := NewMockSchedules(ctrl)
+
+		ex := &schedule.Exclusions{Closed: []string{restaurantID}}
+		mockSchedules.EXPECT().Exclusions(ctx).Return(ex, nil)
+		mockRepo.EXPECT().
+			List(ctx, ListParams{Sort: SortName, Order: OrderAsc, Limit: DefaultLimit, AvailableNow: true, Exclusions: ex}).
+			Return([]*Product{{ID: "p1"}}, nil)
+
+		res, err := NewService(mockRepo, mockSchedules, time.Hour).ListProducts(ctx, ListParams{AvailableNow: true})
+		assert.NoError(t, err)
+		assert.Len(t, res.Items, 1)
+	})
+
+	t.Run("schedules error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSchedules := NewMockSchedules(ctrl)
+		mockSchedules.EXPECT().Exclusions(ctx).Return(nil, errors.New("db error"))
+
+		res, err := NewService(NewMockRepository(ctrl), mockSchedules, time.Hour).ListProducts(ctx, ListParams{AvailableNow: true})
+		assert.Nil(t, res)
+		assert.EqualError(t, err, "db error")
+	})
+
+	t.Run("schedules not consulted without availableNow", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().List(ctx, gomock.Any()).Return(nil, nil)
+
+		_, err := NewService(mockRepo, NewMockSchedules(ctrl), time.Hour).ListProducts(ctx, ListParams{})
+		assert.NoError(t, err)
+	})
+}
+
+func TestService_ListAll(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("lists without paging", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().
+			List(ctx, ListParams{Sort: SortName, Order: OrderAsc}).
+			Return(nil, nil)
+
+		res, err := NewService(mockRepo, nil, time.Hour).ListAll(ctx, ListParams{Limit: 5, After: &Cursor{ID: "p1"}})
+		assert.NoError(t, err)
+		assert.NotNil(t, res)
+		assert.Empty(t, res)
+	})
+
+	t.Run("closed restaurant lists nothing", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSchedules := NewMockSchedules(ctrl)
+		mockSchedules.EXPECT().Exclusions(ctx).Return(&schedule.Exclusions{Closed: []string{restaurantID}}, nil)
+
+		res, err := NewService(NewMockRepository(ctrl), mockSchedules, time.Hour).ListAll(ctx, ListParams{RestaurantID: restaurantID, AvailableNow: true})
+		assert.NoError(t, err)
+		assert.Empty(t, res)
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		_, err := NewService(NewMockRepository(ctrl), nil, time.Hour).ListAll(ctx, ListParams{Sort: "calories"})
+		assert.Error(t, err)
+	})
+}
+
 func TestService_GetProduct(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockRepo := NewMockRepository(ctrl)
-	svc := NewService(mockRepo)
+	svc := NewService(mockRepo, nil, time.Hour)
 
 	ctx := context.Background()
 
 	t.Run("success", func(t *testing.T) {
 		expected := &Product{ID: "p1", Name: "Prod 1"}
+		groups := []ModifierGroup{{ID: "g1", Name: "Size", MinSelect: 1, MaxSelect: 1, Modifiers: []Modifier{{ID: "m1", Name: "Large", PriceDelta: 3}}}}
 
 		mockRepo.EXPECT().
 			GetByID(ctx, "p1").
 			Return(expected, nil)
+		mockRepo.EXPECT().
+			ModifierGroups(ctx, "p1").
+			Return(groups, nil)
 
 		res, err := svc.GetProduct(ctx, "p1")
 		assert.NoError(t, err)
 		assert.Equal(t, expected, res)
+		assert.Equal(t, groups, res.ModifierGroups)
+	})
+
+	t.Run("modifier groups error", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(ctx, "p2").Return(&Product{ID: "p2"}, nil)
+		mockRepo.EXPECT().ModifierGroups(ctx, "p2").Return(nil, errors.New("db error"))
+
+		res, err := svc.GetProduct(ctx, "p2")
+		assert.Nil(t, res)
+		assert.Error(t, err)
 	})
 
 	t.Run("not found", func(t *testing.T) {
@@ -77,3 +254,708 @@ func TestService_GetProduct(t *testing.T) {
 		assert.Equal(t, "not found", err.Error())
 	})
 }
+
+func TestService_CreateProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	svc := NewService(mockRepo, nil, time.Hour)
+	ctx := context.Background()
+
+	t.Run("assigns id and trims fields", func(t *testing.T) {
+		mockRepo.EXPECT().CategoryName(ctx, burgerCategoryID).Return("Burgers", nil)
+		mockRepo.EXPECT().
+			Create(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, p *Product) (*Product, error) {
+				assert.NotEmpty(t, p.ID)
+				assert.Equal(t, "Burger", p.Name)
+				assert.Equal(t, burgerCategoryID, p.CategoryID)
+				assert.Equal(t, "Burgers", p.Category)
+				assert.Equal(t, DefaultRestaurantID, p.RestaurantID)
+				p.Version = 1
+				return p, nil
+			})
+
+		res, err := svc.CreateProduct(ctx, &ProductReq{Name: strPtr(" Burger "), Price: floatPtr(9), CategoryID: strPtr(burgerCategoryID)})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, res.Version)
+	})
+
+	t.Run("unknown category", func(t *testing.T) {
+		mockRepo.EXPECT().CategoryName(ctx, burgerCategoryID).Return("", ErrUnknownCategory)
+
+		res, err := svc.CreateProduct(ctx, &ProductReq{Name: strPtr("Burger"), Price: floatPtr(9), CategoryID: strPtr(burgerCategoryID)})
+		assert.Nil(t, res)
+		assert.Equal(t, ErrUnknownCategory, err)
+	})
+
+	t.Run("for a restaurant", func(t *testing.T) {
+		mockRepo.EXPECT().RestaurantExists(ctx, restaurantID).Return(nil)
+		mockRepo.EXPECT().CategoryName(ctx, burgerCategoryID).Return("Burgers", nil)
+		mockRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, p *Product) (*Product, error) {
+			assert.Equal(t, restaurantID, p.RestaurantID)
+			return p, nil
+		})
+
+		_, err := svc.CreateProduct(ctx, &ProductReq{RestaurantID: strPtr(restaurantID), Name: strPtr("Burger"), Price: floatPtr(9), CategoryID: strPtr(burgerCategoryID)})
+		assert.NoError(t, err)
+	})
+
+	t.Run("unknown restaurant", func(t *testing.T) {
+		mockRepo.EXPECT().RestaurantExists(ctx, restaurantID).Return(ErrUnknownRestaurant)
+
+		_, err := svc.CreateProduct(ctx, &ProductReq{RestaurantID: strPtr(restaurantID), Name: strPtr("Burger"), Price: floatPtr(9), CategoryID: strPtr(burgerCategoryID)})
+		assert.Equal(t, ErrUnknownRestaurant, err)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		res, err := svc.CreateProduct(ctx, &ProductReq{Name: strPtr("Burger")})
+		assert.Nil(t, res)
+		assert.Error(t, err)
+	})
+}
+
+func TestService_ReplaceProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	svc := NewService(mockRepo, nil, time.Hour)
+	ctx := context.Background()
+
+	// allergens, nutrition and the restaurant are not part of the body and carry over
+	nutrition := &Nutrition{EnergyKcal: floatPtr(540)}
+	mockRepo.EXPECT().GetByID(fresh(ctx), "p1").
+		Return(&Product{ID: "p1", RestaurantID: restaurantID, Name: "Old", Allergens: []string{"gluten"}, Dietary: []string{"halal"}, Nutrition: nutrition, Version: 2}, nil)
+	expected := &Product{ID: "p1", RestaurantID: restaurantID, Name: "Burger", Price: 9, CategoryID: burgerCategoryID, Category: "Burgers", Available: true, Version: 2,
+		Allergens: []string{"gluten"}, Dietary: []string{"halal"}, Nutrition: nutrition}
+	mockRepo.EXPECT().CategoryName(ctx, burgerCategoryID).Return("Burgers", nil)
+	mockRepo.EXPECT().Update(ctx, expected).Return(&Product{ID: "p1", Version: 3}, nil)
+
+	res, err := svc.ReplaceProduct(ctx, "p1", &ProductReq{Name: strPtr("Burger"), Price: floatPtr(9), CategoryID: strPtr(burgerCategoryID), Version: intPtr(2)})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, res.Version)
+}
+
+func TestService_PatchProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	svc := NewService(mockRepo, nil, time.Hour)
+	ctx := context.Background()
+
+	t.Run("applies only sent fields", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(fresh(ctx), "p1").
+			Return(&Product{ID: "p1", Name: "Burger", Price: 9, CategoryID: burgerCategoryID, Category: "Burgers", Version: 2}, nil)
+		// the category is unchanged, so it is not looked up again
+		mockRepo.EXPECT().Update(ctx, &Product{ID: "p1", Name: "Burger", Price: 11, CategoryID: burgerCategoryID, Category: "Burgers", Version: 2}).
+			DoAndReturn(func(_ context.Context, p *Product) (*Product, error) {
+				p.Version++
+				return p, nil
+			})
+
+		res, err := svc.PatchProduct(ctx, "p1", &ProductReq{Price: floatPtr(11), CategoryID: strPtr(burgerCategoryID), Version: intPtr(2)})
+		assert.NoError(t, err)
+		assert.Equal(t, 11.0, res.Price)
+		assert.Equal(t, 3, res.Version)
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(fresh(ctx), "p1").Return(&Product{ID: "p1", Version: 3}, nil)
+
+		_, err := svc.PatchProduct(ctx, "p1", &ProductReq{Price: floatPtr(11), Version: intPtr(2)})
+		assert.Equal(t, ErrVersionConflict, err)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(fresh(ctx), "p9").Return(nil, ErrProductNotFound)
+
+		_, err := svc.PatchProduct(ctx, "p9", &ProductReq{Price: floatPtr(11), Version: intPtr(1)})
+		assert.Equal(t, ErrProductNotFound, err)
+	})
+}
+
+func TestService_ArchiveProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	svc := NewService(mockRepo, nil, time.Hour)
+	ctx := context.Background()
+
+	mockRepo.EXPECT().Archive(ctx, "p1", 4).Return(nil)
+	assert.NoError(t, svc.ArchiveProduct(ctx, "p1", 4))
+}
+
+func TestService_SearchProducts(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	svc := NewService(mockRepo, nil, time.Hour)
+	ctx := context.Background()
+
+	t.Run("repo error is returned and retried on next search", func(t *testing.T) {
+		mockRepo.EXPECT().List(fresh(ctx), ListParams{Sort: SortName, Order: OrderAsc}).Return(nil, errors.New("db error"))
+
+		res, err := svc.SearchProducts(ctx, "chicken", 0)
+		assert.Nil(t, res)
+		assert.Error(t, err)
+	})
+
+	t.Run("index is built once", func(t *testing.T) {
+		mockRepo.EXPECT().List(fresh(ctx), ListParams{Sort: SortName, Order: OrderAsc}).
+			Return([]*Product{{ID: "p1", Name: "Chicken Burger", Version: 1}}, nil).Times(1)
+
+		res, err := svc.SearchProducts(ctx, "chicken", 0)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"p1"}, searchIDs(res.Items))
+
+		res, err = svc.SearchProducts(ctx, "chiken", 0)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"p1"}, searchIDs(res.Items))
+	})
+
+	t.Run("writes update the index", func(t *testing.T) {
+		mockRepo.EXPECT().CategoryName(ctx, burgerCategoryID).Return("Wraps", nil).Times(2)
+		mockRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, p *Product) (*Product, error) {
+			p.ID = "p2"
+			p.Version = 1
+			return p, nil
+		})
+		_, err := svc.CreateProduct(ctx, &ProductReq{Name: strPtr("Chicken Wrap"), Price: floatPtr(7), CategoryID: strPtr(burgerCategoryID)})
+		assert.NoError(t, err)
+
+		res, _ := svc.SearchProducts(ctx, "wrap", 0)
+		assert.Equal(t, []string{"p2"}, searchIDs(res.Items))
+
+		mockRepo.EXPECT().GetByID(fresh(ctx), "p2").Return(&Product{ID: "p2", Name: "Chicken Wrap", Version: 1}, nil)
+		mockRepo.EXPECT().Update(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, p *Product) (*Product, error) {
+			p.Version++
+			return p, nil
+		})
+		_, err = svc.ReplaceProduct(ctx, "p2", &ProductReq{Name: strPtr("Falafel Wrap"), Price: floatPtr(7), CategoryID: strPtr(burgerCategoryID), Version: intPtr(1)})
+		assert.NoError(t, err)
+
+		res, _ = svc.SearchProducts(ctx, "chicken", 0)
+		assert.Equal(t, []string{"p1"}, searchIDs(res.Items))
+
+		mockRepo.EXPECT().Archive(ctx, "p2", 2).Return(nil)
+		assert.NoError(t, svc.ArchiveProduct(ctx, "p2", 2))
+
+		res, _ = svc.SearchProducts(ctx, "wrap", 0)
+		assert.Empty(t, res.Items)
+	})
+
+	t.Run("failed write leaves the index alone", func(t *testing.T) {
+		mockRepo.EXPECT().Archive(ctx, "p1", 1).Return(ErrVersionConflict)
+		assert.Error(t, svc.ArchiveProduct(ctx, "p1", 1))
+
+		res, _ := svc.SearchProducts(ctx, "chicken", 0)
+		assert.Equal(t, []string{"p1"}, searchIDs(res.Items))
+	})
+
+	t.Run("index is rebuilt after its ttl", func(t *testing.T) {
+		// e.g. cmd/catalog imported a product straight into the database
+		svc.(*service).indexedAt = time.Now().Add(-2 * time.Hour)
+		mockRepo.EXPECT().List(fresh(ctx), ListParams{Sort: SortName, Order: OrderAsc}).
+			Return([]*Product{{ID: "p1", Name: "Chicken Burger", Version: 1}, {ID: "p3", Name: "Chicken Salad", Version: 1}}, nil)
+
+		res, err := svc.SearchProducts(ctx, "chicken", 0)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{"p1", "p3"}, searchIDs(res.Items))
+
+		res, err = svc.SearchProducts(ctx, "salad", 0)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"p3"}, searchIDs(res.Items))
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		res, err := svc.SearchProducts(ctx, "", 0)
+		assert.Nil(t, res)
+		assert.Error(t, err)
+	})
+}
+
+func TestService_SearchProducts_WriteDuringBuild(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	svc := NewService(mockRepo, nil, time.Hour)
+	ctx := context.Background()
+
+	// the build reads its snapshot before the create commits and finishes after it
+	listing, created := make(chan struct{}), make(chan struct{})
+	mockRepo.EXPECT().List(fresh(ctx), ListParams{Sort: SortName, Order: OrderAsc}).
+		DoAndReturn(func(context.Context, ListParams) ([]*Product, error) {
+			close(listing)
+			<-created
+			// give an unguarded upsert time to land before the rebuild
+			time.Sleep(10 * time.Millisecond)
+			return []*Product{{ID: "p1", Name: "Chicken Burger", Version: 1}}, nil
+		})
+	mockRepo.EXPECT().CategoryName(ctx, burgerCategoryID).Return("Wraps", nil)
+	mockRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, p *Product) (*Product, error) {
+		p.ID = "p2"
+		p.Version = 1
+		close(created)
+		return p, nil
+	})
+
+	searched := make(chan error)
+	go func() {
+		_, err := svc.SearchProducts(ctx, "chicken", 0)
+		searched <- err
+	}()
+
+	<-listing
+	_, err := svc.CreateProduct(ctx, &ProductReq{Name: strPtr("Chicken Wrap"), Price: floatPtr(7), CategoryID: strPtr(burgerCategoryID)})
+	require.NoError(t, err)
+	require.NoError(t, <-searched)
+
+	res, err := svc.SearchProducts(ctx, "wrap", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"p2"}, searchIDs(res.Items))
+}
+
+func TestService_SetModifierGroups(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	svc := NewService(mockRepo, nil, time.Hour)
+	ctx := context.Background()
+
+	req := &ModifierGroupsReq{Groups: []ModifierGroup{{
+		ID:        "client-supplied",
+		Name:      " Size ",
+		MinSelect: 1,
+		MaxSelect: 1,
+		Modifiers: []Modifier{{Name: "Small"}, {Name: "Large", PriceDelta: 3}},
+	}}}
+
+	t.Run("assigns ids and replaces groups", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(ctx, "p1").Return(&Product{ID: "p1", Name: "Pizza"}, nil)
+		mockRepo.EXPECT().ReplaceModifierGroups(ctx, "p1", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, groups []ModifierGroup) error {
+				assert.Len(t, groups, 1)
+				assert.NotEqual(t, "client-supplied", groups[0].ID)
+				assert.Equal(t, "Size", groups[0].Name)
+				assert.NotEmpty(t, groups[0].Modifiers[1].ID)
+				assert.Equal(t, 3.0, groups[0].Modifiers[1].PriceDelta)
+				return nil
+			})
+
+		res, err := svc.SetModifierGroups(ctx, "p1", req)
+		assert.NoError(t, err)
+		assert.Len(t, res.ModifierGroups, 1)
+	})
+
+	t.Run("unknown product", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(ctx, "p9").Return(nil, ErrProductNotFound)
+
+		_, err := svc.SetModifierGroups(ctx, "p9", req)
+		assert.Equal(t, ErrProductNotFound, err)
+	})
+
+	t.Run("invalid groups", func(t *testing.T) {
+		_, err := svc.SetModifierGroups(ctx, "p1", &ModifierGroupsReq{Groups: []ModifierGroup{{Name: "Size", MaxSelect: 1}}})
+		assert.Error(t, err)
+	})
+}
+
+func TestService_AdjustStock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	svc := NewService(mockRepo, nil, time.Hour)
+	ctx := context.Background()
+
+	req := &StockReq{Delta: intPtr(-1)}
+	mockRepo.EXPECT().AdjustStock(ctx, "p1", req).Return(&Product{ID: "p1", Stock: intPtr(4)}, nil)
+
+	p, err := svc.AdjustStock(ctx, "p1", req)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, *p.Stock)
+
+	_, err = svc.AdjustStock(ctx, "p1", &StockReq{})
+	assert.Error(t, err)
+}
+
+func TestService_ImportProducts(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	svc := NewService(mockRepo, nil, time.Hour)
+	ctx := context.Background()
+
+	categories := []CategoryRef{{ID: pizzaCategoryID, Slug: "pizza", Name: "Pizza"}}
+	current := []*Product{{ID: existingID, Name: "Margherita", Price: 9, CategoryID: pizzaCategoryID, Category: "Pizza", Available: true, Version: 2}}
+	listAll := ListParams{RestaurantID: DefaultRestaurantID, Sort: SortName, Order: OrderAsc}
+	csv := "id,name,price,category\n" + existingID + ",Margherita,9.5,pizza\n"
+
+	t.Run("dry run writes nothing", func(t *testing.T) {
+		mockRepo.EXPECT().Categories(ctx).Return(categories, nil)
+		mockRepo.EXPECT().List(fresh(ctx), listAll).Return(current, nil)
+		mockRepo.EXPECT().ProductRestaurants(ctx, gomock.Len(0)).Return(map[string]string{}, nil)
+
+		res, err := svc.ImportProducts(ctx, "", FormatCSV, strings.NewReader(csv), true)
+		assert.NoError(t, err)
+		assert.True(t, res.DryRun)
+		assert.Len(t, res.Updated, 1)
+	})
+
+	t.Run("applies the plan and updates the search index", func(t *testing.T) {
+		calzone := &Product{ID: goneID, Name: "Calzone", Price: 12, CategoryID: pizzaCategoryID, Category: "Pizza", Available: true, Version: 1}
+		mockRepo.EXPECT().List(gomock.Any(), ListParams{Sort: SortName, Order: OrderAsc}).Return(append([]*Product{calzone}, current...), nil)
+		_, err := svc.SearchProducts(ctx, "pizza", 0)
+		require.NoError(t, err)
+
+		mockRepo.EXPECT().Categories(ctx).Return(categories, nil)
+		mockRepo.EXPECT().List(fresh(ctx), listAll).Return([]*Product{calzone, current[0]}, nil)
+		mockRepo.EXPECT().ProductRestaurants(ctx, []string{"e2c1a9b0-1d2e-4c3a-9f44-333333333333"}).Return(map[string]string{}, nil)
+		mockRepo.EXPECT().ApplyImport(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, plan *ImportPlan) error {
+			assert.Len(t, plan.Create, 1)
+			assert.Len(t, plan.Update, 1)
+			assert.Equal(t, 9.5, plan.Update[0].Price)
+			assert.Len(t, plan.Archive, 1)
+			return nil
+		})
+
+		in := csv + "e2c1a9b0-1d2e-4c3a-9f44-333333333333,Diavola,11,pizza\n"
+		res, err := svc.ImportProducts(ctx, "", FormatCSV, strings.NewReader(in), false)
+		assert.NoError(t, err)
+		assert.False(t, res.DryRun)
+
+		// the index is updated in place, the search reads no products
+		found, err := svc.SearchProducts(ctx, "pizza", 0)
+		require.NoError(t, err)
+		require.Len(t, found.Items, 2)
+		prices := map[string]float64{}
+		for _, item := range found.Items {
+			prices[item.Name] = item.Price
+		}
+		assert.Equal(t, map[string]float64{"Margherita": 9.5, "Diavola": 11}, prices)
+	})
+
+	t.Run("ids of archived products or other restaurants", func(t *testing.T) {
+		otherID := "e2c1a9b0-1d2e-4c3a-9f44-444444444444"
+		mockRepo.EXPECT().Categories(ctx).Return(categories, nil)
+		mockRepo.EXPECT().List(fresh(ctx), listAll).Return(current, nil)
+		mockRepo.EXPECT().ProductRestaurants(ctx, []string{goneID, otherID}).
+			Return(map[string]string{goneID: DefaultRestaurantID, otherID: restaurantID}, nil)
+
+		in := "id,name,price,category\n" + goneID + ",Calzone,12,pizza\n" + otherID + ",Diavola,11,pizza\n"
+		_, err := svc.ImportProducts(ctx, "", FormatCSV, strings.NewReader(in), false)
+		appErr, ok := err.(*apperrors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, 422, appErr.Code)
+		assert.Equal(t, map[string]any{"errors": []RowError{
+			{Line: 2, Field: "id", Message: "id belongs to an archived product, import it without an id to create a new one"},
+			{Line: 3, Field: "id", Message: "id belongs to a product of another restaurant"},
+		}}, appErr.Details)
+	})
+
+	t.Run("invalid rows reject the whole file", func(t *testing.T) {
+		mockRepo.EXPECT().Categories(ctx).Return(categories, nil)
+
+		in := "name,price,category\nMargherita,9,pasta\nDiavola,free,pizza\n"
+		_, err := svc.ImportProducts(ctx, "", FormatCSV, strings.NewReader(in), false)
+		appErr, ok := err.(*apperrors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, 422, appErr.Code)
+		assert.Equal(t, map[string]any{"errors": []RowError{
+			{Line: 2, Field: "category", Message: `unknown category "pasta"`},
+			{Line: 3, Field: "price", Message: "price must be a number"},
+		}}, appErr.Details)
+	})
+
+	t.Run("empty file", func(t *testing.T) {
+		_, err := svc.ImportProducts(ctx, "", FormatJSON, strings.NewReader("[]"), false)
+		assert.Equal(t, ErrImportEmpty, err)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := svc.ImportProducts(ctx, "", "xml", strings.NewReader(csv), false)
+		assert.Error(t, err)
+	})
+
+	t.Run("catalog of another restaurant", func(t *testing.T) {
+		mockRepo.EXPECT().RestaurantExists(ctx, restaurantID).Return(nil)
+		mockRepo.EXPECT().Categories(ctx).Return(categories, nil)
+		mockRepo.EXPECT().List(fresh(ctx), ListParams{RestaurantID: restaurantID, Sort: SortName, Order: OrderAsc}).Return(nil, nil)
+		mockRepo.EXPECT().ProductRestaurants(ctx, gomock.Len(0)).Return(map[string]string{}, nil)
+
+		res, err := svc.ImportProducts(ctx, restaurantID, FormatCSV, strings.NewReader("name,price,category\nDiavola,11,pizza\n"), true)
+		assert.NoError(t, err)
+		assert.Len(t, res.Created, 1)
+	})
+
+	t.Run("unknown restaurant", func(t *testing.T) {
+		mockRepo.EXPECT().RestaurantExists(ctx, restaurantID).Return(ErrUnknownRestaurant)
+
+		_, err := svc.ImportProducts(ctx, restaurantID, FormatCSV, strings.NewReader(csv), false)
+		assert.Equal(t, ErrRestaurantNotFound, err)
+	})
+
+	t.Run("apply fails", func(t *testing.T) {
+		mockRepo.EXPECT().Categories(ctx).Return(categories, nil)
+		mockRepo.EXPECT().List(fresh(ctx), listAll).Return(current, nil)
+		mockRepo.EXPECT().ProductRestaurants(ctx, gomock.Len(0)).Return(map[string]string{}, nil)
+		mockRepo.EXPECT().ApplyImport(ctx, gomock.Any()).Return(ErrVersionConflict)
+
+		_, err := svc.ImportProducts(ctx, "", FormatCSV, strings.NewReader(csv), false)
+		assert.Equal(t, ErrVersionConflict, err)
+	})
+}
+
+func TestService_ExportProducts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	svc := NewService(mockRepo, nil, time.Hour)
+	ctx := context.Background()
+
+	mockRepo.EXPECT().Categories(ctx).Return([]CategoryRef{{ID: pizzaCategoryID, Slug: "pizza", Name: "Pizza"}}, nil)
+	mockRepo.EXPECT().List(ctx, ListParams{RestaurantID: DefaultRestaurantID, Sort: SortName, Order: OrderAsc}).
+		Return([]*Product{{ID: existingID, Name: "Margherita", Price: 9, CategoryID: pizzaCategoryID, Available: true}}, nil)
+
+	var buf bytes.Buffer
+	assert.NoError(t, svc.ExportProducts(ctx, "", FormatCSV, &buf))
+	assert.Equal(t, "id,name,price,category,description,available\n"+existingID+",Margherita,9,pizza,,true\n", buf.String())
+
+	assert.Error(t, svc.ExportProducts(ctx, "", "xml", &buf))
+}
+
+func TestService_SetDietary(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	svc := NewService(mockRepo, nil, time.Hour)
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		want := &DietaryReq{Allergens: []string{"sesame"}, Dietary: []string{"vegan"}}
+		mockRepo.EXPECT().GetByID(fresh(ctx), "p1").Return(&Product{ID: "p1", Allergens: []string{"milk"}, Dietary: []string{}}, nil)
+		mockRepo.EXPECT().ReplaceDietary(ctx, "p1", want).Return(nil)
+
+		p, err := svc.SetDietary(ctx, "p1", &DietaryReq{Allergens: []string{"Sesame"}, Dietary: []string{"vegan"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"sesame"}, p.Allergens)
+		assert.True(t, p.AllergensDeclared)
+		assert.Equal(t, []string{"vegan"}, p.Dietary)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(fresh(ctx), "p9").Return(nil, ErrProductNotFound)
+
+		_, err := svc.SetDietary(ctx, "p9", &DietaryReq{Allergens: []string{}})
+		assert.Equal(t, ErrProductNotFound, err)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		_, err := svc.SetDietary(ctx, "p1", &DietaryReq{})
+		assert.Error(t, err)
+	})
+}
+
+func TestService_EffectivePrice(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	svc := NewService(mockRepo, nil, time.Hour)
+	ctx := context.Background()
+
+	// 2026-10-23 is a Friday
+	friday := time.Date(2026, 10, 23, 17, 30, 0, 0, time.UTC)
+	burger := &Product{ID: "p1", CategoryID: "c1", Price: 9}
+	history := []PriceChange{
+		{ID: "h1", Price: 9, EffectiveFrom: friday.AddDate(0, -1, 0)},
+		{ID: "h2", Price: 9.99, EffectiveFrom: friday.Add(-time.Hour)},
+	}
+	happyHour := PriceRule{ID: "r1", Name: "Happy hour", CategoryID: "c1", PercentOff: 15,
+		Windows: []schedule.Window{{Days: []string{"fri"}, Start: "17:00", End: "19:00"}}}
+
+	t.Run("history entry in effect less the active rule", func(t *testing.T) {
+		mockRepo.EXPECT().PriceHistory(ctx, "p1").Return(history, nil)
+		mockRepo.EXPECT().PriceRules(ctx).Return([]PriceRule{happyHour}, nil)
+
+		q, err := svc.EffectivePrice(ctx, burger, friday)
+		assert.NoError(t, err)
+		assert.Equal(t, &PriceQuote{ProductID: "p1", At: friday, BasePrice: 9.99, Price: 8.49,
+			Rule: &RuleRef{ID: "r1", Name: "Happy hour", PercentOff: 15}}, q)
+	})
+
+	t.Run("no rule active", func(t *testing.T) {
+		mockRepo.EXPECT().PriceHistory(ctx, "p1").Return(history, nil)
+		mockRepo.EXPECT().PriceRules(ctx).Return([]PriceRule{happyHour}, nil)
+
+		q, err := svc.EffectivePrice(ctx, burger, friday.Add(-2*time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, 9.0, q.BasePrice)
+		assert.Equal(t, 9.0, q.Price)
+		assert.Nil(t, q.Rule)
+	})
+
+	t.Run("history read fails", func(t *testing.T) {
+		mockRepo.EXPECT().PriceHistory(ctx, "p1").Return(nil, errors.New("db down"))
+
+		_, err := svc.EffectivePrice(ctx, burger, friday)
+		assert.EqualError(t, err, "db down")
+	})
+}
+
+func TestService_PriceHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	svc := NewService(mockRepo, nil, time.Hour)
+	ctx := context.Background()
+
+	t.Run("future changes are marked scheduled", func(t *testing.T) {
+		past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+		mockRepo.EXPECT().GetByID(ctx, "p1").Return(&Product{ID: "p1"}, nil)
+		mockRepo.EXPECT().PriceHistory(ctx, "p1").Return([]PriceChange{
+			{ID: "h1", Price: 9, EffectiveFrom: past},
+			{ID: "h2", Price: 10, EffectiveFrom: future},
+		}, nil)
+
+		history, err := svc.PriceHistory(ctx, "p1")
+		assert.NoError(t, err)
+		assert.False(t, history[0].Scheduled)
+		assert.True(t, history[1].Scheduled)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(ctx, "p9").Return(nil, ErrProductNotFound)
+
+		_, err := svc.PriceHistory(ctx, "p9")
+		assert.Equal(t, ErrProductNotFound, err)
+	})
+}
+
+func TestService_SchedulePrice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	svc := NewService(mockRepo, nil, time.Hour)
+	ctx := context.Background()
+	from := time.Date(2099, 1, 1, 9, 0, 0, 0, time.FixedZone("CET", 3600))
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(ctx, "p1").Return(&Product{ID: "p1"}, nil)
+		mockRepo.EXPECT().SchedulePrice(ctx, "p1", gomock.Any()).Return(nil)
+
+		change, err := svc.SchedulePrice(ctx, "p1", &PriceChangeReq{Price: floatPtr(10.5), EffectiveFrom: &from})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, change.ID)
+		assert.Equal(t, 10.5, change.Price)
+		assert.Equal(t, time.UTC, change.EffectiveFrom.Location())
+		assert.True(t, change.EffectiveFrom.Equal(from))
+		assert.True(t, change.Scheduled)
+	})
+
+	t.Run("in the past", func(t *testing.T) {
+		past := time.Now().Add(-time.Minute)
+		_, err := svc.SchedulePrice(ctx, "p1", &PriceChangeReq{Price: floatPtr(10.5), EffectiveFrom: &past})
+		assert.Equal(t, 400, err.(*apperrors.AppError).Code)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(ctx, "p9").Return(nil, ErrProductNotFound)
+
+		_, err := svc.SchedulePrice(ctx, "p9", &PriceChangeReq{Price: floatPtr(10.5), EffectiveFrom: &from})
+		assert.Equal(t, ErrProductNotFound, err)
+	})
+}
+
+func TestService_ApplyDuePrices(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	svc := NewService(mockRepo, nil, time.Hour)
+	ctx := context.Background()
+
+	mockRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]*Product{{ID: "p1", Name: "Burger", Price: 9}}, nil)
+	_, err := svc.SearchProducts(ctx, "burger", 0)
+	assert.NoError(t, err)
+
+	t.Run("nothing due keeps the search index", func(t *testing.T) {
+		mockRepo.EXPECT().ApplyDuePrices(ctx).Return(int64(0), nil)
+
+		n, err := svc.ApplyDuePrices(ctx)
+		assert.NoError(t, err)
+		assert.Zero(t, n)
+		_, err = svc.SearchProducts(ctx, "burger", 0)
+		assert.NoError(t, err)
+	})
+
+	t.Run("changed prices reprice the search index", func(t *testing.T) {
+		mockRepo.EXPECT().ApplyDuePrices(ctx).Return(int64(1), nil)
+		mockRepo.EXPECT().List(fresh(ctx), ListParams{Sort: SortName, Order: OrderAsc}).Return([]*Product{{ID: "p1", Name: "Burger", Price: 10}}, nil)
+
+		n, err := svc.ApplyDuePrices(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+
+		res, err := svc.SearchProducts(ctx, "burger", 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 10.0, res.Items[0].Price)
+	})
+}
+
+func TestService_CreatePriceRule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	svc := NewService(mockRepo, nil, time.Hour)
+	ctx := context.Background()
+	windows := []schedule.Window{{Days: []string{"fri"}, Start: "17:00", End: "19:00"}}
+
+	t.Run("category rule defaults to UTC", func(t *testing.T) {
+		mockRepo.EXPECT().CategoryName(ctx, burgerCategoryID).Return("Burgers", nil)
+		mockRepo.EXPECT().CreatePriceRule(ctx, gomock.Any()).Return(nil)
+
+		rule, err := svc.CreatePriceRule(ctx, &PriceRuleReq{Name: "Happy hour", CategoryID: burgerCategoryID, PercentOff: 20, Windows: windows})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, rule.ID)
+		assert.Equal(t, "UTC", rule.TimeZone)
+		assert.Equal(t, windows, rule.Windows)
+	})
+
+	t.Run("unknown category", func(t *testing.T) {
+		mockRepo.EXPECT().CategoryName(ctx, burgerCategoryID).Return("", ErrUnknownCategory)
+
+		_, err := svc.CreatePriceRule(ctx, &PriceRuleReq{Name: "Happy hour", CategoryID: burgerCategoryID, PercentOff: 20, Windows: windows})
+		assert.Equal(t, ErrUnknownCategory, err)
+	})
+
+	t.Run("unknown product", func(t *testing.T) {
+		mockRepo.EXPECT().GetByID(ctx, burgerCategoryID).Return(nil, ErrProductNotFound)
+
+		_, err := svc.CreatePriceRule(ctx, &PriceRuleReq{Name: "Happy hour", ProductID: burgerCategoryID, PercentOff: 20, Windows: windows})
+		assert.Equal(t, ErrProductNotFound, err)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		_, err := svc.CreatePriceRule(ctx, &PriceRuleReq{Name: "Happy hour", Windows: windows})
+		assert.Equal(t, 400, err.(*apperrors.AppError).Code)
+	})
+}
@@ -1,15 +1,71 @@
 package product
 
-import "github.com/labstack/echo/v4"
+import (
+	"strconv"
+	"time"
 
-func Setup(e *echo.Echo, repo Repository) {
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/audit"
+	"github.com/mohammadshabab/order-food-online/internal/auth"
+)
+
+// Setup registers the product routes and returns the service so other
+// packages can read the catalog through it. The search index is rebuilt from
+// repo once it is older than indexTTL. Admin changes are recorded in the audit
+// log.
+func Setup(e *echo.Echo, repo Repository, schedules Schedules, indexTTL time.Duration, rec *audit.Recorder) Service {
 	// Create service
-	svc := NewService(repo)
+	svc := NewService(repo, schedules, indexTTL)
 
 	// Create handler
 	h := NewHandler(svc)
 
 	// Register routes
-	e.GET("/product", h.ListProducts)
-	e.GET("/product/:productId", h.GetProduct)
+	read := auth.RequireScope(auth.ScopeCatalogRead)
+	e.GET("/product", h.ListProducts, read)
+	e.GET("/product/search", h.SearchProducts, read)
+	e.GET("/product/:productId", h.GetProduct, read)
+	e.GET("/product/:productId/price", h.GetPrice, read)
+	e.GET("/restaurant/:restaurantId/product", h.ListRestaurantProducts, read)
+
+	// Admin
+	admin := auth.RequireScope(auth.ScopeProductsAdmin)
+	current := func(c echo.Context) (any, error) {
+		return svc.GetProduct(c.Request().Context(), c.Param("productId"))
+	}
+	logged := func(name string) echo.MiddlewareFunc {
+		return rec.Middleware(audit.Action{Name: name, Resource: "product", IDParam: "productId", Before: current})
+	}
+	currentRule := func(c echo.Context) (any, error) {
+		rules, err := svc.ListPriceRules(c.Request().Context())
+		for _, r := range rules {
+			if r.ID == c.Param("ruleId") {
+				return r, err
+			}
+		}
+		return nil, err
+	}
+	dryRun := func(c echo.Context) bool {
+		v, _ := strconv.ParseBool(c.QueryParam("dryRun"))
+		return v
+	}
+
+	e.POST("/product", h.CreateProduct, admin, rec.Middleware(audit.Action{Name: "product.create", Resource: "product"}))
+	e.PUT("/product/:productId", h.UpdateProduct, admin, logged("product.replace"))
+	e.PATCH("/product/:productId", h.PatchProduct, admin, logged("product.update"))
+	e.DELETE("/product/:productId", h.DeleteProduct, admin, logged("product.archive"))
+	e.PUT("/product/:productId/modifiers", h.SetModifierGroups, admin, logged("product.modifiers"))
+	e.PUT("/product/:productId/dietary", h.SetDietary, admin, logged("product.dietary"))
+	e.POST("/product/:productId/stock", h.AdjustStock, admin, logged("product.stock"))
+	e.GET("/product/export", h.ExportProducts, admin)
+	e.POST("/product/import", h.ImportProducts, admin, rec.Middleware(audit.Action{Name: "product.import", Resource: "product", Skip: dryRun}))
+	e.GET("/product/:productId/prices", h.ListPrices, admin)
+	e.POST("/product/:productId/prices", h.SchedulePrice, admin, logged("product.price.schedule"))
+	e.DELETE("/product/:productId/prices/:priceId", h.CancelPriceChange, admin, logged("product.price.cancel"))
+	e.GET("/price-rule", h.ListPriceRules, admin)
+	e.POST("/price-rule", h.CreatePriceRule, admin, rec.Middleware(audit.Action{Name: "price_rule.create", Resource: "price_rule"}))
+	e.DELETE("/price-rule/:ruleId", h.DeletePriceRule, admin,
+		rec.Middleware(audit.Action{Name: "price_rule.delete", Resource: "price_rule", IDParam: "ruleId", Before: currentRule}))
+
+	return svc
 }
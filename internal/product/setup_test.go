@@ -3,6 +3,7 @@ package product
 import (
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/labstack/echo/v4"
@@ -20,7 +21,7 @@ func TestSetup(t *testing.T) {
 		e := echo.New()
 
 		// call the Setup function to register routes
-		Setup(e, mockRepo)
+		Setup(e, mockRepo, nil, time.Minute, nil)
 
 		// verify routes
 		routes := e.Routes()
@@ -42,4 +43,43 @@ func TestSetup(t *testing.T) {
 			t.Errorf("expected GET /product/:productId to be registered but it was not")
 		}
 	})
+
+	t.Run("should register search and admin routes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		e := echo.New()
+		Setup(e, NewMockRepository(ctrl), nil, time.Minute, nil)
+
+		want := map[string]bool{
+			http.MethodGet + " /product/search":                        false,
+			http.MethodPost + " /product":                              false,
+			http.MethodPut + " /product/:productId":                    false,
+			http.MethodPatch + " /product/:productId":                  false,
+			http.MethodDelete + " /product/:productId":                 false,
+			http.MethodPut + " /product/:productId/modifiers":          false,
+			http.MethodPost + " /product/:productId/stock":             false,
+			http.MethodPut + " /product/:productId/dietary":            false,
+			http.MethodPost + " /product/import":                       false,
+			http.MethodGet + " /product/export":                        false,
+			http.MethodGet + " /product/:productId/price":              false,
+			http.MethodGet + " /restaurant/:restaurantId/product":      false,
+			http.MethodGet + " /product/:productId/prices":             false,
+			http.MethodPost + " /product/:productId/prices":            false,
+			http.MethodDelete + " /product/:productId/prices/:priceId": false,
+			http.MethodGet + " /price-rule":                            false,
+			http.MethodPost + " /price-rule":                           false,
+			http.MethodDelete + " /price-rule/:ruleId":                 false,
+		}
+		for _, r := range e.Routes() {
+			if _, ok := want[r.Method+" "+r.Path]; ok {
+				want[r.Method+" "+r.Path] = true
+			}
+		}
+		for route, found := range want {
+			if !found {
+				t.Errorf("expected %s to be registered but it was not", route)
+			}
+		}
+	})
 }
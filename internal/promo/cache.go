@@ -11,6 +11,7 @@ import (
 type Cache interface {
 	Set(code string, cp Coupon)
 	Get(code string) (Coupon, bool)
+	Len() int
 	SetTotalFiles(n int)
 	IncrementLoaded()
 	Progress() float64
@@ -57,6 +58,14 @@ func (c *couponCache) Get(code string) (Coupon, bool) {
 	return v, ok
 }
 
+// Len returns the number of distinct coupon codes loaded
+func (c *couponCache) Len() int {
+	c.mu.RLock()
+	n := len(c.store)
+	c.mu.RUnlock()
+	return n
+}
+
 func (c *couponCache) SetTotalFiles(n int) {
 	c.mu.Lock()
 	c.totalFiles = n
@@ -27,6 +27,11 @@ func TestCacheSetAndGet(t *testing.T) {
 	if cp.FileCount != 2 {
 		t.Fatalf("expected FileCount=2, got %d", cp.FileCount)
 	}
+
+	c.Set("XYZ12345", Coupon{Code: "XYZ12345"})
+	if c.Len() != 2 {
+		t.Fatalf("expected Len=2, got %d", c.Len())
+	}
 }
 
 func TestSetTotalFiles(t *testing.T) {
@@ -20,12 +20,14 @@ type LoaderConfig struct {
 func LoadCouponsWithContext(ctx context.Context, cfg LoaderConfig, cache Cache) error {
 	files, err := filepath.Glob(filepath.Join(cfg.Dir, "*.gz"))
 	if err != nil {
+		loadsTotal.Inc(loadError)
 		return err
 	}
 
 	cache.SetTotalFiles(len(files))
 	if len(files) == 0 {
 		cache.MarkReady()
+		loadsTotal.Inc(loadSuccess)
 		logger.Info(ctx, "[Promo Loader] No .gz files found, marking ready")
 		return nil
 	}
@@ -50,6 +52,7 @@ func LoadCouponsWithContext(ctx context.Context, cfg LoaderConfig, cache Cache)
 					logger.Debug(ctx, "[Promo Loader] Loaded file: %s", path)
 				}
 				cache.IncrementLoaded()
+				recordProgress(cache)
 
 				logger.Debug(ctx, "[Promo Loader] Progress: %.2f%%", cache.Progress()*100)
 			}
@@ -75,11 +78,13 @@ func LoadCouponsWithContext(ctx context.Context, cfg LoaderConfig, cache Cache)
 
 	select {
 	case <-ctx.Done():
+		loadsTotal.Inc(loadTimeout)
 		logger.Error(ctx, "[Promo Loader] Timeout while loading coupons")
 		return ctx.Err()
 	case <-doneCh:
 		cache.MarkLoadedSuccessfully()
 		cache.MarkReady()
+		loadsTotal.Inc(loadSuccess)
 		logger.Info(ctx, "[Promo Loader] All files processed successfully")
 		return nil
 	}
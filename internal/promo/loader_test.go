@@ -45,7 +45,8 @@ func TestLoadCouponsWithContext(t *testing.T) {
 	file1 := writeGzipFile(t, tmp, "a.gz", []string{"A1", "A2"})
 	file2 := writeGzipFile(t, tmp, "b.gz", []string{"B1", "B2", ""})
 
-	mockCache.EXPECT().Progress().AnyTimes()
+	mockCache.EXPECT().Progress().Return(1.0).AnyTimes()
+	mockCache.EXPECT().Len().Return(4).AnyTimes()
 	// We expect total = 2 files
 	mockCache.EXPECT().SetTotalFiles(2)
 	// For each file worker increments `IncrementLoaded`
@@ -69,10 +70,17 @@ func TestLoadCouponsWithContext(t *testing.T) {
 		WorkerCount: 2,
 	}
 
+	loads := loadsTotal.Value(loadSuccess)
 	err := LoadCouponsWithContext(ctx, cfg, mockCache)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
+	if loadsTotal.Value(loadSuccess) != loads+1 {
+		t.Fatalf("expected the load to be counted")
+	}
+	if cacheCoupons.Value() != 4 || loadProgress.Value() != 1 {
+		t.Fatalf("expected cache size 4 and progress 1, got %v and %v", cacheCoupons.Value(), loadProgress.Value())
+	}
 
 	// Ensure files actually existed
 	if _, err := os.Stat(file1); err != nil {
@@ -0,0 +1,35 @@
+package promo
+
+import "github.com/mohammadshabab/order-food-online/internal/metrics"
+
+// Results of a coupon load
+const (
+	loadSuccess = "success"
+	loadError   = "error"
+	loadTimeout = "timeout"
+)
+
+// Reasons a coupon code is rejected
+const (
+	rejectInvalidFormat    = "invalid_format"
+	rejectUnknown          = "unknown_code"
+	rejectTooFewFiles      = "too_few_files"
+	rejectNotForRestaurant = "not_for_restaurant"
+)
+
+var (
+	cacheCoupons = metrics.NewGauge("promo_cache_coupons",
+		"Distinct coupon codes in the promo cache.")
+	loadProgress = metrics.NewGauge("promo_load_progress",
+		"Share of coupon files loaded by the current or last load, from 0 to 1.")
+	loadsTotal = metrics.NewCounter("promo_loads_total",
+		"Coupon loads and reloads, by result.", "result")
+	rejectionsTotal = metrics.NewCounter("promo_coupon_rejections_total",
+		"Coupon codes rejected, by reason.", "reason")
+)
+
+// recordProgress publishes the size and load progress of cache
+func recordProgress(cache Cache) {
+	cacheCoupons.Set(float64(cache.Len()))
+	loadProgress.Set(cache.Progress())
+}
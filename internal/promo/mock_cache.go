@@ -75,6 +75,20 @@ func (mr *MockCacheMockRecorder) IsReady() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsReady", reflect.TypeOf((*MockCache)(nil).IsReady))
 }
 
+// Len mocks base method.
+func (m *MockCache) Len() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Len")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// Len indicates an expected call of Len.
+func (mr *MockCacheMockRecorder) Len() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Len", reflect.TypeOf((*MockCache)(nil).Len))
+}
+
 // LoadedSuccessfully mocks base method.
 func (m *MockCache) LoadedSuccessfully() bool {
 	m.ctrl.T.Helper()
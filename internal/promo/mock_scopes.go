@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: scopes.go
+
+// Package promo is a generated GoMock package.
+package promo
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockScopes is a mock of Scopes interface.
+type MockScopes struct {
+	ctrl     *gomock.Controller
+	recorder *MockScopesMockRecorder
+}
+
+// MockScopesMockRecorder is the mock recorder for MockScopes.
+type MockScopesMockRecorder struct {
+	mock *MockScopes
+}
+
+// NewMockScopes creates a new mock instance.
+func NewMockScopes(ctrl *gomock.Controller) *MockScopes {
+	mock := &MockScopes{ctrl: ctrl}
+	mock.recorder = &MockScopesMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockScopes) EXPECT() *MockScopesMockRecorder {
+	return m.recorder
+}
+
+// CouponRestaurants mocks base method.
+func (m *MockScopes) CouponRestaurants(ctx context.Context, code string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CouponRestaurants", ctx, code)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CouponRestaurants indicates an expected call of CouponRestaurants.
+func (mr *MockScopesMockRecorder) CouponRestaurants(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CouponRestaurants", reflect.TypeOf((*MockScopes)(nil).CouponRestaurants), ctx, code)
+}
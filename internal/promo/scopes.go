@@ -0,0 +1,22 @@
+package promo
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+)
+
+// ErrCouponNotForRestaurant rejects a scoped coupon used on another restaurant's order
+var ErrCouponNotForRestaurant = apperrors.Wrap(http.StatusUnprocessableEntity, "coupon code is not valid for this restaurant", apperrors.LevelWarn, nil).WithCode(apperrors.CodeCouponNotForRestaurant)
+
+// Scopes knows which restaurants a coupon is limited to. The coupon files
+// only say whether a code exists; scopes are kept in the database and
+// restaurant.Repository satisfies this interface.
+//
+//go:generate mockgen -source=scopes.go -destination=mock_scopes.go -package=promo
+type Scopes interface {
+	// CouponRestaurants returns the ids of the restaurants a coupon is scoped
+	// to, none when it is valid on every restaurant
+	CouponRestaurants(ctx context.Context, code string) ([]string, error)
+}
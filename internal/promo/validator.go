@@ -10,7 +10,15 @@ import (
 )
 
 type Validator struct {
-	cache Cache
+	cache  Cache
+	scopes Scopes
+}
+
+// WithScopes makes ValidateFor check coupon scopes; without them every
+// valid coupon applies to every restaurant
+func (v *Validator) WithScopes(scopes Scopes) *Validator {
+	v.scopes = scopes
+	return v
 }
 
 // New creates a new Validator and loads coupons with context timeout
@@ -43,25 +51,61 @@ func New(dir string) (*Validator, error) {
 }
 
 func (v *Validator) Validate(code string) error {
+	return v.validate(context.Background(), code)
+}
+
+// validate checks the code, logging with ctx so failures carry the request ID
+func (v *Validator) validate(ctx context.Context, code string) error {
 	if len(code) < 8 || len(code) > 10 {
-		err := apperrors.BadRequest(fmt.Sprintf("invalid coupon code format: %s", code), nil)
-		logger.Warn(context.Background(), "Coupon validation failed: invalid length", "code", code, "error", err)
+		err := apperrors.BadRequest(fmt.Sprintf("invalid coupon code format: %s", code), nil).WithCode(apperrors.CodeInvalidCoupon)
+		logger.Warn(ctx, "Coupon validation failed: invalid length", "code", code, "error", err)
+		rejectionsTotal.Inc(rejectInvalidFormat)
 		return err
 	}
 
 	cp, ok := v.cache.Get(code)
 	if !ok {
-		err := apperrors.BadRequest(fmt.Sprintf("invalid coupon code: %s", code), nil)
-		logger.Warn(context.Background(), "Coupon validation failed: not found in cache", "code", code, "error", err)
+		err := apperrors.BadRequest(fmt.Sprintf("invalid coupon code: %s", code), nil).WithCode(apperrors.CodeInvalidCoupon)
+		logger.Warn(ctx, "Coupon validation failed: not found in cache", "code", code, "error", err)
+		rejectionsTotal.Inc(rejectUnknown)
 		return err
 	}
 
 	if cp.FileCount < 2 {
-		err := apperrors.BadRequest(fmt.Sprintf("invalid coupon code (not in enough files): %s", code), nil)
-		logger.Warn(context.Background(), "Coupon validation failed: insufficient file count", "code", code, "error", err)
+		err := apperrors.BadRequest(fmt.Sprintf("invalid coupon code (not in enough files): %s", code), nil).WithCode(apperrors.CodeInvalidCoupon)
+		logger.Warn(ctx, "Coupon validation failed: insufficient file count", "code", code, "error", err)
+		rejectionsTotal.Inc(rejectTooFewFiles)
 		return err
 	}
 
-	logger.Debug(context.Background(), "Coupon validated successfully", "code", code)
+	logger.Debug(ctx, "Coupon validated successfully", "code", code)
 	return nil
 }
+
+// ValidateFor validates a coupon for an order from one restaurant: the code
+// must pass Validate and, if it is scoped, be scoped to that restaurant
+func (v *Validator) ValidateFor(ctx context.Context, code, restaurantID string) error {
+	if err := v.validate(ctx, code); err != nil {
+		return err
+	}
+	if v.scopes == nil {
+		return nil
+	}
+
+	restaurants, err := v.scopes.CouponRestaurants(ctx, code)
+	if err != nil {
+		return err
+	}
+	if len(restaurants) == 0 {
+		return nil
+	}
+	for _, id := range restaurants {
+		if id == restaurantID {
+			return nil
+		}
+	}
+
+	logger.Warn(ctx, "Coupon validation failed: scoped to other restaurants", "code", code, "restaurantId", restaurantID)
+	rejectionsTotal.Inc(rejectNotForRestaurant)
+	return ErrCouponNotForRestaurant
+}
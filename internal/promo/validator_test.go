@@ -1,6 +1,8 @@
 package promo
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 	"testing"
 
@@ -20,18 +22,22 @@ func TestValidator_Validate(t *testing.T) {
 	validator := &Validator{cache: mockCache}
 
 	t.Run("invalid code length", func(t *testing.T) {
+		before := rejectionsTotal.Value(rejectInvalidFormat)
 		err := validator.Validate("SHORT")
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "invalid coupon code format")
+		require.Equal(t, before+1, rejectionsTotal.Value(rejectInvalidFormat))
 	})
 
 	t.Run("code not found in cache", func(t *testing.T) {
 		code := "VALID123"
 		mockCache.EXPECT().Get(code).Return(Coupon{}, false)
 
+		before := rejectionsTotal.Value(rejectUnknown)
 		err := validator.Validate(code)
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "invalid coupon code")
+		require.Equal(t, before+1, rejectionsTotal.Value(rejectUnknown))
 	})
 
 	t.Run("code found but insufficient file count", func(t *testing.T) {
@@ -57,3 +63,44 @@ func TestValidator_Validate(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestValidator_ValidateFor(t *testing.T) {
+	logger.Init("test-service", "test", slog.LevelInfo)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	code := "VALID123"
+	mockCache := NewMockCache(ctrl)
+	mockCache.EXPECT().Get(code).Return(Coupon{Code: code, FileCount: 2}, true).AnyTimes()
+	mockScopes := NewMockScopes(ctrl)
+	validator := (&Validator{cache: mockCache}).WithScopes(mockScopes)
+
+	t.Run("unscoped coupon is valid everywhere", func(t *testing.T) {
+		mockScopes.EXPECT().CouponRestaurants(ctx, code).Return(nil, nil)
+		require.NoError(t, validator.ValidateFor(ctx, code, "r1"))
+	})
+
+	t.Run("scoped to the restaurant", func(t *testing.T) {
+		mockScopes.EXPECT().CouponRestaurants(ctx, code).Return([]string{"r1", "r2"}, nil)
+		require.NoError(t, validator.ValidateFor(ctx, code, "r2"))
+	})
+
+	t.Run("scoped to other restaurants", func(t *testing.T) {
+		mockScopes.EXPECT().CouponRestaurants(ctx, code).Return([]string{"r1"}, nil)
+		require.Equal(t, ErrCouponNotForRestaurant, validator.ValidateFor(ctx, code, "r3"))
+	})
+
+	t.Run("scope lookup fails", func(t *testing.T) {
+		mockScopes.EXPECT().CouponRestaurants(ctx, code).Return(nil, errors.New("db down"))
+		require.EqualError(t, validator.ValidateFor(ctx, code, "r1"), "db down")
+	})
+
+	t.Run("invalid code is not looked up", func(t *testing.T) {
+		require.Error(t, validator.ValidateFor(ctx, "SHORT", "r1"))
+	})
+
+	t.Run("without scopes", func(t *testing.T) {
+		require.NoError(t, (&Validator{cache: mockCache}).ValidateFor(ctx, code, "r1"))
+	})
+}
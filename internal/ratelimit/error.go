@@ -0,0 +1,9 @@
+package ratelimit
+
+import (
+	"net/http"
+
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+)
+
+var ErrTooManyRequests = apperrors.Wrap(http.StatusTooManyRequests, "too many requests", apperrors.LevelWarn, nil)
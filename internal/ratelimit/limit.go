@@ -0,0 +1,96 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit allows Requests per Period. It is a token bucket holding Requests
+// tokens that refills at Requests per Period, so bursts up to the full limit
+// are allowed after a quiet period.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Enabled reports whether the limit applies, a zero Limit never limits
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Period > 0
+}
+
+// rate is the refill rate in tokens per second
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+func (l Limit) String() string {
+	return fmt.Sprintf("%d/%s", l.Requests, l.Period)
+}
+
+// Config holds the limit of every route, keyed by method and route path as
+// registered (e.g. "POST /order"). Routes without an entry use Default.
+type Config struct {
+	Default Limit
+	Routes  map[string]Limit
+}
+
+// ParseLimit parses "<requests>/<period>", where period is s, m, h or a Go
+// duration such as 10s. An empty string is no limit.
+func ParseLimit(s string) (Limit, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Limit{}, nil
+	}
+
+	count, period, found := strings.Cut(s, "/")
+	if !found {
+		return Limit{}, fmt.Errorf("rate limit %q must look like 10/m", s)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(count))
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("rate limit %q must allow a positive number of requests", s)
+	}
+
+	var d time.Duration
+	switch period = strings.TrimSpace(period); period {
+	case "s":
+		d = time.Second
+	case "m":
+		d = time.Minute
+	case "h":
+		d = time.Hour
+	default:
+		d, err = time.ParseDuration(period)
+		if err != nil || d <= 0 {
+			return Limit{}, fmt.Errorf("rate limit %q has an invalid period", s)
+		}
+	}
+	return Limit{Requests: n, Period: d}, nil
+}
+
+// ParseRoutes parses comma separated "<METHOD> <path>=<limit>" entries, e.g.
+// "POST /order=10/m, POST /order/:orderId/cancel=5/m"
+func ParseRoutes(s string) (map[string]Limit, error) {
+	routes := map[string]Limit{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		route, limit, found := strings.Cut(entry, "=")
+		method, path, hasPath := strings.Cut(strings.TrimSpace(route), " ")
+		if !found || !hasPath || strings.TrimSpace(path) == "" {
+			return nil, fmt.Errorf("route rate limit %q must look like POST /order=10/m", entry)
+		}
+
+		l, err := ParseLimit(limit)
+		if err != nil {
+			return nil, err
+		}
+		routes[strings.ToUpper(method)+" "+strings.TrimSpace(path)] = l
+	}
+	return routes, nil
+}
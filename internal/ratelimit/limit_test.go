@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Limit
+		wantErr bool
+	}{
+		{in: "", want: Limit{}},
+		{in: "10/s", want: Limit{Requests: 10, Period: time.Second}},
+		{in: " 30 / m ", want: Limit{Requests: 30, Period: time.Minute}},
+		{in: "1000/h", want: Limit{Requests: 1000, Period: time.Hour}},
+		{in: "5/10s", want: Limit{Requests: 5, Period: 10 * time.Second}},
+		{in: "10", wantErr: true},
+		{in: "0/m", wantErr: true},
+		{in: "ten/m", wantErr: true},
+		{in: "10/week", wantErr: true},
+		{in: "10/-1s", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseLimit(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseRoutes(t *testing.T) {
+	routes, err := ParseRoutes("post /order=10/m, POST /order/:orderId/cancel=5/m,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Limit{
+		"POST /order":                 {Requests: 10, Period: time.Minute},
+		"POST /order/:orderId/cancel": {Requests: 5, Period: time.Minute},
+	}, routes)
+
+	routes, err = ParseRoutes("")
+	require.NoError(t, err)
+	assert.Empty(t, routes)
+
+	_, err = ParseRoutes("/order=10/m")
+	assert.Error(t, err)
+	_, err = ParseRoutes("POST /order")
+	assert.Error(t, err)
+	_, err = ParseRoutes("POST /order=often")
+	assert.Error(t, err)
+}
@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	limit := Limit{Requests: 3, Period: 3 * time.Second}
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	for i := 2; i >= 0; i-- {
+		res, err := store.Take(ctx, "client", limit, now)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, i, res.Remaining)
+	}
+
+	res, err := store.Take(ctx, "client", limit, now)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, time.Second, res.RetryAfter)
+	assert.Equal(t, 3*time.Second, res.Reset)
+
+	// other keys have their own bucket
+	res, _ = store.Take(ctx, "other", limit, now)
+	assert.True(t, res.Allowed)
+
+	// one token is back after a second
+	res, _ = store.Take(ctx, "client", limit, now.Add(time.Second))
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+
+	// the bucket never holds more than the limit
+	res, _ = store.Take(ctx, "client", limit, now.Add(time.Hour))
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 2, res.Remaining)
+	assert.Equal(t, time.Second, res.Reset)
+}
+
+func TestMemoryStore_LimitChange(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	now := time.Now()
+
+	_, _ = store.Take(ctx, "client", Limit{Requests: 1, Period: time.Minute}, now)
+	res, _ := store.Take(ctx, "client", Limit{Requests: 5, Period: time.Minute}, now)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 4, res.Remaining)
+}
+
+func TestMemoryStore_Prune(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	limit := Limit{Requests: 2, Period: time.Minute}
+	now := time.Now()
+
+	_, _ = store.Take(ctx, "idle", limit, now.Add(-time.Hour))
+	_, _ = store.Take(ctx, "busy", limit, now)
+
+	assert.Equal(t, 1, store.Prune(now))
+	assert.Len(t, store.buckets, 1)
+	assert.Contains(t, store.buckets, "busy")
+}
@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/auth"
+	"github.com/mohammadshabab/order-food-online/internal/health"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+)
+
+// Response headers, following the IETF RateLimit header fields draft
+const (
+	HeaderLimit      = "RateLimit-Limit"
+	HeaderRemaining  = "RateLimit-Remaining"
+	HeaderReset      = "RateLimit-Reset"
+	HeaderPolicy     = "RateLimit-Policy"
+	HeaderRetryAfter = "Retry-After"
+)
+
+// Middleware limits requests per route and client. Clients are told apart by
+// their API key, their bearer token subject or, for neither, their IP, so it
+// must run after the authentication middlewares. Requests over the limit get a
+// 429 with Retry-After. When the store fails requests are let through.
+func Middleware(store Store, cfg Config) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if health.IsHealthCheck(c.Path()) {
+				return next(c)
+			}
+
+			route := c.Request().Method + " " + c.Path()
+			limit, ok := cfg.Routes[route]
+			if !ok {
+				limit = cfg.Default
+			}
+			if !limit.Enabled() {
+				return next(c)
+			}
+
+			ctx := c.Request().Context()
+			client := clientID(c)
+			res, err := store.Take(ctx, route+"|"+client, limit, time.Now())
+			if err != nil {
+				logger.Error(ctx, "rate limit store failed, request let through", "route", route, "error", err.Error())
+				return next(c)
+			}
+
+			h := c.Response().Header()
+			h.Set(HeaderLimit, strconv.Itoa(limit.Requests))
+			h.Set(HeaderRemaining, strconv.Itoa(res.Remaining))
+			h.Set(HeaderReset, ceilSeconds(res.Reset))
+			h.Set(HeaderPolicy, strconv.Itoa(limit.Requests)+";w="+ceilSeconds(limit.Period))
+
+			if !res.Allowed {
+				h.Set(HeaderRetryAfter, ceilSeconds(res.RetryAfter))
+				logger.Warn(ctx, ErrTooManyRequests.Message, "route", route, "client", client, "limit", limit.String())
+				return ErrTooManyRequests
+			}
+			return next(c)
+		}
+	}
+}
+
+// clientID names the caller a bucket belongs to
+func clientID(c echo.Context) string {
+	ctx := c.Request().Context()
+	if k := auth.FromContext(ctx); k != nil {
+		if k.ID != "" {
+			return "key:" + k.ID
+		}
+		// the bootstrap key has no ID
+		return "key:" + k.Name
+	}
+	if u := auth.UserFromContext(ctx); u != nil {
+		return "user:" + u.Subject
+	}
+	return "ip:" + c.RealIP()
+}
+
+// ceilSeconds formats d as whole seconds, rounded up so clients never retry early
+func ceilSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
@@ -0,0 +1,112 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/auth"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+
+	cfg := Config{
+		Default: Limit{Requests: 100, Period: time.Minute},
+		Routes:  map[string]Limit{"POST /order": {Requests: 2, Period: time.Minute}},
+	}
+	next := func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	}
+	serve := func(store Store, method, path string, ctx func(context.Context) context.Context) *httptest.ResponseRecorder {
+		e := echo.New()
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = "203.0.113.7:51234"
+		if ctx != nil {
+			req = req.WithContext(ctx(req.Context()))
+		}
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath(path)
+		apperrors.Handle(Middleware(store, cfg)(next), c)
+		return rec
+	}
+
+	t.Run("route limit with headers", func(t *testing.T) {
+		store := NewMemoryStore()
+
+		rec := serve(store, http.MethodPost, "/order", nil)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, "2", rec.Header().Get(HeaderLimit))
+		assert.Equal(t, "1", rec.Header().Get(HeaderRemaining))
+		assert.Equal(t, "30", rec.Header().Get(HeaderReset))
+		assert.Equal(t, "2;w=60", rec.Header().Get(HeaderPolicy))
+
+		serve(store, http.MethodPost, "/order", nil)
+		rec = serve(store, http.MethodPost, "/order", nil)
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "30", rec.Header().Get(HeaderRetryAfter))
+		assert.Equal(t, "0", rec.Header().Get(HeaderRemaining))
+		assert.JSONEq(t, `{"type":"urn:order-food-online:problem:too_many_requests","title":"Too many requests","status":429,
+			"detail":"too many requests","instance":"/order","code":"too_many_requests"}`, rec.Body.String())
+
+		// other routes use the default limit
+		rec = serve(store, http.MethodGet, "/product", nil)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, "100", rec.Header().Get(HeaderLimit))
+	})
+
+	t.Run("clients are keyed by API key, user and IP", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		store := NewMockStore(ctrl)
+		ok := Result{Allowed: true, Remaining: 1}
+		store.EXPECT().Take(gomock.Any(), "POST /order|key:key-1", cfg.Routes["POST /order"], gomock.Any()).Return(ok, nil)
+		store.EXPECT().Take(gomock.Any(), "POST /order|key:bootstrap", gomock.Any(), gomock.Any()).Return(ok, nil)
+		store.EXPECT().Take(gomock.Any(), "POST /order|user:user-42", gomock.Any(), gomock.Any()).Return(ok, nil)
+		store.EXPECT().Take(gomock.Any(), "POST /order|ip:203.0.113.7", gomock.Any(), gomock.Any()).Return(ok, nil)
+
+		serve(store, http.MethodPost, "/order", func(ctx context.Context) context.Context {
+			return auth.NewContext(ctx, &auth.Key{ID: "key-1", Name: "kiosk"})
+		})
+		serve(store, http.MethodPost, "/order", func(ctx context.Context) context.Context {
+			return auth.NewContext(ctx, &auth.Key{Name: "bootstrap"})
+		})
+		serve(store, http.MethodPost, "/order", func(ctx context.Context) context.Context {
+			return auth.NewUserContext(ctx, &auth.User{Subject: "user-42"})
+		})
+		serve(store, http.MethodPost, "/order", nil)
+	})
+
+	t.Run("store failure lets the request through", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		store := NewMockStore(ctrl)
+		store.EXPECT().Take(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(Result{}, errors.New("store down"))
+
+		rec := serve(store, http.MethodPost, "/order", nil)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Empty(t, rec.Header().Get(HeaderLimit))
+	})
+
+	t.Run("health checks and unlimited routes are not counted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		store := NewMockStore(ctrl)
+
+		assert.Equal(t, http.StatusNoContent, serve(store, http.MethodGet, "/health", nil).Code)
+		assert.Equal(t, http.StatusNoContent, serve(store, http.MethodGet, "/health/ping", nil).Code)
+
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/product", nil), rec)
+		c.SetPath("/product")
+		apperrors.Handle(Middleware(store, Config{})(next), c)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+}
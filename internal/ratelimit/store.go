@@ -0,0 +1,27 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Result is the state of a bucket after a request took from it
+type Result struct {
+	Allowed bool
+	// Remaining is the number of requests left right now
+	Remaining int
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is how long until the next request is allowed, zero when Allowed
+	RetryAfter time.Duration
+}
+
+// Store keeps the token buckets. The in-memory store limits each instance on
+// its own; a shared store limits across instances.
+//
+//go:generate mockgen -source=store.go -destination=mock_store.go -package=ratelimit
+type Store interface {
+	// Take removes a token from the bucket of key, which refills at limit,
+	// unless the bucket is empty
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
@@ -0,0 +1,7 @@
+// Package defaults holds the default restaurant. It is a package of its own so
+// the product and schedule modules, which restaurant builds on, can use it.
+package defaults
+
+// RestaurantID is the restaurant products, orders and opening hours belong to
+// when a request names none. Migration 0013 creates it, keep the two in sync.
+const RestaurantID = "00000000-0000-4000-8000-000000000001"
@@ -0,0 +1,54 @@
+package restaurant
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/category"
+)
+
+var (
+	ErrRestaurantNotFound = apperrors.NotFound("restaurant not found", nil).WithCode(apperrors.CodeRestaurantNotFound)
+	ErrSlugTaken          = apperrors.Conflict("a restaurant with this slug already exists", nil).WithCode(apperrors.CodeSlugTaken)
+	ErrCouponNotScoped    = apperrors.NotFound("coupon is not scoped to this restaurant", nil).WithCode(apperrors.CodeCouponNotScoped)
+	ErrInvalidCouponCode  = apperrors.BadRequest("coupon code must be 8-10 characters", nil).WithCode(apperrors.CodeInvalidCoupon)
+)
+
+const (
+	maxNameLength = 100
+	maxSlugLength = 100
+
+	// coupon codes have the length the promo validator accepts
+	minCouponLength = 8
+	maxCouponLength = 10
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Validate checks the request and fills in the slug from the name when it is missing
+func (r *RestaurantReq) Validate() *apperrors.AppError {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" || len(r.Name) > maxNameLength {
+		return apperrors.BadRequest("name must be 1-100 characters", nil)
+	}
+
+	if r.Slug == "" {
+		r.Slug = category.Slugify(r.Name)
+		if r.Slug == "" {
+			return apperrors.BadRequest("slug is required when the name has no letters or digits", nil)
+		}
+	}
+	if len(r.Slug) > maxSlugLength || !slugPattern.MatchString(r.Slug) {
+		return apperrors.BadRequest("slug must be lower case letters, digits and single dashes, at most 100 characters", nil)
+	}
+	return nil
+}
+
+// ValidateCouponCode checks the code of a coupon scope
+func ValidateCouponCode(code string) *apperrors.AppError {
+	if len(code) < minCouponLength || len(code) > maxCouponLength {
+		return ErrInvalidCouponCode
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package restaurant
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestaurantReq_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     RestaurantReq
+		slug    string
+		message string
+	}{
+		{name: "slug from name", req: RestaurantReq{Name: " Luigi's Pizza "}, slug: "luigi-s-pizza"},
+		{name: "explicit slug", req: RestaurantReq{Name: "Luigi's Pizza", Slug: "luigi"}, slug: "luigi"},
+		{name: "missing name", req: RestaurantReq{}, message: "name must be 1-100 characters"},
+		{name: "name without letters", req: RestaurantReq{Name: "&&"}, message: "slug is required when the name has no letters or digits"},
+		{name: "invalid slug", req: RestaurantReq{Name: "Luigi", Slug: "Luigi Pizza"}, message: "slug must be lower case letters, digits and single dashes, at most 100 characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			appErr := req.Validate()
+			if tt.message == "" {
+				assert.Nil(t, appErr)
+				assert.Equal(t, tt.slug, req.Slug)
+				return
+			}
+			assert.Equal(t, tt.message, appErr.Message)
+		})
+	}
+}
+
+func TestValidateCouponCode(t *testing.T) {
+	assert.Nil(t, ValidateCouponCode("LUIGI10A"))
+	assert.Nil(t, ValidateCouponCode("LUIGI10ABC"))
+	assert.Equal(t, ErrInvalidCouponCode, ValidateCouponCode("LUIGI10"))
+	assert.Equal(t, ErrInvalidCouponCode, ValidateCouponCode("LUIGI10ABCD"))
+}
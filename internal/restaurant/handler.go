@@ -0,0 +1,121 @@
+package restaurant
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+)
+
+type Handler struct {
+	svc Service
+}
+
+func NewHandler(svc Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+func (h *Handler) ListRestaurants(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	res, err := h.svc.ListRestaurants(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// GetRestaurant serves GET /restaurant/{restaurantId}
+func (h *Handler) GetRestaurant(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, appErr := restaurantID(c)
+	if appErr != nil {
+		return appErr
+	}
+
+	res, err := h.svc.GetRestaurant(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) CreateRestaurant(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req RestaurantReq
+	if err := c.Bind(&req); err != nil {
+		return apperrors.BadRequest("invalid restaurant request", err)
+	}
+
+	res, err := h.svc.CreateRestaurant(ctx, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, res)
+}
+
+// ListCoupons serves GET /restaurant/{restaurantId}/coupon
+func (h *Handler) ListCoupons(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, appErr := restaurantID(c)
+	if appErr != nil {
+		return appErr
+	}
+
+	res, err := h.svc.ListCoupons(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// ScopeCoupon serves PUT /restaurant/{restaurantId}/coupon/{code}
+func (h *Handler) ScopeCoupon(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, appErr := restaurantID(c)
+	if appErr != nil {
+		return appErr
+	}
+
+	if err := h.svc.ScopeCoupon(ctx, id, c.Param("code")); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// UnscopeCoupon serves DELETE /restaurant/{restaurantId}/coupon/{code}
+func (h *Handler) UnscopeCoupon(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, appErr := restaurantID(c)
+	if appErr != nil {
+		return appErr
+	}
+
+	if err := h.svc.UnscopeCoupon(ctx, id, c.Param("code")); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func restaurantID(c echo.Context) (string, *apperrors.AppError) {
+	id := c.Param("restaurantId")
+	if _, err := uuid.Parse(id); err != nil {
+		appErr := apperrors.BadRequest("invalid ID supplied", err)
+		logger.Warn(c.Request().Context(), appErr.Message, "id", id)
+		return "", appErr
+	}
+	return id, nil
+}
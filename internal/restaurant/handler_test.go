@@ -0,0 +1,177 @@
+package restaurant
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_ListRestaurants(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+
+	t.Run("success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().ListRestaurants(gomock.Any()).
+			Return(&RestaurantList{Items: []*Restaurant{{ID: luigiID, Slug: "luigi", Name: "Luigi's"}}}, nil)
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/restaurant", nil), rec)
+
+		apperrors.Handle(NewHandler(mockSvc).ListRestaurants, c)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"items":[{"id":"`+luigiID+`","slug":"luigi","name":"Luigi's"}]}`, rec.Body.String())
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().ListRestaurants(gomock.Any()).Return(nil, errors.New("db down"))
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/restaurant", nil), rec)
+
+		apperrors.Handle(NewHandler(mockSvc).ListRestaurants, c)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestHandler_GetRestaurant(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+
+	get := func(h *Handler, id string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/restaurant/"+id, nil), rec)
+		c.SetParamNames("restaurantId")
+		c.SetParamValues(id)
+		apperrors.Handle(h.GetRestaurant, c)
+		return rec
+	}
+
+	t.Run("found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().GetRestaurant(gomock.Any(), luigiID).Return(&Restaurant{ID: luigiID, Slug: "luigi", Name: "Luigi's"}, nil)
+
+		rec := get(NewHandler(mockSvc), luigiID)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().GetRestaurant(gomock.Any(), luigiID).Return(nil, ErrRestaurantNotFound)
+
+		rec := get(NewHandler(mockSvc), luigiID)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		rec := get(NewHandler(NewMockService(ctrl)), "luigi")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestHandler_CreateRestaurant(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+
+	newRequest := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/restaurant", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		return req
+	}
+
+	t.Run("created", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().CreateRestaurant(gomock.Any(), &RestaurantReq{Name: "Luigi's"}).
+			Return(&Restaurant{ID: luigiID, Slug: "luigi-s", Name: "Luigi's"}, nil)
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(newRequest(`{"name":"Luigi's"}`), rec)
+
+		apperrors.Handle(NewHandler(mockSvc).CreateRestaurant, c)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("slug taken", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().CreateRestaurant(gomock.Any(), gomock.Any()).Return(nil, ErrSlugTaken)
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(newRequest(`{"name":"Default"}`), rec)
+
+		apperrors.Handle(NewHandler(mockSvc).CreateRestaurant, c)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(newRequest(`{"name":`), rec)
+
+		apperrors.Handle(NewHandler(NewMockService(ctrl)).CreateRestaurant, c)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestHandler_Coupons(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	e := echo.New()
+
+	call := func(handle func(echo.Context) error, method, id, code string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(method, "/restaurant/"+id+"/coupon/"+code, nil), rec)
+		c.SetParamNames("restaurantId", "code")
+		c.SetParamValues(id, code)
+		apperrors.Handle(handle, c)
+		return rec
+	}
+
+	t.Run("list", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().ListCoupons(gomock.Any(), luigiID).Return(&CouponList{Codes: []string{"LUIGI10A"}}, nil)
+
+		rec := call(NewHandler(mockSvc).ListCoupons, http.MethodGet, luigiID, "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"codes":["LUIGI10A"]}`, rec.Body.String())
+	})
+
+	t.Run("scope", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().ScopeCoupon(gomock.Any(), luigiID, "LUIGI10A").Return(nil)
+
+		rec := call(NewHandler(mockSvc).ScopeCoupon, http.MethodPut, luigiID, "LUIGI10A")
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("unscope a coupon that is not scoped", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().UnscopeCoupon(gomock.Any(), luigiID, "LUIGI10A").Return(ErrCouponNotScoped)
+
+		rec := call(NewHandler(mockSvc).UnscopeCoupon, http.MethodDelete, luigiID, "LUIGI10A")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("invalid restaurant id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		rec := call(NewHandler(NewMockService(ctrl)).ScopeCoupon, http.MethodPut, "luigi", "LUIGI10A")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
@@ -0,0 +1,148 @@
+package restaurant
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/db"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+)
+
+type MariaDBRepository struct{}
+
+func NewMariaDBRepository() Repository {
+	return &MariaDBRepository{}
+}
+
+func (r *MariaDBRepository) List(ctx context.Context) ([]*Restaurant, error) {
+	query := `SELECT id, slug, name FROM restaurants ORDER BY name, id`
+
+	rows, err := db.Pool.Query(ctx, query)
+	if err != nil {
+		appErr := apperrors.Internal("failed to list restaurants", err)
+		logger.Error(ctx, appErr.Message, "error", err.Error())
+		return nil, appErr
+	}
+	defer rows.Close()
+
+	var restaurants []*Restaurant
+	for rows.Next() {
+		var res Restaurant
+		if err := rows.Scan(&res.ID, &res.Slug, &res.Name); err != nil {
+			appErr := apperrors.Internal("failed to scan restaurant row", err)
+			logger.Error(ctx, appErr.Message, "error", err.Error())
+			return nil, appErr
+		}
+		restaurants = append(restaurants, &res)
+	}
+	if err := rows.Err(); err != nil {
+		appErr := apperrors.Internal("failed to read restaurant rows", err)
+		logger.Error(ctx, appErr.Message, "error", err.Error())
+		return nil, appErr
+	}
+
+	return restaurants, nil
+}
+
+func (r *MariaDBRepository) GetByID(ctx context.Context, id string) (*Restaurant, error) {
+	var res Restaurant
+	err := db.Pool.QueryRow(ctx, `SELECT id, slug, name FROM restaurants WHERE id = ?`, id).Scan(&res.ID, &res.Slug, &res.Name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Warn(ctx, ErrRestaurantNotFound.Message, "id", id)
+			return nil, ErrRestaurantNotFound
+		}
+		appErr := apperrors.Internal("failed to fetch restaurant", err)
+		logger.Error(ctx, appErr.Message, "id", id, "error", err.Error())
+		return nil, appErr
+	}
+	return &res, nil
+}
+
+func (r *MariaDBRepository) Create(ctx context.Context, res *Restaurant) error {
+	query := `INSERT INTO restaurants (id, slug, name) VALUES (?, ?, ?)`
+
+	if _, err := db.Pool.Exec(ctx, query, res.ID, res.Slug, res.Name); err != nil {
+		appErr := apperrors.Internal("failed to create restaurant", err)
+		logger.Error(ctx, appErr.Message, "slug", res.Slug, "error", err.Error())
+		return appErr
+	}
+
+	logger.Info(ctx, "restaurant created", "id", res.ID, "slug", res.Slug)
+	return nil
+}
+
+func (r *MariaDBRepository) Coupons(ctx context.Context, restaurantID string) ([]string, error) {
+	return r.queryColumn(ctx, `SELECT code FROM coupon_scopes WHERE restaurant_id = ? ORDER BY code`, restaurantID)
+}
+
+func (r *MariaDBRepository) CouponRestaurants(ctx context.Context, code string) ([]string, error) {
+	return r.queryColumn(ctx, `SELECT restaurant_id FROM coupon_scopes WHERE code = ?`, code)
+}
+
+// queryColumn reads the single column rows of query
+func (r *MariaDBRepository) queryColumn(ctx context.Context, query string, arg string) ([]string, error) {
+	rows, err := db.Pool.Query(ctx, query, arg)
+	if err != nil {
+		appErr := apperrors.Internal("failed to list coupon scopes", err)
+		logger.Error(ctx, appErr.Message, "error", err.Error())
+		return nil, appErr
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			appErr := apperrors.Internal("failed to scan coupon scope row", err)
+			logger.Error(ctx, appErr.Message, "error", err.Error())
+			return nil, appErr
+		}
+		values = append(values, v)
+	}
+	if err := rows.Err(); err != nil {
+		appErr := apperrors.Internal("failed to read coupon scope rows", err)
+		logger.Error(ctx, appErr.Message, "error", err.Error())
+		return nil, appErr
+	}
+	return values, nil
+}
+
+func (r *MariaDBRepository) ScopeCoupon(ctx context.Context, restaurantID, code string) error {
+	// scoping a coupon twice is not an error, PUT is idempotent
+	query := `INSERT IGNORE INTO coupon_scopes (code, restaurant_id) VALUES (?, ?)`
+
+	if _, err := db.Pool.Exec(ctx, query, code, restaurantID); err != nil {
+		appErr := apperrors.Internal("failed to scope coupon", err)
+		logger.Error(ctx, appErr.Message, "restaurantId", restaurantID, "error", err.Error())
+		return appErr
+	}
+
+	logger.Info(ctx, "coupon scoped", "restaurantId", restaurantID, "code", code)
+	return nil
+}
+
+func (r *MariaDBRepository) UnscopeCoupon(ctx context.Context, restaurantID, code string) error {
+	res, err := db.Pool.Exec(ctx, `DELETE FROM coupon_scopes WHERE code = ? AND restaurant_id = ?`, code, restaurantID)
+	if err != nil {
+		appErr := apperrors.Internal("failed to unscope coupon", err)
+		logger.Error(ctx, appErr.Message, "restaurantId", restaurantID, "error", err.Error())
+		return appErr
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		appErr := apperrors.Internal("failed to read affected rows", err)
+		logger.Error(ctx, appErr.Message, "restaurantId", restaurantID, "error", err.Error())
+		return appErr
+	}
+	if n == 0 {
+		logger.Warn(ctx, ErrCouponNotScoped.Message, "restaurantId", restaurantID, "code", code)
+		return ErrCouponNotScoped
+	}
+
+	logger.Info(ctx, "coupon unscoped", "restaurantId", restaurantID, "code", code)
+	return nil
+}
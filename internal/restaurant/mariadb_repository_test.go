@@ -0,0 +1,110 @@
+package restaurant
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mohammadshabab/order-food-online/internal/db"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMariaDBRepository(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	db.Pool = db.NewTestPool(sqlDB)
+
+	repo := NewMariaDBRepository()
+	cols := []string{"id", "slug", "name"}
+
+	t.Run("list", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, slug, name FROM restaurants ORDER BY name, id").
+			WillReturnRows(sqlmock.NewRows(cols).AddRow(defaultID, "default", "Default restaurant").AddRow(luigiID, "luigi", "Luigi's"))
+
+		restaurants, err := repo.List(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []*Restaurant{
+			{ID: defaultID, Slug: "default", Name: "Default restaurant"},
+			{ID: luigiID, Slug: "luigi", Name: "Luigi's"},
+		}, restaurants)
+	})
+
+	t.Run("list fails", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM restaurants").WillReturnError(errors.New("db failed"))
+
+		_, err := repo.List(ctx)
+		assert.ErrorContains(t, err, "db failed")
+	})
+
+	t.Run("get", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, slug, name FROM restaurants WHERE id = \\?").
+			WithArgs(luigiID).
+			WillReturnRows(sqlmock.NewRows(cols).AddRow(luigiID, "luigi", "Luigi's"))
+
+		r, err := repo.GetByID(ctx, luigiID)
+		require.NoError(t, err)
+		assert.Equal(t, "Luigi's", r.Name)
+	})
+
+	t.Run("get unknown", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM restaurants WHERE id").WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.GetByID(ctx, luigiID)
+		assert.Equal(t, ErrRestaurantNotFound, err)
+	})
+
+	t.Run("create", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO restaurants \\(id, slug, name\\) VALUES").
+			WithArgs(luigiID, "luigi", "Luigi's").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.Create(ctx, &Restaurant{ID: luigiID, Slug: "luigi", Name: "Luigi's"}))
+	})
+
+	t.Run("coupons of a restaurant", func(t *testing.T) {
+		mock.ExpectQuery("SELECT code FROM coupon_scopes WHERE restaurant_id = \\? ORDER BY code").
+			WithArgs(luigiID).
+			WillReturnRows(sqlmock.NewRows([]string{"code"}).AddRow("LUIGI10A").AddRow("PIZZA2024"))
+
+		codes, err := repo.Coupons(ctx, luigiID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"LUIGI10A", "PIZZA2024"}, codes)
+	})
+
+	t.Run("restaurants of a coupon", func(t *testing.T) {
+		mock.ExpectQuery("SELECT restaurant_id FROM coupon_scopes WHERE code = \\?").
+			WithArgs("LUIGI10A").
+			WillReturnRows(sqlmock.NewRows([]string{"restaurant_id"}))
+
+		ids, err := repo.CouponRestaurants(ctx, "LUIGI10A")
+		require.NoError(t, err)
+		assert.Empty(t, ids)
+	})
+
+	t.Run("scope coupon", func(t *testing.T) {
+		mock.ExpectExec("INSERT IGNORE INTO coupon_scopes \\(code, restaurant_id\\) VALUES").
+			WithArgs("LUIGI10A", luigiID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.NoError(t, repo.ScopeCoupon(ctx, luigiID, "LUIGI10A"))
+	})
+
+	t.Run("unscope coupon", func(t *testing.T) {
+		mock.ExpectExec("DELETE FROM coupon_scopes WHERE code = \\? AND restaurant_id = \\?").
+			WithArgs("LUIGI10A", luigiID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		assert.NoError(t, repo.UnscopeCoupon(ctx, luigiID, "LUIGI10A"))
+
+		mock.ExpectExec("DELETE FROM coupon_scopes").WillReturnResult(sqlmock.NewResult(0, 0))
+		assert.Equal(t, ErrCouponNotScoped, repo.UnscopeCoupon(ctx, luigiID, "LUIGI10A"))
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
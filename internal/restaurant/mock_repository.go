@@ -0,0 +1,137 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repository.go
+
+// Package restaurant is a generated GoMock package.
+package restaurant
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CouponRestaurants mocks base method.
+func (m *MockRepository) CouponRestaurants(ctx context.Context, code string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CouponRestaurants", ctx, code)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CouponRestaurants indicates an expected call of CouponRestaurants.
+func (mr *MockRepositoryMockRecorder) CouponRestaurants(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CouponRestaurants", reflect.TypeOf((*MockRepository)(nil).CouponRestaurants), ctx, code)
+}
+
+// Coupons mocks base method.
+func (m *MockRepository) Coupons(ctx context.Context, restaurantID string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Coupons", ctx, restaurantID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Coupons indicates an expected call of Coupons.
+func (mr *MockRepositoryMockRecorder) Coupons(ctx, restaurantID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Coupons", reflect.TypeOf((*MockRepository)(nil).Coupons), ctx, restaurantID)
+}
+
+// Create mocks base method.
+func (m *MockRepository) Create(ctx context.Context, r *Restaurant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockRepositoryMockRecorder) Create(ctx, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), ctx, r)
+}
+
+// GetByID mocks base method.
+func (m *MockRepository) GetByID(ctx context.Context, id string) (*Restaurant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*Restaurant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockRepositoryMockRecorder) GetByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockRepository)(nil).GetByID), ctx, id)
+}
+
+// List mocks base method.
+func (m *MockRepository) List(ctx context.Context) ([]*Restaurant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]*Restaurant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockRepositoryMockRecorder) List(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRepository)(nil).List), ctx)
+}
+
+// ScopeCoupon mocks base method.
+func (m *MockRepository) ScopeCoupon(ctx context.Context, restaurantID, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScopeCoupon", ctx, restaurantID, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ScopeCoupon indicates an expected call of ScopeCoupon.
+func (mr *MockRepositoryMockRecorder) ScopeCoupon(ctx, restaurantID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScopeCoupon", reflect.TypeOf((*MockRepository)(nil).ScopeCoupon), ctx, restaurantID, code)
+}
+
+// UnscopeCoupon mocks base method.
+func (m *MockRepository) UnscopeCoupon(ctx context.Context, restaurantID, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnscopeCoupon", ctx, restaurantID, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnscopeCoupon indicates an expected call of UnscopeCoupon.
+func (mr *MockRepositoryMockRecorder) UnscopeCoupon(ctx, restaurantID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnscopeCoupon", reflect.TypeOf((*MockRepository)(nil).UnscopeCoupon), ctx, restaurantID, code)
+}
@@ -0,0 +1,123 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: service.go
+
+// Package restaurant is a generated GoMock package.
+package restaurant
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateRestaurant mocks base method.
+func (m *MockService) CreateRestaurant(ctx context.Context, req *RestaurantReq) (*Restaurant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRestaurant", ctx, req)
+	ret0, _ := ret[0].(*Restaurant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRestaurant indicates an expected call of CreateRestaurant.
+func (mr *MockServiceMockRecorder) CreateRestaurant(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRestaurant", reflect.TypeOf((*MockService)(nil).CreateRestaurant), ctx, req)
+}
+
+// GetRestaurant mocks base method.
+func (m *MockService) GetRestaurant(ctx context.Context, id string) (*Restaurant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRestaurant", ctx, id)
+	ret0, _ := ret[0].(*Restaurant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRestaurant indicates an expected call of GetRestaurant.
+func (mr *MockServiceMockRecorder) GetRestaurant(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRestaurant", reflect.TypeOf((*MockService)(nil).GetRestaurant), ctx, id)
+}
+
+// ListCoupons mocks base method.
+func (m *MockService) ListCoupons(ctx context.Context, id string) (*CouponList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCoupons", ctx, id)
+	ret0, _ := ret[0].(*CouponList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCoupons indicates an expected call of ListCoupons.
+func (mr *MockServiceMockRecorder) ListCoupons(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCoupons", reflect.TypeOf((*MockService)(nil).ListCoupons), ctx, id)
+}
+
+// ListRestaurants mocks base method.
+func (m *MockService) ListRestaurants(ctx context.Context) (*RestaurantList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRestaurants", ctx)
+	ret0, _ := ret[0].(*RestaurantList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRestaurants indicates an expected call of ListRestaurants.
+func (mr *MockServiceMockRecorder) ListRestaurants(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRestaurants", reflect.TypeOf((*MockService)(nil).ListRestaurants), ctx)
+}
+
+// ScopeCoupon mocks base method.
+func (m *MockService) ScopeCoupon(ctx context.Context, id, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScopeCoupon", ctx, id, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ScopeCoupon indicates an expected call of ScopeCoupon.
+func (mr *MockServiceMockRecorder) ScopeCoupon(ctx, id, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScopeCoupon", reflect.TypeOf((*MockService)(nil).ScopeCoupon), ctx, id, code)
+}
+
+// UnscopeCoupon mocks base method.
+func (m *MockService) UnscopeCoupon(ctx context.Context, id, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnscopeCoupon", ctx, id, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnscopeCoupon indicates an expected call of UnscopeCoupon.
+func (mr *MockServiceMockRecorder) UnscopeCoupon(ctx, id, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnscopeCoupon", reflect.TypeOf((*MockService)(nil).UnscopeCoupon), ctx, id, code)
+}
@@ -0,0 +1,26 @@
+package restaurant
+
+// Restaurant is a vendor on the platform. Products and orders belong to one restaurant.
+type Restaurant struct {
+	ID   string `json:"id"`
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+// RestaurantReq is the body of POST /restaurant. Slug is derived from Name when empty.
+type RestaurantReq struct {
+	Name string `json:"name"`
+	Slug string `json:"slug,omitempty"`
+}
+
+// RestaurantList is the response of GET /restaurant
+type RestaurantList struct {
+	Items []*Restaurant `json:"items"`
+}
+
+// CouponList is the response of GET /restaurant/{restaurantId}/coupon: the
+// coupon codes scoped to the restaurant. Coupons without any scope are valid
+// on every restaurant and are not listed.
+type CouponList struct {
+	Codes []string `json:"codes"`
+}
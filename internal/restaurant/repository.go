@@ -0,0 +1,21 @@
+package restaurant
+
+import "context"
+
+//go:generate mockgen -source=repository.go -destination=mock_repository.go -package=restaurant
+type Repository interface {
+	// List returns every restaurant ordered by name
+	List(ctx context.Context) ([]*Restaurant, error)
+	// GetByID returns ErrRestaurantNotFound when there is no such restaurant
+	GetByID(ctx context.Context, id string) (*Restaurant, error)
+	Create(ctx context.Context, r *Restaurant) error
+	// Coupons returns the codes scoped to a restaurant in code order
+	Coupons(ctx context.Context, restaurantID string) ([]string, error)
+	// ScopeCoupon limits a coupon to a restaurant, among the others it is scoped to
+	ScopeCoupon(ctx context.Context, restaurantID, code string) error
+	// UnscopeCoupon returns ErrCouponNotScoped when the coupon had no such scope
+	UnscopeCoupon(ctx context.Context, restaurantID, code string) error
+	// CouponRestaurants returns the restaurants a coupon is scoped to, which
+	// makes the repository a promo.Scopes
+	CouponRestaurants(ctx context.Context, code string) ([]string, error)
+}
@@ -0,0 +1,92 @@
+package restaurant
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+//go:generate mockgen -source=service.go -destination=mock_service.go -package=restaurant
+type Service interface {
+	ListRestaurants(ctx context.Context) (*RestaurantList, error)
+	GetRestaurant(ctx context.Context, id string) (*Restaurant, error)
+	CreateRestaurant(ctx context.Context, req *RestaurantReq) (*Restaurant, error)
+	ListCoupons(ctx context.Context, id string) (*CouponList, error)
+	// ScopeCoupon limits a coupon to the restaurant. A coupon scoped to several
+	// restaurants is valid on each of them.
+	ScopeCoupon(ctx context.Context, id, code string) error
+	UnscopeCoupon(ctx context.Context, id, code string) error
+}
+
+type service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) ListRestaurants(ctx context.Context) (*RestaurantList, error) {
+	restaurants, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if restaurants == nil {
+		restaurants = []*Restaurant{}
+	}
+	return &RestaurantList{Items: restaurants}, nil
+}
+
+func (s *service) GetRestaurant(ctx context.Context, id string) (*Restaurant, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *service) CreateRestaurant(ctx context.Context, req *RestaurantReq) (*Restaurant, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range existing {
+		if r.Slug == req.Slug {
+			return nil, ErrSlugTaken
+		}
+	}
+
+	r := &Restaurant{ID: uuid.New().String(), Slug: req.Slug, Name: req.Name}
+	if err := s.repo.Create(ctx, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (s *service) ListCoupons(ctx context.Context, id string) (*CouponList, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	codes, err := s.repo.Coupons(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if codes == nil {
+		codes = []string{}
+	}
+	return &CouponList{Codes: codes}, nil
+}
+
+func (s *service) ScopeCoupon(ctx context.Context, id, code string) error {
+	if err := ValidateCouponCode(code); err != nil {
+		return err
+	}
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return err
+	}
+	return s.repo.ScopeCoupon(ctx, id, code)
+}
+
+func (s *service) UnscopeCoupon(ctx context.Context, id, code string) error {
+	return s.repo.UnscopeCoupon(ctx, id, code)
+}
@@ -0,0 +1,105 @@
+package restaurant
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	defaultID = "00000000-0000-4000-8000-000000000001"
+	luigiID   = "55555555-5555-4555-8555-555555555555"
+)
+
+func TestService_ListRestaurants(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	svc := NewService(mockRepo)
+
+	mockRepo.EXPECT().List(ctx).Return(nil, nil)
+	res, err := svc.ListRestaurants(ctx)
+	require.NoError(t, err)
+	assert.NotNil(t, res.Items)
+
+	mockRepo.EXPECT().List(ctx).Return(nil, errors.New("db error"))
+	_, err = svc.ListRestaurants(ctx)
+	assert.EqualError(t, err, "db error")
+}
+
+func TestService_CreateRestaurant(t *testing.T) {
+	ctx := context.Background()
+	existing := []*Restaurant{{ID: defaultID, Slug: "default", Name: "Default restaurant"}}
+
+	t.Run("success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().List(ctx).Return(existing, nil)
+		mockRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, r *Restaurant) error {
+			assert.NotEmpty(t, r.ID)
+			assert.Equal(t, "luigi-s", r.Slug)
+			return nil
+		})
+
+		r, err := NewService(mockRepo).CreateRestaurant(ctx, &RestaurantReq{Name: "Luigi's"})
+		require.NoError(t, err)
+		assert.Equal(t, "Luigi's", r.Name)
+	})
+
+	t.Run("slug taken", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().List(ctx).Return(existing, nil)
+
+		_, err := NewService(mockRepo).CreateRestaurant(ctx, &RestaurantReq{Name: "Default"})
+		assert.Equal(t, ErrSlugTaken, err)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		_, err := NewService(NewMockRepository(ctrl)).CreateRestaurant(ctx, &RestaurantReq{})
+		assert.Error(t, err)
+	})
+}
+
+func TestService_Coupons(t *testing.T) {
+	ctx := context.Background()
+	luigi := &Restaurant{ID: luigiID, Slug: "luigi", Name: "Luigi's"}
+
+	t.Run("list", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().GetByID(ctx, luigiID).Return(luigi, nil)
+		mockRepo.EXPECT().Coupons(ctx, luigiID).Return(nil, nil)
+
+		res, err := NewService(mockRepo).ListCoupons(ctx, luigiID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{}, res.Codes)
+	})
+
+	t.Run("scope", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().GetByID(ctx, luigiID).Return(luigi, nil)
+		mockRepo.EXPECT().ScopeCoupon(ctx, luigiID, "LUIGI10A").Return(nil)
+
+		assert.NoError(t, NewService(mockRepo).ScopeCoupon(ctx, luigiID, "LUIGI10A"))
+	})
+
+	t.Run("scope on unknown restaurant", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().GetByID(ctx, luigiID).Return(nil, ErrRestaurantNotFound)
+
+		assert.Equal(t, ErrRestaurantNotFound, NewService(mockRepo).ScopeCoupon(ctx, luigiID, "LUIGI10A"))
+	})
+
+	t.Run("invalid code", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		assert.Equal(t, ErrInvalidCouponCode, NewService(NewMockRepository(ctrl)).ScopeCoupon(ctx, luigiID, "LUIGI"))
+	})
+}
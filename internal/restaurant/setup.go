@@ -0,0 +1,30 @@
+package restaurant
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/audit"
+	"github.com/mohammadshabab/order-food-online/internal/auth"
+)
+
+// Setup registers the restaurant and coupon scope routes. The products of a
+// restaurant are served by the product module at GET /restaurant/{restaurantId}/product.
+func Setup(e *echo.Echo, repo Repository, rec *audit.Recorder) {
+	svc := NewService(repo)
+	h := NewHandler(svc)
+
+	read := auth.RequireScope(auth.ScopeCatalogRead)
+	admin := auth.RequireScope(auth.ScopeProductsAdmin)
+	coupons := func(c echo.Context) (any, error) {
+		return svc.ListCoupons(c.Request().Context(), c.Param("restaurantId"))
+	}
+	logged := func(name string) echo.MiddlewareFunc {
+		return rec.Middleware(audit.Action{Name: name, Resource: "restaurant", IDParam: "restaurantId", Before: coupons})
+	}
+
+	e.GET("/restaurant", h.ListRestaurants, read)
+	e.GET("/restaurant/:restaurantId", h.GetRestaurant, read)
+	e.POST("/restaurant", h.CreateRestaurant, admin, rec.Middleware(audit.Action{Name: "restaurant.create", Resource: "restaurant"}))
+	e.GET("/restaurant/:restaurantId/coupon", h.ListCoupons, admin)
+	e.PUT("/restaurant/:restaurantId/coupon/:code", h.ScopeCoupon, admin, logged("restaurant.coupon.scope"))
+	e.DELETE("/restaurant/:restaurantId/coupon/:code", h.UnscopeCoupon, admin, logged("restaurant.coupon.unscope"))
+}
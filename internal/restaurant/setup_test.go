@@ -0,0 +1,36 @@
+package restaurant
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+)
+
+func TestSetup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	e := echo.New()
+	Setup(e, NewMockRepository(ctrl), nil)
+
+	want := map[string]bool{
+		http.MethodGet + " /restaurant":                               false,
+		http.MethodGet + " /restaurant/:restaurantId":                 false,
+		http.MethodPost + " /restaurant":                              false,
+		http.MethodGet + " /restaurant/:restaurantId/coupon":          false,
+		http.MethodPut + " /restaurant/:restaurantId/coupon/:code":    false,
+		http.MethodDelete + " /restaurant/:restaurantId/coupon/:code": false,
+	}
+	for _, r := range e.Routes() {
+		if _, ok := want[r.Method+" "+r.Path]; ok {
+			want[r.Method+" "+r.Path] = true
+		}
+	}
+	for route, found := range want {
+		if !found {
+			t.Errorf("expected %s to be registered but it was not", route)
+		}
+	}
+}
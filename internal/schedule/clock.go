@@ -0,0 +1,19 @@
+package schedule
+
+import "time"
+
+// Clock tells the current time. Tests inject a fixed clock to check schedules
+// at chosen moments.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the wall clock
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time { return time.Now() }
+
+// ClockFunc adapts a function to Clock
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time { return f() }
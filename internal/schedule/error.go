@@ -0,0 +1,47 @@
+package schedule
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+)
+
+const maxWindows = 50
+
+var (
+	ErrRestaurantClosed = apperrors.Wrap(http.StatusUnprocessableEntity, "restaurant is closed", apperrors.LevelWarn, nil).WithCode(apperrors.CodeRestaurantClosed)
+	ErrItemsUnavailable = apperrors.Wrap(http.StatusUnprocessableEntity, "some items are not available at this time", apperrors.LevelWarn, nil).WithCode(apperrors.CodeItemsUnavailable)
+
+	ErrRestaurantNotFound = apperrors.NotFound("restaurant not found", nil).WithCode(apperrors.CodeRestaurantNotFound)
+	ErrProductNotFound    = apperrors.NotFound("product not found", nil).WithCode(apperrors.CodeProductNotFound)
+	ErrCategoryNotFound   = apperrors.NotFound("category not found", nil).WithCode(apperrors.CodeCategoryNotFound)
+
+	// base of the field errors of a request, the message is used when there are several
+	errInvalidSchedule = apperrors.BadRequest("invalid schedule", nil).WithCode(apperrors.CodeValidationFailed)
+)
+
+// targetNotFound is the error for a schedule target that does not exist
+func targetNotFound(targetType TargetType) *apperrors.AppError {
+	switch targetType {
+	case TargetProduct:
+		return ErrProductNotFound
+	case TargetCategory:
+		return ErrCategoryNotFound
+	default:
+		return ErrRestaurantNotFound
+	}
+}
+
+// Validate checks the time zone, the day names and the HH:MM times
+func (r *ScheduleReq) Validate() *apperrors.AppError {
+	var v apperrors.Validation
+	windows := r.Windows
+	if len(windows) > maxWindows {
+		v.Add(apperrors.Pointer("windows"), apperrors.FieldOutOfRange, fmt.Sprintf("at most %d windows are allowed", maxWindows))
+		// one error for the list rather than one per window
+		windows = nil
+	}
+	ValidateWindows(&v, r.TimeZone, windows)
+	return v.Err(errInvalidSchedule)
+}
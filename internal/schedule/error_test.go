@@ -0,0 +1,56 @@
+package schedule
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleReq_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     ScheduleReq
+		wantErr string
+	}{
+		{name: "valid", req: ScheduleReq{TimeZone: "Europe/Berlin", Windows: []Window{{Days: []string{"mon"}, Start: "09:00", End: "17:00"}}}},
+		{name: "empty removes the schedule", req: ScheduleReq{}},
+		{name: "unknown time zone", req: ScheduleReq{TimeZone: "Nowhere/City"}, wantErr: "unknown time zone"},
+		{name: "bad day", req: ScheduleReq{Windows: []Window{{Days: []string{"xyz"}, Start: "09:00", End: "17:00"}}}, wantErr: "windows[0].days[0]: unknown day"},
+		{name: "bad time", req: ScheduleReq{Windows: []Window{{Start: "9am", End: "17:00"}}}, wantErr: "windows[0].start"},
+		{name: "too many windows", req: ScheduleReq{Windows: make([]Window, maxWindows+1)}, wantErr: "at most 50 windows"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appErr := tt.req.Validate()
+			if tt.wantErr == "" {
+				assert.Nil(t, appErr)
+				return
+			}
+			if assert.NotNil(t, appErr) {
+				assert.Equal(t, http.StatusBadRequest, appErr.Code)
+				assert.True(t, strings.Contains(appErr.Message, tt.wantErr), appErr.Message)
+			}
+		})
+	}
+}
+
+func TestScheduleReq_Validate_CollectsFieldErrors(t *testing.T) {
+	req := ScheduleReq{TimeZone: "Nowhere/City", Windows: []Window{
+		{Days: []string{"mon"}, Start: "09:00", End: "17:00"},
+		{Days: []string{"sat", "SAT"}, Start: "9am", End: "17:00"},
+	}}
+	err := req.Validate()
+	if assert.NotNil(t, err) {
+		assert.Equal(t, apperrors.CodeValidationFailed, err.ErrorCode)
+		assert.Equal(t, "invalid schedule", err.Message)
+		assert.Equal(t, []apperrors.FieldError{
+			{Pointer: "/timeZone", Code: apperrors.FieldInvalid, Detail: `unknown time zone "Nowhere/City"`},
+			{Pointer: "/windows/1/days/1", Code: apperrors.FieldDuplicate, Detail: `windows[1].days[1]: day "SAT" is listed twice`},
+			{Pointer: "/windows/1/start", Code: apperrors.FieldInvalid, Detail: `windows[1].start: "9am" is not a HH:MM time`},
+		}, err.Errors)
+	}
+}
@@ -0,0 +1,77 @@
+package schedule
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+)
+
+type Handler struct {
+	svc Service
+}
+
+func NewHandler(svc Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+func (h *Handler) ListSchedules(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	res, err := h.svc.ListSchedules(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{"items": res})
+}
+
+// SetOpeningHours serves PUT /schedule/opening-hours, the hours of the default restaurant
+func (h *Handler) SetOpeningHours(c echo.Context) error {
+	return h.set(c, TargetRestaurant, DefaultRestaurantID)
+}
+
+// SetRestaurantSchedule serves PUT /schedule/restaurant/{restaurantId}
+func (h *Handler) SetRestaurantSchedule(c echo.Context) error {
+	return h.setByID(c, TargetRestaurant, "restaurantId")
+}
+
+// SetProductSchedule serves PUT /schedule/product/{productId}
+func (h *Handler) SetProductSchedule(c echo.Context) error {
+	return h.setByID(c, TargetProduct, "productId")
+}
+
+// SetCategorySchedule serves PUT /schedule/category/{categoryId}
+func (h *Handler) SetCategorySchedule(c echo.Context) error {
+	return h.setByID(c, TargetCategory, "categoryId")
+}
+
+// setByID validates the id path parameter before storing the schedule
+func (h *Handler) setByID(c echo.Context, targetType TargetType, param string) error {
+	id := c.Param(param)
+	if _, err := uuid.Parse(id); err != nil {
+		return apperrors.BadRequest("invalid ID supplied", err)
+	}
+	return h.set(c, targetType, id)
+}
+
+func (h *Handler) set(c echo.Context, targetType TargetType, target string) error {
+	ctx := c.Request().Context()
+
+	var req ScheduleReq
+	if err := c.Bind(&req); err != nil {
+		return apperrors.BadRequest("invalid schedule request", err)
+	}
+
+	if appErr := req.Validate(); appErr != nil {
+		return appErr
+	}
+
+	res, err := h.svc.SetSchedule(ctx, targetType, target, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
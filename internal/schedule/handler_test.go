@@ -0,0 +1,194 @@
+package schedule
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newJSONRequest(method, target, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	return req
+}
+
+func TestHandler_ListSchedules(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+
+	t.Run("success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().ListSchedules(gomock.Any()).Return([]Schedule{
+			{TargetType: TargetRestaurant, Target: DefaultRestaurantID, TimeZone: "UTC", Windows: []Window{{Start: "10:00", End: "22:00"}}},
+		}, nil)
+
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/schedule", nil), rec)
+
+		apperrors.Handle(NewHandler(mockSvc).ListSchedules, c)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"items":[{"targetType":"restaurant","target":"`+DefaultRestaurantID+`","timeZone":"UTC","windows":[{"start":"10:00","end":"22:00"}]}]}`, rec.Body.String())
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().ListSchedules(gomock.Any()).Return(nil, errors.New("db down"))
+
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/schedule", nil), rec)
+
+		apperrors.Handle(NewHandler(mockSvc).ListSchedules, c)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestHandler_SetSchedules(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+
+	body := `{"timeZone":"Europe/Berlin","windows":[{"days":["mon"],"start":"11:00","end":"15:00"}]}`
+
+	t.Run("opening hours", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().SetSchedule(gomock.Any(), TargetRestaurant, DefaultRestaurantID, gomock.Any()).
+			DoAndReturn(func(_ any, tt TargetType, target string, req *ScheduleReq) (*Schedule, error) {
+				return &Schedule{TargetType: tt, Target: target, TimeZone: req.TimeZone, Windows: req.Windows}, nil
+			})
+
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(newJSONRequest(http.MethodPut, "/schedule/opening-hours", body), rec)
+
+		apperrors.Handle(NewHandler(mockSvc).SetOpeningHours, c)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var got Schedule
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		assert.Equal(t, "Europe/Berlin", got.TimeZone)
+		assert.Len(t, got.Windows, 1)
+	})
+
+	t.Run("restaurant opening hours", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().SetSchedule(gomock.Any(), TargetRestaurant, restaurantID, gomock.Any()).Return(&Schedule{}, nil)
+
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(newJSONRequest(http.MethodPut, "/", body), rec)
+		c.SetParamNames("restaurantId")
+		c.SetParamValues(restaurantID)
+
+		apperrors.Handle(NewHandler(mockSvc).SetRestaurantSchedule, c)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("product schedule", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().SetSchedule(gomock.Any(), TargetProduct, pizzaID, gomock.Any()).Return(&Schedule{}, nil)
+
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(newJSONRequest(http.MethodPut, "/", body), rec)
+		c.SetParamNames("productId")
+		c.SetParamValues(pizzaID)
+
+		apperrors.Handle(NewHandler(mockSvc).SetProductSchedule, c)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("product schedule with invalid id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(newJSONRequest(http.MethodPut, "/", body), rec)
+		c.SetParamNames("productId")
+		c.SetParamValues("not-a-uuid")
+
+		apperrors.Handle(NewHandler(mockSvc).SetProductSchedule, c)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("category schedule", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().SetSchedule(gomock.Any(), TargetCategory, breakfastID, gomock.Any()).Return(&Schedule{}, nil)
+
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(newJSONRequest(http.MethodPut, "/", body), rec)
+		c.SetParamNames("categoryId")
+		c.SetParamValues(breakfastID)
+
+		apperrors.Handle(NewHandler(mockSvc).SetCategorySchedule, c)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("category schedule with invalid id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(newJSONRequest(http.MethodPut, "/", body), rec)
+		c.SetParamNames("categoryId")
+		c.SetParamValues("Breakfast")
+
+		apperrors.Handle(NewHandler(mockSvc).SetCategorySchedule, c)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("invalid window", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(newJSONRequest(http.MethodPut, "/", `{"windows":[{"start":"25:00","end":"10:00"}]}`), rec)
+
+		apperrors.Handle(NewHandler(mockSvc).SetOpeningHours, c)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"pointer":"/windows/0/start"`)
+	})
+
+	t.Run("malformed body", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(newJSONRequest(http.MethodPut, "/", `{"windows":`), rec)
+
+		apperrors.Handle(NewHandler(mockSvc).SetOpeningHours, c)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockSvc := NewMockService(ctrl)
+		mockSvc.EXPECT().SetSchedule(gomock.Any(), TargetRestaurant, DefaultRestaurantID, gomock.Any()).Return(nil, errors.New("db down"))
+
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(newJSONRequest(http.MethodPut, "/", body), rec)
+
+		apperrors.Handle(NewHandler(mockSvc).SetOpeningHours, c)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
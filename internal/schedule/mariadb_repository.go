@@ -0,0 +1,109 @@
+package schedule
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/db"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+)
+
+type MariaDBRepository struct{}
+
+func NewMariaDBRepository() Repository {
+	return &MariaDBRepository{}
+}
+
+func (r *MariaDBRepository) List(ctx context.Context) ([]Schedule, error) {
+	query := `SELECT target_type, target, timezone, days, start_time, end_time
+	          FROM schedule_windows ORDER BY target_type, target, position`
+
+	rows, err := db.Pool.Query(ctx, query)
+	if err != nil {
+		appErr := apperrors.Internal("failed to list schedules", err)
+		logger.Error(ctx, appErr.Message, "error", err.Error())
+		return nil, appErr
+	}
+	defer rows.Close()
+
+	var schedules []Schedule
+	for rows.Next() {
+		var (
+			s    Schedule
+			w    Window
+			days string
+		)
+		if err := rows.Scan(&s.TargetType, &s.Target, &s.TimeZone, &days, &w.Start, &w.End); err != nil {
+			appErr := apperrors.Internal("failed to scan schedule row", err)
+			logger.Error(ctx, appErr.Message, "error", err.Error())
+			return nil, appErr
+		}
+		if days != "" {
+			w.Days = strings.Split(days, ",")
+		}
+
+		// rows arrive grouped by target
+		if n := len(schedules); n == 0 || schedules[n-1].TargetType != s.TargetType || schedules[n-1].Target != s.Target {
+			schedules = append(schedules, s)
+		}
+		last := &schedules[len(schedules)-1]
+		last.Windows = append(last.Windows, w)
+	}
+	if err := rows.Err(); err != nil {
+		appErr := apperrors.Internal("failed to read schedule rows", err)
+		logger.Error(ctx, appErr.Message, "error", err.Error())
+		return nil, appErr
+	}
+
+	return schedules, nil
+}
+
+func (r *MariaDBRepository) Replace(ctx context.Context, s *Schedule) error {
+	return db.Pool.InTx(ctx, func(tx *db.SQLTx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM schedule_windows WHERE target_type = ? AND target = ?`, s.TargetType, s.Target); err != nil {
+			appErr := apperrors.Internal("failed to remove schedule", err)
+			logger.Error(ctx, appErr.Message, "target", s.Target, "error", err.Error())
+			return appErr
+		}
+
+		query := `INSERT INTO schedule_windows (target_type, target, timezone, days, start_time, end_time, position)
+		          VALUES (?, ?, ?, ?, ?, ?, ?)`
+		for i, w := range s.Windows {
+			days := strings.ToLower(strings.Join(w.Days, ","))
+			if _, err := tx.Exec(ctx, query, s.TargetType, s.Target, s.TimeZone, days, w.Start, w.End, i); err != nil {
+				appErr := apperrors.Internal("failed to insert schedule window", err)
+				logger.Error(ctx, appErr.Message, "target", s.Target, "error", err.Error())
+				return appErr
+			}
+		}
+
+		logger.Info(ctx, "schedule replaced", "targetType", string(s.TargetType), "target", s.Target, "windows", len(s.Windows))
+		return nil
+	})
+}
+
+// targetTables are the tables holding the targets of each type
+var targetTables = map[TargetType]string{
+	TargetRestaurant: "restaurants",
+	TargetProduct:    "products",
+	TargetCategory:   "categories",
+}
+
+func (r *MariaDBRepository) TargetExists(ctx context.Context, targetType TargetType, target string) error {
+	var one int
+	err := db.Pool.QueryRow(ctx, `SELECT 1 FROM `+targetTables[targetType]+` WHERE id = ?`, target).Scan(&one)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			notFound := targetNotFound(targetType)
+			logger.Warn(ctx, notFound.Message, "targetType", string(targetType), "target", target)
+			return notFound
+		}
+		appErr := apperrors.Internal("failed to fetch schedule target", err)
+		logger.Error(ctx, appErr.Message, "targetType", string(targetType), "target", target, "error", err.Error())
+		return appErr
+	}
+	return nil
+}
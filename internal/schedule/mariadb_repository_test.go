@@ -0,0 +1,155 @@
+package schedule
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/db"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var windowColumns = []string{"target_type", "target", "timezone", "days", "start_time", "end_time"}
+
+func TestMariaDBRepository_List(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	db.Pool = db.NewTestPool(sqlDB)
+
+	repo := NewMariaDBRepository()
+
+	t.Run("groups windows by target", func(t *testing.T) {
+		rows := sqlmock.NewRows(windowColumns).
+			AddRow("category", breakfastID, "UTC", "sat,sun", "09:00", "12:00").
+			AddRow("category", breakfastID, "UTC", "", "07:00", "10:00").
+			AddRow("restaurant", "", "Europe/Berlin", "", "10:00", "22:00")
+
+		mock.ExpectQuery("SELECT (.+) FROM schedule_windows ORDER BY target_type, target, position").WillReturnRows(rows)
+
+		schedules, err := repo.List(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []Schedule{
+			{TargetType: TargetCategory, Target: breakfastID, TimeZone: "UTC", Windows: []Window{
+				{Days: []string{"sat", "sun"}, Start: "09:00", End: "12:00"},
+				{Start: "07:00", End: "10:00"},
+			}},
+			{TargetType: TargetRestaurant, TimeZone: "Europe/Berlin", Windows: []Window{{Start: "10:00", End: "22:00"}}},
+		}, schedules)
+	})
+
+	t.Run("query fails", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM schedule_windows").WillReturnError(errors.New("db failed"))
+
+		schedules, err := repo.List(ctx)
+		assert.Nil(t, schedules)
+
+		appErr, ok := err.(*apperrors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, 500, appErr.Code)
+		assert.Contains(t, appErr.Err.Error(), "db failed")
+	})
+
+	t.Run("scan fails", func(t *testing.T) {
+		rows := sqlmock.NewRows(windowColumns).AddRow(nil, nil, nil, nil, nil, nil)
+		mock.ExpectQuery("SELECT (.+) FROM schedule_windows").WillReturnRows(rows)
+
+		_, err := repo.List(ctx)
+		appErr, ok := err.(*apperrors.AppError)
+		require.True(t, ok)
+		assert.Equal(t, "failed to scan schedule row", appErr.Message)
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMariaDBRepository_Replace(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	db.Pool = db.NewTestPool(sqlDB)
+
+	repo := NewMariaDBRepository()
+	sched := &Schedule{TargetType: TargetCategory, Target: breakfastID, TimeZone: "UTC", Windows: []Window{
+		{Days: []string{"Sat", "Sun"}, Start: "09:00", End: "12:00"},
+		{Start: "07:00", End: "10:00"},
+	}}
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("DELETE FROM schedule_windows WHERE target_type = \\? AND target = \\?").
+			WithArgs(TargetCategory, breakfastID).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec("INSERT INTO schedule_windows").
+			WithArgs(TargetCategory, breakfastID, "UTC", "sat,sun", "09:00", "12:00", 0).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO schedule_windows").
+			WithArgs(TargetCategory, breakfastID, "UTC", "", "07:00", "10:00", 1).
+			WillReturnResult(sqlmock.NewResult(2, 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, repo.Replace(ctx, sched))
+	})
+
+	t.Run("insert fails rolls back", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("DELETE FROM schedule_windows").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO schedule_windows").WillReturnError(errors.New("insert failed"))
+		mock.ExpectRollback()
+
+		err := repo.Replace(ctx, sched)
+		_, ok := err.(*apperrors.AppError)
+		require.True(t, ok)
+		assert.ErrorContains(t, err, "insert failed")
+	})
+
+	t.Run("delete fails rolls back", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("DELETE FROM schedule_windows").WillReturnError(errors.New("delete failed"))
+		mock.ExpectRollback()
+
+		err := repo.Replace(ctx, sched)
+		_, ok := err.(*apperrors.AppError)
+		require.True(t, ok)
+		assert.ErrorContains(t, err, "delete failed")
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMariaDBRepository_TargetExists(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	db.Pool = db.NewTestPool(sqlDB)
+
+	repo := NewMariaDBRepository()
+
+	mock.ExpectQuery("SELECT 1 FROM products WHERE id = \\?").
+		WithArgs(pizzaID).
+		WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+	assert.NoError(t, repo.TargetExists(ctx, TargetProduct, pizzaID))
+
+	mock.ExpectQuery("SELECT 1 FROM categories WHERE id = \\?").WillReturnError(sql.ErrNoRows)
+	assert.Equal(t, ErrCategoryNotFound, repo.TargetExists(ctx, TargetCategory, mainsID))
+
+	mock.ExpectQuery("SELECT 1 FROM restaurants WHERE id = \\?").WillReturnError(sql.ErrNoRows)
+	assert.Equal(t, ErrRestaurantNotFound, repo.TargetExists(ctx, TargetRestaurant, DefaultRestaurantID))
+
+	mock.ExpectQuery("SELECT 1 FROM products").WillReturnError(errors.New("db failed"))
+	appErr, ok := repo.TargetExists(ctx, TargetProduct, pizzaID).(*apperrors.AppError)
+	require.True(t, ok)
+	assert.Equal(t, 500, appErr.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,110 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: service.go
+
+// Package schedule is a generated GoMock package.
+package schedule
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// Exclusions mocks base method.
+func (m *MockService) Exclusions(ctx context.Context) (*Exclusions, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Exclusions", ctx)
+	ret0, _ := ret[0].(*Exclusions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Exclusions indicates an expected call of Exclusions.
+func (mr *MockServiceMockRecorder) Exclusions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exclusions", reflect.TypeOf((*MockService)(nil).Exclusions), ctx)
+}
+
+// IsOpen mocks base method.
+func (m *MockService) IsOpen(ctx context.Context, restaurantID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsOpen", ctx, restaurantID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsOpen indicates an expected call of IsOpen.
+func (mr *MockServiceMockRecorder) IsOpen(ctx, restaurantID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsOpen", reflect.TypeOf((*MockService)(nil).IsOpen), ctx, restaurantID)
+}
+
+// ListSchedules mocks base method.
+func (m *MockService) ListSchedules(ctx context.Context) ([]Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSchedules", ctx)
+	ret0, _ := ret[0].([]Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSchedules indicates an expected call of ListSchedules.
+func (mr *MockServiceMockRecorder) ListSchedules(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSchedules", reflect.TypeOf((*MockService)(nil).ListSchedules), ctx)
+}
+
+// SetSchedule mocks base method.
+func (m *MockService) SetSchedule(ctx context.Context, targetType TargetType, target string, req *ScheduleReq) (*Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSchedule", ctx, targetType, target, req)
+	ret0, _ := ret[0].(*Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetSchedule indicates an expected call of SetSchedule.
+func (mr *MockServiceMockRecorder) SetSchedule(ctx, targetType, target, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSchedule", reflect.TypeOf((*MockService)(nil).SetSchedule), ctx, targetType, target, req)
+}
+
+// Unavailable mocks base method.
+func (m *MockService) Unavailable(ctx context.Context, items []Item) ([]Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unavailable", ctx, items)
+	ret0, _ := ret[0].([]Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Unavailable indicates an expected call of Unavailable.
+func (mr *MockServiceMockRecorder) Unavailable(ctx, items interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unavailable", reflect.TypeOf((*MockService)(nil).Unavailable), ctx, items)
+}
@@ -0,0 +1,63 @@
+package schedule
+
+import "github.com/mohammadshabab/order-food-online/internal/restaurant/defaults"
+
+type TargetType string
+
+// DefaultRestaurantID is the restaurant PUT /schedule/opening-hours sets the
+// hours of
+const DefaultRestaurantID = defaults.RestaurantID
+
+const (
+	// TargetRestaurant holds the opening hours of the restaurant in Target
+	TargetRestaurant TargetType = "restaurant"
+	TargetProduct    TargetType = "product"
+	TargetCategory   TargetType = "category"
+)
+
+// Window is a recurring time range. Days are "mon".."sun", empty means every
+// day. Start and End are "HH:MM" in the schedule time zone; an End before
+// Start runs past midnight and Start == End covers the whole day.
+type Window struct {
+	Days  []string `json:"days,omitempty"`
+	Start string   `json:"start"`
+	End   string   `json:"end"`
+}
+
+// Schedule restricts when a restaurant is open, or when a product or a whole
+// category can be ordered. Target is the restaurant, product or category id.
+// Without a schedule there is no restriction.
+type Schedule struct {
+	TargetType TargetType `json:"targetType"`
+	Target     string     `json:"target,omitempty"`
+	TimeZone   string     `json:"timeZone"`
+	Windows    []Window   `json:"windows"`
+}
+
+// ScheduleReq is the body of the PUT /schedule endpoints. No windows removes the schedule.
+type ScheduleReq struct {
+	TimeZone string   `json:"timeZone"`
+	Windows  []Window `json:"windows"`
+}
+
+// Item is a product to check against the menu schedules
+type Item struct {
+	ProductID  string `json:"productId"`
+	Name       string `json:"name"`
+	CategoryID string `json:"categoryId,omitempty"`
+}
+
+// Exclusions describes what cannot be ordered at a point in time, in a form
+// the product listing can turn into SQL filters.
+type Exclusions struct {
+	// Closed are ids of restaurants outside their opening hours, none of
+	// their products can be ordered
+	Closed []string
+	// ProductIDs have their own schedule and are off right now
+	ProductIDs []string
+	// Categories are ids of categories that have a schedule and are off right now
+	Categories []string
+	// Overrides have their own schedule and are on right now; a product
+	// schedule takes precedence over the schedule of its category
+	Overrides []string
+}
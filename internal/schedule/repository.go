@@ -0,0 +1,14 @@
+package schedule
+
+import "context"
+
+//go:generate mockgen -source=repository.go -destination=mock_repository.go -package=schedule
+type Repository interface {
+	// List returns every schedule, opening hours included
+	List(ctx context.Context) ([]Schedule, error)
+	// Replace swaps the schedule of a target; a schedule without windows is removed
+	Replace(ctx context.Context, s *Schedule) error
+	// TargetExists returns the not found error of the target type if there is
+	// no such restaurant, product or category
+	TargetExists(ctx context.Context, targetType TargetType, target string) error
+}
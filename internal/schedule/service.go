@@ -0,0 +1,194 @@
+package schedule
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+)
+
+//go:generate mockgen -source=service.go -destination=mock_service.go -package=schedule
+type Service interface {
+	ListSchedules(ctx context.Context) ([]Schedule, error)
+	SetSchedule(ctx context.Context, targetType TargetType, target string, req *ScheduleReq) (*Schedule, error)
+
+	// IsOpen reports whether a restaurant takes orders right now
+	IsOpen(ctx context.Context, restaurantID string) (bool, error)
+	// Exclusions lists what is off the menu right now
+	Exclusions(ctx context.Context) (*Exclusions, error)
+	// Unavailable returns the items whose menu schedule is off right now
+	Unavailable(ctx context.Context, items []Item) ([]Item, error)
+}
+
+type service struct {
+	repo  Repository
+	clock Clock
+	ttl   time.Duration
+
+	mu       sync.Mutex
+	loaded   *set
+	loadedAt time.Time
+}
+
+// set is every schedule compiled and keyed by target
+type set struct {
+	restaurants map[string]*compiled
+	products    map[string]*compiled
+	categories  map[string]*compiled
+}
+
+// NewService checks schedules against clock. Schedules are cached for ttl so
+// writes made by other instances show up after at most ttl.
+func NewService(repo Repository, clock Clock, ttl time.Duration) Service {
+	if clock == nil {
+		clock = SystemClock{}
+	}
+	return &service{repo: repo, clock: clock, ttl: ttl}
+}
+
+func (s *service) ListSchedules(ctx context.Context) ([]Schedule, error) {
+	schedules, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if schedules == nil {
+		schedules = []Schedule{}
+	}
+	return schedules, nil
+}
+
+func (s *service) SetSchedule(ctx context.Context, targetType TargetType, target string, req *ScheduleReq) (*Schedule, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.TargetExists(ctx, targetType, target); err != nil {
+		return nil, err
+	}
+
+	sched := &Schedule{
+		TargetType: targetType,
+		Target:     target,
+		TimeZone:   req.TimeZone,
+		Windows:    req.Windows,
+	}
+	if sched.TimeZone == "" {
+		sched.TimeZone = "UTC"
+	}
+	if sched.Windows == nil {
+		sched.Windows = []Window{}
+	}
+
+	if err := s.repo.Replace(ctx, sched); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return sched, nil
+}
+
+func (s *service) IsOpen(ctx context.Context, restaurantID string) (bool, error) {
+	set, err := s.load(ctx)
+	if err != nil {
+		return false, err
+	}
+	c, ok := set.restaurants[restaurantID]
+	return !ok || c.activeAt(s.clock.Now()), nil
+}
+
+func (s *service) Exclusions(ctx context.Context) (*Exclusions, error) {
+	set, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	ex := &Exclusions{}
+	for id, c := range set.restaurants {
+		if !c.activeAt(now) {
+			ex.Closed = append(ex.Closed, id)
+		}
+	}
+	for id, c := range set.products {
+		if c.activeAt(now) {
+			ex.Overrides = append(ex.Overrides, id)
+		} else {
+			ex.ProductIDs = append(ex.ProductIDs, id)
+		}
+	}
+	for category, c := range set.categories {
+		if !c.activeAt(now) {
+			ex.Categories = append(ex.Categories, category)
+		}
+	}
+
+	// stable order keeps the generated SQL and the tests deterministic
+	sort.Strings(ex.Closed)
+	sort.Strings(ex.ProductIDs)
+	sort.Strings(ex.Overrides)
+	sort.Strings(ex.Categories)
+	return ex, nil
+}
+
+func (s *service) Unavailable(ctx context.Context, items []Item) ([]Item, error) {
+	set, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	var off []Item
+	for _, item := range items {
+		c, ok := set.products[item.ProductID]
+		if !ok {
+			c, ok = set.categories[item.CategoryID]
+		}
+		if ok && !c.activeAt(now) {
+			off = append(off, item)
+		}
+	}
+	return off, nil
+}
+
+func (s *service) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loaded = nil
+}
+
+// load returns the cached schedules, reading them again once the ttl passed
+func (s *service) load(ctx context.Context) (*set, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.loaded != nil && time.Since(s.loadedAt) < s.ttl {
+		return s.loaded, nil
+	}
+
+	schedules, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := &set{restaurants: make(map[string]*compiled), products: make(map[string]*compiled), categories: make(map[string]*compiled)}
+	for _, sched := range schedules {
+		c, err := compile(sched)
+		if err != nil {
+			// rows are validated on write, a bad one is skipped rather than closing the menu
+			logger.Warn(ctx, "skipping invalid schedule", "targetType", string(sched.TargetType), "target", sched.Target, "error", err.Error())
+			continue
+		}
+		switch sched.TargetType {
+		case TargetRestaurant:
+			loaded.restaurants[sched.Target] = c
+		case TargetProduct:
+			loaded.products[sched.Target] = c
+		case TargetCategory:
+			loaded.categories[sched.Target] = c
+		}
+	}
+
+	s.loaded = loaded
+	s.loadedAt = time.Now()
+	return loaded, nil
+}
@@ -0,0 +1,248 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	pizzaID     = "6f1f5a8e-3f7e-4b0c-9d4e-1c2b3a4d5e6f"
+	soupID      = "0a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d"
+	breakfastID = "b1b2b3b4-c5c6-4d7e-8f90-a1a2a3a4a5a6"
+	mainsID     = "d1d2d3d4-e5e6-4f70-8a9b-c1c2c3c4c5c6"
+	// restaurantID keeps late opening hours next to those of the default restaurant
+	restaurantID = "55555555-5555-4555-8555-555555555555"
+)
+
+// Monday 2026-10-19 at hh:mm UTC
+func monday(hour, minute int) Clock {
+	return ClockFunc(func() time.Time { return time.Date(2026, 10, 19, hour, minute, 0, 0, time.UTC) })
+}
+
+func testSchedules() []Schedule {
+	return []Schedule{
+		{TargetType: TargetRestaurant, Target: DefaultRestaurantID, TimeZone: "UTC", Windows: []Window{{Start: "10:00", End: "22:00"}}},
+		{TargetType: TargetRestaurant, Target: restaurantID, TimeZone: "UTC", Windows: []Window{{Start: "18:00", End: "02:00"}}},
+		{TargetType: TargetCategory, Target: breakfastID, TimeZone: "UTC", Windows: []Window{{Start: "10:00", End: "11:30"}}},
+		{TargetType: TargetProduct, Target: pizzaID, TimeZone: "UTC", Windows: []Window{{Start: "17:00", End: "22:00"}}},
+		{TargetType: TargetProduct, Target: soupID, TimeZone: "UTC", Windows: []Window{{Start: "10:00", End: "22:00"}}},
+	}
+}
+
+func TestService_IsOpen(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	t.Run("inside opening hours", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().List(ctx).Return(testSchedules(), nil)
+
+		open, err := NewService(mockRepo, monday(12, 0), time.Minute).IsOpen(ctx, DefaultRestaurantID)
+		require.NoError(t, err)
+		assert.True(t, open)
+	})
+
+	t.Run("outside opening hours", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().List(ctx).Return(testSchedules(), nil)
+
+		open, err := NewService(mockRepo, monday(23, 0), time.Minute).IsOpen(ctx, DefaultRestaurantID)
+		require.NoError(t, err)
+		assert.False(t, open)
+	})
+
+	t.Run("each restaurant has its own hours", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().List(ctx).Return(testSchedules(), nil)
+		svc := NewService(mockRepo, monday(23, 0), time.Minute)
+
+		open, err := svc.IsOpen(ctx, restaurantID)
+		require.NoError(t, err)
+		assert.True(t, open)
+
+		// a restaurant without opening hours is always open
+		open, err = svc.IsOpen(ctx, "r-without-hours")
+		require.NoError(t, err)
+		assert.True(t, open)
+	})
+
+	t.Run("no opening hours means always open", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().List(ctx).Return(nil, nil)
+
+		open, err := NewService(mockRepo, monday(3, 0), time.Minute).IsOpen(ctx, DefaultRestaurantID)
+		require.NoError(t, err)
+		assert.True(t, open)
+	})
+
+	t.Run("invalid stored schedule is skipped", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().List(ctx).Return([]Schedule{
+			{TargetType: TargetRestaurant, Target: DefaultRestaurantID, TimeZone: "Nowhere/City", Windows: []Window{{Start: "10:00", End: "11:00"}}},
+		}, nil)
+
+		open, err := NewService(mockRepo, monday(3, 0), time.Minute).IsOpen(ctx, DefaultRestaurantID)
+		require.NoError(t, err)
+		assert.True(t, open)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().List(ctx).Return(nil, errors.New("db down"))
+
+		_, err := NewService(mockRepo, monday(12, 0), time.Minute).IsOpen(ctx, DefaultRestaurantID)
+		assert.EqualError(t, err, "db down")
+	})
+}
+
+func TestService_Exclusions(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	t.Run("lunch time", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().List(ctx).Return(testSchedules(), nil)
+
+		ex, err := NewService(mockRepo, monday(12, 0), time.Minute).Exclusions(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, &Exclusions{
+			Closed:     []string{restaurantID},
+			ProductIDs: []string{pizzaID},
+			Categories: []string{breakfastID},
+			Overrides:  []string{soupID},
+		}, ex)
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().List(ctx).Return(testSchedules(), nil)
+
+		ex, err := NewService(mockRepo, monday(23, 0), time.Minute).Exclusions(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{DefaultRestaurantID}, ex.Closed)
+	})
+}
+
+func TestService_Unavailable(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	mockRepo.EXPECT().List(ctx).Return(testSchedules(), nil)
+
+	svc := NewService(mockRepo, monday(12, 0), time.Minute)
+
+	items := []Item{
+		{ProductID: pizzaID, Name: "Pizza", CategoryID: mainsID},
+		{ProductID: "p-eggs", Name: "Eggs", CategoryID: breakfastID},
+		{ProductID: "p-burger", Name: "Burger", CategoryID: mainsID},
+		{ProductID: "p-water", Name: "Water"},
+		// own schedule wins over the category schedule
+		{ProductID: soupID, Name: "Soup", CategoryID: breakfastID},
+	}
+
+	off, err := svc.Unavailable(ctx, items)
+	require.NoError(t, err)
+	assert.Equal(t, []Item{items[0], items[1]}, off)
+}
+
+func TestService_Cache(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	svc := NewService(mockRepo, monday(12, 0), time.Hour)
+
+	// loaded once and served from the cache afterwards
+	mockRepo.EXPECT().List(ctx).Return(testSchedules(), nil).Times(1)
+	_, err := svc.IsOpen(ctx, DefaultRestaurantID)
+	require.NoError(t, err)
+	_, err = svc.Exclusions(ctx)
+	require.NoError(t, err)
+
+	// a write drops the cache
+	req := &ScheduleReq{Windows: []Window{{Start: "00:00", End: "01:00"}}}
+	mockRepo.EXPECT().TargetExists(ctx, TargetRestaurant, DefaultRestaurantID).Return(nil)
+	mockRepo.EXPECT().Replace(ctx, gomock.Any()).Return(nil)
+	_, err = svc.SetSchedule(ctx, TargetRestaurant, DefaultRestaurantID, req)
+	require.NoError(t, err)
+
+	mockRepo.EXPECT().List(ctx).Return([]Schedule{{TargetType: TargetRestaurant, Target: DefaultRestaurantID, Windows: req.Windows}}, nil).Times(1)
+	open, err := svc.IsOpen(ctx, DefaultRestaurantID)
+	require.NoError(t, err)
+	assert.False(t, open)
+}
+
+func TestService_SetSchedule(t *testing.T) {
+	logger.Init("test-service", "test", 0)
+	ctx := context.Background()
+
+	t.Run("defaults the time zone", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+
+		want := &Schedule{TargetType: TargetCategory, Target: mainsID, TimeZone: "UTC", Windows: []Window{}}
+		mockRepo.EXPECT().TargetExists(ctx, TargetCategory, mainsID).Return(nil)
+		mockRepo.EXPECT().Replace(ctx, want).Return(nil)
+
+		got, err := NewService(mockRepo, nil, time.Minute).SetSchedule(ctx, TargetCategory, mainsID, &ScheduleReq{})
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+
+		_, err := NewService(mockRepo, nil, time.Minute).SetSchedule(ctx, TargetRestaurant, DefaultRestaurantID, &ScheduleReq{TimeZone: "bad"})
+		assert.ErrorContains(t, err, "unknown time zone")
+	})
+
+	t.Run("unknown target", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().TargetExists(ctx, TargetProduct, pizzaID).Return(ErrProductNotFound)
+
+		_, err := NewService(mockRepo, nil, time.Minute).SetSchedule(ctx, TargetProduct, pizzaID, &ScheduleReq{})
+		assert.Equal(t, ErrProductNotFound, err)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().TargetExists(ctx, TargetRestaurant, DefaultRestaurantID).Return(nil)
+		mockRepo.EXPECT().Replace(ctx, gomock.Any()).Return(errors.New("db down"))
+
+		_, err := NewService(mockRepo, nil, time.Minute).SetSchedule(ctx, TargetRestaurant, DefaultRestaurantID, &ScheduleReq{})
+		assert.EqualError(t, err, "db down")
+	})
+}
+
+func TestService_ListSchedules(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	mockRepo := NewMockRepository(ctrl)
+	mockRepo.EXPECT().List(ctx).Return(nil, nil)
+
+	res, err := NewService(mockRepo, nil, time.Minute).ListSchedules(ctx)
+	require.NoError(t, err)
+	assert.NotNil(t, res)
+	assert.Empty(t, res)
+}
@@ -0,0 +1,44 @@
+package schedule
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/audit"
+	"github.com/mohammadshabab/order-food-online/internal/auth"
+)
+
+// Setup registers the schedule admin routes and returns the service, which
+// the product listing and order creation use to check what is on the menu.
+func Setup(e *echo.Echo, repo Repository, clock Clock, ttl time.Duration, rec *audit.Recorder) Service {
+	svc := NewService(repo, clock, ttl)
+	h := NewHandler(svc)
+
+	admin := auth.RequireScope(auth.ScopeProductsAdmin)
+	logged := func(name string, targetType TargetType, idParam string) echo.MiddlewareFunc {
+		target := func(c echo.Context) string {
+			if idParam == "" {
+				return DefaultRestaurantID
+			}
+			return c.Param(idParam)
+		}
+		current := func(c echo.Context) (any, error) {
+			schedules, err := svc.ListSchedules(c.Request().Context())
+			for _, s := range schedules {
+				if s.TargetType == targetType && s.Target == target(c) {
+					return s, err
+				}
+			}
+			return nil, err
+		}
+		return rec.Middleware(audit.Action{Name: name, Resource: "schedule", IDParam: idParam, Before: current})
+	}
+
+	e.GET("/schedule", h.ListSchedules, auth.RequireScope(auth.ScopeCatalogRead))
+	e.PUT("/schedule/opening-hours", h.SetOpeningHours, admin, logged("schedule.opening_hours", TargetRestaurant, ""))
+	e.PUT("/schedule/restaurant/:restaurantId", h.SetRestaurantSchedule, admin, logged("schedule.opening_hours", TargetRestaurant, "restaurantId"))
+	e.PUT("/schedule/product/:productId", h.SetProductSchedule, admin, logged("schedule.product", TargetProduct, "productId"))
+	e.PUT("/schedule/category/:categoryId", h.SetCategorySchedule, admin, logged("schedule.category", TargetCategory, "categoryId"))
+
+	return svc
+}
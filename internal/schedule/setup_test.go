@@ -0,0 +1,38 @@
+package schedule
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	e := echo.New()
+	svc := Setup(e, NewMockRepository(ctrl), SystemClock{}, time.Minute, nil)
+	assert.NotNil(t, svc)
+
+	want := map[string]bool{
+		http.MethodGet + " /schedule":                          false,
+		http.MethodPut + " /schedule/opening-hours":            false,
+		http.MethodPut + " /schedule/restaurant/:restaurantId": false,
+		http.MethodPut + " /schedule/product/:productId":       false,
+		http.MethodPut + " /schedule/category/:categoryId":     false,
+	}
+	for _, r := range e.Routes() {
+		if _, ok := want[r.Method+" "+r.Path]; ok {
+			want[r.Method+" "+r.Path] = true
+		}
+	}
+	for route, found := range want {
+		if !found {
+			t.Errorf("expected %s to be registered but it was not", route)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+)
+
+const minutesPerDay = 24 * 60
+
+var dayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// compiled is a Schedule prepared for fast checks
+type compiled struct {
+	loc     *time.Location
+	windows []window
+}
+
+type window struct {
+	days       uint8 // bit per time.Weekday
+	start, end int   // minutes since midnight
+}
+
+func compile(s Schedule) (*compiled, error) {
+	loc, err := loadLocation(s.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &compiled{loc: loc, windows: make([]window, 0, len(s.Windows))}
+	for _, w := range s.Windows {
+		cw, err := compileWindow(w)
+		if err != nil {
+			return nil, err
+		}
+		c.windows = append(c.windows, cw)
+	}
+	return c, nil
+}
+
+// Windows are recurring windows in a time zone, prepared for checks. Other
+// packages use them for their own time based rules, such as happy hour prices.
+type Windows struct {
+	c *compiled
+}
+
+// CompileWindows validates the time zone and windows the way schedules are
+// validated and prepares them for ActiveAt. Window errors are prefixed with
+// their index, "windows[1]: ...".
+func CompileWindows(timeZone string, windows []Window) (*Windows, error) {
+	loc, err := loadLocation(timeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &compiled{loc: loc, windows: make([]window, 0, len(windows))}
+	for i, w := range windows {
+		cw, err := compileWindow(w)
+		if err != nil {
+			return nil, fmt.Errorf("windows[%d]: %w", i, err)
+		}
+		c.windows = append(c.windows, cw)
+	}
+	return &Windows{c: c}, nil
+}
+
+// ActiveAt reports whether t falls in any of the windows
+func (w *Windows) ActiveAt(t time.Time) bool {
+	return w.c.activeAt(t)
+}
+
+func compileWindow(w Window) (window, error) {
+	var cw window
+	if len(w.Days) == 0 {
+		cw.days = 0x7f
+	}
+	for _, d := range w.Days {
+		bit, err := dayBit(d)
+		if err != nil {
+			return cw, err
+		}
+		if cw.days&bit != 0 {
+			return cw, fmt.Errorf("day %q is listed twice", d)
+		}
+		cw.days |= bit
+	}
+
+	var err error
+	if cw.start, err = parseClock(w.Start); err != nil {
+		return cw, fmt.Errorf("start: %w", err)
+	}
+	if cw.end, err = parseClock(w.End); err != nil {
+		return cw, fmt.Errorf("end: %w", err)
+	}
+	return cw, nil
+}
+
+// ValidateWindows records a field error for an unknown time zone and for every
+// bad day and time of windows, e.g. /windows/1/start
+func ValidateWindows(v *apperrors.Validation, timeZone string, windows []Window) {
+	if _, err := loadLocation(timeZone); err != nil {
+		v.Add(apperrors.Pointer("timeZone"), apperrors.FieldInvalid, err.Error())
+	}
+
+	for i, w := range windows {
+		var seen uint8
+		for j, d := range w.Days {
+			bit, err := dayBit(d)
+			switch {
+			case err != nil:
+				v.Add(apperrors.Pointer("windows", i, "days", j), apperrors.FieldInvalid, fmt.Sprintf("windows[%d].days[%d]: %s", i, j, err))
+			case seen&bit != 0:
+				v.Add(apperrors.Pointer("windows", i, "days", j), apperrors.FieldDuplicate, fmt.Sprintf("windows[%d].days[%d]: day %q is listed twice", i, j, d))
+			}
+			seen |= bit
+		}
+
+		for _, f := range []struct{ name, value string }{{"start", w.Start}, {"end", w.End}} {
+			if _, err := parseClock(f.value); err != nil {
+				v.Add(apperrors.Pointer("windows", i, f.name), apperrors.FieldInvalid, fmt.Sprintf("windows[%d].%s: %s", i, f.name, err))
+			}
+		}
+	}
+}
+
+// dayBit returns the bit of a day name in window.days
+func dayBit(d string) (uint8, error) {
+	day, ok := dayNames[strings.ToLower(d)]
+	if !ok {
+		return 0, fmt.Errorf("unknown day %q, use mon, tue, wed, thu, fri, sat or sun", d)
+	}
+	return 1 << day, nil
+}
+
+func loadLocation(tz string) (*time.Location, error) {
+	if tz == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q", tz)
+	}
+	return loc, nil
+}
+
+// parseClock turns "HH:MM" into minutes since midnight
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a HH:MM time", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// activeAt reports whether t falls in any window of the schedule
+func (c *compiled) activeAt(t time.Time) bool {
+	local := t.In(c.loc)
+	day := local.Weekday()
+	minute := local.Hour()*60 + local.Minute()
+
+	for _, w := range c.windows {
+		if w.contains(day, minute) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w window) contains(day time.Weekday, minute int) bool {
+	on := func(d time.Weekday) bool { return w.days&(1<<d) != 0 }
+
+	switch {
+	case w.start == w.end:
+		return on(day)
+	case w.start < w.end:
+		return on(day) && minute >= w.start && minute < w.end
+	default:
+		// past midnight: the evening part belongs to day, the early
+		// morning part to the day the window started on
+		yesterday := (day + 6) % 7
+		return (on(day) && minute >= w.start) || (on(yesterday) && minute < w.end)
+	}
+}
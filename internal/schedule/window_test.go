@@ -0,0 +1,132 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 2026-10-19 is a Monday
+func at(t *testing.T, loc *time.Location, day, hour, minute int) time.Time {
+	t.Helper()
+	return time.Date(2026, 10, day, hour, minute, 0, 0, loc)
+}
+
+func TestCompiled_ActiveAt(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		sched  Schedule
+		now    time.Time
+		active bool
+	}{
+		{
+			name:   "inside a daytime window",
+			sched:  Schedule{Windows: []Window{{Start: "11:00", End: "15:00"}}},
+			now:    at(t, time.UTC, 19, 12, 30),
+			active: true,
+		},
+		{
+			name:   "end is exclusive",
+			sched:  Schedule{Windows: []Window{{Start: "11:00", End: "15:00"}}},
+			now:    at(t, time.UTC, 19, 15, 0),
+			active: false,
+		},
+		{
+			name:   "wrong day",
+			sched:  Schedule{Windows: []Window{{Days: []string{"sat", "sun"}, Start: "11:00", End: "15:00"}}},
+			now:    at(t, time.UTC, 19, 12, 0),
+			active: false,
+		},
+		{
+			name:   "day names are case insensitive",
+			sched:  Schedule{Windows: []Window{{Days: []string{"Mon"}, Start: "11:00", End: "15:00"}}},
+			now:    at(t, time.UTC, 19, 12, 0),
+			active: true,
+		},
+		{
+			name:   "overnight window before midnight",
+			sched:  Schedule{Windows: []Window{{Days: []string{"fri"}, Start: "22:00", End: "02:00"}}},
+			now:    at(t, time.UTC, 23, 23, 30),
+			active: true,
+		},
+		{
+			name:   "overnight window after midnight belongs to the previous day",
+			sched:  Schedule{Windows: []Window{{Days: []string{"fri"}, Start: "22:00", End: "02:00"}}},
+			now:    at(t, time.UTC, 24, 1, 30),
+			active: true,
+		},
+		{
+			name:   "overnight window does not start early on its own day",
+			sched:  Schedule{Windows: []Window{{Days: []string{"fri"}, Start: "22:00", End: "02:00"}}},
+			now:    at(t, time.UTC, 23, 1, 30),
+			active: false,
+		},
+		{
+			name:   "start equal to end covers the whole day",
+			sched:  Schedule{Windows: []Window{{Days: []string{"mon"}, Start: "00:00", End: "00:00"}}},
+			now:    at(t, time.UTC, 19, 23, 59),
+			active: true,
+		},
+		{
+			name:   "checked in the schedule time zone",
+			sched:  Schedule{TimeZone: "Europe/Berlin", Windows: []Window{{Start: "11:00", End: "15:00"}}},
+			now:    at(t, time.UTC, 19, 9, 30), // 11:30 in Berlin
+			active: true,
+		},
+		{
+			name:   "time zone shifts the day",
+			sched:  Schedule{TimeZone: "Europe/Berlin", Windows: []Window{{Days: []string{"tue"}, Start: "00:00", End: "02:00"}}},
+			now:    at(t, berlin, 20, 0, 30).UTC(), // still Monday in UTC
+			active: true,
+		},
+		{
+			name:   "no windows is never active",
+			sched:  Schedule{},
+			now:    at(t, time.UTC, 19, 12, 0),
+			active: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := compile(tt.sched)
+			require.NoError(t, err)
+			assert.Equal(t, tt.active, c.activeAt(tt.now))
+		})
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	_, err := compile(Schedule{TimeZone: "Mars/Olympus"})
+	assert.ErrorContains(t, err, "unknown time zone")
+
+	_, err = compile(Schedule{Windows: []Window{{Days: []string{"funday"}, Start: "10:00", End: "11:00"}}})
+	assert.ErrorContains(t, err, "unknown day")
+
+	_, err = compile(Schedule{Windows: []Window{{Days: []string{"mon", "Mon"}, Start: "10:00", End: "11:00"}}})
+	assert.ErrorContains(t, err, "listed twice")
+
+	_, err = compile(Schedule{Windows: []Window{{Start: "25:00", End: "11:00"}}})
+	assert.ErrorContains(t, err, "start")
+
+	_, err = compile(Schedule{Windows: []Window{{Start: "10:00", End: "noon"}}})
+	assert.ErrorContains(t, err, "end")
+}
+
+func TestCompileWindows(t *testing.T) {
+	w, err := CompileWindows("Europe/Berlin", []Window{{Days: []string{"fri"}, Start: "17:00", End: "19:00"}})
+	require.NoError(t, err)
+	assert.True(t, w.ActiveAt(at(t, time.UTC, 23, 15, 30)))  // 17:30 in Berlin
+	assert.False(t, w.ActiveAt(at(t, time.UTC, 23, 17, 30))) // 19:30 in Berlin
+
+	_, err = CompileWindows("Mars/Olympus", nil)
+	assert.ErrorContains(t, err, "unknown time zone")
+
+	_, err = CompileWindows("", []Window{{Start: "10:00", End: "11:00"}, {Start: "10:00", End: "noon"}})
+	assert.EqualError(t, err, `windows[1]: end: "noon" is not a HH:MM time`)
+}
@@ -0,0 +1,178 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+
+	"github.com/mohammadshabab/order-food-online/internal/event"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+)
+
+const subscriberBuffer = 64
+
+var ErrBrokerClosed = errors.New("event stream is shut down")
+
+// Message is a single server-sent event ready to be written to a client.
+type Message struct {
+	ID      uint64
+	Type    event.EventType
+	OrderID string
+	Data    []byte
+}
+
+// Filter decides which messages a subscriber receives. Zero value matches everything.
+type Filter struct {
+	Types   map[event.EventType]bool
+	OrderID string
+}
+
+func (f Filter) Match(m Message) bool {
+	if len(f.Types) > 0 && !f.Types[m.Type] {
+		return false
+	}
+	if f.OrderID != "" && f.OrderID != m.OrderID {
+		return false
+	}
+	return true
+}
+
+// Subscription receives live messages on C. C is closed when the subscriber
+// falls too far behind, unsubscribes, or the broker shuts down.
+type Subscription struct {
+	C      <-chan Message
+	ch     chan Message
+	filter Filter
+	once   sync.Once
+}
+
+func (s *Subscription) close() {
+	s.once.Do(func() { close(s.ch) })
+}
+
+// Broker fans order events out to SSE clients and keeps a bounded replay
+// buffer so reconnecting clients can resume from Last-Event-ID.
+// It implements event.EventPublisher.
+type Broker struct {
+	mu     sync.Mutex
+	nextID uint64
+	buffer []Message // ring buffer, oldest at head
+	head   int
+	size   int
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+func NewBroker(replaySize int) *Broker {
+	if replaySize <= 0 {
+		replaySize = 1000
+	}
+	return &Broker{
+		buffer: make([]Message, replaySize),
+		subs:   make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish implements event.EventPublisher
+func (b *Broker) Publish(ctx context.Context, evt event.Event) error {
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrBrokerClosed
+	}
+
+	b.nextID++
+	msg := Message{ID: b.nextID, Type: evt.Type, OrderID: evt.OrderID, Data: data}
+	b.append(msg)
+
+	for sub := range b.subs {
+		if !sub.filter.Match(msg) {
+			continue
+		}
+		select {
+		case sub.ch <- msg:
+		default:
+			// Slow consumer: drop it, the client reconnects with Last-Event-ID
+			// and catches up from the replay buffer.
+			logger.Warn(ctx, "dropping slow event stream subscriber", "lastEventId", msg.ID)
+			delete(b.subs, sub)
+			sub.close()
+		}
+	}
+	return nil
+}
+
+func (b *Broker) append(msg Message) {
+	capacity := len(b.buffer)
+	if b.size < capacity {
+		b.buffer[(b.head+b.size)%capacity] = msg
+		b.size++
+		return
+	}
+	b.buffer[b.head] = msg
+	b.head = (b.head + 1) % capacity
+}
+
+// Subscribe registers a subscriber and returns the buffered messages newer than
+// lastEventID that match the filter. Replay and live delivery are taken under
+// the same lock so no message is lost or duplicated in between.
+func (b *Broker) Subscribe(filter Filter, lastEventID uint64) (*Subscription, []Message, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, nil, ErrBrokerClosed
+	}
+
+	var replay []Message
+	if lastEventID > 0 {
+		for i := 0; i < b.size; i++ {
+			msg := b.buffer[(b.head+i)%len(b.buffer)]
+			if msg.ID > lastEventID && filter.Match(msg) {
+				replay = append(replay, msg)
+			}
+		}
+	}
+
+	ch := make(chan Message, subscriberBuffer)
+	sub := &Subscription{C: ch, ch: ch, filter: filter}
+	b.subs[sub] = struct{}{}
+	return sub, replay, nil
+}
+
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	delete(b.subs, sub)
+	b.mu.Unlock()
+	sub.close()
+}
+
+// Close disconnects every subscriber so open streams return and the HTTP
+// server can shut down without waiting for them to time out.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for sub := range b.subs {
+		sub.close()
+	}
+	b.subs = make(map[*Subscription]struct{})
+}
+
+// ParseEventID parses a Last-Event-ID value, returning 0 when absent or invalid.
+func ParseEventID(v string) uint64 {
+	id, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
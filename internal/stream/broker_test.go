@@ -0,0 +1,116 @@
+package stream
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/mohammadshabab/order-food-online/internal/event"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func publish(t *testing.T, b *Broker, typ event.EventType, orderID string) {
+	t.Helper()
+	require.NoError(t, b.Publish(context.Background(), event.Event{Type: typ, OrderID: orderID}))
+}
+
+func TestBroker_PublishAndSubscribe(t *testing.T) {
+	logger.Init("test-service", "test", slog.LevelInfo)
+
+	t.Run("delivers matching live events", func(t *testing.T) {
+		b := NewBroker(10)
+		sub, replay, err := b.Subscribe(Filter{OrderID: "o1"}, 0)
+		require.NoError(t, err)
+		assert.Empty(t, replay)
+
+		publish(t, b, event.EventOrderCreated, "o2")
+		publish(t, b, event.EventOrderCreated, "o1")
+
+		msg := <-sub.C
+		assert.Equal(t, uint64(2), msg.ID)
+		assert.Equal(t, "o1", msg.OrderID)
+		assert.Contains(t, string(msg.Data), `"type":"order.created"`)
+		assert.Empty(t, sub.C)
+	})
+
+	t.Run("filters by event type", func(t *testing.T) {
+		b := NewBroker(10)
+		sub, _, err := b.Subscribe(Filter{Types: map[event.EventType]bool{event.EventOrderCancelled: true}}, 0)
+		require.NoError(t, err)
+
+		publish(t, b, event.EventOrderCreated, "o1")
+		publish(t, b, event.EventOrderCancelled, "o1")
+
+		msg := <-sub.C
+		assert.Equal(t, event.EventOrderCancelled, msg.Type)
+		assert.Empty(t, sub.C)
+	})
+
+	t.Run("replays events after Last-Event-ID", func(t *testing.T) {
+		b := NewBroker(10)
+		for i := 0; i < 5; i++ {
+			publish(t, b, event.EventOrderCreated, "o1")
+		}
+
+		_, replay, err := b.Subscribe(Filter{}, 3)
+		require.NoError(t, err)
+		require.Len(t, replay, 2)
+		assert.Equal(t, uint64(4), replay[0].ID)
+		assert.Equal(t, uint64(5), replay[1].ID)
+	})
+
+	t.Run("replay buffer is bounded", func(t *testing.T) {
+		b := NewBroker(3)
+		for i := 0; i < 7; i++ {
+			publish(t, b, event.EventOrderCreated, "o1")
+		}
+
+		_, replay, err := b.Subscribe(Filter{}, 1)
+		require.NoError(t, err)
+		require.Len(t, replay, 3)
+		assert.Equal(t, uint64(5), replay[0].ID)
+		assert.Equal(t, uint64(7), replay[2].ID)
+	})
+
+	t.Run("slow subscriber is dropped", func(t *testing.T) {
+		b := NewBroker(10)
+		sub, _, err := b.Subscribe(Filter{}, 0)
+		require.NoError(t, err)
+
+		for i := 0; i < subscriberBuffer+1; i++ {
+			publish(t, b, event.EventOrderCreated, "o1")
+		}
+
+		count := 0
+		for range sub.C {
+			count++
+		}
+		assert.Equal(t, subscriberBuffer, count)
+	})
+}
+
+func TestBroker_Close(t *testing.T) {
+	b := NewBroker(10)
+	sub, _, err := b.Subscribe(Filter{}, 0)
+	require.NoError(t, err)
+
+	b.Close()
+	b.Close() // idempotent
+
+	_, ok := <-sub.C
+	assert.False(t, ok, "subscription should be closed")
+
+	assert.ErrorIs(t, b.Publish(context.Background(), event.Event{Type: event.EventOrderCreated}), ErrBrokerClosed)
+	_, _, err = b.Subscribe(Filter{}, 0)
+	assert.ErrorIs(t, err, ErrBrokerClosed)
+
+	assert.NotPanics(t, func() { b.Unsubscribe(sub) })
+}
+
+func TestParseEventID(t *testing.T) {
+	assert.Equal(t, uint64(42), ParseEventID("42"))
+	assert.Equal(t, uint64(0), ParseEventID(""))
+	assert.Equal(t, uint64(0), ParseEventID("abc"))
+}
@@ -0,0 +1,143 @@
+package stream
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/event"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+)
+
+type Handler struct {
+	broker    *Broker
+	heartbeat time.Duration
+}
+
+func NewHandler(broker *Broker, heartbeat time.Duration) *Handler {
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+	return &Handler{broker: broker, heartbeat: heartbeat}
+}
+
+// Stream serves every order event, optionally filtered with ?types=order.created,order.paid
+func (h *Handler) Stream(c echo.Context) error {
+	filter, appErr := parseFilter(c)
+	if appErr != nil {
+		return appErr
+	}
+	return h.serve(c, filter)
+}
+
+// OrderEvents serves the events of a single order.
+func (h *Handler) OrderEvents(c echo.Context) error {
+	id := c.Param("orderId")
+
+	if _, err := uuid.Parse(id); err != nil {
+		return apperrors.BadRequest("invalid ID supplied", err)
+	}
+
+	filter, appErr := parseFilter(c)
+	if appErr != nil {
+		return appErr
+	}
+	filter.OrderID = id
+	return h.serve(c, filter)
+}
+
+func (h *Handler) serve(c echo.Context, filter Filter) error {
+	ctx := c.Request().Context()
+
+	lastID := c.Request().Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = c.QueryParam("lastEventId")
+	}
+
+	sub, replay, err := h.broker.Subscribe(filter, ParseEventID(lastID))
+	if err != nil {
+		return apperrors.Wrap(http.StatusServiceUnavailable, "event stream unavailable", apperrors.LevelWarn, err)
+	}
+	defer h.broker.Unsubscribe(sub)
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	logger.Info(ctx, "event stream opened", "orderId", filter.OrderID, "replayed", len(replay))
+
+	// Tell the client how long to wait before reconnecting
+	fmt.Fprintf(res, "retry: %d\n\n", h.heartbeat.Milliseconds())
+	for _, msg := range replay {
+		if err := writeMessage(res, msg); err != nil {
+			return nil
+		}
+	}
+	res.Flush()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info(ctx, "event stream closed by client")
+			return nil
+		case msg, ok := <-sub.C:
+			if !ok {
+				logger.Info(ctx, "event stream closed by server")
+				return nil
+			}
+			if err := writeMessage(res, msg); err != nil {
+				return nil
+			}
+			res.Flush()
+		case <-ticker.C:
+			if _, err := fmt.Fprint(res, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
+
+func writeMessage(w http.ResponseWriter, msg Message) error {
+	_, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", msg.ID, msg.Type, msg.Data)
+	return err
+}
+
+var knownTypes = map[event.EventType]bool{
+	event.EventOrderCreated:   true,
+	event.EventOrderPaid:      true,
+	event.EventOrderCancelled: true,
+}
+
+func parseFilter(c echo.Context) (Filter, *apperrors.AppError) {
+	var f Filter
+	raw := c.QueryParam("types")
+	if raw == "" {
+		return f, nil
+	}
+
+	f.Types = make(map[event.EventType]bool)
+	for _, t := range strings.Split(raw, ",") {
+		typ := event.EventType(strings.TrimSpace(t))
+		if typ == "" {
+			continue
+		}
+		if !knownTypes[typ] {
+			appErr := apperrors.BadRequest(fmt.Sprintf("unknown event type: %s", typ), nil)
+			logger.Warn(c.Request().Context(), appErr.Message)
+			return f, appErr
+		}
+		f.Types[typ] = true
+	}
+	return f, nil
+}
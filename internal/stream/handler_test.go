@@ -0,0 +1,171 @@
+package stream
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/apperrors"
+	"github.com/mohammadshabab/order-food-online/internal/event"
+	"github.com/mohammadshabab/order-food-online/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const orderID = "3f6b5b2a-7f66-4b3f-9a1b-000000000000"
+
+// waitForSubscribers blocks until the handler has registered with the broker.
+func waitForSubscribers(t *testing.T, b *Broker, n int) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		return len(b.subs) == n
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestHandler_Stream(t *testing.T) {
+	logger.Init("test-service", "test", slog.LevelInfo)
+	e := echo.New()
+
+	t.Run("streams events until client disconnects", func(t *testing.T) {
+		b := NewBroker(10)
+		h := NewHandler(b, time.Hour)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		req := httptest.NewRequest(http.MethodGet, "/events/stream", nil).WithContext(ctx)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		done := make(chan error)
+		go func() { done <- h.Stream(c) }()
+
+		waitForSubscribers(t, b, 1)
+		require.NoError(t, b.Publish(context.Background(), event.Event{Type: event.EventOrderCreated, OrderID: orderID}))
+		require.Eventually(t, func() bool {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			for sub := range b.subs {
+				return len(sub.ch) == 0
+			}
+			return false
+		}, time.Second, 5*time.Millisecond)
+
+		cancel()
+		require.NoError(t, <-done)
+		waitForSubscribers(t, b, 0)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/event-stream", rec.Header().Get(echo.HeaderContentType))
+		assert.Contains(t, rec.Body.String(), "id: 1\nevent: order.created\ndata: {")
+	})
+
+	t.Run("resumes from Last-Event-ID", func(t *testing.T) {
+		b := NewBroker(10)
+		h := NewHandler(b, time.Hour)
+		for i := 0; i < 3; i++ {
+			require.NoError(t, b.Publish(context.Background(), event.Event{Type: event.EventOrderCreated}))
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/events/stream", nil)
+		req.Header.Set("Last-Event-ID", "2")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		done := make(chan error)
+		go func() { done <- h.Stream(c) }()
+		waitForSubscribers(t, b, 1)
+		b.Close()
+		require.NoError(t, <-done)
+
+		assert.NotContains(t, rec.Body.String(), "id: 2\n")
+		assert.Contains(t, rec.Body.String(), "id: 3\n")
+	})
+
+	t.Run("sends heartbeat comments", func(t *testing.T) {
+		b := NewBroker(10)
+		h := NewHandler(b, 10*time.Millisecond)
+
+		req := httptest.NewRequest(http.MethodGet, "/events/stream", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		done := make(chan error)
+		go func() { done <- h.Stream(c) }()
+		waitForSubscribers(t, b, 1)
+		time.Sleep(50 * time.Millisecond)
+		b.Close()
+		require.NoError(t, <-done)
+
+		assert.Contains(t, rec.Body.String(), ": heartbeat\n\n")
+	})
+
+	t.Run("unknown event type", func(t *testing.T) {
+		h := NewHandler(NewBroker(10), time.Hour)
+
+		req := httptest.NewRequest(http.MethodGet, "/events/stream?types=order.shipped", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		apperrors.Handle(h.Stream, c)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("broker shut down", func(t *testing.T) {
+		b := NewBroker(10)
+		b.Close()
+		h := NewHandler(b, time.Hour)
+
+		req := httptest.NewRequest(http.MethodGet, "/events/stream", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		apperrors.Handle(h.Stream, c)
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	})
+}
+
+func TestHandler_OrderEvents(t *testing.T) {
+	logger.Init("test-service", "test", slog.LevelInfo)
+	e := echo.New()
+
+	t.Run("invalid order id", func(t *testing.T) {
+		h := NewHandler(NewBroker(10), time.Hour)
+
+		req := httptest.NewRequest(http.MethodGet, "/order/x1/events", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("orderId")
+		c.SetParamValues("x1")
+
+		apperrors.Handle(h.OrderEvents, c)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("only events of the requested order", func(t *testing.T) {
+		b := NewBroker(10)
+		h := NewHandler(b, time.Hour)
+		require.NoError(t, b.Publish(context.Background(), event.Event{Type: event.EventOrderCreated, OrderID: "other"}))
+		require.NoError(t, b.Publish(context.Background(), event.Event{Type: event.EventOrderCreated, OrderID: orderID}))
+
+		req := httptest.NewRequest(http.MethodGet, "/order/"+orderID+"/events?lastEventId=0", nil)
+		req.Header.Set("Last-Event-ID", "1")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("orderId")
+		c.SetParamValues(orderID)
+
+		done := make(chan error)
+		go func() { done <- h.OrderEvents(c) }()
+		waitForSubscribers(t, b, 1)
+		b.Close()
+		require.NoError(t, <-done)
+
+		assert.Contains(t, rec.Body.String(), "id: 2\n")
+		assert.NotContains(t, rec.Body.String(), "id: 1\n")
+	})
+}
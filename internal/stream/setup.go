@@ -0,0 +1,16 @@
+package stream
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mohammadshabab/order-food-online/internal/auth"
+)
+
+func Setup(e *echo.Echo, broker *Broker, heartbeat time.Duration) {
+	h := NewHandler(broker, heartbeat)
+	read := auth.RequireScope(auth.ScopeOrdersRead)
+
+	e.GET("/events/stream", h.Stream, read)
+	e.GET("/order/:orderId/events", h.OrderEvents, read)
+}
@@ -0,0 +1,32 @@
+package stream
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestSetup(t *testing.T) {
+	t.Run("should register SSE routes", func(t *testing.T) {
+		e := echo.New()
+		Setup(e, NewBroker(10), time.Second)
+
+		want := map[string]bool{
+			"/events/stream":         false,
+			"/order/:orderId/events": false,
+		}
+		for _, r := range e.Routes() {
+			if _, ok := want[r.Path]; ok && r.Method == http.MethodGet {
+				want[r.Path] = true
+			}
+		}
+
+		for path, found := range want {
+			if !found {
+				t.Errorf("expected GET %s to be registered but it was not", path)
+			}
+		}
+	})
+}
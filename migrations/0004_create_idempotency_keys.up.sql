@@ -0,0 +1,21 @@
+-- Idempotency keys belong to the caller that sent them (key:<id> or
+-- user:<subject>, empty without credentials), so two callers using the same
+-- key get their own responses. In progress reservations hold until
+-- locked_until; a retry after that takes the key over under a new lease_id,
+-- and only the request holding the current lease_id may store its outcome.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+  actor VARCHAR(150) NOT NULL DEFAULT '',
+  idempotency_key VARCHAR(255) NOT NULL,
+  fingerprint CHAR(64) NOT NULL,
+  lease_id CHAR(36) NOT NULL,
+  status VARCHAR(20) NOT NULL,
+  locked_until TIMESTAMP NOT NULL,
+  response_code INT,
+  content_type VARCHAR(255),
+  response_body MEDIUMBLOB,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  expires_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (actor, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
@@ -0,0 +1,8 @@
+-- Columns for product admin: optimistic concurrency and soft delete.
+-- Archived products stay in the table so order_items foreign keys remain valid.
+ALTER TABLE products
+  ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1,
+  ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NULL DEFAULT NULL,
+  ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP NULL DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_products_archived_at ON products(archived_at);
@@ -0,0 +1,2 @@
+ALTER TABLE products
+  ADD COLUMN IF NOT EXISTS description TEXT NULL;
@@ -0,0 +1,41 @@
+-- Modifier groups (size, toppings, extras) attached to products.
+-- Orders snapshot the selected modifiers, so groups can be replaced freely
+-- without touching order history.
+CREATE TABLE IF NOT EXISTS modifier_groups (
+  id CHAR(36) PRIMARY KEY,
+  product_id CHAR(36) NOT NULL,
+  name VARCHAR(100) NOT NULL,
+  min_select INT NOT NULL DEFAULT 0,
+  max_select INT NOT NULL DEFAULT 1,
+  position INT NOT NULL DEFAULT 0,
+  CONSTRAINT fk_modifier_group_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_modifier_groups_product_id ON modifier_groups(product_id);
+
+CREATE TABLE IF NOT EXISTS modifiers (
+  id CHAR(36) PRIMARY KEY,
+  group_id CHAR(36) NOT NULL,
+  name VARCHAR(100) NOT NULL,
+  price_delta DECIMAL(12,2) NOT NULL DEFAULT 0,
+  position INT NOT NULL DEFAULT 0,
+  CONSTRAINT fk_modifier_group FOREIGN KEY (group_id) REFERENCES modifier_groups(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_modifiers_group_id ON modifiers(group_id);
+
+ALTER TABLE order_items
+  ADD COLUMN IF NOT EXISTS unit_price DECIMAL(12,2) NULL,
+  ADD COLUMN IF NOT EXISTS line_total DECIMAL(12,2) NULL;
+
+CREATE TABLE IF NOT EXISTS order_item_modifiers (
+  id CHAR(36) PRIMARY KEY,
+  order_item_id CHAR(36) NOT NULL,
+  modifier_id CHAR(36) NOT NULL,
+  group_name VARCHAR(100) NOT NULL,
+  name VARCHAR(100) NOT NULL,
+  price_delta DECIMAL(12,2) NOT NULL,
+  CONSTRAINT fk_order_item_modifier_item FOREIGN KEY (order_item_id) REFERENCES order_items(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_item_modifiers_item_id ON order_item_modifiers(order_item_id);
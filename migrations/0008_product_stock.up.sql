@@ -0,0 +1,14 @@
+-- Availability and optional stock counts. A NULL stock means the product is not
+-- stock tracked and can always be ordered while it is available.
+ALTER TABLE products
+  ADD COLUMN IF NOT EXISTS available BOOLEAN NOT NULL DEFAULT TRUE,
+  ADD COLUMN IF NOT EXISTS stock INT NULL DEFAULT NULL;
+
+-- Order status for cancellation, and which items took stock so a cancel
+-- restores exactly what was decremented.
+ALTER TABLE orders
+  ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'placed',
+  ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMP NULL DEFAULT NULL;
+
+ALTER TABLE order_items
+  ADD COLUMN IF NOT EXISTS stock_reserved BOOLEAN NOT NULL DEFAULT FALSE;
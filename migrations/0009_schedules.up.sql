@@ -0,0 +1,15 @@
+-- Opening hours (target_type 'restaurant') and menu schedules for products and
+-- categories, one row per recurring window. Times are HH:MM in the schedule
+-- time zone; days is a comma separated list of mon..sun, empty for every day.
+CREATE TABLE IF NOT EXISTS schedule_windows (
+  id BIGINT AUTO_INCREMENT PRIMARY KEY,
+  target_type VARCHAR(20) NOT NULL,
+  target VARCHAR(100) NOT NULL DEFAULT '',
+  timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+  days VARCHAR(27) NOT NULL DEFAULT '',
+  start_time CHAR(5) NOT NULL,
+  end_time CHAR(5) NOT NULL,
+  position INT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_schedule_windows_target ON schedule_windows(target_type, target);
@@ -0,0 +1,41 @@
+-- Categories become their own table; products reference them by id.
+CREATE TABLE IF NOT EXISTS categories (
+  id CHAR(36) PRIMARY KEY,
+  slug VARCHAR(100) NOT NULL,
+  name VARCHAR(100) NOT NULL,
+  sort_order INT NOT NULL DEFAULT 0,
+  parent_id CHAR(36) NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE KEY uq_categories_slug (slug),
+  CONSTRAINT fk_categories_parent FOREIGN KEY (parent_id) REFERENCES categories(id)
+);
+
+-- One category per distinct free text value, ignoring case and surrounding
+-- spaces, so 'Waffle' and 'waffle' end up in the same category. The display
+-- name is capitalised: 'pizza' becomes 'Pizza'.
+INSERT IGNORE INTO categories (id, slug, name)
+SELECT UUID(), slug, CONCAT(UPPER(LEFT(label, 1)), LOWER(SUBSTRING(label, 2)))
+FROM (
+  SELECT LOWER(REPLACE(TRIM(category), ' ', '-')) AS slug, MIN(TRIM(category)) AS label
+  FROM products
+  WHERE TRIM(COALESCE(category, '')) <> ''
+  GROUP BY LOWER(REPLACE(TRIM(category), ' ', '-'))
+) AS legacy;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS category_id CHAR(36) NULL;
+
+UPDATE products p
+JOIN categories c ON c.slug = LOWER(REPLACE(TRIM(p.category), ' ', '-'))
+SET p.category_id = c.id
+WHERE p.category_id IS NULL;
+
+ALTER TABLE products ADD CONSTRAINT fk_products_category FOREIGN KEY IF NOT EXISTS (category_id) REFERENCES categories(id);
+
+-- Category schedules were keyed by the free text category, move them to the id
+UPDATE schedule_windows s
+JOIN categories c ON c.slug = LOWER(REPLACE(TRIM(s.target), ' ', '-'))
+SET s.target = c.id
+WHERE s.target_type = 'category';
+
+-- products.category stays because earlier migrations (the seed data) still
+-- write it; the application no longer reads it.
@@ -0,0 +1,47 @@
+-- Allergens, dietary flags and nutrition facts of products.
+-- Allergens and flags are codes from fixed lists checked by the API
+-- (product.Allergens and product.DietaryFlags), one row per product and code.
+CREATE TABLE IF NOT EXISTS product_allergens (
+  product_id CHAR(36) NOT NULL,
+  allergen VARCHAR(32) NOT NULL,
+  PRIMARY KEY (product_id, allergen),
+  CONSTRAINT fk_product_allergen_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_allergens_allergen ON product_allergens(allergen);
+
+CREATE TABLE IF NOT EXISTS product_dietary_flags (
+  product_id CHAR(36) NOT NULL,
+  flag VARCHAR(32) NOT NULL,
+  PRIMARY KEY (product_id, flag),
+  CONSTRAINT fk_product_dietary_flag_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_dietary_flags_flag ON product_dietary_flags(flag);
+
+-- Nutrition facts per serving, every value is optional
+CREATE TABLE IF NOT EXISTS product_nutrition (
+  product_id CHAR(36) PRIMARY KEY,
+  serving_size_g DECIMAL(8,2) NULL,
+  energy_kcal DECIMAL(8,2) NULL,
+  fat_g DECIMAL(8,2) NULL,
+  saturated_fat_g DECIMAL(8,2) NULL,
+  carbohydrates_g DECIMAL(8,2) NULL,
+  sugars_g DECIMAL(8,2) NULL,
+  fibre_g DECIMAL(8,2) NULL,
+  protein_g DECIMAL(8,2) NULL,
+  salt_g DECIMAL(8,2) NULL,
+  CONSTRAINT fk_product_nutrition_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+-- When the allergens of a product were declared. Products without a
+-- declaration may contain any allergen, so allergen filters leave them out.
+-- Products with stored dietary information were declared through the API.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS allergens_declared_at DATETIME NULL;
+
+UPDATE products SET allergens_declared_at = UTC_TIMESTAMP()
+WHERE allergens_declared_at IS NULL AND (
+  id IN (SELECT product_id FROM product_allergens) OR
+  id IN (SELECT product_id FROM product_dietary_flags) OR
+  id IN (SELECT product_id FROM product_nutrition)
+);
@@ -0,0 +1,46 @@
+-- Price history of products. Every price a product had or will have is a row
+-- effective from its timestamp (UTC) until the next row; rows in the future are
+-- scheduled changes. products.price keeps the current price for listings and is
+-- moved forward by the API when a scheduled change comes due.
+CREATE TABLE IF NOT EXISTS product_prices (
+  id CHAR(36) PRIMARY KEY,
+  product_id CHAR(36) NOT NULL,
+  price DECIMAL(12,2) NOT NULL,
+  effective_from DATETIME(6) NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT fk_product_price_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_prices_effective ON product_prices(product_id, effective_from);
+
+-- Products without history start with their current price, effective since
+-- they were created. Products added by seed migrations get theirs on the next run.
+INSERT INTO product_prices (id, product_id, price, effective_from)
+SELECT UUID(), p.id, p.price, COALESCE(p.created_at, UTC_TIMESTAMP(6))
+FROM products p
+WHERE NOT EXISTS (SELECT 1 FROM product_prices pp WHERE pp.product_id = p.id);
+
+-- Happy hour rules: a percentage off the price of one product, one category or
+-- (with neither set) the whole menu while any of the rule windows is on.
+-- Windows use the same format as schedule_windows.
+CREATE TABLE IF NOT EXISTS price_rules (
+  id CHAR(36) PRIMARY KEY,
+  name VARCHAR(100) NOT NULL,
+  product_id CHAR(36) NULL,
+  category_id CHAR(36) NULL,
+  percent_off DECIMAL(5,2) NOT NULL,
+  timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT fk_price_rule_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+  CONSTRAINT fk_price_rule_category FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS price_rule_windows (
+  rule_id CHAR(36) NOT NULL,
+  position INT NOT NULL,
+  days VARCHAR(27) NOT NULL DEFAULT '',
+  start_time CHAR(5) NOT NULL,
+  end_time CHAR(5) NOT NULL,
+  PRIMARY KEY (rule_id, position),
+  CONSTRAINT fk_price_rule_window_rule FOREIGN KEY (rule_id) REFERENCES price_rules(id) ON DELETE CASCADE
+);
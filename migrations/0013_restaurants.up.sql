@@ -0,0 +1,39 @@
+-- Restaurants (vendors) hosted on the platform. Products and orders belong to
+-- one restaurant; everything created before there were several belongs to the
+-- default restaurant, which is also used when a request names none.
+CREATE TABLE IF NOT EXISTS restaurants (
+  id CHAR(36) PRIMARY KEY,
+  slug VARCHAR(100) NOT NULL,
+  name VARCHAR(100) NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE KEY uq_restaurants_slug (slug)
+);
+
+-- Keep in sync with defaults.RestaurantID (internal/restaurant/defaults)
+INSERT IGNORE INTO restaurants (id, slug, name)
+VALUES ('00000000-0000-4000-8000-000000000001', 'default', 'Default restaurant');
+
+-- Opening hours are kept per restaurant: the hours stored before restaurants
+-- existed belong to the default restaurant
+UPDATE schedule_windows SET target = '00000000-0000-4000-8000-000000000001'
+WHERE target_type = 'restaurant' AND target = '';
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS restaurant_id CHAR(36) NOT NULL DEFAULT '00000000-0000-4000-8000-000000000001';
+ALTER TABLE products ADD CONSTRAINT fk_products_restaurant FOREIGN KEY IF NOT EXISTS (restaurant_id) REFERENCES restaurants(id);
+CREATE INDEX IF NOT EXISTS idx_products_restaurant ON products(restaurant_id);
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS restaurant_id CHAR(36) NOT NULL DEFAULT '00000000-0000-4000-8000-000000000001';
+ALTER TABLE orders ADD CONSTRAINT fk_orders_restaurant FOREIGN KEY IF NOT EXISTS (restaurant_id) REFERENCES restaurants(id);
+CREATE INDEX IF NOT EXISTS idx_orders_restaurant ON orders(restaurant_id);
+
+-- Coupons are platform wide unless scoped: a coupon with rows here is only
+-- valid for orders from those restaurants.
+CREATE TABLE IF NOT EXISTS coupon_scopes (
+  code VARCHAR(100) NOT NULL,
+  restaurant_id CHAR(36) NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (code, restaurant_id),
+  CONSTRAINT fk_coupon_scope_restaurant FOREIGN KEY (restaurant_id) REFERENCES restaurants(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_coupon_scopes_restaurant ON coupon_scopes(restaurant_id);
@@ -0,0 +1,17 @@
+-- API keys. Only a salted SHA-256 of the secret part is stored; prefix is the
+-- public part that finds the key. scopes is a comma separated list, see the
+-- auth package for the known scopes; cancelling orders takes orders:cancel,
+-- orders:write does not include it. Revoked keys are kept for the audit trail.
+CREATE TABLE IF NOT EXISTS api_keys (
+  id CHAR(36) PRIMARY KEY,
+  name VARCHAR(100) NOT NULL,
+  prefix CHAR(12) NOT NULL,
+  salt CHAR(32) NOT NULL,
+  hash CHAR(64) NOT NULL,
+  scopes VARCHAR(255) NOT NULL,
+  expires_at DATETIME(6) NULL,
+  last_used_at DATETIME(6) NULL,
+  revoked_at DATETIME(6) NULL,
+  created_at DATETIME(6) NOT NULL,
+  UNIQUE KEY uq_api_keys_prefix (prefix)
+);
@@ -0,0 +1,25 @@
+-- Audit log of admin changes: who made them, from which request, and the
+-- state of the resource before and after as JSON. actor is key:<id>,
+-- key:bootstrap or user:<subject>. Rows are only ever appended, the triggers
+-- below reject updates and deletes.
+CREATE TABLE IF NOT EXISTS audit_log (
+  id BIGINT AUTO_INCREMENT PRIMARY KEY,
+  occurred_at DATETIME(6) NOT NULL,
+  actor VARCHAR(150) NOT NULL,
+  actor_name VARCHAR(150) NOT NULL DEFAULT '',
+  action VARCHAR(100) NOT NULL,
+  resource VARCHAR(50) NOT NULL,
+  resource_id VARCHAR(100) NOT NULL DEFAULT '',
+  request_id VARCHAR(128) NOT NULL DEFAULT '',
+  before_state JSON NULL,
+  after_state JSON NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_resource ON audit_log(resource, resource_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor);
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_update BEFORE UPDATE ON audit_log
+FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_log is append-only';
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_delete BEFORE DELETE ON audit_log
+FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_log is append-only';